@@ -488,6 +488,51 @@ func setupMerchantRoutes(router fiber.Router, service ServiceConfig) {
 		return proxyRequestWithPath(c, service.URL, "/api/v1/merchant-products")
 	})
 
+	transferOrderGroup := router.Group("/transfer-orders")
+	transferOrderGroup.All("/*", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/transfer-orders")
+	})
+
+	transferOrderGroup.All("/", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/transfer-orders")
+	})
+
+	replenishmentGroup := router.Group("/replenishments")
+	replenishmentGroup.All("/*", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/replenishments")
+	})
+
+	replenishmentGroup.All("/", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/replenishments")
+	})
+
+	stockReturnGroup := router.Group("/stock-returns")
+	stockReturnGroup.All("/*", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/stock-returns")
+	})
+
+	stockReturnGroup.All("/", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/stock-returns")
+	})
+
+	stocktakeGroup := router.Group("/stocktakes")
+	stocktakeGroup.All("/*", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/stocktakes")
+	})
+
+	stocktakeGroup.All("/", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/stocktakes")
+	})
+
+	barcodeRuleGroup := router.Group("/barcode-rules")
+	barcodeRuleGroup.All("/*", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/barcode-rules")
+	})
+
+	barcodeRuleGroup.All("/", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/barcode-rules")
+	})
+
 	uploadGroup := router.Group("/upload-merchant")
 	uploadGroup.All("/*", func(c *fiber.Ctx) error {
 		return proxyRequest(c, service.URL)
@@ -518,6 +563,28 @@ func setupTransactionRoutes(router fiber.Router, service ServiceConfig) {
 	dashboardGroup.All("/", func(c *fiber.Ctx) error {
 		return proxyRequestWithPath(c, service.URL, "/api/v1/dashboard")
 	})
+
+	accountingGroup := router.Group("/accounting")
+
+	accountingGroup.All("/*", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/accounting")
+	})
+
+	accountingGroup.All("/", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/accounting")
+	})
+
+	riskGroup := router.Group("/risk")
+
+	riskGroup.All("/*", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/risk")
+	})
+
+	paymentsGroup := router.Group("/payments")
+
+	paymentsGroup.All("/*", func(c *fiber.Ctx) error {
+		return proxyRequestWithPath(c, service.URL, "/api/v1/payments")
+	})
 }
 
 func setupWarehouseRoutes(router fiber.Router, service ServiceConfig) {
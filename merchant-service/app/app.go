@@ -50,7 +50,7 @@ func RunServer() {
 	}
 
 	merchantProductRepo := repository.NewMerchantProductRepository(db.DB)
-	stockConsumer, err := rabbitmq.NewStockConsumer(cfg.RabbitMQ.URL(), merchantProductRepo)
+	stockConsumer, err := rabbitmq.NewStockConsumer(cfg.RabbitMQ.URL(), merchantProductRepo, container.StockAlertUsecase, cfg.StockConsumer)
 	if err != nil {
 		log.Fatalf("Failed to create stock consumer: %v", err)
 	} else {
@@ -62,6 +62,24 @@ func RunServer() {
 		}()
 	}
 
+	go func() {
+		if err := container.CacheInvalidationConsumer.Consume(context.Background()); err != nil {
+			log.Errorf("Failed to consume cache invalidation events: %v", err)
+		}
+	}()
+
+	go func() {
+		if err := container.ProductProjectionConsumer.Consume(context.Background()); err != nil {
+			log.Errorf("Failed to consume product projection events: %v", err)
+		}
+	}()
+
+	go func() {
+		if err := container.AllocationRejectedConsumer.Consume(context.Background()); err != nil {
+			log.Errorf("Failed to consume allocation rejected events: %v", err)
+		}
+	}()
+
 	port := cfg.App.AppPort
 	if port == "" {
 		port = os.Getenv("APP_PORT")
@@ -15,9 +15,29 @@ import (
 )
 
 type Container struct {
-	MerchantController        controller.MerchantControllerInterface
-	MerchantProductController controller.MerchantProductControllerInterface
-	UploadController          controller.UploadControllerInterface
+	MerchantController         controller.MerchantControllerInterface
+	MerchantProductController  controller.MerchantProductControllerInterface
+	UploadController           controller.UploadControllerInterface
+	TransferOrderController    controller.TransferOrderControllerInterface
+	ReplenishmentController    controller.ReplenishmentControllerInterface
+	StockReturnController      controller.StockReturnControllerInterface
+	StocktakeController        controller.StocktakeControllerInterface
+	MerchantStaffController    controller.MerchantStaffControllerInterface
+	MerchantScheduleController controller.MerchantScheduleControllerInterface
+	BarcodeRuleController      controller.BarcodeRuleControllerInterface
+	AvailabilityController     controller.AvailabilityControllerInterface
+	MerchantLocationController controller.MerchantLocationControllerInterface
+	DeliveryZoneController     controller.MerchantDeliveryZoneControllerInterface
+	CacheController            controller.CacheControllerInterface
+
+	CacheInvalidationConsumer  *rabbitmq.CacheInvalidationConsumer
+	ProductProjectionConsumer  *rabbitmq.ProductProjectionConsumer
+	AllocationRejectedConsumer *rabbitmq.AllocationRejectedConsumer
+
+	StockAlertUsecase        usecase.StockAlertUsecaseInterface
+	ReplenishmentUsecase     usecase.ReplenishmentUsecaseInterface
+	ProductProjectionUsecase usecase.ProductProjectionUsecaseInterface
+	TrashUsecase             usecase.TrashUsecaseInterface
 }
 
 func BuildContainer() *Container {
@@ -34,28 +54,107 @@ func BuildContainer() *Container {
 		log.Fatalf("Failed to connect to rabbitmq: %v", err)
 	}
 
+	cacheMetrics := httpclient.NewCacheMetrics()
 	userClient := httpclient.NewUserClient(*cfg)
-	cachedUserClient := httpclient.NewCachedUserClient(userClient, redisClient)
+	cachedUserClient := httpclient.NewCachedUserClient(userClient, redisClient, cacheMetrics)
 	warehouseClient := httpclient.NewWarehouseClient(*cfg)
-	cachedWarehouseClient := httpclient.NewCachedWarehouseClient(warehouseClient, redisClient)
+	cachedWarehouseClient := httpclient.NewCachedWarehouseClient(warehouseClient, redisClient, cacheMetrics)
 	productClient := httpclient.NewProductClient(*cfg)
-	cachedProductClient := httpclient.NewCachedProductClient(productClient, redisClient)
+	cachedProductClient := httpclient.NewCachedProductClient(productClient, redisClient, cacheMetrics)
+	transactionClient := httpclient.NewTransactionClient(*cfg)
+	cacheController := controller.NewCacheController(cacheMetrics)
+
+	cacheInvalidationConsumer, err := rabbitmq.NewCacheInvalidationConsumer(cfg.RabbitMQ.URL(), cachedProductClient, cachedUserClient, cachedWarehouseClient)
+	if err != nil {
+		log.Fatalf("Failed to create cache invalidation consumer: %v", err)
+	}
+
+	productProjectionRepo := repository.NewProductProjectionRepository(db.DB)
+	productProjectionUsecase := usecase.NewProductProjectionUsecase(productProjectionRepo, productClient)
+	productProjectionConsumer, err := rabbitmq.NewProductProjectionConsumer(cfg.RabbitMQ.URL(), productProjectionRepo)
+	if err != nil {
+		log.Fatalf("Failed to create product projection consumer: %v", err)
+	}
 
 	merchantRepo := repository.NewMerchantRepository(db.DB)
-	merchantUsecase := usecase.NewMerchantUsecase(merchantRepo, cachedUserClient, cachedWarehouseClient, cachedProductClient)
+	merchantProductRepo := repository.NewMerchantProductRepository(db.DB)
+	allocationRejectedConsumer, err := rabbitmq.NewAllocationRejectedConsumer(cfg.RabbitMQ.URL(), merchantProductRepo)
+	if err != nil {
+		log.Fatalf("Failed to create allocation rejected consumer: %v", err)
+	}
+	merchantUsecase := usecase.NewMerchantUsecase(merchantRepo, merchantProductRepo, cachedUserClient, cachedWarehouseClient, cachedProductClient, transactionClient)
 	merchantController := controller.NewMerchantController(merchantUsecase)
 
-	merchantProductRepo := repository.NewMerchantProductRepository(db.DB)
-	merchantProductUsecase := usecase.NewMerchantProductUsecase(merchantProductRepo, cachedProductClient, cachedWarehouseClient, rabbitMQService)
+	trashUsecase := usecase.NewTrashUsecase(merchantRepo, merchantProductRepo)
+
+	merchantStaffRepo := repository.NewMerchantStaffRepository(db.DB)
+	merchantStaffUsecase := usecase.NewMerchantStaffUsecase(merchantStaffRepo, merchantRepo, cachedUserClient)
+	merchantStaffController := controller.NewMerchantStaffController(merchantStaffUsecase)
+
+	merchantScheduleRepo := repository.NewMerchantScheduleRepository(db.DB)
+	merchantScheduleUsecase := usecase.NewMerchantScheduleUsecase(merchantScheduleRepo, merchantRepo)
+	merchantScheduleController := controller.NewMerchantScheduleController(merchantScheduleUsecase)
+
+	merchantLocationUsecase := usecase.NewMerchantLocationUsecase(merchantRepo, cachedWarehouseClient)
+	merchantLocationController := controller.NewMerchantLocationController(merchantLocationUsecase)
+
+	deliveryZoneRepo := repository.NewMerchantDeliveryZoneRepository(db.DB)
+	deliveryZoneUsecase := usecase.NewMerchantDeliveryZoneUsecase(deliveryZoneRepo)
+	deliveryZoneController := controller.NewMerchantDeliveryZoneController(deliveryZoneUsecase)
+
+	barcodeRuleRepo := repository.NewBarcodeRuleRepository(db.DB)
+	barcodeRuleUsecase := usecase.NewBarcodeRuleUsecase(barcodeRuleRepo)
+	barcodeRuleController := controller.NewBarcodeRuleController(barcodeRuleUsecase)
+
+	stockAlertUsecase := usecase.NewStockAlertUsecase(merchantProductRepo, merchantRepo, cachedUserClient, cachedProductClient, rabbitMQService)
+	merchantProductPriceRepo := repository.NewMerchantProductPriceRepository(db.DB)
+	merchantProductUsecase := usecase.NewMerchantProductUsecase(merchantProductRepo, merchantProductPriceRepo, barcodeRuleRepo, cachedProductClient, cachedWarehouseClient, rabbitMQService, stockAlertUsecase)
 	merchantProductController := controller.NewMerchantProductController(merchantProductUsecase)
 
+	availabilityUsecase := usecase.NewAvailabilityUsecase(merchantProductRepo, merchantRepo, barcodeRuleRepo, cachedProductClient, cachedWarehouseClient)
+	availabilityController := controller.NewAvailabilityController(availabilityUsecase)
+
+	transferOrderRepo := repository.NewTransferOrderRepository(db.DB)
+	transferOrderUsecase := usecase.NewTransferOrderUsecase(transferOrderRepo, merchantRepo, cachedWarehouseClient)
+	transferOrderController := controller.NewTransferOrderController(transferOrderUsecase)
+
+	replenishmentOrderRepo := repository.NewReplenishmentOrderRepository(db.DB)
+	replenishmentUsecase := usecase.NewReplenishmentUsecase(merchantProductRepo, replenishmentOrderRepo, merchantRepo, cachedWarehouseClient, merchantProductUsecase)
+	replenishmentController := controller.NewReplenishmentController(replenishmentUsecase)
+
+	stockReturnRepo := repository.NewStockReturnRepository(db.DB)
+	stockReturnUsecase := usecase.NewStockReturnUsecase(stockReturnRepo, merchantRepo, rabbitMQService, stockAlertUsecase)
+	stockReturnController := controller.NewStockReturnController(stockReturnUsecase)
+
+	stocktakeRepo := repository.NewStocktakeRepository(db.DB)
+	stocktakeUsecase := usecase.NewStocktakeUsecase(stocktakeRepo, merchantRepo, cachedProductClient, stockAlertUsecase)
+	stocktakeController := controller.NewStocktakeController(stocktakeUsecase)
+
 	supabaseStorage := storage.NewSupabaseStorage(*cfg)
 	fileUploadHelper := storage.NewFileUploadHelper(supabaseStorage, *cfg)
 	uploadController := controller.NewUploadController(fileUploadHelper)
 
 	return &Container{
-		MerchantController:        merchantController,
-		MerchantProductController: merchantProductController,
-		UploadController:          uploadController,
+		MerchantController:         merchantController,
+		MerchantProductController:  merchantProductController,
+		UploadController:           uploadController,
+		TransferOrderController:    transferOrderController,
+		ReplenishmentController:    replenishmentController,
+		StockReturnController:      stockReturnController,
+		StocktakeController:        stocktakeController,
+		MerchantStaffController:    merchantStaffController,
+		MerchantScheduleController: merchantScheduleController,
+		BarcodeRuleController:      barcodeRuleController,
+		AvailabilityController:     availabilityController,
+		MerchantLocationController: merchantLocationController,
+		DeliveryZoneController:     deliveryZoneController,
+		CacheController:            cacheController,
+		CacheInvalidationConsumer:  cacheInvalidationConsumer,
+		ProductProjectionConsumer:  productProjectionConsumer,
+		AllocationRejectedConsumer: allocationRejectedConsumer,
+		StockAlertUsecase:          stockAlertUsecase,
+		ReplenishmentUsecase:       replenishmentUsecase,
+		ProductProjectionUsecase:   productProjectionUsecase,
+		TrashUsecase:               trashUsecase,
 	}
 }
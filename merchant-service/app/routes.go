@@ -1,6 +1,11 @@
 package app
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"micro-warehouse/merchant-service/middleware"
+	"micro-warehouse/merchant-service/pkg/authz"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func SetupRoutes(app *fiber.App, c *Container) {
 	api := app.Group("/api/v1")
@@ -8,19 +13,95 @@ func SetupRoutes(app *fiber.App, c *Container) {
 	merchants := api.Group("/merchants")
 	merchants.Post("/", c.MerchantController.CreateMerchant)
 	merchants.Get("/", c.MerchantController.GetAllMerchants)
+	merchants.Get("/cache/metrics", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.CacheController.GetCacheMetrics)
+	merchants.Get("/trash", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantController.GetDeletedMerchants)
+	merchants.Get("/nearby", c.MerchantLocationController.GetNearbyMerchants)
+	merchants.Get("/delivery-route", c.DeliveryZoneController.RouteDelivery)
 	merchants.Get("/:id", c.MerchantController.GetMerchantByID)
 	merchants.Put("/:id", c.MerchantController.UpdateMerchant)
 	merchants.Delete("/:id", c.MerchantController.DeleteMerchant)
+	merchants.Get("/:id/staff", middleware.UserContext(), c.MerchantStaffController.GetStaff)
+	merchants.Post("/:id/staff", middleware.UserContext(), c.MerchantStaffController.AddStaff)
+	merchants.Put("/:id/staff/:staff_id", middleware.UserContext(), c.MerchantStaffController.UpdateStaff)
+	merchants.Delete("/:id/staff/:staff_id", middleware.UserContext(), c.MerchantStaffController.EndStaff)
+	merchants.Put("/:id/status", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantController.UpdateMerchantStatus)
+	merchants.Post("/:id/archive", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantController.ArchiveMerchant)
+	merchants.Post("/:id/unarchive", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantController.UnarchiveMerchant)
+	merchants.Post("/:id/restore", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantController.RestoreMerchant)
+	merchants.Get("/:id/opening-hours", c.MerchantScheduleController.GetSchedule)
+	merchants.Put("/:id/opening-hours", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantScheduleController.ReplaceOpeningHours)
+	merchants.Get("/:id/closures", c.MerchantScheduleController.GetClosures)
+	merchants.Post("/:id/closures", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantScheduleController.CreateClosure)
+	merchants.Delete("/:id/closures/:closure_id", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantScheduleController.DeleteClosure)
+	merchants.Get("/:id/nearest-warehouses", c.MerchantLocationController.GetNearestWarehouses)
+	merchants.Get("/:id/delivery-zones", c.DeliveryZoneController.GetDeliveryZones)
+	merchants.Post("/:id/delivery-zones", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.DeliveryZoneController.CreateDeliveryZone)
+	merchants.Put("/:id/delivery-zones/:zone_id", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.DeliveryZoneController.UpdateDeliveryZone)
+	merchants.Delete("/:id/delivery-zones/:zone_id", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.DeliveryZoneController.DeleteDeliveryZone)
 
 	merchantProducts := api.Group("/merchant-products")
 	merchantProducts.Post("/", c.MerchantProductController.CreateMerchantProduct)
+	merchantProducts.Get("/low-stock", c.MerchantProductController.GetLowStockMerchantProducts)
+	merchantProducts.Get("/availability", c.AvailabilityController.GetProductAvailability)
+	merchantProducts.Get("/trash", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantProductController.GetDeletedMerchantProducts)
+	merchantProducts.Get("/allocation-changes", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantProductController.GetAllocationChanges)
+	merchantProducts.Post("/allocation-changes/:id/republish", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantProductController.RepublishAllocationChange)
+	merchantProducts.Get("/warehouses/:warehouse_id/allocations", middleware.InternalOnly(), c.MerchantProductController.GetWarehouseAllocations)
 	merchantProducts.Get("/:merchant_product_id", c.MerchantProductController.GetMerchantProductByID)
 	merchantProducts.Get("/", c.MerchantProductController.GetMerchantProducts)
 	merchantProducts.Get("/barcode/:barcode", c.MerchantProductController.GetMerchantProductByBarcode)
+	merchantProducts.Post("/barcodes/resolve", c.MerchantProductController.ResolveBarcodes)
 	merchantProducts.Put("/:merchant_product_id", c.MerchantProductController.UpdateMerchantProduct)
 	merchantProducts.Delete("/:merchant_product_id", c.MerchantProductController.DeleteMerchantProduct)
+	merchantProducts.Post("/:merchant_product_id/restore", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantProductController.RestoreMerchantProduct)
 	merchantProducts.Delete("/product/:product_id", c.MerchantProductController.DeleteAllProductMerchantProducts)
 	merchantProducts.Get("/:product_id/total-stock", c.MerchantProductController.GetProductTotalStock)
+	merchantProducts.Get("/:merchant_product_id/movements", c.MerchantProductController.GetStockMovements)
+	merchantProducts.Post("/:merchant_product_id/movements", c.MerchantProductController.RecordStockMovement)
+	merchantProducts.Put("/:merchant_product_id/min-stock", c.MerchantProductController.UpdateMinStock)
+	merchantProducts.Put("/:merchant_product_id/stock-levels", c.MerchantProductController.UpdateStockLevels)
+	merchantProducts.Get("/:merchant_product_id/prices", c.MerchantProductController.GetPrices)
+	merchantProducts.Post("/:merchant_product_id/prices", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantProductController.SetPrice)
+	merchantProducts.Delete("/:merchant_product_id/prices/:price_id", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.MerchantProductController.DeleteScheduledPrice)
+
+	transferOrders := api.Group("/transfer-orders", middleware.UserContext())
+	transferOrders.Post("/", c.TransferOrderController.CreateTransferOrder)
+	transferOrders.Get("/", c.TransferOrderController.GetTransferOrders)
+	transferOrders.Get("/:id", c.TransferOrderController.GetTransferOrderByID)
+	transferOrders.Post("/:id/approve", middleware.RequireRole(authz.RoleManager), c.TransferOrderController.ApproveTransferOrder)
+	transferOrders.Post("/:id/reject", middleware.RequireRole(authz.RoleManager), c.TransferOrderController.RejectTransferOrder)
+	transferOrders.Post("/:id/cancel", c.TransferOrderController.CancelTransferOrder)
+	transferOrders.Post("/:id/pick", middleware.RequireRole(authz.RoleManager), c.TransferOrderController.PickTransferOrder)
+	transferOrders.Post("/:id/ship", middleware.RequireRole(authz.RoleManager), c.TransferOrderController.ShipTransferOrder)
+	transferOrders.Post("/:id/receipts", c.TransferOrderController.ReceiveTransferOrder)
+
+	replenishments := api.Group("/replenishments", middleware.UserContext())
+	replenishments.Get("/suggestions", c.ReplenishmentController.GetSuggestions)
+	replenishments.Post("/generate", middleware.RequireRole(authz.RoleManager), c.ReplenishmentController.GenerateReplenishmentOrders)
+	replenishments.Get("/", c.ReplenishmentController.GetReplenishmentOrders)
+	replenishments.Get("/:id", c.ReplenishmentController.GetReplenishmentOrderByID)
+	replenishments.Post("/:id/approve", middleware.RequireRole(authz.RoleManager), c.ReplenishmentController.ApproveReplenishmentOrder)
+	replenishments.Post("/:id/reject", middleware.RequireRole(authz.RoleManager), c.ReplenishmentController.RejectReplenishmentOrder)
+
+	stockReturns := api.Group("/stock-returns", middleware.UserContext())
+	stockReturns.Post("/", c.StockReturnController.CreateStockReturn)
+	stockReturns.Get("/", c.StockReturnController.GetStockReturns)
+	stockReturns.Get("/:id", c.StockReturnController.GetStockReturnByID)
+	stockReturns.Post("/:id/republish", middleware.RequireRole(authz.RoleManager), c.StockReturnController.RepublishStockReturn)
+
+	stocktakes := api.Group("/stocktakes", middleware.UserContext())
+	stocktakes.Post("/", c.StocktakeController.OpenStocktake)
+	stocktakes.Get("/", c.StocktakeController.GetStocktakes)
+	stocktakes.Get("/:id", c.StocktakeController.GetStocktakeByID)
+	stocktakes.Post("/:id/counts", c.StocktakeController.RecordCount)
+	stocktakes.Post("/:id/approve", middleware.RequireRole(authz.RoleManager), c.StocktakeController.ApproveStocktake)
+	stocktakes.Post("/:id/cancel", c.StocktakeController.CancelStocktake)
+
+	barcodeRules := api.Group("/barcode-rules", middleware.UserContext())
+	barcodeRules.Get("/", c.BarcodeRuleController.GetBarcodeRules)
+	barcodeRules.Post("/", middleware.RequireRole(authz.RoleManager), c.BarcodeRuleController.CreateBarcodeRule)
+	barcodeRules.Put("/:id", middleware.RequireRole(authz.RoleManager), c.BarcodeRuleController.UpdateBarcodeRule)
+	barcodeRules.Delete("/:id", middleware.RequireRole(authz.RoleManager), c.BarcodeRuleController.DeleteBarcodeRule)
 
 	api.Post("/upload-merchant", c.UploadController.UploadMerchantPhoto)
 }
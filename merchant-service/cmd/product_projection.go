@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"micro-warehouse/merchant-service/app"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/spf13/cobra"
+)
+
+var productProjectionCmd = &cobra.Command{
+	Use:   "product-projection",
+	Short: "Local copy of product name, barcode and category used for merchant product search",
+}
+
+// Dijalankan sekali setelah deploy atau saat event product terlewat; product yang sudah ada ditimpa
+var productProjectionSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Copy every product from product-service into the product projection",
+	Run: func(cmd *cobra.Command, args []string) {
+		container := app.BuildContainer()
+
+		synced, err := container.ProductProjectionUsecase.SyncAllProducts(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to sync product projection after %d products: %v", synced, err)
+		}
+
+		fmt.Printf("Synced %d products\n", synced)
+	},
+}
+
+func init() {
+	productProjectionCmd.AddCommand(productProjectionSyncCmd)
+	rootCmd.AddCommand(productProjectionCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"micro-warehouse/merchant-service/app"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/spf13/cobra"
+)
+
+var replenishmentCmd = &cobra.Command{
+	Use:   "replenishment",
+	Short: "Automatic replenishment from min/max stock levels",
+}
+
+// Dijalankan terjadwal (cron / Kubernetes CronJob); draft yang belum direview tidak dibuat ulang
+var replenishmentGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Store draft replenishment orders for products below their min stock",
+	Run: func(cmd *cobra.Command, args []string) {
+		merchantID, _ := cmd.Flags().GetUint("merchant-id")
+
+		container := app.BuildContainer()
+
+		replenishmentOrders, err := container.ReplenishmentUsecase.GenerateDraftOrders(context.Background(), merchantID, 0)
+		if err != nil {
+			log.Fatalf("Failed to generate replenishment orders: %v", err)
+		}
+
+		if len(replenishmentOrders) == 0 {
+			fmt.Println("No products need replenishment")
+			return
+		}
+
+		for _, replenishmentOrder := range replenishmentOrders {
+			fmt.Printf("replenishment_order=%d merchant=%d items=%d\n",
+				replenishmentOrder.ID, replenishmentOrder.MerchantID, len(replenishmentOrder.Items))
+		}
+	},
+}
+
+func init() {
+	replenishmentGenerateCmd.Flags().Uint("merchant-id", 0, "only generate for this merchant (default all merchants)")
+
+	replenishmentCmd.AddCommand(replenishmentGenerateCmd)
+	rootCmd.AddCommand(replenishmentCmd)
+}
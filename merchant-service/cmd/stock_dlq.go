@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"micro-warehouse/merchant-service/configs"
+	"micro-warehouse/merchant-service/pkg/rabbitmq"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/spf13/cobra"
+)
+
+var stockDLQCmd = &cobra.Command{
+	Use:   "stock-dlq",
+	Short: "Inspect and replay dead-lettered stock events",
+}
+
+var stockDLQListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stock events in the dead-letter queue",
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		service := newStockDeadLetterService()
+		defer service.Close()
+
+		events, err := service.List(limit)
+		if err != nil {
+			log.Fatalf("Failed to list dead-lettered stock events: %v", err)
+		}
+
+		if len(events) == 0 {
+			fmt.Println("No dead-lettered stock events")
+			return
+		}
+
+		for _, event := range events {
+			fmt.Printf("order=%s merchant=%d products=%d retries=%d dead_lettered_at=%s reason=%q\n",
+				event.OrderID, event.MerchantID, event.Products, event.RetryCount, event.DeadLetteredAt, event.Reason)
+		}
+	},
+}
+
+var stockDLQReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Send dead-lettered stock events back to the stock queue",
+	Run: func(cmd *cobra.Command, args []string) {
+		orderID, _ := cmd.Flags().GetString("order-id")
+		all, _ := cmd.Flags().GetBool("all")
+
+		if orderID == "" && !all {
+			log.Fatal("Specify --order-id or --all")
+		}
+
+		service := newStockDeadLetterService()
+		defer service.Close()
+
+		replayed, err := service.Replay(orderID)
+		if err != nil {
+			log.Fatalf("Failed to replay dead-lettered stock events: %v", err)
+		}
+
+		log.Infof("Replayed %d dead-lettered stock events", replayed)
+	},
+}
+
+func newStockDeadLetterService() *rabbitmq.StockDeadLetterService {
+	cfg := configs.NewConfig()
+
+	service, err := rabbitmq.NewStockDeadLetterService(cfg.RabbitMQ.URL())
+	if err != nil {
+		log.Fatalf("Failed to connect to rabbitmq: %v", err)
+	}
+
+	return service
+}
+
+func init() {
+	stockDLQListCmd.Flags().Int("limit", 50, "maximum number of events to list")
+	stockDLQReplayCmd.Flags().String("order-id", "", "replay only the event for this order")
+	stockDLQReplayCmd.Flags().Bool("all", false, "replay every event in the dead-letter queue")
+
+	stockDLQCmd.AddCommand(stockDLQListCmd, stockDLQReplayCmd)
+	rootCmd.AddCommand(stockDLQCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"micro-warehouse/merchant-service/app"
+	"micro-warehouse/merchant-service/configs"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/spf13/cobra"
+)
+
+const defaultSoftDeleteRetentionDays = 30
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage soft-deleted merchants and merchant products",
+}
+
+// Dijalankan terjadwal (cron); data yang sudah dihapus permanen tidak bisa di-restore lagi
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently remove merchants and merchant products that have been in the trash longer than the retention period",
+	Run: func(cmd *cobra.Command, args []string) {
+		retentionDays, _ := cmd.Flags().GetInt("retention-days")
+		if retentionDays <= 0 {
+			retentionDays = configs.NewConfig().SoftDelete.RetentionDays
+		}
+		if retentionDays <= 0 {
+			retentionDays = defaultSoftDeleteRetentionDays
+		}
+
+		container := app.BuildContainer()
+
+		result, err := container.TrashUsecase.PurgeDeleted(context.Background(), time.Duration(retentionDays)*24*time.Hour)
+		if err != nil {
+			log.Fatalf("Failed to purge deleted rows after %d merchant products and %d merchants: %v", result.MerchantProducts, result.Merchants, err)
+		}
+
+		fmt.Printf("Purged %d merchant products and %d merchants deleted more than %d days ago\n", result.MerchantProducts, result.Merchants, retentionDays)
+		if result.RetainedMerchants > 0 {
+			fmt.Printf("Kept %d merchants that are still referenced by transfer orders, replenishment orders, stock returns or stocktakes\n", result.RetainedMerchants)
+		}
+	},
+}
+
+func init() {
+	trashPurgeCmd.Flags().Int("retention-days", 0, "purge rows deleted more than this many days ago (default SOFT_DELETE_RETENTION_DAYS or 30)")
+
+	trashCmd.AddCommand(trashPurgeCmd)
+	rootCmd.AddCommand(trashCmd)
+}
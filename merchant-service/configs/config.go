@@ -39,6 +39,19 @@ type RabbitMQ struct {
 	Password string `json:"password"`
 }
 
+// StockConsumer mengatur retry dan dead-letter untuk event merchant.stock.*
+type StockConsumer struct {
+	MaxRetries       int `json:"max_retries"`
+	RetryBaseSeconds int `json:"retry_base_seconds"`
+	RetryMaxSeconds  int `json:"retry_max_seconds"`
+	Prefetch         int `json:"prefetch"`
+}
+
+// SoftDelete mengatur berapa lama data di trash disimpan sebelum boleh di-purge
+type SoftDelete struct {
+	RetentionDays int `json:"retention_days"`
+}
+
 type Supabase struct {
 	Url    string `json:"url"`
 	Key    string `json:"key"`
@@ -46,11 +59,13 @@ type Supabase struct {
 }
 
 type Config struct {
-	App      App      `json:"app"`
-	SqlDB    SqlDB    `json:"sql_db"`
-	Redis    Redis    `json:"redis"`
-	RabbitMQ RabbitMQ `json:"rabbitmq"`
-	Supabase Supabase `json:"supabase"`
+	App           App           `json:"app"`
+	SqlDB         SqlDB         `json:"sql_db"`
+	Redis         Redis         `json:"redis"`
+	RabbitMQ      RabbitMQ      `json:"rabbitmq"`
+	StockConsumer StockConsumer `json:"stock_consumer"`
+	SoftDelete    SoftDelete    `json:"soft_delete"`
+	Supabase      Supabase      `json:"supabase"`
 }
 
 // URL returns the RabbitMQ connection string
@@ -87,6 +102,15 @@ func NewConfig() *Config {
 			Username: viper.GetString("RABBITMQ_USER"),
 			Password: viper.GetString("RABBITMQ_PASSWORD"),
 		},
+		StockConsumer: StockConsumer{
+			MaxRetries:       viper.GetInt("STOCK_CONSUMER_MAX_RETRIES"),
+			RetryBaseSeconds: viper.GetInt("STOCK_CONSUMER_RETRY_BASE_SECONDS"),
+			RetryMaxSeconds:  viper.GetInt("STOCK_CONSUMER_RETRY_MAX_SECONDS"),
+			Prefetch:         viper.GetInt("STOCK_CONSUMER_PREFETCH"),
+		},
+		SoftDelete: SoftDelete{
+			RetentionDays: viper.GetInt("SOFT_DELETE_RETENTION_DAYS"),
+		},
 		Supabase: Supabase{
 			Url:    viper.GetString("SUPABASE_URL"),
 			Key:    viper.GetString("SUPABASE_KEY"),
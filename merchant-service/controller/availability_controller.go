@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"errors"
+	"math"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/pkg/geo"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type AvailabilityControllerInterface interface {
+	GetProductAvailability(c *fiber.Ctx) error
+}
+
+type availabilityController struct {
+	availabilityUsecase usecase.AvailabilityUsecaseInterface
+}
+
+// GetProductAvailability implements AvailabilityControllerInterface.
+func (a *availabilityController) GetProductAvailability(c *fiber.Ctx) error {
+	var req request.GetProductAvailabilityRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[AvailabilityController] GetProductAvailability - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[AvailabilityController] GetProductAvailability - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	query := usecase.AvailabilityQuery{
+		ProductID:        req.ProductID,
+		Barcode:          req.Barcode,
+		OriginMerchantID: req.MerchantID,
+		SortBy:           req.SortBy,
+	}
+	if req.Latitude != nil && req.Longitude != nil {
+		query.Origin = &geo.Point{Latitude: *req.Latitude, Longitude: *req.Longitude}
+	}
+
+	availability, err := a.availabilityUsecase.GetProductAvailability(c.Context(), query)
+	if err != nil {
+		log.Errorf("[AvailabilityController] GetProductAvailability - 3: %v", err)
+		switch {
+		case errors.Is(err, usecase.ErrInvalidAvailabilityQuery), errors.Is(err, usecase.ErrInvalidBarcode):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get product availability",
+		})
+	}
+
+	now := time.Now()
+	resp := response.ProductAvailabilityResponse{
+		ProductID:      availability.Product.ID,
+		ProductName:    availability.Product.Name,
+		ProductBarcode: availability.Product.Barcode,
+		SortBy:         availability.SortBy,
+		Merchants:      []response.MerchantAvailabilityResponse{},
+		Warehouses:     []response.WarehouseAvailabilityResponse{},
+	}
+
+	for _, item := range availability.Merchants {
+		merchant := item.MerchantProduct.Merchant
+		isOpen, closedReason := merchant.IsOpenAt(now)
+		resp.TotalMerchantStock += item.MerchantProduct.Stock
+		resp.Merchants = append(resp.Merchants, response.MerchantAvailabilityResponse{
+			MerchantID:   item.MerchantProduct.MerchantID,
+			MerchantName: merchant.Name,
+			Address:      merchant.Address,
+			Phone:        merchant.Phone,
+			Stock:        item.MerchantProduct.Stock,
+			IsOpen:       isOpen,
+			ClosedReason: closedReason,
+			Latitude:     merchant.Latitude,
+			Longitude:    merchant.Longitude,
+			DistanceKm:   roundDistance(item.DistanceKm),
+		})
+	}
+
+	for _, item := range availability.Warehouses {
+		resp.TotalWarehouseStock += item.Stock.Stock
+		resp.Warehouses = append(resp.Warehouses, response.WarehouseAvailabilityResponse{
+			WarehouseID:   item.Stock.WarehouseID,
+			WarehouseName: item.Stock.WarehouseName,
+			Stock:         item.Stock.Stock,
+			Latitude:      item.Stock.Latitude,
+			Longitude:     item.Stock.Longitude,
+			DistanceKm:    roundDistance(item.DistanceKm),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Product availability fetched successfully",
+		"data":    resp,
+	})
+}
+
+func NewAvailabilityController(availabilityUsecase usecase.AvailabilityUsecaseInterface) AvailabilityControllerInterface {
+	return &availabilityController{
+		availabilityUsecase: availabilityUsecase,
+	}
+}
+
+// roundDistance membulatkan jarak ke 2 angka di belakang koma (10 meter)
+func roundDistance(distance *float64) *float64 {
+	if distance == nil {
+		return nil
+	}
+
+	rounded := math.Round(*distance*100) / 100
+	return &rounded
+}
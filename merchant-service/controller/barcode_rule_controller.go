@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/conv"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/repository"
+	"micro-warehouse/merchant-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type BarcodeRuleControllerInterface interface {
+	GetBarcodeRules(c *fiber.Ctx) error
+	CreateBarcodeRule(c *fiber.Ctx) error
+	UpdateBarcodeRule(c *fiber.Ctx) error
+	DeleteBarcodeRule(c *fiber.Ctx) error
+}
+
+type barcodeRuleController struct {
+	barcodeRuleUsecase usecase.BarcodeRuleUsecaseInterface
+}
+
+// GetBarcodeRules implements BarcodeRuleControllerInterface.
+func (b *barcodeRuleController) GetBarcodeRules(c *fiber.Ctx) error {
+	rules, err := b.barcodeRuleUsecase.GetBarcodeRules(c.Context())
+	if err != nil {
+		log.Errorf("[BarcodeRuleController] GetBarcodeRules - 1: %v", err)
+		return barcodeRuleErrorResponse(c, err, "Failed to get barcode rules")
+	}
+
+	resps := []response.BarcodeRuleResponse{}
+	for _, rule := range rules {
+		resps = append(resps, toBarcodeRuleResponse(rule))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Barcode rules fetched successfully",
+		"data":    resps,
+	})
+}
+
+// CreateBarcodeRule implements BarcodeRuleControllerInterface.
+func (b *barcodeRuleController) CreateBarcodeRule(c *fiber.Ctx) error {
+	rule, err := parseBarcodeRuleRequest(c)
+	if err != nil {
+		log.Errorf("[BarcodeRuleController] CreateBarcodeRule - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if err := b.barcodeRuleUsecase.CreateBarcodeRule(c.Context(), rule); err != nil {
+		log.Errorf("[BarcodeRuleController] CreateBarcodeRule - 2: %v", err)
+		return barcodeRuleErrorResponse(c, err, "Failed to create barcode rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Barcode rule created successfully",
+		"data":    toBarcodeRuleResponse(*rule),
+	})
+}
+
+// UpdateBarcodeRule implements BarcodeRuleControllerInterface.
+func (b *barcodeRuleController) UpdateBarcodeRule(c *fiber.Ctx) error {
+	rule, err := parseBarcodeRuleRequest(c)
+	if err != nil {
+		log.Errorf("[BarcodeRuleController] UpdateBarcodeRule - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	rule.ID = conv.StringToUint(c.Params("id"))
+
+	updated, err := b.barcodeRuleUsecase.UpdateBarcodeRule(c.Context(), rule)
+	if err != nil {
+		log.Errorf("[BarcodeRuleController] UpdateBarcodeRule - 2: %v", err)
+		return barcodeRuleErrorResponse(c, err, "Failed to update barcode rule")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Barcode rule updated successfully",
+		"data":    toBarcodeRuleResponse(*updated),
+	})
+}
+
+// DeleteBarcodeRule implements BarcodeRuleControllerInterface.
+func (b *barcodeRuleController) DeleteBarcodeRule(c *fiber.Ctx) error {
+	if err := b.barcodeRuleUsecase.DeleteBarcodeRule(c.Context(), conv.StringToUint(c.Params("id"))); err != nil {
+		log.Errorf("[BarcodeRuleController] DeleteBarcodeRule - 1: %v", err)
+		return barcodeRuleErrorResponse(c, err, "Failed to delete barcode rule")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Barcode rule deleted successfully",
+	})
+}
+
+func NewBarcodeRuleController(barcodeRuleUsecase usecase.BarcodeRuleUsecaseInterface) BarcodeRuleControllerInterface {
+	return &barcodeRuleController{
+		barcodeRuleUsecase: barcodeRuleUsecase,
+	}
+}
+
+// parseBarcodeRuleRequest: rule baru aktif kecuali is_active dikirim false
+func parseBarcodeRuleRequest(c *fiber.Ctx) (*model.BarcodeRule, error) {
+	var req request.BarcodeRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, errors.New("Invalid request body")
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	rule := model.BarcodeRule{
+		Name:               req.Name,
+		Prefix:             req.Prefix,
+		ItemCodeLength:     req.ItemCodeLength,
+		Measure:            req.Measure,
+		ValueLength:        req.ValueLength,
+		Decimals:           req.Decimals,
+		HasValueCheckDigit: req.HasValueCheckDigit,
+		IsActive:           true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	return &rule, nil
+}
+
+func barcodeRuleErrorResponse(c *fiber.Ctx, err error, fallbackMessage string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Barcode rule not found",
+		})
+	case errors.Is(err, repository.ErrBarcodePrefixTaken):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrInvalidBarcodeRule):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": fallbackMessage,
+	})
+}
+
+func toBarcodeRuleResponse(rule model.BarcodeRule) response.BarcodeRuleResponse {
+	return response.BarcodeRuleResponse{
+		ID:                 rule.ID,
+		Name:               rule.Name,
+		Prefix:             rule.Prefix,
+		ItemCodeLength:     rule.ItemCodeLength,
+		Measure:            rule.Measure,
+		ValueLength:        rule.ValueLength,
+		Decimals:           rule.Decimals,
+		HasValueCheckDigit: rule.HasValueCheckDigit,
+		IsActive:           rule.IsActive,
+		CreatedAt:          rule.CreatedAt,
+		UpdatedAt:          rule.UpdatedAt,
+	}
+}
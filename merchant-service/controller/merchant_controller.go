@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"micro-warehouse/merchant-service/controller/request"
 	"micro-warehouse/merchant-service/controller/response"
 	"micro-warehouse/merchant-service/model"
@@ -9,9 +10,11 @@ import (
 	"micro-warehouse/merchant-service/pkg/pagination"
 	"micro-warehouse/merchant-service/pkg/validator"
 	"micro-warehouse/merchant-service/usecase"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
 type MerchantControllerInterface interface {
@@ -20,6 +23,11 @@ type MerchantControllerInterface interface {
 	GetMerchantByID(c *fiber.Ctx) error
 	UpdateMerchant(c *fiber.Ctx) error
 	DeleteMerchant(c *fiber.Ctx) error
+	UpdateMerchantStatus(c *fiber.Ctx) error
+	ArchiveMerchant(c *fiber.Ctx) error
+	UnarchiveMerchant(c *fiber.Ctx) error
+	GetDeletedMerchants(c *fiber.Ctx) error
+	RestoreMerchant(c *fiber.Ctx) error
 }
 
 type merchantController struct {
@@ -44,11 +52,20 @@ func (m *merchantController) CreateMerchant(c *fiber.Ctx) error {
 	}
 
 	reqModel := model.Merchant{
-		Name:     req.Name,
-		KeeperID: req.KeeperID,
-		Address:  req.Address,
-		Phone:    req.Phone,
-		Photo:    req.Photo,
+		Name:      req.Name,
+		Address:   req.Address,
+		Phone:     req.Phone,
+		Photo:     req.Photo,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}
+
+	if req.KeeperID != 0 {
+		reqModel.Staff = []model.MerchantStaff{{
+			UserID:    req.KeeperID,
+			Role:      model.MerchantStaffRoleLead,
+			StartDate: time.Now(),
+		}}
 	}
 
 	if err := m.merchantUsecase.CreateMerchant(c.Context(), &reqModel); err != nil {
@@ -70,6 +87,19 @@ func (m *merchantController) DeleteMerchant(c *fiber.Ctx) error {
 
 	if err := m.merchantUsecase.DeleteMerchant(c.Context(), merchantID); err != nil {
 		log.Errorf("[MerchantController] DeleteMerchant - 1: %v", err)
+		var blocked *usecase.DeletionBlockedError
+		if errors.As(err, &blocked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message":  "Merchant still has stock or pending transactions; archive it instead or resolve the blockers first",
+				"code":     "DELETION_BLOCKED",
+				"blockers": toDeletionBlockerResponses(blocked.Blockers),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": err.Error(),
 		})
@@ -82,7 +112,7 @@ func (m *merchantController) DeleteMerchant(c *fiber.Ctx) error {
 
 // GetAllMerchants implements MerchantControllerInterface.
 func (m *merchantController) GetAllMerchants(c *fiber.Ctx) error {
-	var req request.GetMerchantProductRequest
+	var req request.GetMerchantsRequest
 	if err := c.QueryParser(&req); err != nil {
 		log.Errorf("[MerchantController] GetAllMerchants - 1: %v", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -90,6 +120,13 @@ func (m *merchantController) GetAllMerchants(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantController] GetAllMerchants - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
 	if req.Page <= 0 {
 		req.Page = 1
 	}
@@ -102,45 +139,56 @@ func (m *merchantController) GetAllMerchants(c *fiber.Ctx) error {
 		productMap := make(map[uint]*httpclient.ProductResponse)
 		warehouseMap := make(map[uint]*httpclient.WarehouseResponse)
 
-		merchant, products, warehouses, err := m.merchantUsecase.GetMerchantByKeeperID(c.Context(), req.KeeperID)
+		merchants, products, warehouses, err := m.merchantUsecase.GetMerchantsByKeeperID(c.Context(), req.KeeperID)
 		if err != nil {
-			log.Errorf("[MerchantController] GetAllMerchants - 2: %v", err)
+			log.Errorf("[MerchantController] GetAllMerchants - 3: %v", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"message": "Failed to get merchant by keeper id",
 			})
 		}
 
-		if merchant.ID == 0 {
+		if len(merchants) == 0 {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"message": "No Merchant found for this keeper",
 			})
 		}
 
-		keeperNames, err := m.merchantUsecase.GetKeeperName(c.Context(), merchant.KeeperID)
-		if err != nil {
-			log.Errorf("[MerchantController] GetAllMerchants - 3: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"message": "Failed to get keeper name",
-			})
+		for i := range products {
+			productMap[products[i].ID] = &products[i]
 		}
 
-		merchantResponse := response.MerchantWithProductResponse{
-			ID:         merchant.ID,
-			Name:       merchant.Name,
-			Address:    merchant.Address,
-			Photo:      merchant.Photo,
-			Phone:      merchant.Phone,
-			KeeperID:   merchant.KeeperID,
-			KeeperName: keeperNames,
+		for i := range warehouses {
+			warehouseMap[warehouses[i].ID] = &warehouses[i]
 		}
 
-		if len(merchant.MerchantProducts) > 0 {
-			for i := range products {
-				productMap[products[i].ID] = &products[i]
+		now := time.Now()
+		merchantResponses := []response.MerchantWithProductResponse{}
+		for _, merchant := range merchants {
+			leadID := merchant.LeadUserID(now)
+			keeperName, err := m.merchantUsecase.GetKeeperName(c.Context(), leadID)
+			if err != nil {
+				log.Errorf("[MerchantController] GetAllMerchants - 4: %v", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"message": "Failed to get keeper name",
+				})
 			}
 
-			for i := range warehouses {
-				warehouseMap[warehouses[i].ID] = &warehouses[i]
+			isOpen, closedReason := merchant.IsOpenAt(now)
+			merchantResponse := response.MerchantWithProductResponse{
+				ID:           merchant.ID,
+				Name:         merchant.Name,
+				Address:      merchant.Address,
+				Photo:        merchant.Photo,
+				Phone:        merchant.Phone,
+				KeeperID:     leadID,
+				KeeperName:   keeperName,
+				Status:       merchant.Status,
+				IsOpen:       isOpen,
+				ClosedReason: closedReason,
+				Latitude:     merchant.Latitude,
+				Longitude:    merchant.Longitude,
+				ArchivedAt:   merchant.ArchivedAt,
+				Staff:        toMerchantStaffResponses(merchant.ActiveStaff(now), now),
 			}
 
 			for _, mp := range merchant.MerchantProducts {
@@ -149,6 +197,9 @@ func (m *merchantController) GetAllMerchants(c *fiber.Ctx) error {
 					MerchantID:  mp.MerchantID,
 					ProductID:   mp.ProductID,
 					Stock:       mp.Stock,
+					MinStock:    mp.MinStock,
+					MaxStock:    mp.MaxStock,
+					IsLowStock:  mp.IsLowStock(),
 					WarehouseID: mp.WarehouseID,
 				}
 
@@ -169,17 +220,19 @@ func (m *merchantController) GetAllMerchants(c *fiber.Ctx) error {
 
 				merchantResponse.MerchantProducts = append(merchantResponse.MerchantProducts, productResponse)
 			}
+
+			merchantResponses = append(merchantResponses, merchantResponse)
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"data":    merchantResponse,
+			"data":    merchantResponses,
 			"message": "Merchant products fetched successfully",
 		})
 	}
 
-	merchants, total, err := m.merchantUsecase.GetAllMerchants(c.Context(), req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder)
+	merchants, total, err := m.merchantUsecase.GetAllMerchants(c.Context(), req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder, req.Status, req.OpenNow, req.Archived)
 	if err != nil {
-		log.Errorf("[MerchantController] GetAllMerchants - 4: %v", err)
+		log.Errorf("[MerchantController] GetAllMerchants - 5: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get all merchants",
 		})
@@ -194,25 +247,18 @@ func (m *merchantController) GetAllMerchants(c *fiber.Ctx) error {
 		})
 	}
 
+	now := time.Now()
 	var merchantsResponse []response.MerchantResponse
 	for _, merchant := range merchants {
-		keeperName, err := m.merchantUsecase.GetKeeperName(c.Context(), merchant.KeeperID)
+		leadID := merchant.LeadUserID(now)
+		keeperName, err := m.merchantUsecase.GetKeeperName(c.Context(), leadID)
 		if err != nil {
-			log.Errorf("[MerchantController] GetAllMerchants - 5: %v", err)
+			log.Errorf("[MerchantController] GetAllMerchants - 6: %v", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"message": "Failed to get keeper name",
 			})
 		}
-		merchantsResponse = append(merchantsResponse, response.MerchantResponse{
-			ID:           merchant.ID,
-			Name:         merchant.Name,
-			Address:      merchant.Address,
-			Photo:        merchant.Photo,
-			Phone:        merchant.Phone,
-			KeeperID:     merchant.KeeperID,
-			KeeperName:   keeperName,
-			ProductCount: len(merchant.MerchantProducts),
-		})
+		merchantsResponse = append(merchantsResponse, toMerchantResponse(merchant, leadID, keeperName, now))
 	}
 
 	resp := response.MerchantPaginationResponse{
@@ -241,9 +287,11 @@ func (m *merchantController) GetMerchantByID(c *fiber.Ctx) error {
 		})
 	}
 
+	now := time.Now()
+	leadID := merchant.LeadUserID(now)
 	var keeperName string
-	if merchant.KeeperID != 0 {
-		keeperName, err = m.merchantUsecase.GetKeeperName(c.Context(), merchant.KeeperID)
+	if leadID != 0 {
+		keeperName, err = m.merchantUsecase.GetKeeperName(c.Context(), leadID)
 		if err != nil {
 			log.Errorf("[MerchantController] GetMerchantByID - 2: %v", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -252,19 +300,8 @@ func (m *merchantController) GetMerchantByID(c *fiber.Ctx) error {
 		}
 	}
 
-	response := response.MerchantResponse{
-		ID:           merchant.ID,
-		Name:         merchant.Name,
-		Address:      merchant.Address,
-		Photo:        merchant.Photo,
-		Phone:        merchant.Phone,
-		KeeperID:     merchant.KeeperID,
-		KeeperName:   keeperName,
-		ProductCount: len(merchant.MerchantProducts),
-	}
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"data":    response,
+		"data":    toMerchantResponse(*merchant, leadID, keeperName, now),
 		"message": "Merchant fetched successfully",
 	})
 }
@@ -274,7 +311,7 @@ func (m *merchantController) UpdateMerchant(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id := conv.StringToUint(idStr)
 
-	var req request.CreateMerchantRequest
+	var req request.UpdateMerchantRequest
 	if err := c.BodyParser(&req); err != nil {
 		log.Errorf("[MerchantController] UpdateMerchant - 1: %v", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -290,12 +327,13 @@ func (m *merchantController) UpdateMerchant(c *fiber.Ctx) error {
 	}
 
 	reqModel := model.Merchant{
-		ID:       id,
-		Name:     req.Name,
-		KeeperID: req.KeeperID,
-		Address:  req.Address,
-		Phone:    req.Phone,
-		Photo:    req.Photo,
+		ID:        id,
+		Name:      req.Name,
+		Address:   req.Address,
+		Phone:     req.Phone,
+		Photo:     req.Photo,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
 	}
 
 	if err := m.merchantUsecase.UpdateMerchant(c.Context(), &reqModel); err != nil {
@@ -310,8 +348,226 @@ func (m *merchantController) UpdateMerchant(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateMerchantStatus implements MerchantControllerInterface.
+func (m *merchantController) UpdateMerchantStatus(c *fiber.Ctx) error {
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.UpdateMerchantStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantController] UpdateMerchantStatus - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantController] UpdateMerchantStatus - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if err := m.merchantUsecase.UpdateMerchantStatus(c.Context(), id, req.Status, req.Reason); err != nil {
+		log.Errorf("[MerchantController] UpdateMerchantStatus - 3: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant not found",
+			})
+		}
+		if errors.Is(err, usecase.ErrInvalidMerchantStatus) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to update merchant status",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant status updated successfully",
+	})
+}
+
+// ArchiveMerchant implements MerchantControllerInterface.
+func (m *merchantController) ArchiveMerchant(c *fiber.Ctx) error {
+	id := conv.StringToUint(c.Params("id"))
+
+	if err := m.merchantUsecase.ArchiveMerchant(c.Context(), id); err != nil {
+		log.Errorf("[MerchantController] ArchiveMerchant - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to archive merchant",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant archived successfully",
+	})
+}
+
+// UnarchiveMerchant implements MerchantControllerInterface.
+func (m *merchantController) UnarchiveMerchant(c *fiber.Ctx) error {
+	id := conv.StringToUint(c.Params("id"))
+
+	if err := m.merchantUsecase.UnarchiveMerchant(c.Context(), id); err != nil {
+		log.Errorf("[MerchantController] UnarchiveMerchant - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to unarchive merchant",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant unarchived successfully",
+	})
+}
+
+// GetDeletedMerchants implements MerchantControllerInterface.
+func (m *merchantController) GetDeletedMerchants(c *fiber.Ctx) error {
+	var req request.GetDeletedMerchantsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantController] GetDeletedMerchants - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantController] GetDeletedMerchants - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	merchants, total, err := m.merchantUsecase.GetDeletedMerchants(c.Context(), req.Page, req.Limit, req.Search)
+	if err != nil {
+		log.Errorf("[MerchantController] GetDeletedMerchants - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get deleted merchants",
+		})
+	}
+
+	merchantResponses := []response.DeletedMerchantResponse{}
+	for _, merchant := range merchants {
+		merchantResponses = append(merchantResponses, response.DeletedMerchantResponse{
+			ID:         merchant.ID,
+			Name:       merchant.Name,
+			Address:    merchant.Address,
+			Photo:      merchant.Photo,
+			Phone:      merchant.Phone,
+			Status:     merchant.Status,
+			ArchivedAt: merchant.ArchivedAt,
+			DeletedAt:  merchant.DeletedAt.Time,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Deleted merchants fetched successfully",
+		"data": response.GetDeletedMerchantsResponse{
+			Merchants:  merchantResponses,
+			Pagination: pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// RestoreMerchant implements MerchantControllerInterface.
+func (m *merchantController) RestoreMerchant(c *fiber.Ctx) error {
+	id := conv.StringToUint(c.Params("id"))
+
+	if err := m.merchantUsecase.RestoreMerchant(c.Context(), id); err != nil {
+		log.Errorf("[MerchantController] RestoreMerchant - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant not found in trash",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to restore merchant",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant restored successfully",
+	})
+}
+
 func NewMerchantController(merchantUsecase usecase.MerchantUsecaseInterface) MerchantControllerInterface {
 	return &merchantController{
 		merchantUsecase: merchantUsecase,
 	}
 }
+
+func toMerchantResponse(merchant model.Merchant, leadID uint, keeperName string, now time.Time) response.MerchantResponse {
+	isOpen, closedReason := merchant.IsOpenAt(now)
+	return response.MerchantResponse{
+		ID:           merchant.ID,
+		Name:         merchant.Name,
+		Address:      merchant.Address,
+		Photo:        merchant.Photo,
+		Phone:        merchant.Phone,
+		KeeperID:     leadID,
+		KeeperName:   keeperName,
+		ProductCount: len(merchant.MerchantProducts),
+		Status:       merchant.Status,
+		StatusReason: merchant.StatusReason,
+		Timezone:     merchant.Timezone,
+		IsOpen:       isOpen,
+		ClosedReason: closedReason,
+		Latitude:     merchant.Latitude,
+		Longitude:    merchant.Longitude,
+		ArchivedAt:   merchant.ArchivedAt,
+		Staff:        toMerchantStaffResponses(merchant.ActiveStaff(now), now),
+	}
+}
+
+func toDeletionBlockerResponses(blockers []usecase.DeletionBlocker) []response.DeletionBlockerResponse {
+	resps := []response.DeletionBlockerResponse{}
+	for _, blocker := range blockers {
+		resps = append(resps, response.DeletionBlockerResponse{
+			Type:     blocker.Type,
+			Count:    blocker.Count,
+			Quantity: blocker.Quantity,
+			Message:  blocker.Message,
+		})
+	}
+
+	return resps
+}
+
+func toMerchantStaffResponses(staff []model.MerchantStaff, at time.Time) []response.MerchantStaffResponse {
+	resps := []response.MerchantStaffResponse{}
+	for _, member := range staff {
+		resps = append(resps, toMerchantStaffResponse(member, at))
+	}
+
+	return resps
+}
+
+func toMerchantStaffResponse(staff model.MerchantStaff, at time.Time) response.MerchantStaffResponse {
+	return response.MerchantStaffResponse{
+		ID:         staff.ID,
+		MerchantID: staff.MerchantID,
+		UserID:     staff.UserID,
+		Role:       staff.Role,
+		StartDate:  staff.StartDate,
+		EndDate:    staff.EndDate,
+		IsActive:   staff.IsActiveAt(at),
+	}
+}
@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/conv"
+	"micro-warehouse/merchant-service/pkg/geo"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type MerchantDeliveryZoneControllerInterface interface {
+	GetDeliveryZones(c *fiber.Ctx) error
+	CreateDeliveryZone(c *fiber.Ctx) error
+	UpdateDeliveryZone(c *fiber.Ctx) error
+	DeleteDeliveryZone(c *fiber.Ctx) error
+	RouteDelivery(c *fiber.Ctx) error
+}
+
+type merchantDeliveryZoneController struct {
+	deliveryZoneUsecase usecase.MerchantDeliveryZoneUsecaseInterface
+}
+
+// GetDeliveryZones implements MerchantDeliveryZoneControllerInterface.
+func (m *merchantDeliveryZoneController) GetDeliveryZones(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	zones, err := m.deliveryZoneUsecase.GetDeliveryZones(c.Context(), merchantID)
+	if err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] GetDeliveryZones - 1: %v", err)
+		return deliveryZoneErrorResponse(c, err, "Failed to get merchant delivery zones")
+	}
+
+	resps := []response.MerchantDeliveryZoneResponse{}
+	for _, zone := range zones {
+		resps = append(resps, toMerchantDeliveryZoneResponse(zone))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant delivery zones fetched successfully",
+		"data":    resps,
+	})
+}
+
+// CreateDeliveryZone implements MerchantDeliveryZoneControllerInterface.
+func (m *merchantDeliveryZoneController) CreateDeliveryZone(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	var req request.DeliveryZoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] CreateDeliveryZone - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] CreateDeliveryZone - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	zone := model.MerchantDeliveryZone{
+		MerchantID: merchantID,
+		Name:       req.Name,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := m.deliveryZoneUsecase.CreateDeliveryZone(c.Context(), &zone, toPolygon(req.Polygon)); err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] CreateDeliveryZone - 3: %v", err)
+		return deliveryZoneErrorResponse(c, err, "Failed to create merchant delivery zone")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Merchant delivery zone created successfully",
+		"data":    toMerchantDeliveryZoneResponse(zone),
+	})
+}
+
+// UpdateDeliveryZone implements MerchantDeliveryZoneControllerInterface.
+func (m *merchantDeliveryZoneController) UpdateDeliveryZone(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+	zoneID := conv.StringToUint(c.Params("zone_id"))
+
+	var req request.DeliveryZoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] UpdateDeliveryZone - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] UpdateDeliveryZone - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	zone := model.MerchantDeliveryZone{
+		ID:         zoneID,
+		MerchantID: merchantID,
+		Name:       req.Name,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := m.deliveryZoneUsecase.UpdateDeliveryZone(c.Context(), &zone, toPolygon(req.Polygon)); err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] UpdateDeliveryZone - 3: %v", err)
+		return deliveryZoneErrorResponse(c, err, "Failed to update merchant delivery zone")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant delivery zone updated successfully",
+		"data":    toMerchantDeliveryZoneResponse(zone),
+	})
+}
+
+// DeleteDeliveryZone implements MerchantDeliveryZoneControllerInterface.
+func (m *merchantDeliveryZoneController) DeleteDeliveryZone(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+	zoneID := conv.StringToUint(c.Params("zone_id"))
+
+	if err := m.deliveryZoneUsecase.DeleteDeliveryZone(c.Context(), merchantID, zoneID); err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] DeleteDeliveryZone - 1: %v", err)
+		return deliveryZoneErrorResponse(c, err, "Failed to delete merchant delivery zone")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant delivery zone deleted successfully",
+	})
+}
+
+// RouteDelivery implements MerchantDeliveryZoneControllerInterface.
+func (m *merchantDeliveryZoneController) RouteDelivery(c *fiber.Ctx) error {
+	var req request.RouteDeliveryRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] RouteDelivery - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] RouteDelivery - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	route, err := m.deliveryZoneUsecase.RouteDelivery(c.Context(), geo.Point{Latitude: *req.Latitude, Longitude: *req.Longitude})
+	if err != nil {
+		log.Errorf("[MerchantDeliveryZoneController] RouteDelivery - 3: %v", err)
+		if errors.Is(err, usecase.ErrLocationNotServed) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to route delivery",
+		})
+	}
+
+	resp := response.DeliveryRouteResponse{
+		Latitude:   route.Point.Latitude,
+		Longitude:  route.Point.Longitude,
+		Candidates: []response.DeliveryCandidateResponse{},
+	}
+	for _, candidate := range route.Candidates {
+		resp.Candidates = append(resp.Candidates, response.DeliveryCandidateResponse{
+			MerchantID:   candidate.Zone.MerchantID,
+			MerchantName: candidate.Zone.Merchant.Name,
+			Address:      candidate.Zone.Merchant.Address,
+			Phone:        candidate.Zone.Merchant.Phone,
+			ZoneID:       candidate.Zone.ID,
+			ZoneName:     candidate.Zone.Name,
+			IsOpen:       candidate.IsOpen,
+			ClosedReason: candidate.ClosedReason,
+			DistanceKm:   roundDistance(candidate.DistanceKm),
+		})
+	}
+	resp.Merchant = resp.Candidates[0]
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Delivery routed successfully",
+		"data":    resp,
+	})
+}
+
+func NewMerchantDeliveryZoneController(deliveryZoneUsecase usecase.MerchantDeliveryZoneUsecaseInterface) MerchantDeliveryZoneControllerInterface {
+	return &merchantDeliveryZoneController{
+		deliveryZoneUsecase: deliveryZoneUsecase,
+	}
+}
+
+func deliveryZoneErrorResponse(c *fiber.Ctx, err error, fallbackMessage string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Merchant or delivery zone not found",
+		})
+	case errors.Is(err, usecase.ErrInvalidDeliveryZone):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": fallbackMessage,
+	})
+}
+
+// toPolygon dipanggil setelah validasi sehingga latitude/longitude tidak pernah nil
+func toPolygon(points []request.CoordinateRequest) geo.Polygon {
+	polygon := make(geo.Polygon, 0, len(points))
+	for _, point := range points {
+		polygon = append(polygon, geo.Point{Latitude: *point.Latitude, Longitude: *point.Longitude})
+	}
+
+	return polygon
+}
+
+func toMerchantDeliveryZoneResponse(zone model.MerchantDeliveryZone) response.MerchantDeliveryZoneResponse {
+	return response.MerchantDeliveryZoneResponse{
+		ID:         zone.ID,
+		MerchantID: zone.MerchantID,
+		Name:       zone.Name,
+		Polygon:    zone.Polygon,
+		IsActive:   zone.IsActive,
+		CreatedAt:  zone.CreatedAt,
+		UpdatedAt:  zone.UpdatedAt,
+	}
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/pkg/conv"
+	"micro-warehouse/merchant-service/pkg/geo"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type MerchantLocationControllerInterface interface {
+	GetNearbyMerchants(c *fiber.Ctx) error
+	GetNearestWarehouses(c *fiber.Ctx) error
+}
+
+type merchantLocationController struct {
+	merchantLocationUsecase usecase.MerchantLocationUsecaseInterface
+}
+
+// GetNearbyMerchants implements MerchantLocationControllerInterface.
+func (m *merchantLocationController) GetNearbyMerchants(c *fiber.Ctx) error {
+	var req request.GetNearbyMerchantsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantLocationController] GetNearbyMerchants - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantLocationController] GetNearbyMerchants - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	nearby, err := m.merchantLocationUsecase.GetNearbyMerchants(c.Context(), usecase.NearbyMerchantsQuery{
+		Origin:   geo.Point{Latitude: *req.Latitude, Longitude: *req.Longitude},
+		RadiusKm: req.RadiusKm,
+		Status:   req.Status,
+		OpenNow:  req.OpenNow,
+		Limit:    req.Limit,
+	})
+	if err != nil {
+		log.Errorf("[MerchantLocationController] GetNearbyMerchants - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get nearby merchants",
+		})
+	}
+
+	now := time.Now()
+	resps := []response.NearbyMerchantResponse{}
+	for _, item := range nearby {
+		isOpen, closedReason := item.Merchant.IsOpenAt(now)
+		resps = append(resps, response.NearbyMerchantResponse{
+			ID:           item.Merchant.ID,
+			Name:         item.Merchant.Name,
+			Address:      item.Merchant.Address,
+			Phone:        item.Merchant.Phone,
+			Status:       item.Merchant.Status,
+			IsOpen:       isOpen,
+			ClosedReason: closedReason,
+			Latitude:     *item.Merchant.Latitude,
+			Longitude:    *item.Merchant.Longitude,
+			DistanceKm:   *roundDistance(&item.DistanceKm),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Nearby merchants fetched successfully",
+		"data":    resps,
+	})
+}
+
+// GetNearestWarehouses implements MerchantLocationControllerInterface.
+func (m *merchantLocationController) GetNearestWarehouses(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	var req request.GetNearestWarehousesRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantLocationController] GetNearestWarehouses - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantLocationController] GetNearestWarehouses - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	merchant, warehouses, err := m.merchantLocationUsecase.GetNearestWarehouses(c.Context(), merchantID, req.Limit)
+	if err != nil {
+		log.Errorf("[MerchantLocationController] GetNearestWarehouses - 3: %v", err)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant not found",
+			})
+		case errors.Is(err, usecase.ErrMerchantWithoutCoordinates):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get nearest warehouses",
+		})
+	}
+
+	resp := response.NearestWarehousesResponse{
+		MerchantID:   merchant.ID,
+		MerchantName: merchant.Name,
+		Latitude:     *merchant.Latitude,
+		Longitude:    *merchant.Longitude,
+		Warehouses:   []response.NearbyWarehouseResponse{},
+	}
+	for _, item := range warehouses {
+		resp.Warehouses = append(resp.Warehouses, response.NearbyWarehouseResponse{
+			WarehouseID:   item.Warehouse.ID,
+			WarehouseName: item.Warehouse.Name,
+			Address:       item.Warehouse.Address,
+			Phone:         item.Warehouse.Phone,
+			Latitude:      item.Warehouse.Latitude,
+			Longitude:     item.Warehouse.Longitude,
+			DistanceKm:    *roundDistance(&item.DistanceKm),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Nearest warehouses fetched successfully",
+		"data":    resp,
+	})
+}
+
+func NewMerchantLocationController(merchantLocationUsecase usecase.MerchantLocationUsecaseInterface) MerchantLocationControllerInterface {
+	return &merchantLocationController{
+		merchantLocationUsecase: merchantLocationUsecase,
+	}
+}
@@ -1,28 +1,55 @@
 package controller
 
 import (
+	"errors"
 	"micro-warehouse/merchant-service/controller/request"
 	"micro-warehouse/merchant-service/controller/response"
 	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
 	"micro-warehouse/merchant-service/pkg/conv"
 	"micro-warehouse/merchant-service/pkg/httpclient"
 	"micro-warehouse/merchant-service/pkg/pagination"
 	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/repository"
 	"micro-warehouse/merchant-service/usecase"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
+const movementDateLayout = "2006-01-02"
+
 type MerchantProductControllerInterface interface {
 	CreateMerchantProduct(c *fiber.Ctx) error
 	GetMerchantProductByID(c *fiber.Ctx) error
 	GetMerchantProducts(c *fiber.Ctx) error
 	GetMerchantProductByBarcode(c *fiber.Ctx) error
+	ResolveBarcodes(c *fiber.Ctx) error
 	UpdateMerchantProduct(c *fiber.Ctx) error
 	DeleteMerchantProduct(c *fiber.Ctx) error
 	DeleteAllProductMerchantProducts(c *fiber.Ctx) error
 	GetProductTotalStock(c *fiber.Ctx) error
+	GetWarehouseAllocations(c *fiber.Ctx) error
+
+	GetStockMovements(c *fiber.Ctx) error
+	RecordStockMovement(c *fiber.Ctx) error
+
+	UpdateMinStock(c *fiber.Ctx) error
+	GetLowStockMerchantProducts(c *fiber.Ctx) error
+
+	UpdateStockLevels(c *fiber.Ctx) error
+
+	SetPrice(c *fiber.Ctx) error
+	GetPrices(c *fiber.Ctx) error
+	DeleteScheduledPrice(c *fiber.Ctx) error
+
+	GetDeletedMerchantProducts(c *fiber.Ctx) error
+	RestoreMerchantProduct(c *fiber.Ctx) error
+
+	GetAllocationChanges(c *fiber.Ctx) error
+	RepublishAllocationChange(c *fiber.Ctx) error
 }
 
 type merchantProductController struct {
@@ -51,10 +78,13 @@ func (m *merchantProductController) CreateMerchantProduct(c *fiber.Ctx) error {
 		ProductID:   req.ProductID,
 		WarehouseID: req.WarehouseID,
 		Stock:       req.Stock,
+		MinStock:    req.MinStock,
+		MaxStock:    req.MaxStock,
 		MerchantID:  req.MerchantID,
 	}
 
-	if err := m.merchantProductUsecase.CreateMerchantProduct(ctx, &reqModel); err != nil {
+	actorID := conv.StringToUint(c.Get("X-User-ID"))
+	if err := m.merchantProductUsecase.CreateMerchantProduct(ctx, &reqModel, actorID); err != nil {
 		log.Errorf("[MerchantProductController] CreateMerchantProduct - 3: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to create merchant product",
@@ -102,6 +132,163 @@ func (m *merchantProductController) DeleteMerchantProduct(c *fiber.Ctx) error {
 	})
 }
 
+// GetDeletedMerchantProducts implements MerchantProductControllerInterface.
+func (m *merchantProductController) GetDeletedMerchantProducts(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.GetDeletedMerchantProductsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantProductController] GetDeletedMerchantProducts - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] GetDeletedMerchantProducts - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	merchantProducts, total, err := m.merchantProductUsecase.GetDeletedMerchantProducts(ctx, req.MerchantID, req.ProductID, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[MerchantProductController] GetDeletedMerchantProducts - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get deleted merchant products",
+		})
+	}
+
+	resps := []response.DeletedMerchantProductResponse{}
+	for _, merchantProduct := range merchantProducts {
+		resps = append(resps, response.DeletedMerchantProductResponse{
+			ID:          merchantProduct.ID,
+			MerchantID:  merchantProduct.MerchantID,
+			ProductID:   merchantProduct.ProductID,
+			WarehouseID: merchantProduct.WarehouseID,
+			Stock:       merchantProduct.Stock,
+			DeletedAt:   merchantProduct.DeletedAt.Time,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Deleted merchant products fetched successfully",
+		"data": response.GetDeletedMerchantProductsResponse{
+			MerchantProducts: resps,
+			Pagination:       pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// RestoreMerchantProduct implements MerchantProductControllerInterface.
+func (m *merchantProductController) RestoreMerchantProduct(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantProductID := conv.StringToUint(c.Params("merchant_product_id"))
+
+	if err := m.merchantProductUsecase.RestoreMerchantProduct(ctx, merchantProductID); err != nil {
+		log.Errorf("[MerchantProductController] RestoreMerchantProduct - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant product not found in trash",
+			})
+		}
+		if errors.Is(err, repository.ErrMerchantInTrash) || errors.Is(err, repository.ErrMerchantProductExists) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to restore merchant product",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant product restored successfully",
+	})
+}
+
+// GetAllocationChanges implements MerchantProductControllerInterface.
+func (m *merchantProductController) GetAllocationChanges(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.GetAllocationChangesRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantProductController] GetAllocationChanges - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] GetAllocationChanges - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	changes, total, err := m.merchantProductUsecase.GetAllocationChanges(ctx, req.MerchantID, req.Unpublished, req.Failed, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[MerchantProductController] GetAllocationChanges - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get allocation changes",
+		})
+	}
+
+	resps := []response.MerchantAllocationChangeResponse{}
+	for _, change := range changes {
+		resps = append(resps, toMerchantAllocationChangeResponse(change))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Allocation changes fetched successfully",
+		"data": response.GetAllocationChangesResponse{
+			Changes:    resps,
+			Pagination: pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// RepublishAllocationChange implements MerchantProductControllerInterface.
+func (m *merchantProductController) RepublishAllocationChange(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	change, err := m.merchantProductUsecase.RepublishAllocationChange(ctx, authz.GetIdentity(c), id)
+	if err != nil {
+		log.Errorf("[MerchantProductController] RepublishAllocationChange - 1: %v", err)
+		switch {
+		case errors.Is(err, authz.ErrForbidden):
+			return authz.Forbidden(c, "You do not have access to this allocation change")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Allocation change not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to republish allocation change",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Allocation change republished successfully",
+		"data":    toMerchantAllocationChangeResponse(*change),
+	})
+}
+
 // GetMerchantProductByBarcode implements MerchantProductControllerInterface.
 func (m *merchantProductController) GetMerchantProductByBarcode(c *fiber.Ctx) error {
 	ctx := c.Context()
@@ -112,25 +299,20 @@ func (m *merchantProductController) GetMerchantProductByBarcode(c *fiber.Ctx) er
 		merchantIDUint = conv.StringToUint(c.Query("merchant_id"))
 	}
 
-	merchantProduct, product, warehouse, err := m.merchantProductUsecase.GetMerchantProductByBarcode(ctx, barcode, merchantIDUint)
+	merchantProduct, product, warehouse, scale, err := m.merchantProductUsecase.GetMerchantProductByBarcode(ctx, barcode, merchantIDUint)
 	if err != nil {
 		log.Errorf("[MerchantProductController] GetMerchantProductByBarcode - 1: %v", err)
+		if errors.Is(err, usecase.ErrInvalidBarcode) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get merchant product by barcode",
 		})
 	}
 
-	productResponse := httpclient.MapProductResponseToMerchantProduct(product)
-	warehouseResponse := httpclient.MapWarehouseResponseToMerchantProduct(warehouse)
-
-	productResponse.ID = merchantProduct.ID
-	productResponse.MerchantID = merchantProduct.MerchantID
-	productResponse.ProductID = merchantProduct.ProductID
-	productResponse.Stock = merchantProduct.Stock
-	productResponse.WarehouseID = merchantProduct.WarehouseID
-	productResponse.WarehouseName = warehouseResponse.WarehouseName
-	productResponse.WarehousePhoto = warehouseResponse.WarehousePhoto
-	productResponse.WarehousePhone = warehouseResponse.WarehousePhone
+	productResponse := toScannedMerchantProductResponse(merchantProduct, product, warehouse, scale)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Merchant product fetched successfully",
@@ -138,6 +320,57 @@ func (m *merchantProductController) GetMerchantProductByBarcode(c *fiber.Ctx) er
 	})
 }
 
+// ResolveBarcodes implements MerchantProductControllerInterface.
+func (m *merchantProductController) ResolveBarcodes(c *fiber.Ctx) error {
+	var req request.ResolveBarcodesRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantProductController] ResolveBarcodes - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] ResolveBarcodes - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	resolutions, err := m.merchantProductUsecase.ResolveBarcodes(c.Context(), req.Barcodes, req.MerchantID)
+	if err != nil {
+		log.Errorf("[MerchantProductController] ResolveBarcodes - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to resolve barcodes",
+		})
+	}
+
+	resp := response.ResolveBarcodesResponse{
+		Results:    []response.BarcodeResolutionResponse{},
+		Unresolved: []string{},
+	}
+	for _, resolution := range resolutions {
+		result := response.BarcodeResolutionResponse{
+			Barcode: resolution.Barcode,
+			Status:  resolution.Status,
+		}
+
+		if resolution.Status == usecase.BarcodeResolved {
+			merchantProduct := toScannedMerchantProductResponse(resolution.MerchantProduct, resolution.Product, resolution.Warehouse, resolution.Scale)
+			result.MerchantProduct = &merchantProduct
+		} else {
+			resp.Unresolved = append(resp.Unresolved, resolution.Barcode)
+		}
+
+		resp.Results = append(resp.Results, result)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Barcodes resolved successfully",
+		"data":    resp,
+	})
+}
+
 // GetMerchantProductByID implements MerchantProductControllerInterface.
 func (m *merchantProductController) GetMerchantProductByID(c *fiber.Ctx) error {
 	ctx := c.Context()
@@ -159,6 +392,11 @@ func (m *merchantProductController) GetMerchantProductByID(c *fiber.Ctx) error {
 	productResponse.MerchantID = merchantProduct.MerchantID
 	productResponse.ProductID = merchantProduct.ProductID
 	productResponse.Stock = merchantProduct.Stock
+	productResponse.MinStock = merchantProduct.MinStock
+	productResponse.MaxStock = merchantProduct.MaxStock
+	productResponse.IsLowStock = merchantProduct.IsLowStock()
+	productResponse.EffectivePrice = int(merchantProduct.EffectivePrice(int64(productResponse.ProductPrice)))
+	productResponse.HasPriceOverride = merchantProduct.HasPriceOverride()
 	productResponse.WarehouseID = merchantProduct.WarehouseID
 	productResponse.WarehouseName = warehouseResponse.WarehouseName
 	productResponse.WarehousePhoto = warehouseResponse.WarehousePhoto
@@ -182,6 +420,19 @@ func (m *merchantProductController) GetMerchantProducts(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] GetMerchantProducts - 3: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.StockMin != nil && req.StockMax != nil && *req.StockMin > *req.StockMax {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "stock_min must not be greater than stock_max",
+		})
+	}
+
 	if req.Page <= 0 {
 		req.Page = 1
 	}
@@ -189,7 +440,21 @@ func (m *merchantProductController) GetMerchantProducts(c *fiber.Ctx) error {
 		req.Limit = 10
 	}
 
-	merchantProducts, products, warehouses, total, err := m.merchantProductUsecase.GetMerchantProducts(ctx, req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder, req.MerchantID, req.ProductID)
+	filter := repository.MerchantProductFilter{
+		Page:        req.Page,
+		Limit:       req.Limit,
+		Search:      req.Search,
+		SortBy:      req.SortBy,
+		SortOrder:   req.SortOrder,
+		MerchantID:  req.MerchantID,
+		ProductID:   req.ProductID,
+		WarehouseID: req.WarehouseID,
+		CategoryID:  req.CategoryID,
+		StockMin:    req.StockMin,
+		StockMax:    req.StockMax,
+	}
+
+	merchantProducts, products, warehouses, total, err := m.merchantProductUsecase.GetMerchantProducts(ctx, filter)
 	if err != nil {
 		log.Errorf("[MerchantProductController] GetMerchantProducts - 2: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -215,6 +480,9 @@ func (m *merchantProductController) GetMerchantProducts(c *fiber.Ctx) error {
 			MerchantID:  mp.MerchantID,
 			ProductID:   mp.ProductID,
 			Stock:       mp.Stock,
+			MinStock:    mp.MinStock,
+			MaxStock:    mp.MaxStock,
+			IsLowStock:  mp.IsLowStock(),
 			WarehouseID: mp.WarehouseID,
 		}
 
@@ -226,6 +494,8 @@ func (m *merchantProductController) GetMerchantProducts(c *fiber.Ctx) error {
 			productResponse.ProductCategory = product.Category.Name
 			productResponse.ProductCategoryPhoto = product.Category.Photo
 		}
+		productResponse.EffectivePrice = int(mp.EffectivePrice(int64(productResponse.ProductPrice)))
+		productResponse.HasPriceOverride = mp.HasPriceOverride()
 
 		if warehouse, exists := warehouseMap[mp.WarehouseID]; exists {
 			productResponse.WarehouseName = warehouse.Name
@@ -268,6 +538,30 @@ func (m *merchantProductController) GetProductTotalStock(c *fiber.Ctx) error {
 	})
 }
 
+// GetWarehouseAllocations implements MerchantProductControllerInterface.
+// Dipakai warehouse-service sebelum menghapus warehouse.
+func (m *merchantProductController) GetWarehouseAllocations(c *fiber.Ctx) error {
+	warehouseID := conv.StringToUint(c.Params("warehouse_id"))
+
+	summary, err := m.merchantProductUsecase.GetWarehouseAllocationSummary(c.Context(), warehouseID)
+	if err != nil {
+		log.Errorf("[MerchantProductController] GetWarehouseAllocations - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get warehouse allocations",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Warehouse allocations fetched successfully",
+		"data": response.WarehouseAllocationResponse{
+			WarehouseID:          warehouseID,
+			MerchantCount:        summary.MerchantCount,
+			MerchantProductCount: summary.ProductCount,
+			TotalStock:           summary.TotalStock,
+		},
+	})
+}
+
 // UpdateMerchantProduct implements MerchantProductControllerInterface.
 func (m *merchantProductController) UpdateMerchantProduct(c *fiber.Ctx) error {
 	ctx := c.Context()
@@ -297,8 +591,14 @@ func (m *merchantProductController) UpdateMerchantProduct(c *fiber.Ctx) error {
 		MerchantID:  req.MerchantID,
 	}
 
-	if err := m.merchantProductUsecase.UpdateMerchantProduct(ctx, &reqModel); err != nil {
+	actorID := conv.StringToUint(c.Get("X-User-ID"))
+	if err := m.merchantProductUsecase.UpdateMerchantProduct(ctx, &reqModel, actorID); err != nil {
 		log.Errorf("[MerchantProductController] UpdateMerchantProduct - 3: %v", err)
+		if errors.Is(err, repository.ErrSupplierChangeReducesStock) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to update merchant product",
 		})
@@ -309,8 +609,473 @@ func (m *merchantProductController) UpdateMerchantProduct(c *fiber.Ctx) error {
 	})
 }
 
+// GetStockMovements implements MerchantProductControllerInterface.
+func (m *merchantProductController) GetStockMovements(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantProductID := conv.StringToUint(c.Params("merchant_product_id"))
+
+	var req request.GetStockMovementsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantProductController] GetStockMovements - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] GetStockMovements - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	// end_date inklusif, sehingga batas atas adalah awal hari berikutnya
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		parsed, _ := time.ParseInLocation(movementDateLayout, req.StartDate, time.Local)
+		startDate = &parsed
+	}
+	if req.EndDate != "" {
+		parsed, _ := time.ParseInLocation(movementDateLayout, req.EndDate, time.Local)
+		parsed = parsed.AddDate(0, 0, 1)
+		endDate = &parsed
+	}
+
+	movements, total, err := m.merchantProductUsecase.GetStockMovements(ctx, merchantProductID, startDate, endDate, req.MovementType, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[MerchantProductController] GetStockMovements - 3: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant product not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get stock movements",
+		})
+	}
+
+	movementResponses := []response.StockMovementResponse{}
+	for _, movement := range movements {
+		movementResponses = append(movementResponses, toStockMovementResponse(movement))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stock movements fetched successfully",
+		"data": response.GetStockMovementsResponse{
+			Movements:  movementResponses,
+			Pagination: pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// RecordStockMovement implements MerchantProductControllerInterface.
+func (m *merchantProductController) RecordStockMovement(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantProductID := conv.StringToUint(c.Params("merchant_product_id"))
+
+	var req request.RecordStockMovementRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantProductController] RecordStockMovement - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] RecordStockMovement - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	actorID := conv.StringToUint(c.Get("X-User-ID"))
+	movement, err := m.merchantProductUsecase.RecordStockMovement(ctx, merchantProductID, req.MovementType, req.Quantity, actorID, req.Reference, req.Note)
+	if err != nil {
+		log.Errorf("[MerchantProductController] RecordStockMovement - 3: %v", err)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant product not found",
+			})
+		case errors.Is(err, repository.ErrStockNotEnough), errors.Is(err, usecase.ErrInvalidStockMovement):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to record stock movement",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Stock movement recorded successfully",
+		"data":    toStockMovementResponse(*movement),
+	})
+}
+
+// UpdateMinStock implements MerchantProductControllerInterface.
+func (m *merchantProductController) UpdateMinStock(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantProductID := conv.StringToUint(c.Params("merchant_product_id"))
+
+	var req request.UpdateMinStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantProductController] UpdateMinStock - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] UpdateMinStock - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	merchantProduct, err := m.merchantProductUsecase.UpdateMinStock(ctx, merchantProductID, *req.MinStock)
+	if err != nil {
+		log.Errorf("[MerchantProductController] UpdateMinStock - 3: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant product not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to update minimum stock",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Minimum stock updated successfully",
+		"data": fiber.Map{
+			"id":           merchantProduct.ID,
+			"stock":        merchantProduct.Stock,
+			"min_stock":    merchantProduct.MinStock,
+			"is_low_stock": merchantProduct.IsLowStock(),
+		},
+	})
+}
+
+// UpdateStockLevels implements MerchantProductControllerInterface.
+func (m *merchantProductController) UpdateStockLevels(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantProductID := conv.StringToUint(c.Params("merchant_product_id"))
+
+	var req request.UpdateStockLevelsRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantProductController] UpdateStockLevels - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] UpdateStockLevels - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	merchantProduct, err := m.merchantProductUsecase.UpdateStockLevels(ctx, merchantProductID, *req.MinStock, *req.MaxStock)
+	if err != nil {
+		log.Errorf("[MerchantProductController] UpdateStockLevels - 3: %v", err)
+		if errors.Is(err, usecase.ErrInvalidStockLevels) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant product not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to update stock levels",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stock levels updated successfully",
+		"data": fiber.Map{
+			"id":                     merchantProduct.ID,
+			"stock":                  merchantProduct.Stock,
+			"min_stock":              merchantProduct.MinStock,
+			"max_stock":              merchantProduct.MaxStock,
+			"is_low_stock":           merchantProduct.IsLowStock(),
+			"replenishment_quantity": merchantProduct.ReplenishmentQuantity(),
+		},
+	})
+}
+
+// GetLowStockMerchantProducts implements MerchantProductControllerInterface.
+func (m *merchantProductController) GetLowStockMerchantProducts(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.GetLowStockRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantProductController] GetLowStockMerchantProducts - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] GetLowStockMerchantProducts - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	merchantProducts, products, total, err := m.merchantProductUsecase.GetLowStockMerchantProducts(ctx, req.MerchantID, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[MerchantProductController] GetLowStockMerchantProducts - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get low stock merchant products",
+		})
+	}
+
+	productMap := make(map[uint]*httpclient.ProductResponse)
+	for i := range products {
+		productMap[products[i].ID] = &products[i]
+	}
+
+	lowStockResponses := []response.LowStockMerchantProductResponse{}
+	for _, mp := range merchantProducts {
+		lowStockResponse := response.LowStockMerchantProductResponse{
+			MerchantProductID: mp.ID,
+			MerchantID:        mp.MerchantID,
+			MerchantName:      mp.Merchant.Name,
+			ProductID:         mp.ProductID,
+			Stock:             mp.Stock,
+			MinStock:          mp.MinStock,
+			Shortage:          mp.MinStock - mp.Stock,
+		}
+
+		if product, exists := productMap[mp.ProductID]; exists {
+			lowStockResponse.ProductName = product.Name
+			lowStockResponse.ProductPhoto = product.Thumbnail
+		}
+
+		lowStockResponses = append(lowStockResponses, lowStockResponse)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Low stock merchant products fetched successfully",
+		"data": response.GetLowStockMerchantProductsResponse{
+			MerchantProducts: lowStockResponses,
+			Pagination:       pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// SetPrice implements MerchantProductControllerInterface.
+func (m *merchantProductController) SetPrice(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantProductID := conv.StringToUint(c.Params("merchant_product_id"))
+
+	var req request.SetMerchantProductPriceRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantProductController] SetPrice - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantProductController] SetPrice - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	var effectiveFrom *time.Time
+	if req.EffectiveFrom != "" {
+		parsed, _ := time.Parse(time.RFC3339, req.EffectiveFrom)
+		effectiveFrom = &parsed
+	}
+
+	price, err := m.merchantProductUsecase.SetPrice(ctx, merchantProductID, req.Price, effectiveFrom, authz.GetIdentity(c).UserID)
+	if err != nil {
+		log.Errorf("[MerchantProductController] SetPrice - 3: %v", err)
+		if errors.Is(err, usecase.ErrInvalidPrice) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant product not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to set merchant product price",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Merchant product price set successfully",
+		"data":    toMerchantProductPriceResponse(*price),
+	})
+}
+
+// GetPrices implements MerchantProductControllerInterface.
+func (m *merchantProductController) GetPrices(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantProductID := conv.StringToUint(c.Params("merchant_product_id"))
+
+	prices, err := m.merchantProductUsecase.GetPrices(ctx, merchantProductID)
+	if err != nil {
+		log.Errorf("[MerchantProductController] GetPrices - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant product not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get merchant product prices",
+		})
+	}
+
+	resps := []response.MerchantProductPriceResponse{}
+	for _, price := range prices {
+		resps = append(resps, toMerchantProductPriceResponse(price))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant product prices fetched successfully",
+		"data":    resps,
+	})
+}
+
+// DeleteScheduledPrice implements MerchantProductControllerInterface.
+func (m *merchantProductController) DeleteScheduledPrice(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantProductID := conv.StringToUint(c.Params("merchant_product_id"))
+	priceID := conv.StringToUint(c.Params("price_id"))
+
+	if err := m.merchantProductUsecase.DeleteScheduledPrice(ctx, merchantProductID, priceID); err != nil {
+		log.Errorf("[MerchantProductController] DeleteScheduledPrice - 1: %v", err)
+		if errors.Is(err, repository.ErrPriceAlreadyEffective) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Merchant product price not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to delete merchant product price",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant product price deleted successfully",
+	})
+}
+
 func NewMerchantProductController(merchantProductUsecase usecase.MerchantProductUsecaseInterface) MerchantProductControllerInterface {
 	return &merchantProductController{
 		merchantProductUsecase: merchantProductUsecase,
 	}
 }
+
+func toMerchantAllocationChangeResponse(change model.MerchantAllocationChange) response.MerchantAllocationChangeResponse {
+	return response.MerchantAllocationChangeResponse{
+		ID:                change.ID,
+		Reference:         change.Reference(),
+		MerchantProductID: change.MerchantProductID,
+		MerchantID:        change.MerchantID,
+		ProductID:         change.ProductID,
+		WarehouseID:       change.WarehouseID,
+		Quantity:          change.Quantity,
+		PublishedAt:       change.PublishedAt,
+		FailedAt:          change.FailedAt,
+		FailureReason:     change.FailureReason,
+		CreatedAt:         change.CreatedAt,
+	}
+}
+
+func toStockMovementResponse(movement model.MerchantStockMovement) response.StockMovementResponse {
+	return response.StockMovementResponse{
+		ID:                movement.ID,
+		MerchantProductID: movement.MerchantProductID,
+		MerchantID:        movement.MerchantID,
+		ProductID:         movement.ProductID,
+		MovementType:      movement.MovementType,
+		QuantityDelta:     movement.QuantityDelta,
+		BalanceAfter:      movement.BalanceAfter,
+		ActorID:           movement.ActorID,
+		Reference:         movement.Reference,
+		Note:              movement.Note,
+		CreatedAt:         movement.CreatedAt,
+	}
+}
+
+func toMerchantProductPriceResponse(price model.MerchantProductPrice) response.MerchantProductPriceResponse {
+	return response.MerchantProductPriceResponse{
+		ID:                price.ID,
+		MerchantProductID: price.MerchantProductID,
+		Price:             price.Price,
+		EffectiveFrom:     price.EffectiveFrom,
+		IsScheduled:       price.IsScheduled(time.Now()),
+		CreatedBy:         price.CreatedBy,
+		CreatedAt:         price.CreatedAt,
+	}
+}
+
+// toScannedMerchantProductResponse menggabungkan merchant product, detail product, warehouse,
+// dan hasil label timbangan (jika ada) untuk respons scan barcode
+func toScannedMerchantProductResponse(merchantProduct *model.MerchantProduct, product *httpclient.ProductResponse, warehouse *httpclient.WarehouseResponse, scale *model.ScaleBarcode) response.MerchantProduct {
+	productResponse := httpclient.MapProductResponseToMerchantProduct(product)
+
+	productResponse.ID = merchantProduct.ID
+	productResponse.MerchantID = merchantProduct.MerchantID
+	productResponse.ProductID = merchantProduct.ProductID
+	productResponse.Stock = merchantProduct.Stock
+	productResponse.MinStock = merchantProduct.MinStock
+	productResponse.MaxStock = merchantProduct.MaxStock
+	productResponse.IsLowStock = merchantProduct.IsLowStock()
+	productResponse.EffectivePrice = int(merchantProduct.EffectivePrice(int64(productResponse.ProductPrice)))
+	productResponse.HasPriceOverride = merchantProduct.HasPriceOverride()
+	productResponse.WarehouseID = merchantProduct.WarehouseID
+	if warehouse != nil {
+		warehouseResponse := httpclient.MapWarehouseResponseToMerchantProduct(warehouse)
+		productResponse.WarehouseName = warehouseResponse.WarehouseName
+		productResponse.WarehousePhoto = warehouseResponse.WarehousePhoto
+		productResponse.WarehousePhone = warehouseResponse.WarehousePhone
+	}
+
+	if scale != nil {
+		unitPrice := int64(productResponse.EffectivePrice)
+		productResponse.Scale = &response.ScaleBarcodeResponse{
+			Barcode:       scale.Barcode,
+			RuleID:        scale.Rule.ID,
+			RuleName:      scale.Rule.Name,
+			Measure:       scale.Rule.Measure,
+			ItemCode:      scale.ItemCode,
+			EmbeddedValue: scale.EmbeddedValue,
+			Quantity:      scale.Quantity(unitPrice),
+			UnitPrice:     unitPrice,
+			LinePrice:     scale.LinePrice(unitPrice),
+		}
+	}
+
+	return productResponse
+}
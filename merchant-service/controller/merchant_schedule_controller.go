@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/conv"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type MerchantScheduleControllerInterface interface {
+	GetSchedule(c *fiber.Ctx) error
+	ReplaceOpeningHours(c *fiber.Ctx) error
+	GetClosures(c *fiber.Ctx) error
+	CreateClosure(c *fiber.Ctx) error
+	DeleteClosure(c *fiber.Ctx) error
+}
+
+type merchantScheduleController struct {
+	merchantScheduleUsecase usecase.MerchantScheduleUsecaseInterface
+}
+
+// GetSchedule implements MerchantScheduleControllerInterface.
+func (m *merchantScheduleController) GetSchedule(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	merchant, err := m.merchantScheduleUsecase.GetSchedule(c.Context(), merchantID)
+	if err != nil {
+		log.Errorf("[MerchantScheduleController] GetSchedule - 1: %v", err)
+		return merchantScheduleErrorResponse(c, err, "Failed to get merchant opening hours")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant opening hours fetched successfully",
+		"data":    toMerchantScheduleResponse(*merchant, time.Now()),
+	})
+}
+
+// ReplaceOpeningHours implements MerchantScheduleControllerInterface.
+func (m *merchantScheduleController) ReplaceOpeningHours(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	var req request.ReplaceOpeningHoursRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantScheduleController] ReplaceOpeningHours - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantScheduleController] ReplaceOpeningHours - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	hours := make([]model.MerchantOpeningHour, 0, len(req.Hours))
+	for _, hour := range req.Hours {
+		hours = append(hours, model.MerchantOpeningHour{
+			Weekday:  *hour.Weekday,
+			OpensAt:  hour.OpensAt,
+			ClosesAt: hour.ClosesAt,
+		})
+	}
+
+	merchant, err := m.merchantScheduleUsecase.ReplaceOpeningHours(c.Context(), merchantID, req.Timezone, hours)
+	if err != nil {
+		log.Errorf("[MerchantScheduleController] ReplaceOpeningHours - 3: %v", err)
+		return merchantScheduleErrorResponse(c, err, "Failed to update merchant opening hours")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant opening hours updated successfully",
+		"data":    toMerchantScheduleResponse(*merchant, time.Now()),
+	})
+}
+
+// GetClosures implements MerchantScheduleControllerInterface.
+func (m *merchantScheduleController) GetClosures(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	var req request.GetMerchantClosuresRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantScheduleController] GetClosures - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	closures, err := m.merchantScheduleUsecase.GetClosures(c.Context(), merchantID, req.IncludePast)
+	if err != nil {
+		log.Errorf("[MerchantScheduleController] GetClosures - 2: %v", err)
+		return merchantScheduleErrorResponse(c, err, "Failed to get merchant closures")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant closures fetched successfully",
+		"data":    toMerchantClosureResponses(closures, time.Now()),
+	})
+}
+
+// CreateClosure implements MerchantScheduleControllerInterface.
+func (m *merchantScheduleController) CreateClosure(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	var req request.CreateMerchantClosureRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantScheduleController] CreateClosure - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantScheduleController] CreateClosure - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	// Format sudah divalidasi oleh validator
+	startsAt, _ := time.Parse(time.RFC3339, req.StartsAt)
+	endsAt, _ := time.Parse(time.RFC3339, req.EndsAt)
+
+	closure := model.MerchantClosure{
+		MerchantID: merchantID,
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
+		Reason:     req.Reason,
+		CreatedBy:  authz.GetIdentity(c).UserID,
+	}
+
+	if err := m.merchantScheduleUsecase.CreateClosure(c.Context(), &closure); err != nil {
+		log.Errorf("[MerchantScheduleController] CreateClosure - 3: %v", err)
+		return merchantScheduleErrorResponse(c, err, "Failed to create merchant closure")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Merchant closure created successfully",
+		"data":    toMerchantClosureResponse(closure, time.Now()),
+	})
+}
+
+// DeleteClosure implements MerchantScheduleControllerInterface.
+func (m *merchantScheduleController) DeleteClosure(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("id"))
+	closureID := conv.StringToUint(c.Params("closure_id"))
+
+	if err := m.merchantScheduleUsecase.DeleteClosure(c.Context(), merchantID, closureID); err != nil {
+		log.Errorf("[MerchantScheduleController] DeleteClosure - 1: %v", err)
+		return merchantScheduleErrorResponse(c, err, "Failed to delete merchant closure")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant closure deleted successfully",
+	})
+}
+
+func NewMerchantScheduleController(merchantScheduleUsecase usecase.MerchantScheduleUsecaseInterface) MerchantScheduleControllerInterface {
+	return &merchantScheduleController{
+		merchantScheduleUsecase: merchantScheduleUsecase,
+	}
+}
+
+func merchantScheduleErrorResponse(c *fiber.Ctx, err error, fallbackMessage string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Merchant or closure not found",
+		})
+	case errors.Is(err, usecase.ErrInvalidMerchantSchedule):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": fallbackMessage,
+	})
+}
+
+func toMerchantScheduleResponse(merchant model.Merchant, now time.Time) response.MerchantScheduleResponse {
+	isOpen, closedReason := merchant.IsOpenAt(now)
+	resp := response.MerchantScheduleResponse{
+		MerchantID:   merchant.ID,
+		Status:       merchant.Status,
+		Timezone:     merchant.Timezone,
+		IsOpen:       isOpen,
+		ClosedReason: closedReason,
+		OpeningHours: []response.MerchantOpeningHourResponse{},
+		Closures:     toMerchantClosureResponses(merchant.Closures, now),
+	}
+
+	for _, hour := range merchant.OpeningHours {
+		resp.OpeningHours = append(resp.OpeningHours, response.MerchantOpeningHourResponse{
+			Weekday:  hour.Weekday,
+			OpensAt:  hour.OpensAt,
+			ClosesAt: hour.ClosesAt,
+		})
+	}
+
+	return resp
+}
+
+func toMerchantClosureResponses(closures []model.MerchantClosure, now time.Time) []response.MerchantClosureResponse {
+	resps := []response.MerchantClosureResponse{}
+	for _, closure := range closures {
+		resps = append(resps, toMerchantClosureResponse(closure, now))
+	}
+
+	return resps
+}
+
+func toMerchantClosureResponse(closure model.MerchantClosure, now time.Time) response.MerchantClosureResponse {
+	return response.MerchantClosureResponse{
+		ID:        closure.ID,
+		StartsAt:  closure.StartsAt,
+		EndsAt:    closure.EndsAt,
+		Reason:    closure.Reason,
+		CreatedBy: closure.CreatedBy,
+		IsActive:  closure.Covers(now),
+	}
+}
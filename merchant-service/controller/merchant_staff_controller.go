@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/conv"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/repository"
+	"micro-warehouse/merchant-service/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type MerchantStaffControllerInterface interface {
+	GetStaff(c *fiber.Ctx) error
+	AddStaff(c *fiber.Ctx) error
+	UpdateStaff(c *fiber.Ctx) error
+	EndStaff(c *fiber.Ctx) error
+}
+
+type merchantStaffController struct {
+	merchantStaffUsecase usecase.MerchantStaffUsecaseInterface
+}
+
+// GetStaff implements MerchantStaffControllerInterface.
+func (m *merchantStaffController) GetStaff(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	var req request.GetMerchantStaffRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[MerchantStaffController] GetStaff - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	staff, userNames, err := m.merchantStaffUsecase.GetStaff(ctx, authz.GetIdentity(c), merchantID, req.IncludeInactive)
+	if err != nil {
+		log.Errorf("[MerchantStaffController] GetStaff - 2: %v", err)
+		return merchantStaffErrorResponse(c, err, "Failed to get merchant staff")
+	}
+
+	now := time.Now()
+	resps := []response.MerchantStaffResponse{}
+	for _, member := range staff {
+		resp := toMerchantStaffResponse(member, now)
+		resp.UserName = userNames[member.UserID]
+		resps = append(resps, resp)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant staff fetched successfully",
+		"data":    resps,
+	})
+}
+
+// AddStaff implements MerchantStaffControllerInterface.
+func (m *merchantStaffController) AddStaff(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantID := conv.StringToUint(c.Params("id"))
+
+	var req request.MerchantStaffRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantStaffController] AddStaff - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantStaffController] AddStaff - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	staff := model.MerchantStaff{
+		MerchantID: merchantID,
+		UserID:     req.UserID,
+		Role:       req.Role,
+		StartDate:  time.Now(),
+		EndDate:    parseStaffDate(req.EndDate),
+	}
+	if startDate := parseStaffDate(req.StartDate); startDate != nil {
+		staff.StartDate = *startDate
+	}
+
+	if err := m.merchantStaffUsecase.AddStaff(ctx, authz.GetIdentity(c), &staff); err != nil {
+		log.Errorf("[MerchantStaffController] AddStaff - 3: %v", err)
+		return merchantStaffErrorResponse(c, err, "Failed to add merchant staff")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Merchant staff added successfully",
+		"data":    toMerchantStaffResponse(staff, time.Now()),
+	})
+}
+
+// UpdateStaff implements MerchantStaffControllerInterface.
+func (m *merchantStaffController) UpdateStaff(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantID := conv.StringToUint(c.Params("id"))
+	staffID := conv.StringToUint(c.Params("staff_id"))
+
+	var req request.UpdateMerchantStaffRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[MerchantStaffController] UpdateStaff - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[MerchantStaffController] UpdateStaff - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	staff := model.MerchantStaff{
+		ID:         staffID,
+		MerchantID: merchantID,
+		Role:       req.Role,
+		StartDate:  *parseStaffDate(req.StartDate),
+		EndDate:    parseStaffDate(req.EndDate),
+	}
+
+	updated, err := m.merchantStaffUsecase.UpdateStaff(ctx, authz.GetIdentity(c), &staff)
+	if err != nil {
+		log.Errorf("[MerchantStaffController] UpdateStaff - 3: %v", err)
+		return merchantStaffErrorResponse(c, err, "Failed to update merchant staff")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant staff updated successfully",
+		"data":    toMerchantStaffResponse(*updated, time.Now()),
+	})
+}
+
+// EndStaff implements MerchantStaffControllerInterface.
+func (m *merchantStaffController) EndStaff(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantID := conv.StringToUint(c.Params("id"))
+	staffID := conv.StringToUint(c.Params("staff_id"))
+
+	staff, err := m.merchantStaffUsecase.EndStaff(ctx, authz.GetIdentity(c), merchantID, staffID)
+	if err != nil {
+		log.Errorf("[MerchantStaffController] EndStaff - 1: %v", err)
+		return merchantStaffErrorResponse(c, err, "Failed to end merchant staff assignment")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Merchant staff assignment ended successfully",
+		"data":    toMerchantStaffResponse(*staff, time.Now()),
+	})
+}
+
+func NewMerchantStaffController(merchantStaffUsecase usecase.MerchantStaffUsecaseInterface) MerchantStaffControllerInterface {
+	return &merchantStaffController{
+		merchantStaffUsecase: merchantStaffUsecase,
+	}
+}
+
+func merchantStaffErrorResponse(c *fiber.Ctx, err error, fallbackMessage string) error {
+	switch {
+	case errors.Is(err, authz.ErrForbidden):
+		return authz.Forbidden(c, "You do not have access to this merchant's staff")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Merchant staff not found",
+		})
+	case errors.Is(err, repository.ErrStaffAssignmentOverlap):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrInvalidMerchantStaff):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": fallbackMessage,
+	})
+}
+
+// parseStaffDate: format sudah divalidasi oleh validator, string kosong berarti nil
+func parseStaffDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil
+	}
+
+	return &date
+}
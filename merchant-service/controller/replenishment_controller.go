@@ -0,0 +1,262 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/conv"
+	"micro-warehouse/merchant-service/pkg/pagination"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/repository"
+	"micro-warehouse/merchant-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type ReplenishmentControllerInterface interface {
+	GetSuggestions(c *fiber.Ctx) error
+	GenerateReplenishmentOrders(c *fiber.Ctx) error
+
+	GetReplenishmentOrders(c *fiber.Ctx) error
+	GetReplenishmentOrderByID(c *fiber.Ctx) error
+	ApproveReplenishmentOrder(c *fiber.Ctx) error
+	RejectReplenishmentOrder(c *fiber.Ctx) error
+}
+
+type replenishmentController struct {
+	replenishmentUsecase usecase.ReplenishmentUsecaseInterface
+}
+
+// GetSuggestions implements ReplenishmentControllerInterface.
+func (r *replenishmentController) GetSuggestions(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var req request.GetReplenishmentSuggestionsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[ReplenishmentController] GetSuggestions - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	suggestions, err := r.replenishmentUsecase.GetSuggestions(ctx, authz.GetIdentity(c), req.MerchantID)
+	if err != nil {
+		log.Errorf("[ReplenishmentController] GetSuggestions - 2: %v", err)
+		return replenishmentErrorResponse(c, err, "Failed to get replenishment suggestions")
+	}
+
+	resps := []response.ReplenishmentOrderResponse{}
+	for _, suggestion := range suggestions {
+		resps = append(resps, toReplenishmentOrderResponse(suggestion))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Replenishment suggestions fetched successfully",
+		"data":    resps,
+	})
+}
+
+// GenerateReplenishmentOrders implements ReplenishmentControllerInterface.
+func (r *replenishmentController) GenerateReplenishmentOrders(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var req request.GenerateReplenishmentOrdersRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		log.Errorf("[ReplenishmentController] GenerateReplenishmentOrders - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	identity := authz.GetIdentity(c)
+	replenishmentOrders, err := r.replenishmentUsecase.GenerateDraftOrders(ctx, req.MerchantID, identity.UserID)
+	if err != nil {
+		log.Errorf("[ReplenishmentController] GenerateReplenishmentOrders - 2: %v", err)
+		return replenishmentErrorResponse(c, err, "Failed to generate replenishment orders")
+	}
+
+	resps := []response.ReplenishmentOrderResponse{}
+	for _, replenishmentOrder := range replenishmentOrders {
+		resps = append(resps, toReplenishmentOrderResponse(replenishmentOrder))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Replenishment orders generated successfully",
+		"data":    resps,
+	})
+}
+
+// GetReplenishmentOrders implements ReplenishmentControllerInterface.
+func (r *replenishmentController) GetReplenishmentOrders(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var req request.GetReplenishmentOrdersRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[ReplenishmentController] GetReplenishmentOrders - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[ReplenishmentController] GetReplenishmentOrders - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	replenishmentOrders, total, err := r.replenishmentUsecase.GetReplenishmentOrders(ctx, authz.GetIdentity(c), req.MerchantID, req.Status, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[ReplenishmentController] GetReplenishmentOrders - 3: %v", err)
+		return replenishmentErrorResponse(c, err, "Failed to get replenishment orders")
+	}
+
+	resps := []response.ReplenishmentOrderResponse{}
+	for _, replenishmentOrder := range replenishmentOrders {
+		resps = append(resps, toReplenishmentOrderResponse(replenishmentOrder))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Replenishment orders fetched successfully",
+		"data": response.GetReplenishmentOrdersResponse{
+			ReplenishmentOrders: resps,
+			Pagination:          pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// GetReplenishmentOrderByID implements ReplenishmentControllerInterface.
+func (r *replenishmentController) GetReplenishmentOrderByID(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	replenishmentOrder, err := r.replenishmentUsecase.GetReplenishmentOrderByID(ctx, authz.GetIdentity(c), id)
+	if err != nil {
+		log.Errorf("[ReplenishmentController] GetReplenishmentOrderByID - 1: %v", err)
+		return replenishmentErrorResponse(c, err, "Failed to get replenishment order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Replenishment order fetched successfully",
+		"data":    toReplenishmentOrderResponse(*replenishmentOrder),
+	})
+}
+
+// ApproveReplenishmentOrder implements ReplenishmentControllerInterface.
+func (r *replenishmentController) ApproveReplenishmentOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.ReviewReplenishmentOrderRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		log.Errorf("[ReplenishmentController] ApproveReplenishmentOrder - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	replenishmentOrder, err := r.replenishmentUsecase.ApproveReplenishmentOrder(ctx, authz.GetIdentity(c), id, req.Note)
+	if err != nil {
+		log.Errorf("[ReplenishmentController] ApproveReplenishmentOrder - 2: %v", err)
+		return replenishmentErrorResponse(c, err, "Failed to approve replenishment order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Replenishment order approved successfully",
+		"data":    toReplenishmentOrderResponse(*replenishmentOrder),
+	})
+}
+
+// RejectReplenishmentOrder implements ReplenishmentControllerInterface.
+func (r *replenishmentController) RejectReplenishmentOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.ReviewReplenishmentOrderRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		log.Errorf("[ReplenishmentController] RejectReplenishmentOrder - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := r.replenishmentUsecase.RejectReplenishmentOrder(ctx, authz.GetIdentity(c), id, req.Note); err != nil {
+		log.Errorf("[ReplenishmentController] RejectReplenishmentOrder - 2: %v", err)
+		return replenishmentErrorResponse(c, err, "Failed to reject replenishment order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Replenishment order rejected successfully",
+	})
+}
+
+func NewReplenishmentController(replenishmentUsecase usecase.ReplenishmentUsecaseInterface) ReplenishmentControllerInterface {
+	return &replenishmentController{
+		replenishmentUsecase: replenishmentUsecase,
+	}
+}
+
+func replenishmentErrorResponse(c *fiber.Ctx, err error, fallbackMessage string) error {
+	switch {
+	case errors.Is(err, authz.ErrForbidden):
+		return authz.Forbidden(c, "You do not have access to this merchant")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Replenishment order not found",
+		})
+	case errors.Is(err, repository.ErrReplenishmentOrderInvalidStatus):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": fallbackMessage,
+	})
+}
+
+func toReplenishmentOrderResponse(replenishmentOrder model.ReplenishmentOrder) response.ReplenishmentOrderResponse {
+	resp := response.ReplenishmentOrderResponse{
+		ID:           replenishmentOrder.ID,
+		MerchantID:   replenishmentOrder.MerchantID,
+		MerchantName: replenishmentOrder.Merchant.Name,
+		Status:       replenishmentOrder.Status,
+		CreatedBy:    replenishmentOrder.CreatedBy,
+		ReviewedBy:   replenishmentOrder.ReviewedBy,
+		ReviewedAt:   replenishmentOrder.ReviewedAt,
+		ReviewNote:   replenishmentOrder.ReviewNote,
+		Items:        []response.ReplenishmentOrderItemResponse{},
+	}
+
+	// Usulan yang belum disimpan tidak punya created_at
+	if !replenishmentOrder.CreatedAt.IsZero() {
+		resp.CreatedAt = &replenishmentOrder.CreatedAt
+	}
+
+	for _, item := range replenishmentOrder.Items {
+		resp.Items = append(resp.Items, response.ReplenishmentOrderItemResponse{
+			ID:                item.ID,
+			MerchantProductID: item.MerchantProductID,
+			ProductID:         item.ProductID,
+			WarehouseID:       item.WarehouseID,
+			CurrentStock:      item.CurrentStock,
+			MinStock:          item.MinStock,
+			MaxStock:          item.MaxStock,
+			WarehouseStock:    item.WarehouseStock,
+			SuggestedQuantity: item.SuggestedQuantity,
+			AllocatedQuantity: item.AllocatedQuantity,
+			Status:            item.Status,
+			FailureReason:     item.FailureReason,
+		})
+	}
+
+	return resp
+}
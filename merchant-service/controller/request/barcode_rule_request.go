@@ -0,0 +1,12 @@
+package request
+
+type BarcodeRuleRequest struct {
+	Name               string `json:"name" validate:"required,max=100"`
+	Prefix             string `json:"prefix" validate:"required,numeric,min=1,max=3"`
+	ItemCodeLength     int    `json:"item_code_length" validate:"required,min=1"`
+	Measure            string `json:"measure" validate:"required,oneof=weight price"`
+	ValueLength        int    `json:"value_length" validate:"required,min=1"`
+	Decimals           int    `json:"decimals" validate:"omitempty,min=0"`
+	HasValueCheckDigit bool   `json:"has_value_check_digit"`
+	IsActive           *bool  `json:"is_active"`
+}
@@ -5,15 +5,88 @@ type CreateMerchantProductRequest struct {
 	WarehouseID uint `json:"warehouse_id" validate:"required"`
 	Stock       int  `json:"stock" validate:"required"`
 	MerchantID  uint `json:"merchant_id" validate:"required"`
+	MinStock    int  `json:"min_stock" validate:"omitempty,min=0"`
+	MaxStock    int  `json:"max_stock" validate:"omitempty,gtfield=MinStock"`
 }
 
+// Search mencari nama, barcode atau nama category product
 type GetMerchantProductRequest struct {
-	Page       int    `query:"page" validate:"omitempty,min=1"`
-	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
-	Search     string `query:"search" validate:"omitempty"`
-	SortBy     string `query:"sort_by" validate:"omitempty,oneof=id product_id warehouse_id stock created_at"`
-	SortOrder  string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
-	MerchantID uint   `query:"merchant_id" validate:"omitempty"`
-	ProductID  uint   `query:"product_id" validate:"omitempty"`
-	KeeperID   uint   `query:"keeper_id" validate:"omitempty"`
+	Page        int    `query:"page" validate:"omitempty,min=1"`
+	Limit       int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Search      string `query:"search" validate:"omitempty"`
+	SortBy      string `query:"sort_by" validate:"omitempty,oneof=id product_id product_name warehouse_id stock created_at"`
+	SortOrder   string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	MerchantID  uint   `query:"merchant_id" validate:"omitempty"`
+	ProductID   uint   `query:"product_id" validate:"omitempty"`
+	WarehouseID uint   `query:"warehouse_id" validate:"omitempty"`
+	CategoryID  uint   `query:"category_id" validate:"omitempty"`
+	StockMin    *int   `query:"stock_min" validate:"omitempty,min=0"`
+	StockMax    *int   `query:"stock_max" validate:"omitempty,min=0"`
+	KeeperID    uint   `query:"keeper_id" validate:"omitempty"`
+}
+
+type ResolveBarcodesRequest struct {
+	MerchantID uint     `json:"merchant_id" validate:"required"`
+	Barcodes   []string `json:"barcodes" validate:"required,min=1,max=200,dive,required"`
+}
+
+type GetStockMovementsRequest struct {
+	Page         int    `query:"page" validate:"omitempty,min=1"`
+	Limit        int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	StartDate    string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate      string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	MovementType string `query:"movement_type" validate:"omitempty,oneof=opening_balance sale transfer_in adjustment return write_off return_to_warehouse stocktake"`
+}
+
+type RecordStockMovementRequest struct {
+	MovementType string `json:"movement_type" validate:"required,oneof=adjustment return write_off"`
+	Quantity     int    `json:"quantity" validate:"required"`
+	Reference    string `json:"reference" validate:"omitempty,max=100"`
+	Note         string `json:"note" validate:"omitempty"`
+}
+
+type UpdateMinStockRequest struct {
+	MinStock *int `json:"min_stock" validate:"required,min=0"`
+}
+
+type UpdateStockLevelsRequest struct {
+	MinStock *int `json:"min_stock" validate:"required,min=0"`
+	MaxStock *int `json:"max_stock" validate:"required,min=0"`
+}
+
+type GetLowStockRequest struct {
+	Page       int  `query:"page" validate:"omitempty,min=1"`
+	Limit      int  `query:"limit" validate:"omitempty,min=1,max=100"`
+	MerchantID uint `query:"merchant_id" validate:"omitempty"`
+}
+
+// Price kosong mengakhiri harga khusus merchant; EffectiveFrom kosong berarti berlaku sekarang
+type SetMerchantProductPriceRequest struct {
+	Price         *int64 `json:"price" validate:"omitempty,min=1"`
+	EffectiveFrom string `json:"effective_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// Latitude/Longitude menjadi titik asal jarak; jika kosong dipakai koordinat merchant_id
+type GetProductAvailabilityRequest struct {
+	ProductID  uint     `query:"product_id" validate:"required_without=Barcode"`
+	Barcode    string   `query:"barcode" validate:"omitempty,max=100"`
+	MerchantID uint     `query:"merchant_id" validate:"omitempty"`
+	Latitude   *float64 `query:"latitude" validate:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude  *float64 `query:"longitude" validate:"required_with=Latitude,omitempty,min=-180,max=180"`
+	SortBy     string   `query:"sort_by" validate:"omitempty,oneof=stock distance"`
+}
+
+type GetDeletedMerchantProductsRequest struct {
+	Page       int  `query:"page" validate:"omitempty,min=1"`
+	Limit      int  `query:"limit" validate:"omitempty,min=1,max=100"`
+	MerchantID uint `query:"merchant_id" validate:"omitempty"`
+	ProductID  uint `query:"product_id" validate:"omitempty"`
+}
+
+type GetAllocationChangesRequest struct {
+	Page        int  `query:"page" validate:"omitempty,min=1"`
+	Limit       int  `query:"limit" validate:"omitempty,min=1,max=100"`
+	MerchantID  uint `query:"merchant_id" validate:"omitempty"`
+	Unpublished bool `query:"unpublished"`
+	Failed      bool `query:"failed"`
 }
@@ -1,9 +1,121 @@
 package request
 
+// KeeperID opsional; jika diisi, user tersebut langsung masuk roster sebagai lead
 type CreateMerchantRequest struct {
 	Name     string `json:"name" validate:"required"`
-	KeeperID uint   `json:"keeper_id" validate:"required"`
+	KeeperID uint   `json:"keeper_id" validate:"omitempty"`
 	Address  string `json:"address" validate:"required"`
 	Phone    string `json:"phone" validate:"required"`
 	Photo    string `json:"photo" validate:"required"`
+
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,min=-180,max=180"`
+}
+
+// Staff merchant diubah lewat endpoint /merchants/:id/staff
+type UpdateMerchantRequest struct {
+	Name    string `json:"name" validate:"required"`
+	Address string `json:"address" validate:"required"`
+	Phone   string `json:"phone" validate:"required"`
+	Photo   string `json:"photo" validate:"required"`
+
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,min=-180,max=180"`
+}
+
+// StartDate kosong berarti mulai hari ini; EndDate kosong berarti tanpa batas
+type MerchantStaffRequest struct {
+	UserID    uint   `json:"user_id" validate:"required"`
+	Role      string `json:"role" validate:"required,oneof=lead cashier"`
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type UpdateMerchantStaffRequest struct {
+	Role      string `json:"role" validate:"required,oneof=lead cashier"`
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type GetMerchantStaffRequest struct {
+	IncludeInactive bool `query:"include_inactive"`
+}
+
+type GetMerchantsRequest struct {
+	Page      int    `query:"page" validate:"omitempty,min=1"`
+	Limit     int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Search    string `query:"search" validate:"omitempty"`
+	SortBy    string `query:"sort_by" validate:"omitempty,oneof=id name status created_at"`
+	SortOrder string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	KeeperID  uint   `query:"keeper_id" validate:"omitempty"`
+	Status    string `query:"status" validate:"omitempty,oneof=active suspended closed"`
+	OpenNow   bool   `query:"open_now"`
+	Archived  bool   `query:"archived"`
+}
+
+// Reason wajib untuk suspended dan closed, diabaikan untuk active
+type UpdateMerchantStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active suspended closed"`
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// Hours kosong berarti merchant buka sepanjang waktu
+type ReplaceOpeningHoursRequest struct {
+	Timezone string               `json:"timezone" validate:"omitempty,max=50"`
+	Hours    []OpeningHourRequest `json:"hours" validate:"omitempty,dive"`
+}
+
+// Weekday 0 = Minggu; ClosesAt <= OpensAt berarti tutup keesokan harinya
+type OpeningHourRequest struct {
+	Weekday  *int   `json:"weekday" validate:"required,min=0,max=6"`
+	OpensAt  string `json:"opens_at" validate:"required,datetime=15:04"`
+	ClosesAt string `json:"closes_at" validate:"required,datetime=15:04"`
+}
+
+type CreateMerchantClosureRequest struct {
+	StartsAt string `json:"starts_at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt   string `json:"ends_at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	Reason   string `json:"reason" validate:"required,max=500"`
+}
+
+type GetMerchantClosuresRequest struct {
+	IncludePast bool `query:"include_past"`
+}
+
+type GetDeletedMerchantsRequest struct {
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Search string `query:"search" validate:"omitempty"`
+}
+
+// RadiusKm dalam kilometer, dihitung sebagai jarak great-circle dari latitude/longitude
+type GetNearbyMerchantsRequest struct {
+	Latitude  *float64 `query:"latitude" validate:"required,min=-90,max=90"`
+	Longitude *float64 `query:"longitude" validate:"required,min=-180,max=180"`
+	RadiusKm  float64  `query:"radius_km" validate:"required,gt=0,max=500"`
+	Status    string   `query:"status" validate:"omitempty,oneof=active suspended closed"`
+	OpenNow   bool     `query:"open_now"`
+	Limit     int      `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type GetNearestWarehousesRequest struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+type CoordinateRequest struct {
+	Latitude  *float64 `json:"latitude" validate:"required,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required,min=-180,max=180"`
+}
+
+// Polygon minimal 3 titik berurutan; titik terakhir tidak perlu mengulang titik pertama.
+// IsActive kosong berarti zona aktif.
+type DeliveryZoneRequest struct {
+	Name     string              `json:"name" validate:"required,max=100"`
+	Polygon  []CoordinateRequest `json:"polygon" validate:"required,min=3,max=500,dive"`
+	IsActive *bool               `json:"is_active"`
+}
+
+type RouteDeliveryRequest struct {
+	Latitude  *float64 `query:"latitude" validate:"required,min=-90,max=90"`
+	Longitude *float64 `query:"longitude" validate:"required,min=-180,max=180"`
 }
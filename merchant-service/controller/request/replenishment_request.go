@@ -0,0 +1,20 @@
+package request
+
+type GetReplenishmentSuggestionsRequest struct {
+	MerchantID uint `query:"merchant_id" validate:"omitempty"`
+}
+
+type GenerateReplenishmentOrdersRequest struct {
+	MerchantID uint `json:"merchant_id" validate:"omitempty"`
+}
+
+type GetReplenishmentOrdersRequest struct {
+	Page       int    `query:"page" validate:"omitempty,min=1"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	MerchantID uint   `query:"merchant_id" validate:"omitempty"`
+	Status     string `query:"status" validate:"omitempty,oneof=draft approved rejected"`
+}
+
+type ReviewReplenishmentOrderRequest struct {
+	Note string `json:"note" validate:"omitempty"`
+}
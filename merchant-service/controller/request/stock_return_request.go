@@ -0,0 +1,20 @@
+package request
+
+type CreateStockReturnRequest struct {
+	MerchantID uint                           `json:"merchant_id" validate:"required"`
+	Reason     string                         `json:"reason" validate:"required"`
+	Items      []CreateStockReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// Condition kosong dianggap sellable; barang damaged masuk ke quarantined_stock di warehouse
+type CreateStockReturnItemRequest struct {
+	ProductID uint   `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	Condition string `json:"condition" validate:"omitempty,oneof=sellable damaged"`
+}
+
+type GetStockReturnsRequest struct {
+	Page       int  `query:"page" validate:"omitempty,min=1"`
+	Limit      int  `query:"limit" validate:"omitempty,min=1,max=100"`
+	MerchantID uint `query:"merchant_id" validate:"omitempty"`
+}
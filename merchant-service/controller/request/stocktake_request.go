@@ -0,0 +1,35 @@
+package request
+
+// CategoryID kosong berarti semua produk merchant ikut dihitung
+type OpenStocktakeRequest struct {
+	MerchantID uint   `json:"merchant_id" validate:"required"`
+	CategoryID uint   `json:"category_id" validate:"omitempty"`
+	Note       string `json:"note" validate:"omitempty,max=1000"`
+}
+
+type GetStocktakesRequest struct {
+	Page       int    `query:"page" validate:"omitempty,min=1"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	MerchantID uint   `query:"merchant_id" validate:"omitempty"`
+	Status     string `query:"status" validate:"omitempty,oneof=open approved cancelled"`
+}
+
+type GetStocktakeRequest struct {
+	VarianceOnly bool `query:"variance_only"`
+}
+
+// Mode add (default) menambahkan quantity ke hitungan sebelumnya sehingga setiap scan cukup quantity 1;
+// mode set menimpa hitungan untuk koreksi.
+type RecordStocktakeCountRequest struct {
+	Barcode    string `json:"barcode" validate:"required_without=ProductID"`
+	ProductID  uint   `json:"product_id" validate:"omitempty"`
+	Quantity   *int   `json:"quantity" validate:"omitempty,min=0"`
+	Mode       string `json:"mode" validate:"omitempty,oneof=add set"`
+	ReasonCode string `json:"reason_code" validate:"omitempty,oneof=miscount damaged expired theft other"`
+}
+
+// ReasonCode dipakai untuk baris selisih yang belum punya reason code
+type ApproveStocktakeRequest struct {
+	ReasonCode string `json:"reason_code" validate:"required,oneof=miscount damaged expired theft other"`
+	Note       string `json:"note" validate:"omitempty,max=1000"`
+}
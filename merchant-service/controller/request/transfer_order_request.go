@@ -0,0 +1,48 @@
+package request
+
+type CreateTransferOrderRequest struct {
+	MerchantID  uint                             `json:"merchant_id" validate:"required"`
+	WarehouseID uint                             `json:"warehouse_id" validate:"required"`
+	Note        string                           `json:"note" validate:"omitempty"`
+	Items       []CreateTransferOrderItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type CreateTransferOrderItemRequest struct {
+	ProductID uint `json:"product_id" validate:"required"`
+	Quantity  int  `json:"quantity" validate:"required,min=1"`
+}
+
+type GetTransferOrdersRequest struct {
+	Page        int    `query:"page" validate:"omitempty,min=1"`
+	Limit       int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	MerchantID  uint   `query:"merchant_id" validate:"omitempty"`
+	WarehouseID uint   `query:"warehouse_id" validate:"omitempty"`
+	Status      string `query:"status" validate:"omitempty,oneof=requested approved rejected cancelled picked shipped partially_received received"`
+}
+
+type ReviewTransferOrderRequest struct {
+	Note string `json:"note" validate:"omitempty"`
+}
+
+// Items kosong berarti semua item dikirim sesuai jumlah yang diminta
+type ShipTransferOrderRequest struct {
+	Items []ShipTransferOrderItemRequest `json:"items" validate:"omitempty,dive"`
+}
+
+type ShipTransferOrderItemRequest struct {
+	ProductID uint `json:"product_id" validate:"required"`
+	Quantity  int  `json:"quantity" validate:"min=0"`
+}
+
+type ReceiveTransferOrderRequest struct {
+	Note  string                            `json:"note" validate:"omitempty"`
+	Lines []ReceiveTransferOrderLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+type ReceiveTransferOrderLineRequest struct {
+	ProductID           uint   `json:"product_id" validate:"required"`
+	ReceivedQuantity    int    `json:"received_quantity" validate:"min=0"`
+	DiscrepancyQuantity int    `json:"discrepancy_quantity" validate:"min=0"`
+	DiscrepancyType     string `json:"discrepancy_type" validate:"omitempty,oneof=missing damaged"`
+	DiscrepancyReason   string `json:"discrepancy_reason" validate:"omitempty"`
+}
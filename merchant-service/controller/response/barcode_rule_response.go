@@ -0,0 +1,30 @@
+package response
+
+import "time"
+
+type BarcodeRuleResponse struct {
+	ID                 uint      `json:"id"`
+	Name               string    `json:"name"`
+	Prefix             string    `json:"prefix"`
+	ItemCodeLength     int       `json:"item_code_length"`
+	Measure            string    `json:"measure"`
+	ValueLength        int       `json:"value_length"`
+	Decimals           int       `json:"decimals"`
+	HasValueCheckDigit bool      `json:"has_value_check_digit"`
+	IsActive           bool      `json:"is_active"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ScaleBarcodeResponse berisi hasil pembacaan label timbangan untuk baris transaksi
+type ScaleBarcodeResponse struct {
+	Barcode       string  `json:"barcode"`
+	RuleID        uint    `json:"rule_id"`
+	RuleName      string  `json:"rule_name"`
+	Measure       string  `json:"measure"`
+	ItemCode      string  `json:"item_code"`
+	EmbeddedValue int64   `json:"embedded_value"`
+	Quantity      float64 `json:"quantity"`
+	UnitPrice     int64   `json:"unit_price"`
+	LinePrice     int64   `json:"line_price"`
+}
@@ -10,6 +10,8 @@ type MerchantProductResponse struct {
 	MerchantID  uint      `json:"merchant_id"`
 	ProductID   uint      `json:"product_id"`
 	Stock       int       `json:"stock"`
+	MinStock    int       `json:"min_stock"`
+	MaxStock    int       `json:"max_stock"`
 	WarehouseID uint      `json:"warehouse_id"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -26,13 +28,32 @@ type MerchantProduct struct {
 	ProductAbout         string `json:"product_about"`
 	ProductPhoto         string `json:"product_photo"`
 	ProductPrice         int    `json:"product_price"`
+	EffectivePrice       int    `json:"effective_price"`
+	HasPriceOverride     bool   `json:"has_price_override"`
 	ProductCategory      string `json:"product_category"`
 	ProductCategoryPhoto string `json:"product_category_photo"`
 	Stock                int    `json:"stock"`
+	MinStock             int    `json:"min_stock"`
+	MaxStock             int    `json:"max_stock"`
+	IsLowStock           bool   `json:"is_low_stock"`
 	WarehouseID          uint   `json:"warehouse_id"`
 	WarehouseName        string `json:"warehouse_name"`
 	WarehousePhoto       string `json:"warehouse_photo"`
 	WarehousePhone       string `json:"warehouse_phone"`
+
+	Scale *ScaleBarcodeResponse `json:"scale,omitempty"`
+}
+
+// BarcodeResolutionResponse: status resolved, invalid_barcode, unknown_barcode, atau not_stocked
+type BarcodeResolutionResponse struct {
+	Barcode         string           `json:"barcode"`
+	Status          string           `json:"status"`
+	MerchantProduct *MerchantProduct `json:"merchant_product"`
+}
+
+type ResolveBarcodesResponse struct {
+	Results    []BarcodeResolutionResponse `json:"results"`
+	Unresolved []string                    `json:"unresolved"`
 }
 
 type ProductTotalStockResponse struct {
@@ -44,3 +65,124 @@ type GetAllMerchantProductsResponse struct {
 	MerchantProducts []MerchantProduct             `json:"merchant_products"`
 	Pagination       pagination.PaginationResponse `json:"pagination"`
 }
+
+type StockMovementResponse struct {
+	ID                uint      `json:"id"`
+	MerchantProductID uint      `json:"merchant_product_id"`
+	MerchantID        uint      `json:"merchant_id"`
+	ProductID         uint      `json:"product_id"`
+	MovementType      string    `json:"movement_type"`
+	QuantityDelta     int       `json:"quantity_delta"`
+	BalanceAfter      int       `json:"balance_after"`
+	ActorID           uint      `json:"actor_id"`
+	Reference         string    `json:"reference"`
+	Note              string    `json:"note"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type GetStockMovementsResponse struct {
+	Movements  []StockMovementResponse       `json:"movements"`
+	Pagination pagination.PaginationResponse `json:"pagination"`
+}
+
+type LowStockMerchantProductResponse struct {
+	MerchantProductID uint   `json:"merchant_product_id"`
+	MerchantID        uint   `json:"merchant_id"`
+	MerchantName      string `json:"merchant_name"`
+	ProductID         uint   `json:"product_id"`
+	ProductName       string `json:"product_name"`
+	ProductPhoto      string `json:"product_photo"`
+	Stock             int    `json:"stock"`
+	MinStock          int    `json:"min_stock"`
+	Shortage          int    `json:"shortage"`
+}
+
+type GetLowStockMerchantProductsResponse struct {
+	MerchantProducts []LowStockMerchantProductResponse `json:"merchant_products"`
+	Pagination       pagination.PaginationResponse     `json:"pagination"`
+}
+
+type MerchantProductPriceResponse struct {
+	ID                uint      `json:"id"`
+	MerchantProductID uint      `json:"merchant_product_id"`
+	Price             *int64    `json:"price"`
+	EffectiveFrom     time.Time `json:"effective_from"`
+	IsScheduled       bool      `json:"is_scheduled"`
+	CreatedBy         uint      `json:"created_by"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// DistanceKm hanya terisi jika titik asal dan koordinat lokasi tersedia
+type MerchantAvailabilityResponse struct {
+	MerchantID   uint     `json:"merchant_id"`
+	MerchantName string   `json:"merchant_name"`
+	Address      string   `json:"address"`
+	Phone        string   `json:"phone"`
+	Stock        int      `json:"stock"`
+	IsOpen       bool     `json:"is_open"`
+	ClosedReason string   `json:"closed_reason,omitempty"`
+	Latitude     *float64 `json:"latitude"`
+	Longitude    *float64 `json:"longitude"`
+	DistanceKm   *float64 `json:"distance_km"`
+}
+
+type WarehouseAvailabilityResponse struct {
+	WarehouseID   uint     `json:"warehouse_id"`
+	WarehouseName string   `json:"warehouse_name"`
+	Stock         int      `json:"stock"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
+	DistanceKm    *float64 `json:"distance_km"`
+}
+
+type ProductAvailabilityResponse struct {
+	ProductID           uint                            `json:"product_id"`
+	ProductName         string                          `json:"product_name"`
+	ProductBarcode      string                          `json:"product_barcode"`
+	SortBy              string                          `json:"sort_by"`
+	TotalMerchantStock  int                             `json:"total_merchant_stock"`
+	TotalWarehouseStock int                             `json:"total_warehouse_stock"`
+	Merchants           []MerchantAvailabilityResponse  `json:"merchants"`
+	Warehouses          []WarehouseAvailabilityResponse `json:"warehouses"`
+}
+
+// WarehouseAllocationResponse merangkum merchant product yang masih disuplai sebuah warehouse
+type WarehouseAllocationResponse struct {
+	WarehouseID          uint  `json:"warehouse_id"`
+	MerchantCount        int64 `json:"merchant_count"`
+	MerchantProductCount int64 `json:"merchant_product_count"`
+	TotalStock           int64 `json:"total_stock"`
+}
+
+type DeletedMerchantProductResponse struct {
+	ID          uint      `json:"id"`
+	MerchantID  uint      `json:"merchant_id"`
+	ProductID   uint      `json:"product_id"`
+	WarehouseID uint      `json:"warehouse_id"`
+	Stock       int       `json:"stock"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+type GetDeletedMerchantProductsResponse struct {
+	MerchantProducts []DeletedMerchantProductResponse `json:"merchant_products"`
+	Pagination       pagination.PaginationResponse    `json:"pagination"`
+}
+
+type MerchantAllocationChangeResponse struct {
+	ID                uint       `json:"id"`
+	Reference         string     `json:"reference"`
+	MerchantProductID uint       `json:"merchant_product_id"`
+	MerchantID        uint       `json:"merchant_id"`
+	ProductID         uint       `json:"product_id"`
+	WarehouseID       uint       `json:"warehouse_id"`
+	Quantity          int        `json:"quantity"`
+	PublishedAt       *time.Time `json:"published_at"`
+	FailedAt          *time.Time `json:"failed_at"`
+	FailureReason     string     `json:"failure_reason,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+type GetAllocationChangesResponse struct {
+	Changes    []MerchantAllocationChangeResponse `json:"changes"`
+	Pagination pagination.PaginationResponse      `json:"pagination"`
+}
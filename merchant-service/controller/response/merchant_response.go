@@ -1,6 +1,10 @@
 package response
 
-import "micro-warehouse/merchant-service/pkg/pagination"
+import (
+	"micro-warehouse/merchant-service/pkg/geo"
+	"micro-warehouse/merchant-service/pkg/pagination"
+	"time"
+)
 
 type MerchantResponse struct {
 	ID           uint   `json:"id"`
@@ -11,6 +15,18 @@ type MerchantResponse struct {
 	KeeperID     uint   `json:"keeper_id"`
 	KeeperName   string `json:"keeper_name"`
 	ProductCount int    `json:"product_count"`
+	Status       string `json:"status"`
+	StatusReason string `json:"status_reason,omitempty"`
+	Timezone     string `json:"timezone"`
+	IsOpen       bool   `json:"is_open"`
+	ClosedReason string `json:"closed_reason,omitempty"`
+
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+
+	Staff []MerchantStaffResponse `json:"staff"`
 }
 
 type MerchantWithProductResponse struct {
@@ -21,7 +37,27 @@ type MerchantWithProductResponse struct {
 	Phone            string            `json:"phone"`
 	KeeperID         uint              `json:"keeper_id"`
 	KeeperName       string            `json:"keeper_name"`
+	Status           string            `json:"status"`
+	IsOpen           bool              `json:"is_open"`
+	ClosedReason     string            `json:"closed_reason,omitempty"`
+	Latitude         *float64          `json:"latitude"`
+	Longitude        *float64          `json:"longitude"`
+	ArchivedAt       *time.Time        `json:"archived_at,omitempty"`
 	MerchantProducts []MerchantProduct `json:"merchant_products"`
+
+	Staff []MerchantStaffResponse `json:"staff"`
+}
+
+// UserName hanya diisi pada endpoint roster staff
+type MerchantStaffResponse struct {
+	ID         uint       `json:"id"`
+	MerchantID uint       `json:"merchant_id"`
+	UserID     uint       `json:"user_id"`
+	UserName   string     `json:"user_name,omitempty"`
+	Role       string     `json:"role"`
+	StartDate  time.Time  `json:"start_date"`
+	EndDate    *time.Time `json:"end_date"`
+	IsActive   bool       `json:"is_active"`
 }
 
 type MerchantPaginationResponse struct {
@@ -30,8 +66,118 @@ type MerchantPaginationResponse struct {
 	Pagination pagination.PaginationResponse `json:"pagination"`
 }
 
+// DeletionBlockerResponse adalah satu alasan entity belum boleh dihapus; quantity diisi untuk blocker stock
+type DeletionBlockerResponse struct {
+	Type     string `json:"type"`
+	Count    int64  `json:"count"`
+	Quantity int64  `json:"quantity,omitempty"`
+	Message  string `json:"message"`
+}
+
+type DeletedMerchantResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Address    string     `json:"address"`
+	Photo      string     `json:"photo"`
+	Phone      string     `json:"phone"`
+	Status     string     `json:"status"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	DeletedAt  time.Time  `json:"deleted_at"`
+}
+
+type GetDeletedMerchantsResponse struct {
+	Merchants  []DeletedMerchantResponse     `json:"merchants"`
+	Pagination pagination.PaginationResponse `json:"pagination"`
+}
+
 type UploadResponse struct {
 	URL      string `json:"url"`
 	Path     string `json:"path"`
 	Filename string `json:"filename"`
 }
+
+type MerchantScheduleResponse struct {
+	MerchantID   uint                          `json:"merchant_id"`
+	Status       string                        `json:"status"`
+	Timezone     string                        `json:"timezone"`
+	IsOpen       bool                          `json:"is_open"`
+	ClosedReason string                        `json:"closed_reason,omitempty"`
+	OpeningHours []MerchantOpeningHourResponse `json:"opening_hours"`
+	Closures     []MerchantClosureResponse     `json:"closures"`
+}
+
+type MerchantOpeningHourResponse struct {
+	Weekday  int    `json:"weekday"`
+	OpensAt  string `json:"opens_at"`
+	ClosesAt string `json:"closes_at"`
+}
+
+type MerchantClosureResponse struct {
+	ID        uint      `json:"id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+	CreatedBy uint      `json:"created_by"`
+	IsActive  bool      `json:"is_active"`
+}
+
+type NearbyMerchantResponse struct {
+	ID           uint    `json:"id"`
+	Name         string  `json:"name"`
+	Address      string  `json:"address"`
+	Phone        string  `json:"phone"`
+	Status       string  `json:"status"`
+	IsOpen       bool    `json:"is_open"`
+	ClosedReason string  `json:"closed_reason,omitempty"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	DistanceKm   float64 `json:"distance_km"`
+}
+
+type NearestWarehousesResponse struct {
+	MerchantID   uint                      `json:"merchant_id"`
+	MerchantName string                    `json:"merchant_name"`
+	Latitude     float64                   `json:"latitude"`
+	Longitude    float64                   `json:"longitude"`
+	Warehouses   []NearbyWarehouseResponse `json:"warehouses"`
+}
+
+type NearbyWarehouseResponse struct {
+	WarehouseID   uint    `json:"warehouse_id"`
+	WarehouseName string  `json:"warehouse_name"`
+	Address       string  `json:"address"`
+	Phone         string  `json:"phone"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	DistanceKm    float64 `json:"distance_km"`
+}
+
+type MerchantDeliveryZoneResponse struct {
+	ID         uint        `json:"id"`
+	MerchantID uint        `json:"merchant_id"`
+	Name       string      `json:"name"`
+	Polygon    []geo.Point `json:"polygon"`
+	IsActive   bool        `json:"is_active"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  *time.Time  `json:"updated_at"`
+}
+
+// Merchant adalah merchant terpilih; Candidates berisi semua merchant yang zonanya memuat titik, termasuk yang terpilih
+type DeliveryRouteResponse struct {
+	Latitude   float64                     `json:"latitude"`
+	Longitude  float64                     `json:"longitude"`
+	Merchant   DeliveryCandidateResponse   `json:"merchant"`
+	Candidates []DeliveryCandidateResponse `json:"candidates"`
+}
+
+type DeliveryCandidateResponse struct {
+	MerchantID   uint     `json:"merchant_id"`
+	MerchantName string   `json:"merchant_name"`
+	Address      string   `json:"address"`
+	Phone        string   `json:"phone"`
+	ZoneID       uint     `json:"zone_id"`
+	ZoneName     string   `json:"zone_name"`
+	IsOpen       bool     `json:"is_open"`
+	ClosedReason string   `json:"closed_reason,omitempty"`
+	DistanceKm   *float64 `json:"distance_km"`
+}
@@ -0,0 +1,40 @@
+package response
+
+import (
+	"micro-warehouse/merchant-service/pkg/pagination"
+	"time"
+)
+
+type ReplenishmentOrderResponse struct {
+	ID           uint       `json:"id,omitempty"`
+	MerchantID   uint       `json:"merchant_id"`
+	MerchantName string     `json:"merchant_name,omitempty"`
+	Status       string     `json:"status"`
+	CreatedBy    uint       `json:"created_by"`
+	ReviewedBy   uint       `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote   string     `json:"review_note,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+
+	Items []ReplenishmentOrderItemResponse `json:"items"`
+}
+
+type ReplenishmentOrderItemResponse struct {
+	ID                uint   `json:"id,omitempty"`
+	MerchantProductID uint   `json:"merchant_product_id"`
+	ProductID         uint   `json:"product_id"`
+	WarehouseID       uint   `json:"warehouse_id"`
+	CurrentStock      int    `json:"current_stock"`
+	MinStock          int    `json:"min_stock"`
+	MaxStock          int    `json:"max_stock"`
+	WarehouseStock    int    `json:"warehouse_stock"`
+	SuggestedQuantity int    `json:"suggested_quantity"`
+	AllocatedQuantity int    `json:"allocated_quantity"`
+	Status            string `json:"status"`
+	FailureReason     string `json:"failure_reason,omitempty"`
+}
+
+type GetReplenishmentOrdersResponse struct {
+	ReplenishmentOrders []ReplenishmentOrderResponse  `json:"replenishment_orders"`
+	Pagination          pagination.PaginationResponse `json:"pagination"`
+}
@@ -0,0 +1,32 @@
+package response
+
+import (
+	"micro-warehouse/merchant-service/pkg/pagination"
+	"time"
+)
+
+type StockReturnResponse struct {
+	ID           uint       `json:"id"`
+	Reference    string     `json:"reference"`
+	MerchantID   uint       `json:"merchant_id"`
+	MerchantName string     `json:"merchant_name"`
+	Reason       string     `json:"reason"`
+	ReturnedBy   uint       `json:"returned_by"`
+	PublishedAt  *time.Time `json:"published_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	Items []StockReturnItemResponse `json:"items"`
+}
+
+type StockReturnItemResponse struct {
+	MerchantProductID uint   `json:"merchant_product_id"`
+	ProductID         uint   `json:"product_id"`
+	WarehouseID       uint   `json:"warehouse_id"`
+	Quantity          int    `json:"quantity"`
+	Condition         string `json:"condition"`
+}
+
+type GetStockReturnsResponse struct {
+	StockReturns []StockReturnResponse         `json:"stock_returns"`
+	Pagination   pagination.PaginationResponse `json:"pagination"`
+}
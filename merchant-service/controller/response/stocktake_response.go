@@ -0,0 +1,50 @@
+package response
+
+import (
+	"micro-warehouse/merchant-service/pkg/pagination"
+	"time"
+)
+
+type StocktakeResponse struct {
+	ID           uint       `json:"id"`
+	Reference    string     `json:"reference"`
+	MerchantID   uint       `json:"merchant_id"`
+	MerchantName string     `json:"merchant_name"`
+	CategoryID   uint       `json:"category_id"`
+	Status       string     `json:"status"`
+	Note         string     `json:"note"`
+	OpenedBy     uint       `json:"opened_by"`
+	ApprovedBy   uint       `json:"approved_by,omitempty"`
+	ApprovedAt   *time.Time `json:"approved_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	Summary *StocktakeSummaryResponse `json:"summary,omitempty"`
+	Lines   []StocktakeLineResponse   `json:"lines,omitempty"`
+}
+
+type StocktakeSummaryResponse struct {
+	TotalLines    int `json:"total_lines"`
+	CountedLines  int `json:"counted_lines"`
+	VarianceLines int `json:"variance_lines"`
+	TotalVariance int `json:"total_variance"`
+	TotalAdjusted int `json:"total_adjusted"`
+}
+
+type StocktakeLineResponse struct {
+	ID                uint       `json:"id"`
+	MerchantProductID uint       `json:"merchant_product_id"`
+	ProductID         uint       `json:"product_id"`
+	ExpectedStock     int        `json:"expected_stock"`
+	CountedQuantity   *int       `json:"counted_quantity"`
+	Variance          int        `json:"variance"`
+	ReasonCode        string     `json:"reason_code,omitempty"`
+	CountedBy         uint       `json:"counted_by,omitempty"`
+	CountedAt         *time.Time `json:"counted_at,omitempty"`
+	AdjustedDelta     int        `json:"adjusted_delta"`
+	MovementID        uint       `json:"movement_id,omitempty"`
+}
+
+type GetStocktakesResponse struct {
+	Stocktakes []StocktakeResponse           `json:"stocktakes"`
+	Pagination pagination.PaginationResponse `json:"pagination"`
+}
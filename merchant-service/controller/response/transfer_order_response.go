@@ -0,0 +1,59 @@
+package response
+
+import (
+	"micro-warehouse/merchant-service/pkg/pagination"
+	"time"
+)
+
+type TransferOrderResponse struct {
+	ID           uint       `json:"id"`
+	MerchantID   uint       `json:"merchant_id"`
+	MerchantName string     `json:"merchant_name"`
+	WarehouseID  uint       `json:"warehouse_id"`
+	Status       string     `json:"status"`
+	Note         string     `json:"note"`
+	RequestedBy  uint       `json:"requested_by"`
+	ReviewedBy   uint       `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote   string     `json:"review_note,omitempty"`
+	PickedBy     uint       `json:"picked_by,omitempty"`
+	PickedAt     *time.Time `json:"picked_at,omitempty"`
+	ShippedBy    uint       `json:"shipped_by,omitempty"`
+	ShippedAt    *time.Time `json:"shipped_at,omitempty"`
+	ReceivedAt   *time.Time `json:"received_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	Items    []TransferOrderItemResponse    `json:"items"`
+	Receipts []TransferOrderReceiptResponse `json:"receipts"`
+}
+
+type TransferOrderItemResponse struct {
+	ProductID           uint `json:"product_id"`
+	RequestedQuantity   int  `json:"requested_quantity"`
+	ShippedQuantity     int  `json:"shipped_quantity"`
+	ReceivedQuantity    int  `json:"received_quantity"`
+	DiscrepancyQuantity int  `json:"discrepancy_quantity"`
+	OutstandingQuantity int  `json:"outstanding_quantity"`
+}
+
+type TransferOrderReceiptResponse struct {
+	ID         uint                               `json:"id"`
+	ReceivedBy uint                               `json:"received_by"`
+	Note       string                             `json:"note"`
+	CreatedAt  time.Time                          `json:"created_at"`
+	Lines      []TransferOrderReceiptLineResponse `json:"lines"`
+}
+
+type TransferOrderReceiptLineResponse struct {
+	ProductID           uint   `json:"product_id"`
+	ReceivedQuantity    int    `json:"received_quantity"`
+	DiscrepancyQuantity int    `json:"discrepancy_quantity"`
+	DiscrepancyType     string `json:"discrepancy_type,omitempty"`
+	DiscrepancyReason   string `json:"discrepancy_reason,omitempty"`
+}
+
+type GetTransferOrdersResponse struct {
+	TransferOrders []TransferOrderResponse       `json:"transfer_orders"`
+	Pagination     pagination.PaginationResponse `json:"pagination"`
+}
@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/conv"
+	"micro-warehouse/merchant-service/pkg/pagination"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/repository"
+	"micro-warehouse/merchant-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type StockReturnControllerInterface interface {
+	CreateStockReturn(c *fiber.Ctx) error
+	GetStockReturns(c *fiber.Ctx) error
+	GetStockReturnByID(c *fiber.Ctx) error
+	RepublishStockReturn(c *fiber.Ctx) error
+}
+
+type stockReturnController struct {
+	stockReturnUsecase usecase.StockReturnUsecaseInterface
+}
+
+// CreateStockReturn implements StockReturnControllerInterface.
+func (s *stockReturnController) CreateStockReturn(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var req request.CreateStockReturnRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[StockReturnController] CreateStockReturn - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[StockReturnController] CreateStockReturn - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	stockReturn := model.StockReturn{
+		MerchantID: req.MerchantID,
+		Reason:     req.Reason,
+	}
+	for _, item := range req.Items {
+		stockReturn.Items = append(stockReturn.Items, model.StockReturnItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Condition: item.Condition,
+		})
+	}
+
+	if err := s.stockReturnUsecase.CreateStockReturn(ctx, authz.GetIdentity(c), &stockReturn); err != nil {
+		log.Errorf("[StockReturnController] CreateStockReturn - 3: %v", err)
+		// Saat create, record not found berarti produk belum dialokasikan ke merchant
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Product is not allocated to this merchant",
+			})
+		}
+		return stockReturnErrorResponse(c, err, "Failed to create stock return")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Stock return created successfully",
+		"data":    toStockReturnResponse(stockReturn),
+	})
+}
+
+// GetStockReturns implements StockReturnControllerInterface.
+func (s *stockReturnController) GetStockReturns(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var req request.GetStockReturnsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[StockReturnController] GetStockReturns - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[StockReturnController] GetStockReturns - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	stockReturns, total, err := s.stockReturnUsecase.GetStockReturns(ctx, authz.GetIdentity(c), req.MerchantID, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[StockReturnController] GetStockReturns - 3: %v", err)
+		return stockReturnErrorResponse(c, err, "Failed to get stock returns")
+	}
+
+	resps := []response.StockReturnResponse{}
+	for _, stockReturn := range stockReturns {
+		resps = append(resps, toStockReturnResponse(stockReturn))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stock returns fetched successfully",
+		"data": response.GetStockReturnsResponse{
+			StockReturns: resps,
+			Pagination:   pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// GetStockReturnByID implements StockReturnControllerInterface.
+func (s *stockReturnController) GetStockReturnByID(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	stockReturn, err := s.stockReturnUsecase.GetStockReturnByID(ctx, authz.GetIdentity(c), id)
+	if err != nil {
+		log.Errorf("[StockReturnController] GetStockReturnByID - 1: %v", err)
+		return stockReturnErrorResponse(c, err, "Failed to get stock return")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stock return fetched successfully",
+		"data":    toStockReturnResponse(*stockReturn),
+	})
+}
+
+// RepublishStockReturn implements StockReturnControllerInterface.
+func (s *stockReturnController) RepublishStockReturn(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	stockReturn, err := s.stockReturnUsecase.RepublishStockReturn(ctx, authz.GetIdentity(c), id)
+	if err != nil {
+		log.Errorf("[StockReturnController] RepublishStockReturn - 1: %v", err)
+		return stockReturnErrorResponse(c, err, "Failed to republish stock return")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stock return republished successfully",
+		"data":    toStockReturnResponse(*stockReturn),
+	})
+}
+
+func NewStockReturnController(stockReturnUsecase usecase.StockReturnUsecaseInterface) StockReturnControllerInterface {
+	return &stockReturnController{
+		stockReturnUsecase: stockReturnUsecase,
+	}
+}
+
+func stockReturnErrorResponse(c *fiber.Ctx, err error, fallbackMessage string) error {
+	switch {
+	case errors.Is(err, authz.ErrForbidden):
+		return authz.Forbidden(c, "You do not have access to this stock return")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Stock return not found",
+		})
+	case errors.Is(err, usecase.ErrInvalidStockReturn):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrStockNotEnough):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": fallbackMessage,
+	})
+}
+
+func toStockReturnResponse(stockReturn model.StockReturn) response.StockReturnResponse {
+	resp := response.StockReturnResponse{
+		ID:           stockReturn.ID,
+		Reference:    stockReturn.Reference(),
+		MerchantID:   stockReturn.MerchantID,
+		MerchantName: stockReturn.Merchant.Name,
+		Reason:       stockReturn.Reason,
+		ReturnedBy:   stockReturn.ReturnedBy,
+		PublishedAt:  stockReturn.PublishedAt,
+		CreatedAt:    stockReturn.CreatedAt,
+		Items:        []response.StockReturnItemResponse{},
+	}
+
+	for _, item := range stockReturn.Items {
+		resp.Items = append(resp.Items, response.StockReturnItemResponse{
+			MerchantProductID: item.MerchantProductID,
+			ProductID:         item.ProductID,
+			WarehouseID:       item.WarehouseID,
+			Quantity:          item.Quantity,
+			Condition:         item.Condition,
+		})
+	}
+
+	return resp
+}
@@ -0,0 +1,320 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/conv"
+	"micro-warehouse/merchant-service/pkg/pagination"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/repository"
+	"micro-warehouse/merchant-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type StocktakeControllerInterface interface {
+	OpenStocktake(c *fiber.Ctx) error
+	GetStocktakes(c *fiber.Ctx) error
+	GetStocktakeByID(c *fiber.Ctx) error
+	RecordCount(c *fiber.Ctx) error
+	ApproveStocktake(c *fiber.Ctx) error
+	CancelStocktake(c *fiber.Ctx) error
+}
+
+type stocktakeController struct {
+	stocktakeUsecase usecase.StocktakeUsecaseInterface
+}
+
+// OpenStocktake implements StocktakeControllerInterface.
+func (s *stocktakeController) OpenStocktake(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var req request.OpenStocktakeRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[StocktakeController] OpenStocktake - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[StocktakeController] OpenStocktake - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	stocktake := model.Stocktake{
+		MerchantID: req.MerchantID,
+		CategoryID: req.CategoryID,
+		Note:       req.Note,
+	}
+
+	if err := s.stocktakeUsecase.OpenStocktake(ctx, authz.GetIdentity(c), &stocktake); err != nil {
+		log.Errorf("[StocktakeController] OpenStocktake - 3: %v", err)
+		return stocktakeErrorResponse(c, err, "Failed to open stocktake")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Stocktake opened successfully",
+		"data":    toStocktakeResponse(stocktake, true, false),
+	})
+}
+
+// GetStocktakes implements StocktakeControllerInterface.
+func (s *stocktakeController) GetStocktakes(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var req request.GetStocktakesRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[StocktakeController] GetStocktakes - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[StocktakeController] GetStocktakes - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	stocktakes, total, err := s.stocktakeUsecase.GetStocktakes(ctx, authz.GetIdentity(c), req.MerchantID, req.Status, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[StocktakeController] GetStocktakes - 3: %v", err)
+		return stocktakeErrorResponse(c, err, "Failed to get stocktakes")
+	}
+
+	resps := []response.StocktakeResponse{}
+	for _, stocktake := range stocktakes {
+		resps = append(resps, toStocktakeResponse(stocktake, false, false))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stocktakes fetched successfully",
+		"data": response.GetStocktakesResponse{
+			Stocktakes: resps,
+			Pagination: pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// GetStocktakeByID implements StocktakeControllerInterface.
+func (s *stocktakeController) GetStocktakeByID(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.GetStocktakeRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[StocktakeController] GetStocktakeByID - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	stocktake, err := s.stocktakeUsecase.GetStocktakeByID(ctx, authz.GetIdentity(c), id)
+	if err != nil {
+		log.Errorf("[StocktakeController] GetStocktakeByID - 2: %v", err)
+		return stocktakeErrorResponse(c, err, "Failed to get stocktake")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stocktake fetched successfully",
+		"data":    toStocktakeResponse(*stocktake, true, req.VarianceOnly),
+	})
+}
+
+// RecordCount implements StocktakeControllerInterface.
+func (s *stocktakeController) RecordCount(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.RecordStocktakeCountRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[StocktakeController] RecordCount - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[StocktakeController] RecordCount - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	count := usecase.StocktakeCount{
+		Barcode:    req.Barcode,
+		ProductID:  req.ProductID,
+		Quantity:   1,
+		Replace:    req.Mode == "set",
+		ReasonCode: req.ReasonCode,
+	}
+	if req.Quantity != nil {
+		count.Quantity = *req.Quantity
+	}
+
+	line, err := s.stocktakeUsecase.RecordCount(ctx, authz.GetIdentity(c), id, count)
+	if err != nil {
+		log.Errorf("[StocktakeController] RecordCount - 3: %v", err)
+		return stocktakeErrorResponse(c, err, "Failed to record stocktake count")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stocktake count recorded successfully",
+		"data":    toStocktakeLineResponse(*line),
+	})
+}
+
+// ApproveStocktake implements StocktakeControllerInterface.
+func (s *stocktakeController) ApproveStocktake(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.ApproveStocktakeRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[StocktakeController] ApproveStocktake - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[StocktakeController] ApproveStocktake - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	stocktake, err := s.stocktakeUsecase.ApproveStocktake(ctx, authz.GetIdentity(c), id, req.ReasonCode, req.Note)
+	if err != nil {
+		log.Errorf("[StocktakeController] ApproveStocktake - 3: %v", err)
+		return stocktakeErrorResponse(c, err, "Failed to approve stocktake")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stocktake approved successfully",
+		"data":    toStocktakeResponse(*stocktake, true, true),
+	})
+}
+
+// CancelStocktake implements StocktakeControllerInterface.
+func (s *stocktakeController) CancelStocktake(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	stocktake, err := s.stocktakeUsecase.CancelStocktake(ctx, authz.GetIdentity(c), id)
+	if err != nil {
+		log.Errorf("[StocktakeController] CancelStocktake - 1: %v", err)
+		return stocktakeErrorResponse(c, err, "Failed to cancel stocktake")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stocktake cancelled successfully",
+		"data":    toStocktakeResponse(*stocktake, false, false),
+	})
+}
+
+func NewStocktakeController(stocktakeUsecase usecase.StocktakeUsecaseInterface) StocktakeControllerInterface {
+	return &stocktakeController{
+		stocktakeUsecase: stocktakeUsecase,
+	}
+}
+
+func stocktakeErrorResponse(c *fiber.Ctx, err error, fallbackMessage string) error {
+	switch {
+	case errors.Is(err, authz.ErrForbidden):
+		return authz.Forbidden(c, "You do not have access to this stocktake")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Stocktake not found",
+		})
+	case errors.Is(err, repository.ErrStocktakeLineNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrStocktakeAlreadyOpen), errors.Is(err, repository.ErrStocktakeInvalidStatus):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrInvalidStocktake),
+		errors.Is(err, repository.ErrStocktakeInvalidCount),
+		errors.Is(err, repository.ErrStocktakeNothingToCount):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": fallbackMessage,
+	})
+}
+
+// toStocktakeResponse menyertakan summary dan lines hanya jika lines sudah dimuat (withLines)
+func toStocktakeResponse(stocktake model.Stocktake, withLines bool, varianceOnly bool) response.StocktakeResponse {
+	resp := response.StocktakeResponse{
+		ID:           stocktake.ID,
+		Reference:    stocktake.Reference(),
+		MerchantID:   stocktake.MerchantID,
+		MerchantName: stocktake.Merchant.Name,
+		CategoryID:   stocktake.CategoryID,
+		Status:       stocktake.Status,
+		Note:         stocktake.Note,
+		OpenedBy:     stocktake.OpenedBy,
+		ApprovedBy:   stocktake.ApprovedBy,
+		ApprovedAt:   stocktake.ApprovedAt,
+		CreatedAt:    stocktake.CreatedAt,
+	}
+
+	if !withLines {
+		return resp
+	}
+
+	summary := response.StocktakeSummaryResponse{TotalLines: len(stocktake.Lines)}
+	resp.Lines = []response.StocktakeLineResponse{}
+	for _, line := range stocktake.Lines {
+		if line.CountedQuantity != nil {
+			summary.CountedLines++
+		}
+		if variance := line.Variance(); variance != 0 {
+			summary.VarianceLines++
+			summary.TotalVariance += variance
+		} else if varianceOnly {
+			continue
+		}
+		summary.TotalAdjusted += line.AdjustedDelta
+
+		resp.Lines = append(resp.Lines, toStocktakeLineResponse(line))
+	}
+	resp.Summary = &summary
+
+	return resp
+}
+
+func toStocktakeLineResponse(line model.StocktakeLine) response.StocktakeLineResponse {
+	return response.StocktakeLineResponse{
+		ID:                line.ID,
+		MerchantProductID: line.MerchantProductID,
+		ProductID:         line.ProductID,
+		ExpectedStock:     line.ExpectedStock,
+		CountedQuantity:   line.CountedQuantity,
+		Variance:          line.Variance(),
+		ReasonCode:        line.ReasonCode,
+		CountedBy:         line.CountedBy,
+		CountedAt:         line.CountedAt,
+		AdjustedDelta:     line.AdjustedDelta,
+		MovementID:        line.MovementID,
+	}
+}
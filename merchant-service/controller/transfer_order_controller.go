@@ -0,0 +1,389 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/merchant-service/controller/request"
+	"micro-warehouse/merchant-service/controller/response"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/conv"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/pkg/pagination"
+	"micro-warehouse/merchant-service/pkg/validator"
+	"micro-warehouse/merchant-service/repository"
+	"micro-warehouse/merchant-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type TransferOrderControllerInterface interface {
+	CreateTransferOrder(c *fiber.Ctx) error
+	GetTransferOrders(c *fiber.Ctx) error
+	GetTransferOrderByID(c *fiber.Ctx) error
+
+	ApproveTransferOrder(c *fiber.Ctx) error
+	RejectTransferOrder(c *fiber.Ctx) error
+	CancelTransferOrder(c *fiber.Ctx) error
+	PickTransferOrder(c *fiber.Ctx) error
+	ShipTransferOrder(c *fiber.Ctx) error
+	ReceiveTransferOrder(c *fiber.Ctx) error
+}
+
+type transferOrderController struct {
+	transferOrderUsecase usecase.TransferOrderUsecaseInterface
+}
+
+// CreateTransferOrder implements TransferOrderControllerInterface.
+func (t *transferOrderController) CreateTransferOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var req request.CreateTransferOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[TransferOrderController] CreateTransferOrder - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[TransferOrderController] CreateTransferOrder - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	transferOrder := model.TransferOrder{
+		MerchantID:  req.MerchantID,
+		WarehouseID: req.WarehouseID,
+		Note:        req.Note,
+	}
+	for _, item := range req.Items {
+		transferOrder.Items = append(transferOrder.Items, model.TransferOrderItem{
+			ProductID:         item.ProductID,
+			RequestedQuantity: item.Quantity,
+		})
+	}
+
+	if err := t.transferOrderUsecase.CreateTransferOrder(ctx, authz.GetIdentity(c), &transferOrder); err != nil {
+		log.Errorf("[TransferOrderController] CreateTransferOrder - 3: %v", err)
+		return transferOrderErrorResponse(c, err, "Failed to create transfer order")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Transfer order created successfully",
+		"data":    toTransferOrderResponse(transferOrder),
+	})
+}
+
+// GetTransferOrders implements TransferOrderControllerInterface.
+func (t *transferOrderController) GetTransferOrders(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var req request.GetTransferOrdersRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[TransferOrderController] GetTransferOrders - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[TransferOrderController] GetTransferOrders - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	transferOrders, total, err := t.transferOrderUsecase.GetTransferOrders(ctx, authz.GetIdentity(c), req.MerchantID, req.WarehouseID, req.Status, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[TransferOrderController] GetTransferOrders - 3: %v", err)
+		return transferOrderErrorResponse(c, err, "Failed to get transfer orders")
+	}
+
+	transferOrderResponses := []response.TransferOrderResponse{}
+	for _, transferOrder := range transferOrders {
+		transferOrderResponses = append(transferOrderResponses, toTransferOrderResponse(transferOrder))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transfer orders fetched successfully",
+		"data": response.GetTransferOrdersResponse{
+			TransferOrders: transferOrderResponses,
+			Pagination:     pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// GetTransferOrderByID implements TransferOrderControllerInterface.
+func (t *transferOrderController) GetTransferOrderByID(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	transferOrder, err := t.transferOrderUsecase.GetTransferOrderByID(ctx, authz.GetIdentity(c), id)
+	if err != nil {
+		log.Errorf("[TransferOrderController] GetTransferOrderByID - 1: %v", err)
+		return transferOrderErrorResponse(c, err, "Failed to get transfer order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transfer order fetched successfully",
+		"data":    toTransferOrderResponse(*transferOrder),
+	})
+}
+
+// ApproveTransferOrder implements TransferOrderControllerInterface.
+func (t *transferOrderController) ApproveTransferOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.ReviewTransferOrderRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		log.Errorf("[TransferOrderController] ApproveTransferOrder - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := t.transferOrderUsecase.ApproveTransferOrder(ctx, authz.GetIdentity(c), id, req.Note); err != nil {
+		log.Errorf("[TransferOrderController] ApproveTransferOrder - 2: %v", err)
+		return transferOrderErrorResponse(c, err, "Failed to approve transfer order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transfer order approved successfully",
+	})
+}
+
+// RejectTransferOrder implements TransferOrderControllerInterface.
+func (t *transferOrderController) RejectTransferOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.ReviewTransferOrderRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		log.Errorf("[TransferOrderController] RejectTransferOrder - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := t.transferOrderUsecase.RejectTransferOrder(ctx, authz.GetIdentity(c), id, req.Note); err != nil {
+		log.Errorf("[TransferOrderController] RejectTransferOrder - 2: %v", err)
+		return transferOrderErrorResponse(c, err, "Failed to reject transfer order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transfer order rejected successfully",
+	})
+}
+
+// CancelTransferOrder implements TransferOrderControllerInterface.
+func (t *transferOrderController) CancelTransferOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	if err := t.transferOrderUsecase.CancelTransferOrder(ctx, authz.GetIdentity(c), id); err != nil {
+		log.Errorf("[TransferOrderController] CancelTransferOrder - 1: %v", err)
+		return transferOrderErrorResponse(c, err, "Failed to cancel transfer order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transfer order cancelled successfully",
+	})
+}
+
+// PickTransferOrder implements TransferOrderControllerInterface.
+func (t *transferOrderController) PickTransferOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	if err := t.transferOrderUsecase.PickTransferOrder(ctx, authz.GetIdentity(c), id); err != nil {
+		log.Errorf("[TransferOrderController] PickTransferOrder - 1: %v", err)
+		return transferOrderErrorResponse(c, err, "Failed to pick transfer order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transfer order picked successfully",
+	})
+}
+
+// ShipTransferOrder implements TransferOrderControllerInterface.
+func (t *transferOrderController) ShipTransferOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.ShipTransferOrderRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		log.Errorf("[TransferOrderController] ShipTransferOrder - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[TransferOrderController] ShipTransferOrder - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	var shippedQuantities map[uint]int
+	if len(req.Items) > 0 {
+		shippedQuantities = make(map[uint]int, len(req.Items))
+		for _, item := range req.Items {
+			shippedQuantities[item.ProductID] += item.Quantity
+		}
+	}
+
+	if err := t.transferOrderUsecase.ShipTransferOrder(ctx, authz.GetIdentity(c), id, shippedQuantities); err != nil {
+		log.Errorf("[TransferOrderController] ShipTransferOrder - 3: %v", err)
+		return transferOrderErrorResponse(c, err, "Failed to ship transfer order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transfer order shipped successfully",
+	})
+}
+
+// ReceiveTransferOrder implements TransferOrderControllerInterface.
+func (t *transferOrderController) ReceiveTransferOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+	id := conv.StringToUint(c.Params("id"))
+
+	var req request.ReceiveTransferOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[TransferOrderController] ReceiveTransferOrder - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[TransferOrderController] ReceiveTransferOrder - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	receipt := model.TransferOrderReceipt{
+		Note: req.Note,
+	}
+	for _, line := range req.Lines {
+		receipt.Lines = append(receipt.Lines, model.TransferOrderReceiptLine{
+			ProductID:           line.ProductID,
+			ReceivedQuantity:    line.ReceivedQuantity,
+			DiscrepancyQuantity: line.DiscrepancyQuantity,
+			DiscrepancyType:     line.DiscrepancyType,
+			DiscrepancyReason:   line.DiscrepancyReason,
+		})
+	}
+
+	transferOrder, err := t.transferOrderUsecase.ReceiveTransferOrder(ctx, authz.GetIdentity(c), id, &receipt)
+	if err != nil {
+		log.Errorf("[TransferOrderController] ReceiveTransferOrder - 3: %v", err)
+		return transferOrderErrorResponse(c, err, "Failed to receive transfer order")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transfer order received successfully",
+		"data":    toTransferOrderResponse(*transferOrder),
+	})
+}
+
+func NewTransferOrderController(transferOrderUsecase usecase.TransferOrderUsecaseInterface) TransferOrderControllerInterface {
+	return &transferOrderController{
+		transferOrderUsecase: transferOrderUsecase,
+	}
+}
+
+// transferOrderErrorResponse memetakan error usecase ke status HTTP yang sesuai
+func transferOrderErrorResponse(c *fiber.Ctx, err error, fallbackMessage string) error {
+	switch {
+	case errors.Is(err, authz.ErrForbidden):
+		return authz.Forbidden(c, "You do not have access to this transfer order")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Transfer order not found",
+		})
+	case errors.Is(err, repository.ErrTransferOrderInvalidStatus):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrTransferOrderInvalidQuantity), errors.Is(err, usecase.ErrInvalidTransferOrder):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case errors.Is(err, httpclient.ErrWarehouseStockNotEnough):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": fallbackMessage,
+	})
+}
+
+func toTransferOrderResponse(transferOrder model.TransferOrder) response.TransferOrderResponse {
+	resp := response.TransferOrderResponse{
+		ID:           transferOrder.ID,
+		MerchantID:   transferOrder.MerchantID,
+		MerchantName: transferOrder.Merchant.Name,
+		WarehouseID:  transferOrder.WarehouseID,
+		Status:       transferOrder.Status,
+		Note:         transferOrder.Note,
+		RequestedBy:  transferOrder.RequestedBy,
+		ReviewedBy:   transferOrder.ReviewedBy,
+		ReviewedAt:   transferOrder.ReviewedAt,
+		ReviewNote:   transferOrder.ReviewNote,
+		PickedBy:     transferOrder.PickedBy,
+		PickedAt:     transferOrder.PickedAt,
+		ShippedBy:    transferOrder.ShippedBy,
+		ShippedAt:    transferOrder.ShippedAt,
+		ReceivedAt:   transferOrder.ReceivedAt,
+		CreatedAt:    transferOrder.CreatedAt,
+		UpdatedAt:    transferOrder.UpdatedAt,
+		Items:        []response.TransferOrderItemResponse{},
+		Receipts:     []response.TransferOrderReceiptResponse{},
+	}
+
+	for _, item := range transferOrder.Items {
+		resp.Items = append(resp.Items, response.TransferOrderItemResponse{
+			ProductID:           item.ProductID,
+			RequestedQuantity:   item.RequestedQuantity,
+			ShippedQuantity:     item.ShippedQuantity,
+			ReceivedQuantity:    item.ReceivedQuantity,
+			DiscrepancyQuantity: item.DiscrepancyQuantity,
+			OutstandingQuantity: item.OutstandingQuantity(),
+		})
+	}
+
+	for _, receipt := range transferOrder.Receipts {
+		receiptResp := response.TransferOrderReceiptResponse{
+			ID:         receipt.ID,
+			ReceivedBy: receipt.ReceivedBy,
+			Note:       receipt.Note,
+			CreatedAt:  receipt.CreatedAt,
+			Lines:      []response.TransferOrderReceiptLineResponse{},
+		}
+		for _, line := range receipt.Lines {
+			receiptResp.Lines = append(receiptResp.Lines, response.TransferOrderReceiptLineResponse{
+				ProductID:           line.ProductID,
+				ReceivedQuantity:    line.ReceivedQuantity,
+				DiscrepancyQuantity: line.DiscrepancyQuantity,
+				DiscrepancyType:     line.DiscrepancyType,
+				DiscrepancyReason:   line.DiscrepancyReason,
+			})
+		}
+		resp.Receipts = append(resp.Receipts, receiptResp)
+	}
+
+	return resp
+}
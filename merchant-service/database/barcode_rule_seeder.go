@@ -0,0 +1,35 @@
+package database
+
+import (
+	"micro-warehouse/merchant-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// SeedBarcodeRules membuat rule label timbangan bawaan saat tabel masih kosong:
+// prefix 20 untuk berat dalam gram dan prefix 22 untuk harga dalam rupiah, keduanya dengan PLU 5 digit.
+// Prefix GS1 lain (02, 21, 23–29) bisa ditambahkan lewat API.
+func SeedBarcodeRules(db *gorm.DB) {
+	var count int64
+	if err := db.Model(&model.BarcodeRule{}).Count(&count).Error; err != nil {
+		log.Errorf("[BarcodeRuleSeeder] SeedBarcodeRules - 1: %v", err)
+		return
+	}
+
+	if count > 0 {
+		return
+	}
+
+	rules := []model.BarcodeRule{
+		{Name: "Weight label (grams)", Prefix: "20", ItemCodeLength: 5, Measure: model.BarcodeMeasureWeight, ValueLength: 5, Decimals: 3, IsActive: true},
+		{Name: "Price label (rupiah)", Prefix: "22", ItemCodeLength: 5, Measure: model.BarcodeMeasurePrice, ValueLength: 5, Decimals: 0, IsActive: true},
+	}
+
+	if err := db.Create(&rules).Error; err != nil {
+		log.Errorf("[BarcodeRuleSeeder] SeedBarcodeRules - 2: %v", err)
+		return
+	}
+
+	log.Infof("[BarcodeRuleSeeder] SeedBarcodeRules - 3: %d default rules created", len(rules))
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"micro-warehouse/merchant-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// MigrateMerchantKeepers memindahkan kolom lama merchants.keeper_id ke roster merchant_staffs sebagai lead,
+// lalu menghapus kolomnya. Aman dijalankan berulang karena kolom hanya ada sebelum migrasi pertama.
+func MigrateMerchantKeepers(db *gorm.DB) {
+	if !db.Migrator().HasColumn(&model.Merchant{}, "keeper_id") {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`
+			INSERT INTO merchant_staffs (merchant_id, user_id, role, start_date, created_at, updated_at)
+			SELECT m.id, m.keeper_id, ?, m.created_at, NOW(), NOW()
+			FROM merchants m
+			WHERE m.keeper_id <> 0 AND NOT EXISTS (
+				SELECT 1 FROM merchant_staffs ms WHERE ms.merchant_id = m.id AND ms.user_id = m.keeper_id
+			)`, model.MerchantStaffRoleLead)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected > 0 {
+			log.Infof("[MerchantStaffSeeder] MigrateMerchantKeepers - 1: %d keepers moved to the staff roster", result.RowsAffected)
+		}
+
+		return tx.Migrator().DropColumn(&model.Merchant{}, "keeper_id")
+	})
+	if err != nil {
+		log.Errorf("[MerchantStaffSeeder] MigrateMerchantKeepers - 2: %v", err)
+	}
+}
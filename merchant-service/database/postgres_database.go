@@ -24,7 +24,17 @@ func ConnectionPostgres(cfg configs.Config) (*Postgres, error) {
 		return nil, err
 	}
 
-	db.AutoMigrate(&model.Merchant{}, &model.MerchantProduct{})
+	db.AutoMigrate(&model.Merchant{}, &model.MerchantProduct{}, &model.MerchantStockMovement{}, &model.ProcessedStockEvent{},
+		&model.TransferOrder{}, &model.TransferOrderItem{}, &model.TransferOrderReceipt{}, &model.TransferOrderReceiptLine{},
+		&model.ReplenishmentOrder{}, &model.ReplenishmentOrderItem{}, &model.StockReturn{}, &model.StockReturnItem{},
+		&model.MerchantProductPrice{}, &model.MerchantAllocationChange{}, &model.Stocktake{}, &model.StocktakeLine{},
+		&model.MerchantStaff{}, &model.MerchantOpeningHour{}, &model.MerchantClosure{}, &model.MerchantDeliveryZone{}, &model.BarcodeRule{},
+		&model.ProductProjection{})
+	SeedOpeningStockMovements(db)
+	MigrateMerchantKeepers(db)
+	SeedBarcodeRules(db)
+	CreateProductProjectionIndexes(db)
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorf("[Postgres] ConnectionPostgres - 2: %v", err)
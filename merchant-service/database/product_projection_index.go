@@ -0,0 +1,26 @@
+package database
+
+import (
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// CreateProductProjectionIndexes membuat index trigram supaya pencarian ILIKE '%...%' pada nama, barcode dan
+// category tidak memindai seluruh tabel. Tanpa hak CREATE EXTENSION pencarian tetap jalan, hanya lebih lambat.
+func CreateProductProjectionIndexes(db *gorm.DB) {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Errorf("[ProductProjectionIndex] CreateProductProjectionIndexes - 1: %v", err)
+		return
+	}
+
+	statements := []string{
+		"CREATE INDEX IF NOT EXISTS idx_product_projections_name_trgm ON product_projections USING gin (name gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_product_projections_barcode_trgm ON product_projections USING gin (barcode gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_product_projections_category_name_trgm ON product_projections USING gin (category_name gin_trgm_ops)",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			log.Errorf("[ProductProjectionIndex] CreateProductProjectionIndexes - 2: %v", err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"micro-warehouse/merchant-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// SeedOpeningStockMovements mencatat saldo awal untuk merchant product yang dibuat
+// sebelum ledger ada, sehingga saldo selalu bisa direkonstruksi dari ledger
+func SeedOpeningStockMovements(db *gorm.DB) {
+	result := db.Exec(`
+		INSERT INTO merchant_stock_movements (merchant_product_id, merchant_id, product_id, movement_type, quantity_delta, balance_after, actor_id, reference, note, created_at)
+		SELECT mp.id, mp.merchant_id, mp.product_id, ?, mp.stock, mp.stock, 0, '', 'opening balance', NOW()
+		FROM merchant_products mp
+		WHERE NOT EXISTS (
+			SELECT 1 FROM merchant_stock_movements msm WHERE msm.merchant_product_id = mp.id
+		)`, model.StockMovementOpeningBalance)
+	if result.Error != nil {
+		log.Errorf("[StockMovementSeeder] SeedOpeningStockMovements - 1: %v", result.Error)
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		log.Infof("[StockMovementSeeder] SeedOpeningStockMovements - 2: %d opening balances recorded", result.RowsAffected)
+	}
+}
@@ -2,7 +2,11 @@ module micro-warehouse/merchant-service
 
 go 1.24.3
 
-require github.com/spf13/viper v1.20.1
+require (
+	github.com/spf13/viper v1.20.1
+	golang.org/x/crypto v0.33.0
+	gorm.io/gorm v1.25.10
+)
 
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
@@ -28,10 +32,8 @@ require (
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.51.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
-	golang.org/x/crypto v0.33.0 // indirect
 	golang.org/x/net v0.34.0 // indirect
 	golang.org/x/sync v0.11.0 // indirect
-	gorm.io/gorm v1.25.10 // indirect
 )
 
 require (
@@ -0,0 +1,186 @@
+package model
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	BarcodeMeasureWeight = "weight"
+	BarcodeMeasurePrice  = "price"
+)
+
+// EAN13Length adalah panjang barcode timbangan GS1 (prefix 02 dan 20–29)
+const EAN13Length = 13
+
+// BarcodeRule menjelaskan cara membaca label timbangan EAN-13:
+// prefix + item code + (opsional) check digit nilai + nilai tertanam + check digit barcode.
+// Nilai berupa berat (mis. gram dengan Decimals 3 berarti kg) atau harga (Decimals 0 untuk rupiah).
+type BarcodeRule struct {
+	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name               string    `json:"name" gorm:"type:varchar(100);not null"`
+	Prefix             string    `json:"prefix" gorm:"type:varchar(3);not null;uniqueIndex"`
+	ItemCodeLength     int       `json:"item_code_length" gorm:"type:int;not null"`
+	Measure            string    `json:"measure" gorm:"type:varchar(20);not null"`
+	ValueLength        int       `json:"value_length" gorm:"type:int;not null"`
+	Decimals           int       `json:"decimals" gorm:"type:int;not null;default:0"`
+	HasValueCheckDigit bool      `json:"has_value_check_digit" gorm:"not null;default:false"`
+	IsActive           bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ScaleBarcode adalah hasil pembacaan label timbangan
+type ScaleBarcode struct {
+	Rule          BarcodeRule
+	Barcode       string
+	ItemCode      string
+	EmbeddedValue int64
+}
+
+// Length menghitung total digit yang dipakai rule, harus sama dengan EAN13Length
+func (r BarcodeRule) Length() int {
+	length := len(r.Prefix) + r.ItemCodeLength + r.ValueLength + 1
+	if r.HasValueCheckDigit {
+		length++
+	}
+
+	return length
+}
+
+// Matches hanya mencocokkan prefix dan panjang; check digit diverifikasi oleh Parse
+func (r BarcodeRule) Matches(barcode string) bool {
+	return r.IsActive && len(barcode) == EAN13Length && strings.HasPrefix(barcode, r.Prefix)
+}
+
+// Parse memecah barcode menjadi item code dan nilai tertanam. ok bernilai false jika check digit barcode
+// atau check digit nilai salah.
+func (r BarcodeRule) Parse(barcode string) (ScaleBarcode, bool) {
+	if !r.Matches(barcode) || !IsValidEAN13(barcode) {
+		return ScaleBarcode{}, false
+	}
+
+	start := len(r.Prefix)
+	itemCode := barcode[start : start+r.ItemCodeLength]
+	start += r.ItemCodeLength
+
+	valueDigits := barcode[start+1 : start+1+r.ValueLength]
+	if r.HasValueCheckDigit {
+		if !IsValidValueCheckDigit(valueDigits, barcode[start]) {
+			return ScaleBarcode{}, false
+		}
+	} else {
+		valueDigits = barcode[start : start+r.ValueLength]
+	}
+
+	value, err := strconv.ParseInt(valueDigits, 10, 64)
+	if err != nil {
+		return ScaleBarcode{}, false
+	}
+
+	return ScaleBarcode{
+		Rule:          r,
+		Barcode:       barcode,
+		ItemCode:      itemCode,
+		EmbeddedValue: value,
+	}, true
+}
+
+// Quantity mengembalikan jumlah dalam satuan jual (mis. kg) berdasarkan harga satuan efektif.
+// Untuk label harga, jumlah diturunkan dari harga tertanam dibagi harga satuan.
+func (s ScaleBarcode) Quantity(unitPrice int64) float64 {
+	if s.Rule.Measure == BarcodeMeasureWeight {
+		return s.scaledValue()
+	}
+
+	if unitPrice <= 0 {
+		return 0
+	}
+
+	return math.Round(float64(s.LinePrice(unitPrice))/float64(unitPrice)*1000) / 1000
+}
+
+// LinePrice menghitung harga baris: berat x harga satuan, atau harga yang tertanam di label
+func (s ScaleBarcode) LinePrice(unitPrice int64) int64 {
+	if s.Rule.Measure == BarcodeMeasureWeight {
+		return int64(math.Round(s.scaledValue() * float64(unitPrice)))
+	}
+
+	return int64(math.Round(s.scaledValue()))
+}
+
+func (s ScaleBarcode) scaledValue() float64 {
+	return float64(s.EmbeddedValue) / math.Pow10(s.Rule.Decimals)
+}
+
+// Tabel bobot check digit nilai GS1 untuk digit 0–9
+var (
+	valueWeight2Minus = [10]int{0, 2, 4, 6, 8, 9, 1, 3, 5, 7}
+	valueWeight3      = [10]int{0, 3, 6, 9, 2, 5, 8, 1, 4, 7}
+	valueWeight5Plus  = [10]int{0, 5, 1, 6, 2, 7, 3, 8, 4, 9}
+	valueWeight5Minus = [10]int{0, 5, 9, 4, 8, 3, 7, 2, 6, 1}
+)
+
+// SupportsValueCheckDigit bernilai true untuk panjang nilai yang punya algoritma check digit GS1 (4 atau 5 digit)
+func SupportsValueCheckDigit(valueLength int) bool {
+	return valueLength == 4 || valueLength == 5
+}
+
+// IsValidValueCheckDigit memverifikasi check digit harga/berat GS1. Nilai 4 digit memakai bobot 2-, 2-, 3, 5-
+// dan check digit adalah digit satuan dari jumlah x 3. Nilai 5 digit memakai bobot 5+, 2-, 5-, 5+, 2-
+// dan check digit adalah digit yang hasil bobot 5- nya sama dengan 10 dikurangi digit satuan jumlah.
+func IsValidValueCheckDigit(value string, checkDigit byte) bool {
+	if !SupportsValueCheckDigit(len(value)) || checkDigit < '0' || checkDigit > '9' {
+		return false
+	}
+
+	weights := [][10]int{valueWeight2Minus, valueWeight2Minus, valueWeight3, valueWeight5Minus}
+	if len(value) == 5 {
+		weights = [][10]int{valueWeight5Plus, valueWeight2Minus, valueWeight5Minus, valueWeight5Plus, valueWeight2Minus}
+	}
+
+	sum := 0
+	for i := 0; i < len(value); i++ {
+		digit := value[i]
+		if digit < '0' || digit > '9' {
+			return false
+		}
+		sum += weights[i][digit-'0']
+	}
+
+	if len(value) == 4 {
+		return sum*3%10 == int(checkDigit-'0')
+	}
+
+	return valueWeight5Minus[checkDigit-'0'] == (10-sum%10)%10
+}
+
+// IsValidEAN13 memverifikasi check digit EAN-13 (bobot 1 dan 3 bergantian dari kiri)
+func IsValidEAN13(barcode string) bool {
+	if len(barcode) != EAN13Length {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < EAN13Length-1; i++ {
+		digit := barcode[i]
+		if digit < '0' || digit > '9' {
+			return false
+		}
+
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(digit-'0') * weight
+	}
+
+	checkDigit := barcode[EAN13Length-1]
+	if checkDigit < '0' || checkDigit > '9' {
+		return false
+	}
+
+	return (10-sum%10)%10 == int(checkDigit-'0')
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MerchantAllocationChange adalah selisih alokasi merchant product terhadap warehouse supplier-nya.
+// Quantity positif diambil dari warehouse, negatif dikembalikan ke warehouse. Baris ditulis dalam transaksi
+// yang sama dengan perubahan stock merchant, dan PublishedAt diisi setelah event ke warehouse-service terkirim.
+// FailedAt diisi saat warehouse-service menolak alokasi karena stock tidak cukup.
+type MerchantAllocationChange struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	MerchantProductID uint       `json:"merchant_product_id" gorm:"not null;index"`
+	MerchantID        uint       `json:"merchant_id" gorm:"not null;index"`
+	ProductID         uint       `json:"product_id" gorm:"not null"`
+	WarehouseID       uint       `json:"warehouse_id" gorm:"not null"`
+	Quantity          int        `json:"quantity" gorm:"not null"`
+	PublishedAt       *time.Time `json:"published_at" gorm:"index"`
+	FailedAt          *time.Time `json:"failed_at" gorm:"index"`
+	FailureReason     string     `json:"failure_reason"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// Reference tetap sama saat event dikirim ulang sehingga warehouse-service hanya menerapkannya sekali
+func (c MerchantAllocationChange) Reference() string {
+	return fmt.Sprintf("merchant-allocation:%d", c.ID)
+}
+
+// ParseAllocationChangeReference mengambil ID dari reference merchant-allocation:{id}
+func ParseAllocationChangeReference(reference string) (uint, bool) {
+	raw, ok := strings.CutPrefix(reference, "merchant-allocation:")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"micro-warehouse/merchant-service/pkg/geo"
+	"time"
+)
+
+// MerchantDeliveryZone adalah area pengiriman satu merchant dalam bentuk polygon.
+// Kolom min/max menyimpan bounding box polygon supaya kandidat zona bisa disaring di database.
+type MerchantDeliveryZone struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	MerchantID   uint        `json:"merchant_id" gorm:"not null;index"`
+	Name         string      `json:"name" gorm:"type:varchar(100);not null"`
+	Polygon      geo.Polygon `json:"polygon" gorm:"type:jsonb;serializer:json;not null"`
+	MinLatitude  float64     `json:"-" gorm:"type:double precision;not null"`
+	MaxLatitude  float64     `json:"-" gorm:"type:double precision;not null"`
+	MinLongitude float64     `json:"-" gorm:"type:double precision;not null"`
+	MaxLongitude float64     `json:"-" gorm:"type:double precision;not null"`
+	IsActive     bool        `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    *time.Time  `json:"updated_at"`
+
+	Merchant Merchant `json:"merchant" gorm:"foreignKey:MerchantID"`
+}
+
+// SetPolygon menyimpan polygon bersama bounding box-nya
+func (z *MerchantDeliveryZone) SetPolygon(polygon geo.Polygon) {
+	bounds := polygon.Bounds()
+	z.Polygon = polygon
+	z.MinLatitude = bounds.MinLatitude
+	z.MaxLatitude = bounds.MaxLatitude
+	z.MinLongitude = bounds.MinLongitude
+	z.MaxLongitude = bounds.MaxLongitude
+}
@@ -1,17 +1,132 @@
 package model
 
-import "time"
+import (
+	"micro-warehouse/merchant-service/pkg/geo"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status operasional merchant; hanya merchant active yang bisa buka dan menerima penjualan
+const (
+	MerchantStatusActive    = "active"
+	MerchantStatusSuspended = "suspended"
+	MerchantStatusClosed    = "closed"
+)
+
+// Alasan merchant tidak buka, dikembalikan bersama is_open
+const (
+	MerchantClosedReasonSuspended    = "suspended"
+	MerchantClosedReasonClosed       = "closed"
+	MerchantClosedReasonClosure      = "temporary_closure"
+	MerchantClosedReasonOutsideHours = "outside_opening_hours"
+	MerchantArchivedStatusReason     = "archived"
+	DefaultMerchantTimezone          = "Asia/Jakarta"
+)
 
 type Merchant struct {
-	ID        uint       `json:"id" gorm:"primaryKey"`
-	Name      string     `json:"name" gorm:"type:varchar(100);not null"`
-	Address   string     `json:"address" gorm:"type:text"`
-	Photo     string     `json:"photo"`
-	Phone     string     `json:"phone"`
-	KeeperID  uint       `json:"keeper_id" gorm:"not null"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt *time.Time `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
-
-	MerchantProducts []MerchantProduct `json:"merchant_products" gorm:"foreignKey:MerchantID"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Name         string         `json:"name" gorm:"type:varchar(100);not null"`
+	Address      string         `json:"address" gorm:"type:text"`
+	Photo        string         `json:"photo"`
+	Phone        string         `json:"phone"`
+	Status       string         `json:"status" gorm:"type:varchar(20);not null;default:'active';index"`
+	StatusReason string         `json:"status_reason" gorm:"type:text"`
+	Timezone     string         `json:"timezone" gorm:"type:varchar(50);not null;default:'Asia/Jakarta'"`
+	Latitude     *float64       `json:"latitude" gorm:"type:double precision"`
+	Longitude    *float64       `json:"longitude" gorm:"type:double precision"`
+	ArchivedAt   *time.Time     `json:"archived_at,omitempty" gorm:"index"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    *time.Time     `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	MerchantProducts []MerchantProduct     `json:"merchant_products" gorm:"foreignKey:MerchantID"`
+	Staff            []MerchantStaff       `json:"staff" gorm:"foreignKey:MerchantID"`
+	OpeningHours     []MerchantOpeningHour `json:"opening_hours" gorm:"foreignKey:MerchantID"`
+	Closures         []MerchantClosure     `json:"closures" gorm:"foreignKey:MerchantID"`
+}
+
+// IsArchived bernilai true jika merchant disembunyikan dari daftar tetapi riwayatnya tetap disimpan
+func (m Merchant) IsArchived() bool {
+	return m.ArchivedAt != nil
+}
+
+// Coordinates mengembalikan titik merchant; ok false jika koordinat belum diisi
+func (m Merchant) Coordinates() (geo.Point, bool) {
+	if m.Latitude == nil || m.Longitude == nil {
+		return geo.Point{}, false
+	}
+
+	return geo.Point{Latitude: *m.Latitude, Longitude: *m.Longitude}, true
+}
+
+// Location mengembalikan timezone merchant; timezone yang tidak dikenal jatuh ke default
+func (m Merchant) Location() *time.Location {
+	timezone := m.Timezone
+	if timezone == "" {
+		timezone = DefaultMerchantTimezone
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		location, _ = time.LoadLocation(DefaultMerchantTimezone)
+	}
+	if location == nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// IsOpenAt mengecek status, penutupan sementara, lalu jam buka mingguan pada timezone merchant.
+// Merchant tanpa jam buka dianggap buka sepanjang waktu. OpeningHours dan Closures harus sudah di-preload.
+func (m Merchant) IsOpenAt(at time.Time) (bool, string) {
+	switch m.Status {
+	case MerchantStatusSuspended:
+		return false, MerchantClosedReasonSuspended
+	case MerchantStatusClosed:
+		return false, MerchantClosedReasonClosed
+	}
+
+	for _, closure := range m.Closures {
+		if closure.Covers(at) {
+			return false, MerchantClosedReasonClosure
+		}
+	}
+
+	if len(m.OpeningHours) == 0 {
+		return true, ""
+	}
+
+	local := at.In(m.Location())
+	for _, hour := range m.OpeningHours {
+		if hour.Covers(local) {
+			return true, ""
+		}
+	}
+
+	return false, MerchantClosedReasonOutsideHours
+}
+
+// ActiveStaff mengembalikan staff yang sedang bertugas pada waktu at; Staff harus sudah di-preload
+func (m Merchant) ActiveStaff(at time.Time) []MerchantStaff {
+	active := []MerchantStaff{}
+	for _, staff := range m.Staff {
+		if staff.IsActiveAt(at) {
+			active = append(active, staff)
+		}
+	}
+
+	return active
+}
+
+// LeadUserID adalah lead aktif pertama, dipakai sebagai keeper_id pada response lama; 0 jika tidak ada lead
+func (m Merchant) LeadUserID(at time.Time) uint {
+	for _, staff := range m.ActiveStaff(at) {
+		if staff.Role == MerchantStaffRoleLead {
+			return staff.UserID
+		}
+	}
+
+	return 0
 }
@@ -1,16 +1,71 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type MerchantProduct struct {
-	ID          uint       `json:"id" gorm:"primaryKey"`
-	MerchantID  uint       `json:"merchant_id" gorm:"not null;index"`
-	ProductID   uint       `json:"product_id" gorm:"not null;index"`
-	WarehouseID uint       `json:"warehouse_id" gorm:"not null;index"`
-	Stock       int        `json:"stock" gorm:"not null;default:0"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   *time.Time `json:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	MerchantID  uint           `json:"merchant_id" gorm:"not null;index"`
+	ProductID   uint           `json:"product_id" gorm:"not null;index"`
+	WarehouseID uint           `json:"warehouse_id" gorm:"not null;index"`
+	Stock       int            `json:"stock" gorm:"not null;default:0;index"`
+	MinStock    int            `json:"min_stock" gorm:"not null;default:0"`
+	MaxStock    int            `json:"max_stock" gorm:"not null;default:0"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   *time.Time     `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	Merchant Merchant `json:"merchant,omitempty" gorm:"foreignKey:MerchantID"`
+
+	// CurrentPrice diisi usecase dari riwayat harga; tidak disimpan di tabel merchant_products
+	CurrentPrice *MerchantProductPrice `json:"-" gorm:"-"`
+}
+
+// StockSummary adalah agregat merchant product, dipakai sebagai pengecekan sebelum merchant atau warehouse dihapus
+type StockSummary struct {
+	ProductCount  int64
+	MerchantCount int64
+	TotalStock    int64
+}
+
+// EffectivePrice adalah harga jual merchant: override yang sedang berlaku, atau basePrice dari product-service
+func (m MerchantProduct) EffectivePrice(basePrice int64) int64 {
+	if m.CurrentPrice != nil && m.CurrentPrice.Price != nil {
+		return *m.CurrentPrice.Price
+	}
+	return basePrice
+}
+
+// HasPriceOverride bernilai true jika merchant menjual dengan harga khusus saat ini
+func (m MerchantProduct) HasPriceOverride() bool {
+	return m.CurrentPrice != nil && m.CurrentPrice.Price != nil
+}
+
+// IsLowStock bernilai true jika threshold di-set (min_stock > 0) dan stock sudah di bawahnya
+func (m MerchantProduct) IsLowStock() bool {
+	return m.MinStock > 0 && m.Stock < m.MinStock
+}
+
+// CrossedBelowMinStock bernilai true hanya pada perubahan yang membuat stock turun melewati threshold,
+// sehingga alert tidak dikirim ulang untuk setiap penjualan berikutnya selama stock masih rendah
+func (m MerchantProduct) CrossedBelowMinStock(previousStock int) bool {
+	return m.IsLowStock() && previousStock >= m.MinStock
+}
+
+// HasReplenishmentLevels bernilai true jika min dan max stock sudah di-set dengan max di atas min
+func (m MerchantProduct) HasReplenishmentLevels() bool {
+	return m.MinStock > 0 && m.MaxStock > m.MinStock
+}
+
+// ReplenishmentQuantity adalah jumlah yang perlu ditambahkan agar stock kembali ke max_stock,
+// hanya jika stock sudah di bawah min_stock
+func (m MerchantProduct) ReplenishmentQuantity() int {
+	if !m.HasReplenishmentLevels() || m.Stock >= m.MinStock {
+		return 0
+	}
+
+	return m.MaxStock - m.Stock
 }
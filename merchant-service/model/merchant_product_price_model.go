@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// MerchantProductPrice adalah riwayat harga jual khusus merchant. Harga yang berlaku adalah baris
+// dengan effective_from terbaru yang sudah lewat; Price nil berarti kembali ke harga global product.
+type MerchantProductPrice struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	MerchantProductID uint      `json:"merchant_product_id" gorm:"not null;index:idx_merchant_product_prices_effective"`
+	Price             *int64    `json:"price" gorm:"type:bigint"`
+	EffectiveFrom     time.Time `json:"effective_from" gorm:"not null;index:idx_merchant_product_prices_effective"`
+	CreatedBy         uint      `json:"created_by" gorm:"not null;default:0"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// IsScheduled bernilai true jika harga belum berlaku pada waktu at
+func (m MerchantProductPrice) IsScheduled(at time.Time) bool {
+	return m.EffectiveFrom.After(at)
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// MerchantOpeningHour adalah satu rentang jam buka mingguan pada timezone merchant.
+// Weekday mengikuti time.Weekday (0 = Minggu). ClosesAt <= OpensAt berarti tutup keesokan harinya,
+// sehingga 22:00-02:00 adalah shift malam dan 00:00-00:00 berarti buka 24 jam.
+type MerchantOpeningHour struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	MerchantID uint   `json:"merchant_id" gorm:"not null;index"`
+	Weekday    int    `json:"weekday" gorm:"not null"`
+	OpensAt    string `json:"opens_at" gorm:"type:varchar(5);not null"`
+	ClosesAt   string `json:"closes_at" gorm:"type:varchar(5);not null"`
+}
+
+// Covers mengecek waktu lokal merchant, termasuk sisa shift malam dari hari sebelumnya
+func (h MerchantOpeningHour) Covers(local time.Time) bool {
+	clock := local.Format("15:04")
+	weekday := int(local.Weekday())
+	overnight := h.ClosesAt <= h.OpensAt
+
+	if h.Weekday == weekday && clock >= h.OpensAt && (overnight || clock < h.ClosesAt) {
+		return true
+	}
+
+	previousDay := (weekday + 6) % 7
+	return overnight && h.Weekday == previousDay && clock < h.ClosesAt
+}
+
+// MerchantClosure adalah penutupan sementara (libur, renovasi) di luar jam buka mingguan
+type MerchantClosure struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	MerchantID uint      `json:"merchant_id" gorm:"not null;index"`
+	StartsAt   time.Time `json:"starts_at" gorm:"not null"`
+	EndsAt     time.Time `json:"ends_at" gorm:"not null"`
+	Reason     string    `json:"reason" gorm:"type:text"`
+	CreatedBy  uint      `json:"created_by" gorm:"not null;default:0"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (c MerchantClosure) Covers(at time.Time) bool {
+	return !at.Before(c.StartsAt) && at.Before(c.EndsAt)
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Role staff di dalam merchant; role global (Manager/Keeper) tetap diatur user-service
+const (
+	MerchantStaffRoleLead    = "lead"
+	MerchantStaffRoleCashier = "cashier"
+)
+
+// MerchantStaff adalah satu penugasan user ke merchant. EndDate kosong berarti penugasan masih berjalan;
+// riwayat penugasan tidak dihapus sehingga siapa yang bertugas pada tanggal tertentu tetap bisa dilacak.
+type MerchantStaff struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	MerchantID uint       `json:"merchant_id" gorm:"not null;index"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	Role       string     `json:"role" gorm:"type:varchar(20);not null;default:'cashier'"`
+	StartDate  time.Time  `json:"start_date" gorm:"not null"`
+	EndDate    *time.Time `json:"end_date"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// IsActiveAt: penugasan aktif sejak StartDate sampai sebelum EndDate
+func (s MerchantStaff) IsActiveAt(at time.Time) bool {
+	if s.StartDate.After(at) {
+		return false
+	}
+
+	return s.EndDate == nil || s.EndDate.After(at)
+}
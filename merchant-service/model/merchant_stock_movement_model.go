@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+const (
+	StockMovementOpeningBalance = "opening_balance"
+	StockMovementSale           = "sale"
+	StockMovementTransferIn     = "transfer_in"
+	StockMovementAdjustment     = "adjustment"
+	StockMovementReturn         = "return"
+	StockMovementWriteOff       = "write_off"
+
+	StockMovementReturnToWarehouse = "return_to_warehouse"
+	StockMovementStocktake         = "stocktake"
+)
+
+// MerchantStockMovement adalah ledger append-only untuk setiap perubahan MerchantProduct.Stock.
+// Penjumlahan quantity_delta per merchant product selalu sama dengan stock saat ini.
+type MerchantStockMovement struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	MerchantProductID uint      `json:"merchant_product_id" gorm:"not null;index:idx_merchant_stock_movements_product_created"`
+	MerchantID        uint      `json:"merchant_id" gorm:"not null;index"`
+	ProductID         uint      `json:"product_id" gorm:"not null"`
+	MovementType      string    `json:"movement_type" gorm:"type:varchar(30);not null"`
+	QuantityDelta     int       `json:"quantity_delta" gorm:"not null"`
+	BalanceAfter      int       `json:"balance_after" gorm:"not null"`
+	ActorID           uint      `json:"actor_id" gorm:"not null;default:0"`
+	Reference         string    `json:"reference" gorm:"type:varchar(100);index"`
+	Note              string    `json:"note" gorm:"type:text"`
+	CreatedAt         time.Time `json:"created_at" gorm:"index:idx_merchant_stock_movements_product_created"`
+}
+
+// StockMovementMeta menjelaskan alasan perubahan stock yang akan dicatat ke ledger
+type StockMovementMeta struct {
+	MovementType string
+	ActorID      uint
+	Reference    string
+	Note         string
+}
+
+// StockReductionItem adalah satu baris pengurangan stock dari sebuah order
+type StockReductionItem struct {
+	ProductID uint
+	Quantity  int
+}
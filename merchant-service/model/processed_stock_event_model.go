@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ProcessedStockEvent menandai order yang stock-nya sudah dikurangi,
+// sehingga event yang terkirim ulang oleh RabbitMQ tidak mengurangi stock dua kali
+type ProcessedStockEvent struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	OrderID     string    `json:"order_id" gorm:"type:varchar(100);not null;uniqueIndex"`
+	MerchantID  uint      `json:"merchant_id" gorm:"not null;index"`
+	ProcessedAt time.Time `json:"processed_at" gorm:"autoCreateTime"`
+}
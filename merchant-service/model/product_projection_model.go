@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductProjection adalah salinan ringkas product dari product-service agar merchant product bisa dicari
+// berdasarkan nama, barcode dan category tanpa memanggil product-service. Diisi dari event product.*;
+// SyncedAt adalah versi product (updated_at) sehingga event lama yang datang terlambat tidak menimpa data baru.
+// Product yang dihapus disimpan sebagai tombstone dengan DeletedAt agar update yang terlambat tidak membuatnya muncul lagi.
+type ProductProjection struct {
+	ProductID    uint           `json:"product_id" gorm:"primaryKey;autoIncrement:false"`
+	Name         string         `json:"name" gorm:"type:varchar(255);not null"`
+	Barcode      string         `json:"barcode" gorm:"type:varchar(100);index"`
+	CategoryID   uint           `json:"category_id" gorm:"index"`
+	CategoryName string         `json:"category_name" gorm:"type:varchar(255)"`
+	SyncedAt     time.Time      `json:"synced_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
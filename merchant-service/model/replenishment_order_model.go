@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// Alur replenishment order: draft -> approved / rejected.
+// Draft dibuat oleh job terjadwal atau manager, lalu di-approve manager untuk mengalokasikan stock.
+const (
+	ReplenishmentOrderStatusDraft    = "draft"
+	ReplenishmentOrderStatusApproved = "approved"
+	ReplenishmentOrderStatusRejected = "rejected"
+)
+
+const (
+	ReplenishmentItemStatusPending   = "pending"
+	ReplenishmentItemStatusAllocated = "allocated"
+	ReplenishmentItemStatusFailed    = "failed"
+)
+
+// ReplenishmentOrder adalah usulan penambahan stock untuk satu merchant berdasarkan min/max level
+type ReplenishmentOrder struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	MerchantID uint   `json:"merchant_id" gorm:"not null;index"`
+	Status     string `json:"status" gorm:"type:varchar(20);not null;default:'draft';index"`
+
+	// CreatedBy 0 berarti dibuat oleh job terjadwal
+	CreatedBy  uint       `json:"created_by"`
+	ReviewedBy uint       `json:"reviewed_by"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	ReviewNote string     `json:"review_note" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Merchant Merchant                 `json:"merchant,omitempty" gorm:"foreignKey:MerchantID"`
+	Items    []ReplenishmentOrderItem `json:"items" gorm:"foreignKey:ReplenishmentOrderID"`
+}
+
+// ReplenishmentOrderItem menyimpan snapshot level stock saat usulan dibuat
+type ReplenishmentOrderItem struct {
+	ID                   uint   `json:"id" gorm:"primaryKey"`
+	ReplenishmentOrderID uint   `json:"replenishment_order_id" gorm:"not null;index"`
+	MerchantProductID    uint   `json:"merchant_product_id" gorm:"not null;index"`
+	ProductID            uint   `json:"product_id" gorm:"not null"`
+	WarehouseID          uint   `json:"warehouse_id" gorm:"not null"`
+	CurrentStock         int    `json:"current_stock" gorm:"not null"`
+	MinStock             int    `json:"min_stock" gorm:"not null"`
+	MaxStock             int    `json:"max_stock" gorm:"not null"`
+	WarehouseStock       int    `json:"warehouse_stock" gorm:"not null"`
+	SuggestedQuantity    int    `json:"suggested_quantity" gorm:"not null"`
+	AllocatedQuantity    int    `json:"allocated_quantity" gorm:"not null;default:0"`
+	Status               string `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	FailureReason        string `json:"failure_reason" gorm:"type:text"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	StockReturnConditionSellable = "sellable"
+	StockReturnConditionDamaged  = "damaged"
+)
+
+// StockReturn adalah pengembalian barang dari merchant ke warehouse asal.
+// Stock merchant langsung berkurang; warehouse menambah stock (atau quarantined_stock untuk barang rusak)
+// setelah menerima event warehouse.stock.returned.
+type StockReturn struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	MerchantID  uint       `json:"merchant_id" gorm:"not null;index"`
+	Reason      string     `json:"reason" gorm:"type:text"`
+	ReturnedBy  uint       `json:"returned_by" gorm:"not null"`
+	PublishedAt *time.Time `json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Merchant Merchant          `json:"merchant,omitempty" gorm:"foreignKey:MerchantID"`
+	Items    []StockReturnItem `json:"items" gorm:"foreignKey:StockReturnID"`
+}
+
+// Reference dipakai bersama oleh ledger merchant dan warehouse
+func (s StockReturn) Reference() string {
+	return fmt.Sprintf("stock-return:%d", s.ID)
+}
+
+type StockReturnItem struct {
+	ID                uint   `json:"id" gorm:"primaryKey"`
+	StockReturnID     uint   `json:"stock_return_id" gorm:"not null;index"`
+	MerchantProductID uint   `json:"merchant_product_id" gorm:"not null"`
+	ProductID         uint   `json:"product_id" gorm:"not null"`
+	WarehouseID       uint   `json:"warehouse_id" gorm:"not null"`
+	Quantity          int    `json:"quantity" gorm:"not null"`
+	Condition         string `json:"condition" gorm:"type:varchar(20);not null;default:'sellable'"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Alur stocktake: open -> approved / cancelled.
+// Selama open, stock yang diharapkan dibekukan di setiap line sementara penjualan tetap berjalan.
+const (
+	StocktakeStatusOpen      = "open"
+	StocktakeStatusApproved  = "approved"
+	StocktakeStatusCancelled = "cancelled"
+)
+
+// Reason code untuk penyesuaian stock hasil stocktake
+const (
+	StocktakeReasonMiscount = "miscount"
+	StocktakeReasonDamaged  = "damaged"
+	StocktakeReasonExpired  = "expired"
+	StocktakeReasonTheft    = "theft"
+	StocktakeReasonOther    = "other"
+)
+
+// Stocktake adalah sesi hitung fisik untuk satu merchant, opsional dibatasi satu kategori product
+type Stocktake struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	MerchantID uint   `json:"merchant_id" gorm:"not null;index"`
+	CategoryID uint   `json:"category_id" gorm:"not null;default:0"`
+	Status     string `json:"status" gorm:"type:varchar(20);not null;default:'open';index"`
+	Note       string `json:"note" gorm:"type:text"`
+
+	OpenedBy   uint       `json:"opened_by" gorm:"not null"`
+	ApprovedBy uint       `json:"approved_by"`
+	ApprovedAt *time.Time `json:"approved_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Merchant Merchant        `json:"merchant,omitempty" gorm:"foreignKey:MerchantID"`
+	Lines    []StocktakeLine `json:"lines" gorm:"foreignKey:StocktakeID"`
+}
+
+// Reference dipakai di ledger merchant untuk penyesuaian hasil stocktake
+func (s Stocktake) Reference() string {
+	return fmt.Sprintf("stocktake:%d", s.ID)
+}
+
+// StocktakeLine menyimpan stock yang dibekukan saat sesi dibuka dan hasil hitungnya.
+// CountedQuantity nil berarti product belum dihitung dan tidak disesuaikan saat approve.
+type StocktakeLine struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	StocktakeID       uint       `json:"stocktake_id" gorm:"not null;uniqueIndex:idx_stocktake_lines_product"`
+	MerchantProductID uint       `json:"merchant_product_id" gorm:"not null"`
+	ProductID         uint       `json:"product_id" gorm:"not null;uniqueIndex:idx_stocktake_lines_product"`
+	ExpectedStock     int        `json:"expected_stock" gorm:"not null"`
+	CountedQuantity   *int       `json:"counted_quantity"`
+	CountedBy         uint       `json:"counted_by"`
+	CountedAt         *time.Time `json:"counted_at"`
+	ReasonCode        string     `json:"reason_code" gorm:"type:varchar(20)"`
+
+	// Diisi saat approve: selisih yang benar-benar diterapkan dan movement ledger-nya
+	AdjustedDelta int  `json:"adjusted_delta" gorm:"not null;default:0"`
+	MovementID    uint `json:"movement_id"`
+}
+
+// Variance adalah selisih hitung fisik terhadap stock yang dibekukan; 0 jika belum dihitung
+func (s StocktakeLine) Variance() int {
+	if s.CountedQuantity == nil {
+		return 0
+	}
+	return *s.CountedQuantity - s.ExpectedStock
+}
@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+// Alur transfer order: requested -> approved -> picked -> shipped -> (partially_received ->) received.
+// requested bisa rejected, requested/approved bisa cancelled.
+const (
+	TransferOrderStatusRequested         = "requested"
+	TransferOrderStatusApproved          = "approved"
+	TransferOrderStatusRejected          = "rejected"
+	TransferOrderStatusCancelled         = "cancelled"
+	TransferOrderStatusPicked            = "picked"
+	TransferOrderStatusShipped           = "shipped"
+	TransferOrderStatusPartiallyReceived = "partially_received"
+	TransferOrderStatusReceived          = "received"
+)
+
+const (
+	DiscrepancyTypeMissing = "missing"
+	DiscrepancyTypeDamaged = "damaged"
+)
+
+// TransferOrder adalah permintaan stock dari merchant ke warehouse
+type TransferOrder struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	MerchantID  uint   `json:"merchant_id" gorm:"not null;index"`
+	WarehouseID uint   `json:"warehouse_id" gorm:"not null;index"`
+	Status      string `json:"status" gorm:"type:varchar(30);not null;default:'requested';index"`
+	Note        string `json:"note" gorm:"type:text"`
+
+	RequestedBy uint       `json:"requested_by" gorm:"not null"`
+	ReviewedBy  uint       `json:"reviewed_by"`
+	ReviewedAt  *time.Time `json:"reviewed_at"`
+	ReviewNote  string     `json:"review_note" gorm:"type:text"`
+	PickedBy    uint       `json:"picked_by"`
+	PickedAt    *time.Time `json:"picked_at"`
+	ShippedBy   uint       `json:"shipped_by"`
+	ShippedAt   *time.Time `json:"shipped_at"`
+	ReceivedAt  *time.Time `json:"received_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Merchant Merchant               `json:"merchant,omitempty" gorm:"foreignKey:MerchantID"`
+	Items    []TransferOrderItem    `json:"items" gorm:"foreignKey:TransferOrderID"`
+	Receipts []TransferOrderReceipt `json:"receipts" gorm:"foreignKey:TransferOrderID"`
+}
+
+type TransferOrderItem struct {
+	ID                  uint `json:"id" gorm:"primaryKey"`
+	TransferOrderID     uint `json:"transfer_order_id" gorm:"not null;index"`
+	ProductID           uint `json:"product_id" gorm:"not null"`
+	RequestedQuantity   int  `json:"requested_quantity" gorm:"not null"`
+	ShippedQuantity     int  `json:"shipped_quantity" gorm:"not null;default:0"`
+	ReceivedQuantity    int  `json:"received_quantity" gorm:"not null;default:0"`
+	DiscrepancyQuantity int  `json:"discrepancy_quantity" gorm:"not null;default:0"`
+}
+
+// OutstandingQuantity adalah jumlah yang sudah dikirim tapi belum dikonfirmasi diterima atau dicatat selisih
+func (i TransferOrderItem) OutstandingQuantity() int {
+	return i.ShippedQuantity - i.ReceivedQuantity - i.DiscrepancyQuantity
+}
+
+// TransferOrderReceipt adalah satu konfirmasi penerimaan oleh keeper; satu order bisa diterima bertahap
+type TransferOrderReceipt struct {
+	ID              uint                       `json:"id" gorm:"primaryKey"`
+	TransferOrderID uint                       `json:"transfer_order_id" gorm:"not null;index"`
+	ReceivedBy      uint                       `json:"received_by" gorm:"not null"`
+	Note            string                     `json:"note" gorm:"type:text"`
+	CreatedAt       time.Time                  `json:"created_at"`
+	Lines           []TransferOrderReceiptLine `json:"lines" gorm:"foreignKey:TransferOrderReceiptID"`
+}
+
+type TransferOrderReceiptLine struct {
+	ID                     uint   `json:"id" gorm:"primaryKey"`
+	TransferOrderReceiptID uint   `json:"transfer_order_receipt_id" gorm:"not null;index"`
+	ProductID              uint   `json:"product_id" gorm:"not null"`
+	ReceivedQuantity       int    `json:"received_quantity" gorm:"not null;default:0"`
+	DiscrepancyQuantity    int    `json:"discrepancy_quantity" gorm:"not null;default:0"`
+	DiscrepancyType        string `json:"discrepancy_type" gorm:"type:varchar(20)"`
+	DiscrepancyReason      string `json:"discrepancy_reason" gorm:"type:text"`
+}
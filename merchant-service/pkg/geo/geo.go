@@ -0,0 +1,219 @@
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// earthRadiusKm adalah radius rata-rata bumi yang dipakai rumus haversine
+const earthRadiusKm = 6371.0
+
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// DistanceKm menghitung jarak great-circle antara dua titik dengan rumus haversine
+func DistanceKm(a, b Point) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox adalah persegi lintang/bujur untuk menyaring kandidat di database sebelum jarak
+// atau polygon dihitung ulang dengan tepat
+type BoundingBox struct {
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+}
+
+// Contains bernilai true jika titik berada di dalam atau tepat di tepi box
+func (b BoundingBox) Contains(p Point) bool {
+	return p.Latitude >= b.MinLatitude && p.Latitude <= b.MaxLatitude &&
+		p.Longitude >= b.MinLongitude && p.Longitude <= b.MaxLongitude
+}
+
+// BoundingBoxAround mengembalikan box yang pasti memuat semua titik dalam radiusKm dari center.
+// Dekat kutub atau jika box melewati garis bujur 180 seluruh rentang bujur dipakai.
+func BoundingBoxAround(center Point, radiusKm float64) BoundingBox {
+	deltaLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	box := BoundingBox{
+		MinLatitude:  math.Max(-90, center.Latitude-deltaLat),
+		MaxLatitude:  math.Min(90, center.Latitude+deltaLat),
+		MinLongitude: -180,
+		MaxLongitude: 180,
+	}
+
+	if box.MinLatitude <= -90 || box.MaxLatitude >= 90 {
+		return box
+	}
+
+	// Lingkaran lintang terjauh dari ekuator menentukan lebar bujur terbesar
+	maxLat := math.Max(math.Abs(box.MinLatitude), math.Abs(box.MaxLatitude)) * math.Pi / 180
+	deltaLng := deltaLat / math.Cos(maxLat)
+	if center.Longitude-deltaLng < -180 || center.Longitude+deltaLng > 180 {
+		return box
+	}
+
+	box.MinLongitude = center.Longitude - deltaLng
+	box.MaxLongitude = center.Longitude + deltaLng
+	return box
+}
+
+var (
+	ErrPolygonTooFewPoints    = errors.New("polygon needs at least 3 distinct points")
+	ErrPolygonOutOfRange      = errors.New("polygon point is outside the valid latitude/longitude range")
+	ErrPolygonSelfIntersects  = errors.New("polygon edges must not cross each other")
+	ErrPolygonCrossesMeridian = errors.New("polygon must not span more than 180 degrees of longitude")
+)
+
+// Polygon adalah ring titik berurutan (searah atau berlawanan jarum jam). Titik terakhir tidak perlu
+// sama dengan titik pertama; ring selalu ditutup otomatis. Sisi polygon diperlakukan sebagai garis lurus
+// pada bidang lintang/bujur, cukup akurat untuk zona antar kota.
+type Polygon []Point
+
+// Normalize membuang titik yang berulang berturut-turut dan titik penutup yang sama dengan titik pertama
+func (p Polygon) Normalize() Polygon {
+	normalized := Polygon{}
+	for _, point := range p {
+		if len(normalized) > 0 && normalized[len(normalized)-1] == point {
+			continue
+		}
+		normalized = append(normalized, point)
+	}
+
+	for len(normalized) > 1 && normalized[0] == normalized[len(normalized)-1] {
+		normalized = normalized[:len(normalized)-1]
+	}
+
+	return normalized
+}
+
+// Validate memeriksa polygon yang sudah di-Normalize
+func (p Polygon) Validate() error {
+	if len(p) < 3 {
+		return ErrPolygonTooFewPoints
+	}
+
+	for _, point := range p {
+		if point.Latitude < -90 || point.Latitude > 90 || point.Longitude < -180 || point.Longitude > 180 {
+			return ErrPolygonOutOfRange
+		}
+	}
+
+	bounds := p.Bounds()
+	if bounds.MaxLongitude-bounds.MinLongitude > 180 {
+		return ErrPolygonCrossesMeridian
+	}
+
+	for i := range p {
+		a1, a2 := p[i], p[(i+1)%len(p)]
+		for j := i + 1; j < len(p); j++ {
+			// Sisi yang bersebelahan pasti berbagi satu titik
+			if j == i+1 || (i == 0 && j == len(p)-1) {
+				continue
+			}
+
+			b1, b2 := p[j], p[(j+1)%len(p)]
+			if segmentsIntersect(a1, a2, b1, b2) {
+				return ErrPolygonSelfIntersects
+			}
+		}
+	}
+
+	if p.area() == 0 {
+		return ErrPolygonTooFewPoints
+	}
+
+	return nil
+}
+
+// Bounds mengembalikan box terkecil yang memuat seluruh titik polygon
+func (p Polygon) Bounds() BoundingBox {
+	if len(p) == 0 {
+		return BoundingBox{}
+	}
+
+	box := BoundingBox{
+		MinLatitude:  p[0].Latitude,
+		MaxLatitude:  p[0].Latitude,
+		MinLongitude: p[0].Longitude,
+		MaxLongitude: p[0].Longitude,
+	}
+	for _, point := range p[1:] {
+		box.MinLatitude = math.Min(box.MinLatitude, point.Latitude)
+		box.MaxLatitude = math.Max(box.MaxLatitude, point.Latitude)
+		box.MinLongitude = math.Min(box.MinLongitude, point.Longitude)
+		box.MaxLongitude = math.Max(box.MaxLongitude, point.Longitude)
+	}
+
+	return box
+}
+
+// Contains memakai ray casting; titik yang tepat berada di sisi polygon dianggap di dalam
+func (p Polygon) Contains(point Point) bool {
+	if len(p) < 3 || !p.Bounds().Contains(point) {
+		return false
+	}
+
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if onSegment(a, b, point) {
+			return true
+		}
+
+		if (a.Latitude > point.Latitude) != (b.Latitude > point.Latitude) {
+			crossLng := a.Longitude + (point.Latitude-a.Latitude)*(b.Longitude-a.Longitude)/(b.Latitude-a.Latitude)
+			if point.Longitude < crossLng {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
+
+// area menghitung luas bertanda pada bidang lintang/bujur (shoelace); 0 berarti semua titik segaris
+func (p Polygon) area() float64 {
+	sum := 0.0
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		sum += a.Longitude*b.Latitude - b.Longitude*a.Latitude
+	}
+
+	return sum / 2
+}
+
+// orientation: > 0 berlawanan jarum jam, < 0 searah jarum jam, 0 segaris
+func orientation(a, b, c Point) float64 {
+	return (b.Longitude-a.Longitude)*(c.Latitude-a.Latitude) - (b.Latitude-a.Latitude)*(c.Longitude-a.Longitude)
+}
+
+// onSegment bernilai true jika c segaris dengan a-b dan berada di antara keduanya
+func onSegment(a, b, c Point) bool {
+	return orientation(a, b, c) == 0 &&
+		c.Longitude >= math.Min(a.Longitude, b.Longitude) && c.Longitude <= math.Max(a.Longitude, b.Longitude) &&
+		c.Latitude >= math.Min(a.Latitude, b.Latitude) && c.Latitude <= math.Max(a.Latitude, b.Latitude)
+}
+
+func segmentsIntersect(a1, a2, b1, b2 Point) bool {
+	o1 := orientation(a1, a2, b1)
+	o2 := orientation(a1, a2, b2)
+	o3 := orientation(b1, b2, a1)
+	o4 := orientation(b1, b2, a2)
+
+	if ((o1 > 0 && o2 < 0) || (o1 < 0 && o2 > 0)) && ((o3 > 0 && o4 < 0) || (o3 < 0 && o4 > 0)) {
+		return true
+	}
+
+	return onSegment(a1, a2, b1) || onSegment(a1, a2, b2) || onSegment(b1, b2, a1) || onSegment(b1, b2, a2)
+}
@@ -10,16 +10,18 @@ import (
 )
 
 type CachedProductClient struct {
-	client ProductClientInterface
-	redis  *redis.RedisClient
-	ttl    time.Duration
+	client  ProductClientInterface
+	redis   *redis.RedisClient
+	metrics *CacheMetrics
+	ttl     time.Duration
 }
 
-func NewCachedProductClient(productClient ProductClientInterface, redisClient *redis.RedisClient) *CachedProductClient {
+func NewCachedProductClient(productClient ProductClientInterface, redisClient *redis.RedisClient, metrics *CacheMetrics) *CachedProductClient {
 	return &CachedProductClient{
-		client: productClient,
-		redis:  redisClient,
-		ttl:    1 * time.Hour,
+		client:  productClient,
+		redis:   redisClient,
+		metrics: metrics,
+		ttl:     1 * time.Hour,
 	}
 }
 
@@ -35,14 +37,20 @@ func (cpc *CachedProductClient) generateCacheKeyMultiple(prefix string, ids []ui
 	return key[:len(key)-1]
 }
 
+func (cpc *CachedProductClient) barcodeCacheKey(barcode string) string {
+	return fmt.Sprintf("%s:%s", CacheProductBarcode, barcode)
+}
+
 func (cpc *CachedProductClient) GetProductByID(ctx context.Context, productID uint) (*ProductResponse, error) {
 	cacheKey := cpc.generateCacheKey("single", productID)
 
 	var cachedProduct ProductResponse
 	if err := cpc.redis.Get(ctx, cacheKey, &cachedProduct); err == nil {
 		log.Infof("[CachedProductClient] GetProductByID - 1: %v", cachedProduct)
+		cpc.metrics.Hit(CacheProductSingle)
 		return &cachedProduct, nil
 	}
+	cpc.metrics.Miss(CacheProductSingle)
 
 	product, err := cpc.client.GetProductByID(ctx, productID)
 	if err != nil {
@@ -60,13 +68,15 @@ func (cpc *CachedProductClient) GetProductByID(ctx context.Context, productID ui
 }
 
 func (cpc *CachedProductClient) GetProductByBarcode(ctx context.Context, barcode string) (*ProductResponse, error) {
-	cacheKey := fmt.Sprintf("product:barcode:%s", barcode)
+	cacheKey := cpc.barcodeCacheKey(barcode)
 
 	var cachedProduct ProductResponse
 	if err := cpc.redis.Get(ctx, cacheKey, &cachedProduct); err == nil {
 		log.Infof("[CachedProductClient] GetProductByBarcode - 1: %v", cachedProduct)
+		cpc.metrics.Hit(CacheProductBarcode)
 		return &cachedProduct, nil
 	}
+	cpc.metrics.Miss(CacheProductBarcode)
 
 	product, err := cpc.client.GetProductByBarcode(ctx, barcode)
 	if err != nil {
@@ -83,6 +93,41 @@ func (cpc *CachedProductClient) GetProductByBarcode(ctx context.Context, barcode
 	return product, nil
 }
 
+// GetProductsByBarcodes hanya meminta barcode yang belum ada di cache, dalam satu request
+func (cpc *CachedProductClient) GetProductsByBarcodes(ctx context.Context, barcodes []string) ([]ProductResponse, error) {
+	products := []ProductResponse{}
+	missing := []string{}
+	for _, barcode := range barcodes {
+		var cachedProduct ProductResponse
+		if err := cpc.redis.Get(ctx, cpc.barcodeCacheKey(barcode), &cachedProduct); err == nil {
+			cpc.metrics.Hit(CacheProductBarcode)
+			products = append(products, cachedProduct)
+			continue
+		}
+		cpc.metrics.Miss(CacheProductBarcode)
+		missing = append(missing, barcode)
+	}
+
+	if len(missing) == 0 {
+		return products, nil
+	}
+
+	fetched, err := cpc.client.GetProductsByBarcodes(ctx, missing)
+	if err != nil {
+		log.Errorf("[CachedProductClient] GetProductsByBarcodes - 1: %v", err)
+		return nil, err
+	}
+
+	for _, product := range fetched {
+		if err := cpc.redis.Set(ctx, cpc.barcodeCacheKey(product.Barcode), product, cpc.ttl); err != nil {
+			log.Errorf("[CachedProductClient] GetProductsByBarcodes - 2: %v", err)
+		}
+		products = append(products, product)
+	}
+
+	return products, nil
+}
+
 func (cpc *CachedProductClient) GetProducts(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]ProductResponse, error) {
 	return cpc.client.GetProducts(ctx, page, limit, search, sortBy, sortOrder)
 }
@@ -90,3 +135,29 @@ func (cpc *CachedProductClient) GetProducts(ctx context.Context, page int, limit
 func (cpc *CachedProductClient) HealthCheck(ctx context.Context) error {
 	return cpc.client.HealthCheck(ctx)
 }
+
+// EvictProduct menghapus cache product beserta barcode lama dan barunya; dipanggil saat product berubah atau dihapus
+func (cpc *CachedProductClient) EvictProduct(ctx context.Context, productID uint, barcodes []string) error {
+	deleted, err := cpc.redis.Delete(ctx, cpc.generateCacheKey("single", productID))
+	if err != nil {
+		log.Errorf("[CachedProductClient] EvictProduct - 1: %v", err)
+		return err
+	}
+	cpc.metrics.Evicted(CacheProductSingle, deleted)
+
+	keys := []string{}
+	for _, barcode := range barcodes {
+		if barcode != "" {
+			keys = append(keys, cpc.barcodeCacheKey(barcode))
+		}
+	}
+
+	deleted, err = cpc.redis.Delete(ctx, keys...)
+	if err != nil {
+		log.Errorf("[CachedProductClient] EvictProduct - 2: %v", err)
+		return err
+	}
+	cpc.metrics.Evicted(CacheProductBarcode, deleted)
+
+	return nil
+}
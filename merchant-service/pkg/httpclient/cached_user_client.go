@@ -10,16 +10,18 @@ import (
 )
 
 type CachedUserClient struct {
-	client UserClientInterface
-	redis  *redis.RedisClient
-	ttl    time.Duration
+	client  UserClientInterface
+	redis   *redis.RedisClient
+	metrics *CacheMetrics
+	ttl     time.Duration
 }
 
-func NewCachedUserClient(userClient UserClientInterface, redisClient *redis.RedisClient) *CachedUserClient {
+func NewCachedUserClient(userClient UserClientInterface, redisClient *redis.RedisClient, metrics *CacheMetrics) *CachedUserClient {
 	return &CachedUserClient{
-		client: userClient,
-		redis:  redisClient,
-		ttl:    1 * time.Hour,
+		client:  userClient,
+		redis:   redisClient,
+		metrics: metrics,
+		ttl:     1 * time.Hour,
 	}
 }
 
@@ -27,14 +29,20 @@ func (cuc *CachedUserClient) generateCacheKey(prefix string, id uint) string {
 	return fmt.Sprintf("user:%s:%d", prefix, id)
 }
 
+func (cuc *CachedUserClient) roleCacheKey(roleName string) string {
+	return fmt.Sprintf("%s:%s", CacheUserRole, roleName)
+}
+
 func (cuc *CachedUserClient) GetUserByID(ctx context.Context, userID uint) (*UserResponse, error) {
 	cacheKey := cuc.generateCacheKey("single", userID)
 
 	var cachedUser UserResponse
 	if err := cuc.redis.Get(ctx, cacheKey, &cachedUser); err == nil {
 		log.Infof("[CachedUserClient] GetUserByID - 1: %v", cachedUser)
+		cuc.metrics.Hit(CacheUserSingle)
 		return &cachedUser, nil
 	}
+	cuc.metrics.Miss(CacheUserSingle)
 
 	user, err := cuc.client.GetUserByID(ctx, userID)
 	if err != nil {
@@ -50,3 +58,51 @@ func (cuc *CachedUserClient) GetUserByID(ctx context.Context, userID uint) (*Use
 
 	return user, nil
 }
+
+func (cuc *CachedUserClient) GetUsersByRoleName(ctx context.Context, roleName string) ([]UserResponse, error) {
+	cacheKey := cuc.roleCacheKey(roleName)
+
+	var cachedUsers []UserResponse
+	if err := cuc.redis.Get(ctx, cacheKey, &cachedUsers); err == nil {
+		cuc.metrics.Hit(CacheUserRole)
+		return cachedUsers, nil
+	}
+	cuc.metrics.Miss(CacheUserRole)
+
+	users, err := cuc.client.GetUsersByRoleName(ctx, roleName)
+	if err != nil {
+		log.Errorf("[CachedUserClient] GetUsersByRoleName - 1: %v", err)
+		return nil, err
+	}
+
+	// Daftar user per role lebih sering berubah, simpan lebih singkat dari data user tunggal
+	if err := cuc.redis.Set(ctx, cacheKey, users, 10*time.Minute); err != nil {
+		log.Errorf("[CachedUserClient] GetUsersByRoleName - 2: %v", err)
+	}
+
+	return users, nil
+}
+
+// EvictUser menghapus cache user dan daftar user per role; roles berisi role lama dan baru user tersebut
+func (cuc *CachedUserClient) EvictUser(ctx context.Context, userID uint, roles []string) error {
+	deleted, err := cuc.redis.Delete(ctx, cuc.generateCacheKey("single", userID))
+	if err != nil {
+		log.Errorf("[CachedUserClient] EvictUser - 1: %v", err)
+		return err
+	}
+	cuc.metrics.Evicted(CacheUserSingle, deleted)
+
+	keys := []string{}
+	for _, role := range roles {
+		keys = append(keys, cuc.roleCacheKey(role))
+	}
+
+	deleted, err = cuc.redis.Delete(ctx, keys...)
+	if err != nil {
+		log.Errorf("[CachedUserClient] EvictUser - 2: %v", err)
+		return err
+	}
+	cuc.metrics.Evicted(CacheUserRole, deleted)
+
+	return nil
+}
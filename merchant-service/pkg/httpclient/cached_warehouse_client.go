@@ -10,16 +10,18 @@ import (
 )
 
 type CachedWarehouseClient struct {
-	client WarehouseClientInterface
-	redis  *redis.RedisClient
-	ttl    time.Duration
+	client  WarehouseClientInterface
+	redis   *redis.RedisClient
+	metrics *CacheMetrics
+	ttl     time.Duration
 }
 
-func NewCachedWarehouseClient(warehouseClient WarehouseClientInterface, redisClient *redis.RedisClient) *CachedWarehouseClient {
+func NewCachedWarehouseClient(warehouseClient WarehouseClientInterface, redisClient *redis.RedisClient, metrics *CacheMetrics) *CachedWarehouseClient {
 	return &CachedWarehouseClient{
-		client: warehouseClient,
-		redis:  redisClient,
-		ttl:    1 * time.Hour,
+		client:  warehouseClient,
+		redis:   redisClient,
+		metrics: metrics,
+		ttl:     1 * time.Hour,
 	}
 }
 
@@ -27,14 +29,21 @@ func (cwc *CachedWarehouseClient) generateCacheKey(prefix string, id uint) strin
 	return fmt.Sprintf("warehouse:%s:%d", prefix, id)
 }
 
+// stockCacheKey: stock disimpan per pasangan warehouse dan product, terpisah dari data warehouse
+func (cwc *CachedWarehouseClient) stockCacheKey(warehouseID uint, productID uint) string {
+	return fmt.Sprintf("%s:%d:%d", CacheWarehouseStock, warehouseID, productID)
+}
+
 func (cwc *CachedWarehouseClient) GetWarehouseByID(ctx context.Context, warehouseID uint) (*WarehouseResponse, error) {
 	cacheKey := cwc.generateCacheKey("single", warehouseID)
 
 	var cachedWarehouse WarehouseResponse
 	if err := cwc.redis.Get(ctx, cacheKey, &cachedWarehouse); err == nil {
 		log.Infof("[CachedWarehouseClient] GetWarehouseByID - 1: %v", cachedWarehouse)
+		cwc.metrics.Hit(CacheWarehouseSingle)
 		return &cachedWarehouse, nil
 	}
+	cwc.metrics.Miss(CacheWarehouseSingle)
 
 	warehouse, err := cwc.client.GetWarehouseByID(ctx, warehouseID)
 	if err != nil {
@@ -52,13 +61,15 @@ func (cwc *CachedWarehouseClient) GetWarehouseByID(ctx context.Context, warehous
 }
 
 func (cwc *CachedWarehouseClient) GetWarehouseProductStock(ctx context.Context, warehouseID uint, productID uint) (*WarehouseProductStockResponse, error) {
-	cacheKey := cwc.generateCacheKey("single", warehouseID)
+	cacheKey := cwc.stockCacheKey(warehouseID, productID)
 
 	var cachedWarehouseProductStock WarehouseProductStockResponse
 	if err := cwc.redis.Get(ctx, cacheKey, &cachedWarehouseProductStock); err == nil {
 		log.Infof("[CachedWarehouseClient] GetWarehouseProductStock - 1: %v", cachedWarehouseProductStock)
+		cwc.metrics.Hit(CacheWarehouseStock)
 		return &cachedWarehouseProductStock, nil
 	}
+	cwc.metrics.Miss(CacheWarehouseStock)
 
 	warehouseProductStock, err := cwc.client.GetWarehouseProductStock(ctx, warehouseID, productID)
 	if err != nil {
@@ -74,3 +85,47 @@ func (cwc *CachedWarehouseClient) GetWarehouseProductStock(ctx context.Context,
 
 	return warehouseProductStock, nil
 }
+
+// DeductWarehouseStocks tidak di-cache; stock yang ter-cache dihapus saat warehouse-service mengirim event warehouse.stock_changed
+func (cwc *CachedWarehouseClient) DeductWarehouseStocks(ctx context.Context, warehouseID uint, reference string, items []WarehouseStockDeductionItem) ([]WarehouseStockDeductionItem, error) {
+	return cwc.client.DeductWarehouseStocks(ctx, warehouseID, reference, items)
+}
+
+// GetProductWarehouseStocks tidak di-cache karena dipakai untuk memilih warehouse sumber berdasarkan stock terkini
+func (cwc *CachedWarehouseClient) GetProductWarehouseStocks(ctx context.Context, productID uint) ([]ProductWarehouseStockResponse, error) {
+	return cwc.client.GetProductWarehouseStocks(ctx, productID)
+}
+
+// GetWarehouseLocations tidak di-cache supaya warehouse yang baru dibuat, dipindah atau diarsip langsung terlihat
+func (cwc *CachedWarehouseClient) GetWarehouseLocations(ctx context.Context) ([]WarehouseLocationResponse, error) {
+	return cwc.client.GetWarehouseLocations(ctx)
+}
+
+// EvictWarehouse menghapus cache data warehouse; stock per product dihapus lewat EvictWarehouseProductStocks
+func (cwc *CachedWarehouseClient) EvictWarehouse(ctx context.Context, warehouseID uint) error {
+	deleted, err := cwc.redis.Delete(ctx, cwc.generateCacheKey("single", warehouseID))
+	if err != nil {
+		log.Errorf("[CachedWarehouseClient] EvictWarehouse - 1: %v", err)
+		return err
+	}
+	cwc.metrics.Evicted(CacheWarehouseSingle, deleted)
+
+	return nil
+}
+
+// EvictWarehouseProductStocks menghapus cache stock beberapa product di satu warehouse
+func (cwc *CachedWarehouseClient) EvictWarehouseProductStocks(ctx context.Context, warehouseID uint, productIDs []uint) error {
+	keys := make([]string, 0, len(productIDs))
+	for _, productID := range productIDs {
+		keys = append(keys, cwc.stockCacheKey(warehouseID, productID))
+	}
+
+	deleted, err := cwc.redis.Delete(ctx, keys...)
+	if err != nil {
+		log.Errorf("[CachedWarehouseClient] EvictWarehouseProductStocks - 1: %v", err)
+		return err
+	}
+	cwc.metrics.Evicted(CacheWarehouseStock, deleted)
+
+	return nil
+}
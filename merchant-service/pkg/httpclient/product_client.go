@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -18,6 +19,7 @@ import (
 type ProductClientInterface interface {
 	GetProductByID(ctx context.Context, productID uint) (*ProductResponse, error)
 	GetProductByBarcode(ctx context.Context, barcode string) (*ProductResponse, error)
+	GetProductsByBarcodes(ctx context.Context, barcodes []string) ([]ProductResponse, error)
 	GetProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]ProductResponse, error)
 	HealthCheck(ctx context.Context) error
 }
@@ -82,6 +84,64 @@ func (p *ProductClient) GetProductByBarcode(ctx context.Context, barcode string)
 	return &productResponse.Data, nil
 }
 
+// GetProductsByBarcodes implements ProductClientInterface.
+// Barcode yang tidak dikenal product-service tidak ada di hasil.
+func (p *ProductClient) GetProductsByBarcodes(ctx context.Context, barcodes []string) ([]ProductResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/products/barcodes", p.UrlApiGateway)
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"barcodes": barcodes,
+	})
+	if err != nil {
+		log.Errorf("[ProductClient] GetProductsByBarcodes - 1: %v", err)
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		log.Errorf("[ProductClient] GetProductsByBarcodes - 2: %v", err)
+		return nil, err
+	}
+
+	token, err := p.generateInternalToken()
+	if err != nil {
+		log.Errorf("[ProductClient] GetProductsByBarcodes - 3: %v", err)
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Internal-Request", "true")
+	req.Header.Set("X-Gateway", "warehouse-api-gateway")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[ProductClient] GetProductsByBarcodes - 4: %v", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("[ProductClient] GetProductsByBarcodes - 5: %v", err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("[ProductClient] GetProductsByBarcodes - 6: %s", string(body))
+		return nil, errors.New("failed to get products by barcodes")
+	}
+
+	var productListResponse ProductListResponse
+	if err := json.Unmarshal(body, &productListResponse); err != nil {
+		log.Errorf("[ProductClient] GetProductsByBarcodes - 7: %v", err)
+		return nil, err
+	}
+
+	return productListResponse.Data, nil
+}
+
 // GetProductByID implements ProductClientInterface.
 func (p *ProductClient) GetProductByID(ctx context.Context, productID uint) (*ProductResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/products/%d", p.UrlApiGateway, productID)
@@ -173,13 +233,13 @@ func (p *ProductClient) GetProducts(ctx context.Context, page int, limit int, se
 		return nil, errors.New("failed to get products")
 	}
 
-	var productListResponse ProductListResponse
-	if err := json.Unmarshal(body, &productListResponse); err != nil {
+	var productPageResponse ProductPageResponse
+	if err := json.Unmarshal(body, &productPageResponse); err != nil {
 		log.Errorf("[ProductClient] GetProducts - 6: %v", err)
 		return nil, err
 	}
 
-	return productListResponse.Data, nil
+	return productPageResponse.Data.Products, nil
 }
 
 // HealthCheck implements ProductClientInterface.
@@ -233,6 +293,15 @@ type ProductListResponse struct {
 	Error   string            `json:"error,omitempty"`
 }
 
+// ProductPageResponse: daftar product dari GET /products dibungkus bersama pagination
+type ProductPageResponse struct {
+	Message string `json:"message"`
+	Data    struct {
+		Products []ProductResponse `json:"products"`
+	} `json:"data"`
+	Error string `json:"error,omitempty"`
+}
+
 func NewProductClient(cfg configs.Config) ProductClientInterface {
 	return &ProductClient{httpClient: &http.Client{
 		Timeout: 30 * time.Second,
@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"micro-warehouse/merchant-service/configs"
+	"micro-warehouse/merchant-service/pkg/jwt"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type TransactionClientInterface interface {
+	CountPendingTransactions(ctx context.Context, merchantID uint) (int64, error)
+}
+
+type TransactionClient struct {
+	UrlApiGateway string
+	httpClient    *http.Client
+	config        configs.Config
+}
+
+func (t *TransactionClient) generateInternalToken() (string, error) {
+	return jwt.GenerateInternalToken(t.config)
+}
+
+// CountPendingTransactions implements TransactionClientInterface.
+func (t *TransactionClient) CountPendingTransactions(ctx context.Context, merchantID uint) (int64, error) {
+	url := fmt.Sprintf("%s/api/v1/transactions/merchants/%d/pending-count", t.UrlApiGateway, merchantID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Errorf("[TransactionClient] CountPendingTransactions - 1: %v", err)
+		return 0, err
+	}
+
+	token, err := t.generateInternalToken()
+	if err != nil {
+		log.Errorf("[TransactionClient] CountPendingTransactions - 2: %v", err)
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Internal-Request", "true")
+	req.Header.Set("X-Gateway", "warehouse-api-gateway")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[TransactionClient] CountPendingTransactions - 3: %v", err)
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("[TransactionClient] CountPendingTransactions - 4: %v", err)
+		return 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("[TransactionClient] CountPendingTransactions - 5: %s", string(body))
+		return 0, errors.New("failed to count pending transactions")
+	}
+
+	var pendingCountResponse MerchantPendingCountServiceResponse
+	if err := json.Unmarshal(body, &pendingCountResponse); err != nil {
+		log.Errorf("[TransactionClient] CountPendingTransactions - 6: %v", err)
+		return 0, err
+	}
+
+	return pendingCountResponse.Data.PendingTransactions, nil
+}
+
+type MerchantPendingCountResponse struct {
+	MerchantID          uint  `json:"merchant_id"`
+	PendingTransactions int64 `json:"pending_transactions"`
+}
+
+type MerchantPendingCountServiceResponse struct {
+	Message string                       `json:"message"`
+	Data    MerchantPendingCountResponse `json:"data"`
+	Error   string                       `json:"error,omitempty"`
+}
+
+func NewTransactionClient(cfg configs.Config) TransactionClientInterface {
+	return &TransactionClient{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		UrlApiGateway: cfg.App.UrlApiGateway,
+		config:        cfg,
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 type UserClientInterface interface {
 	GetUserByID(ctx context.Context, userID uint) (*UserResponse, error)
+	GetUsersByRoleName(ctx context.Context, roleName string) ([]UserResponse, error)
 }
 
 type UserClient struct {
@@ -78,6 +79,55 @@ func (u *UserClient) GetUserByID(ctx context.Context, userID uint) (*UserRespons
 	return &userResponse.Data, nil
 }
 
+// GetUsersByRoleName implements UserClientInterface.
+func (u *UserClient) GetUsersByRoleName(ctx context.Context, roleName string) ([]UserResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/users/role/%s", u.UrlApiGateway, roleName)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Errorf("[UserClient] GetUsersByRoleName - 1: %v", err)
+		return nil, err
+	}
+
+	token, err := u.generateInternalToken()
+	if err != nil {
+		log.Errorf("[UserClient] GetUsersByRoleName - 2: %v", err)
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Internal-Request", "true")
+	req.Header.Set("X-Gateway", "warehouse-api-gateway")
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[UserClient] GetUsersByRoleName - 3: %v", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("[UserClient] GetUsersByRoleName - 4: %v", err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("[UserClient] GetUsersByRoleName - 5: %s", string(body))
+		return nil, errors.New("failed to get users by role name")
+	}
+
+	var usersResponse UsersServiceResponse
+	if err := json.Unmarshal(body, &usersResponse); err != nil {
+		log.Errorf("[UserClient] GetUsersByRoleName - 6: %v", err)
+		return nil, err
+	}
+
+	return usersResponse.Data, nil
+}
+
 type UserResponse struct {
 	ID    uint   `json:"id"`
 	Name  string `json:"name"`
@@ -92,6 +142,12 @@ type UserServiceResponse struct {
 	Error   string       `json:"error,omitempty"`
 }
 
+type UsersServiceResponse struct {
+	Message string         `json:"message"`
+	Data    []UserResponse `json:"data"`
+	Error   string         `json:"error,omitempty"`
+}
+
 func NewUserClient(cfg configs.Config) UserClientInterface {
 	return &UserClient{
 		httpClient: &http.Client{
@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -17,6 +18,27 @@ import (
 type WarehouseClientInterface interface {
 	GetWarehouseByID(ctx context.Context, warehouseID uint) (*WarehouseResponse, error)
 	GetWarehouseProductStock(ctx context.Context, warehouseID, productID uint) (*WarehouseProductStockResponse, error)
+	DeductWarehouseStocks(ctx context.Context, warehouseID uint, reference string, items []WarehouseStockDeductionItem) ([]WarehouseStockDeductionItem, error)
+	GetProductWarehouseStocks(ctx context.Context, productID uint) ([]ProductWarehouseStockResponse, error)
+	GetWarehouseLocations(ctx context.Context) ([]WarehouseLocationResponse, error)
+}
+
+var ErrWarehouseStockNotEnough = errors.New("warehouse stock not enough")
+
+type WarehouseStockDeductionItem struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+// WarehouseStockDeductionServiceResponse: Items adalah jumlah yang tercatat untuk reference,
+// sehingga request yang diulang mendapat jumlah dari request pertama
+type WarehouseStockDeductionServiceResponse struct {
+	Message string `json:"message"`
+	Data    struct {
+		Reference string                        `json:"reference"`
+		Applied   bool                          `json:"applied"`
+		Items     []WarehouseStockDeductionItem `json:"items"`
+	} `json:"data"`
 }
 
 type WarehouseClient struct {
@@ -130,11 +152,13 @@ func (w *WarehouseClient) GetWarehouseProductStock(ctx context.Context, warehous
 }
 
 type WarehouseResponse struct {
-	ID      uint   `json:"id"`
-	Name    string `json:"name"`
-	Address string `json:"address"`
-	Photo   string `json:"photo"`
-	Phone   string `json:"phone"`
+	ID        uint     `json:"id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Photo     string   `json:"photo"`
+	Phone     string   `json:"phone"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
 }
 
 type WarehouseServiceResponse struct {
@@ -156,6 +180,199 @@ type WarehouseProductStockServiceResponse struct {
 	Error   string                        `json:"error,omitempty"`
 }
 
+// WarehouseLocationResponse hanya dikirim untuk warehouse aktif yang koordinatnya sudah diisi
+type WarehouseLocationResponse struct {
+	ID        uint    `json:"id"`
+	Name      string  `json:"name"`
+	Address   string  `json:"address"`
+	Phone     string  `json:"phone"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type WarehouseLocationsServiceResponse struct {
+	Message string                      `json:"message"`
+	Data    []WarehouseLocationResponse `json:"data"`
+	Error   string                      `json:"error,omitempty"`
+}
+
+type ProductWarehouseStockResponse struct {
+	WarehouseID   uint     `json:"warehouse_id"`
+	WarehouseName string   `json:"warehouse_name"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
+	ProductID     uint     `json:"product_id"`
+	Stock         int      `json:"stock"`
+}
+
+type ProductWarehouseStocksServiceResponse struct {
+	Message string                          `json:"message"`
+	Data    []ProductWarehouseStockResponse `json:"data"`
+	Error   string                          `json:"error,omitempty"`
+}
+
+// GetProductWarehouseStocks implements WarehouseClientInterface.
+// Hasil diurutkan dari warehouse dengan stock terbanyak.
+func (w *WarehouseClient) GetProductWarehouseStocks(ctx context.Context, productID uint) ([]ProductWarehouseStockResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/warehouse-products/detail/products/%d/stocks", w.UrlApiGateway, productID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Errorf("[WarehouseClient] GetProductWarehouseStocks - 1: %v", err)
+		return nil, err
+	}
+
+	token, err := w.generateInternalToken()
+	if err != nil {
+		log.Errorf("[WarehouseClient] GetProductWarehouseStocks - 2: %v", err)
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Internal-Request", "true")
+	req.Header.Set("X-Gateway", "warehouse-api-gateway")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[WarehouseClient] GetProductWarehouseStocks - 3: %v", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("[WarehouseClient] GetProductWarehouseStocks - 4: %v", err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("[WarehouseClient] GetProductWarehouseStocks - 5: %s", string(body))
+		return nil, errors.New("failed to get product warehouse stocks")
+	}
+
+	var stocksResponse ProductWarehouseStocksServiceResponse
+	if err := json.Unmarshal(body, &stocksResponse); err != nil {
+		log.Errorf("[WarehouseClient] GetProductWarehouseStocks - 6: %v", err)
+		return nil, err
+	}
+
+	return stocksResponse.Data, nil
+}
+
+// GetWarehouseLocations implements WarehouseClientInterface.
+func (w *WarehouseClient) GetWarehouseLocations(ctx context.Context) ([]WarehouseLocationResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/warehouses/locations", w.UrlApiGateway)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Errorf("[WarehouseClient] GetWarehouseLocations - 1: %v", err)
+		return nil, err
+	}
+
+	token, err := w.generateInternalToken()
+	if err != nil {
+		log.Errorf("[WarehouseClient] GetWarehouseLocations - 2: %v", err)
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Internal-Request", "true")
+	req.Header.Set("X-Gateway", "warehouse-api-gateway")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[WarehouseClient] GetWarehouseLocations - 3: %v", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("[WarehouseClient] GetWarehouseLocations - 4: %v", err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("[WarehouseClient] GetWarehouseLocations - 5: %s", string(body))
+		return nil, errors.New("failed to get warehouse locations")
+	}
+
+	var locationsResponse WarehouseLocationsServiceResponse
+	if err := json.Unmarshal(body, &locationsResponse); err != nil {
+		log.Errorf("[WarehouseClient] GetWarehouseLocations - 6: %v", err)
+		return nil, err
+	}
+
+	return locationsResponse.Data, nil
+}
+
+// DeductWarehouseStocks implements WarehouseClientInterface.
+// reference yang sama hanya diproses sekali oleh warehouse-service sehingga aman untuk di-retry;
+// yang dikembalikan adalah item yang benar-benar dikurangi untuk reference tersebut.
+func (w *WarehouseClient) DeductWarehouseStocks(ctx context.Context, warehouseID uint, reference string, items []WarehouseStockDeductionItem) ([]WarehouseStockDeductionItem, error) {
+	url := fmt.Sprintf("%s/api/v1/warehouse-products/%d/deductions", w.UrlApiGateway, warehouseID)
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"reference": reference,
+		"items":     items,
+	})
+	if err != nil {
+		log.Errorf("[WarehouseClient] DeductWarehouseStocks - 1: %v", err)
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		log.Errorf("[WarehouseClient] DeductWarehouseStocks - 2: %v", err)
+		return nil, err
+	}
+
+	token, err := w.generateInternalToken()
+	if err != nil {
+		log.Errorf("[WarehouseClient] DeductWarehouseStocks - 3: %v", err)
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Internal-Request", "true")
+	req.Header.Set("X-Gateway", "warehouse-api-gateway")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[WarehouseClient] DeductWarehouseStocks - 4: %v", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("[WarehouseClient] DeductWarehouseStocks - 5: %v", err)
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var deductionResponse WarehouseStockDeductionServiceResponse
+		if err := json.Unmarshal(body, &deductionResponse); err != nil {
+			log.Errorf("[WarehouseClient] DeductWarehouseStocks - 8: %v", err)
+			return nil, err
+		}
+		return deductionResponse.Data.Items, nil
+	case http.StatusUnprocessableEntity:
+		log.Errorf("[WarehouseClient] DeductWarehouseStocks - 6: %s", string(body))
+		return nil, fmt.Errorf("%w: %s", ErrWarehouseStockNotEnough, string(body))
+	default:
+		log.Errorf("[WarehouseClient] DeductWarehouseStocks - 7: %s", string(body))
+		return nil, errors.New("failed to deduct warehouse stock")
+	}
+}
+
 func NewWarehouseClient(cfg configs.Config) WarehouseClientInterface {
 	return &WarehouseClient{
 		httpClient: &http.Client{
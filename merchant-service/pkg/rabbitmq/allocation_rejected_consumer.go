@@ -0,0 +1,131 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/streadway/amqp"
+)
+
+const (
+	AllocationRejectedQueue      = "merchant_allocation_rejected"
+	AllocationRejectedRoutingKey = "merchant.allocation.rejected"
+)
+
+// AllocationRejectedEvent dikirim warehouse-service saat StockReductionEvent ditolak karena stock tidak cukup
+type AllocationRejectedEvent struct {
+	Reference   string    `json:"reference"`
+	MerchantID  uint      `json:"merchant_id"`
+	WarehouseID uint      `json:"warehouse_id"`
+	ProductID   uint      `json:"product_id"`
+	Quantity    int       `json:"quantity"`
+	Reason      string    `json:"reason"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// AllocationRejectedConsumer menandai allocation change yang ditolak warehouse-service sebagai gagal,
+// sehingga manager bisa melihatnya dan mengirim ulang setelah stock warehouse ditambah
+type AllocationRejectedConsumer struct {
+	conn                *amqp.Connection
+	ch                  *amqp.Channel
+	merchantProductRepo repository.MerchantProductRepositoryInterface
+}
+
+func NewAllocationRejectedConsumer(url string, merchantProductRepo repository.MerchantProductRepositoryInterface) (*AllocationRejectedConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		log.Errorf("[AllocationRejectedConsumer] NewAllocationRejectedConsumer - 1: %v", err)
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[AllocationRejectedConsumer] NewAllocationRejectedConsumer - 2: %v", err)
+		return nil, err
+	}
+
+	if err := ch.ExchangeDeclare(ExhangeName, "topic", true, false, false, false, nil); err != nil {
+		log.Errorf("[AllocationRejectedConsumer] NewAllocationRejectedConsumer - 3: %v", err)
+		return nil, err
+	}
+
+	q, err := ch.QueueDeclare(AllocationRejectedQueue, true, false, false, false, nil)
+	if err != nil {
+		log.Errorf("[AllocationRejectedConsumer] NewAllocationRejectedConsumer - 4: %v", err)
+		return nil, err
+	}
+
+	if err := ch.QueueBind(q.Name, AllocationRejectedRoutingKey, ExhangeName, false, nil); err != nil {
+		log.Errorf("[AllocationRejectedConsumer] NewAllocationRejectedConsumer - 5: %v", err)
+		return nil, err
+	}
+
+	return &AllocationRejectedConsumer{
+		conn:                conn,
+		ch:                  ch,
+		merchantProductRepo: merchantProductRepo,
+	}, nil
+}
+
+func (ac *AllocationRejectedConsumer) Consume(ctx context.Context) error {
+	msgs, err := ac.ch.Consume(AllocationRejectedQueue, "", false, false, false, false, nil)
+	if err != nil {
+		log.Errorf("[AllocationRejectedConsumer] Consume - 1: %v", err)
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Stopping allocation rejected consumer...")
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				log.Info("Allocation rejected consumer channel closed")
+				return nil
+			}
+			ac.handle(ctx, msg)
+		}
+	}
+}
+
+// handle membuang pesan rusak dan me-requeue kegagalan database sekali; pesan aslinya tetap tersimpan
+// di DLQ warehouse-service sehingga penolakan yang terlewat masih bisa ditelusuri
+func (ac *AllocationRejectedConsumer) handle(ctx context.Context, msg amqp.Delivery) {
+	var event AllocationRejectedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Errorf("[AllocationRejectedConsumer] handle - 1: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	id, ok := model.ParseAllocationChangeReference(event.Reference)
+	if !ok {
+		log.Errorf("[AllocationRejectedConsumer] handle - 2: invalid reference %q", event.Reference)
+		msg.Nack(false, false)
+		return
+	}
+
+	if err := ac.merchantProductRepo.MarkAllocationChangeFailed(ctx, id, event.Reason); err != nil {
+		log.Errorf("[AllocationRejectedConsumer] handle - 3: %v", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
+	}
+
+	log.Warnf("[AllocationRejectedConsumer] handle - %s rejected by warehouse %d: %s", event.Reference, event.WarehouseID, event.Reason)
+	msg.Ack(false)
+}
+
+func (ac *AllocationRejectedConsumer) Close() error {
+	if ac.ch != nil {
+		ac.ch.Close()
+	}
+	if ac.conn != nil {
+		return ac.conn.Close()
+	}
+	return nil
+}
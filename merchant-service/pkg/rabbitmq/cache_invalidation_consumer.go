@@ -0,0 +1,185 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/streadway/amqp"
+)
+
+// EntityChangedEvent dikirim product-, user- dan warehouse-service setiap kali data yang di-cache service lain berubah.
+// Routing key-nya "<entity>.<action>", mis. product.updated atau warehouse.stock_changed.
+type EntityChangedEvent struct {
+	Entity     string           `json:"entity"`
+	Action     string           `json:"action"`
+	ID         uint             `json:"id"`
+	Barcodes   []string         `json:"barcodes,omitempty"`
+	Roles      []string         `json:"roles,omitempty"`
+	ProductIDs []uint           `json:"product_ids,omitempty"`
+	Product    *ProductSnapshot `json:"product,omitempty"`
+	Timestamp  time.Time        `json:"timestamp"`
+}
+
+// ProductSnapshot adalah data product terbaru pada event product.created/updated; UpdatedAt adalah versinya
+type ProductSnapshot struct {
+	Name         string    `json:"name"`
+	Barcode      string    `json:"barcode"`
+	CategoryID   uint      `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+const (
+	EntityEventsExchange   = "entity_events"
+	CacheInvalidationQueue = "merchant_cache_invalidation"
+
+	EntityProduct   = "product"
+	EntityUser      = "user"
+	EntityWarehouse = "warehouse"
+
+	EntityActionCreated      = "created"
+	EntityActionUpdated      = "updated"
+	EntityActionDeleted      = "deleted"
+	EntityActionStockChanged = "stock_changed"
+)
+
+type ProductCacheEvictor interface {
+	EvictProduct(ctx context.Context, productID uint, barcodes []string) error
+}
+
+type UserCacheEvictor interface {
+	EvictUser(ctx context.Context, userID uint, roles []string) error
+}
+
+type WarehouseCacheEvictor interface {
+	EvictWarehouse(ctx context.Context, warehouseID uint) error
+	EvictWarehouseProductStocks(ctx context.Context, warehouseID uint, productIDs []uint) error
+}
+
+// CacheInvalidationConsumer menghapus key redis milik cached client saat data sumbernya berubah.
+// Queue dipakai bersama semua instance merchant-service karena redis-nya juga bersama.
+type CacheInvalidationConsumer struct {
+	conn       *amqp.Connection
+	ch         *amqp.Channel
+	products   ProductCacheEvictor
+	users      UserCacheEvictor
+	warehouses WarehouseCacheEvictor
+}
+
+func NewCacheInvalidationConsumer(url string, products ProductCacheEvictor, users UserCacheEvictor, warehouses WarehouseCacheEvictor) (*CacheInvalidationConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		log.Errorf("[CacheInvalidationConsumer] NewCacheInvalidationConsumer - 1: %v", err)
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[CacheInvalidationConsumer] NewCacheInvalidationConsumer - 2: %v", err)
+		return nil, err
+	}
+
+	if err := ch.ExchangeDeclare(EntityEventsExchange, "topic", true, false, false, false, nil); err != nil {
+		log.Errorf("[CacheInvalidationConsumer] NewCacheInvalidationConsumer - 3: %v", err)
+		return nil, err
+	}
+
+	q, err := ch.QueueDeclare(CacheInvalidationQueue, true, false, false, false, nil)
+	if err != nil {
+		log.Errorf("[CacheInvalidationConsumer] NewCacheInvalidationConsumer - 4: %v", err)
+		return nil, err
+	}
+
+	for _, entity := range []string{EntityProduct, EntityUser, EntityWarehouse} {
+		if err := ch.QueueBind(q.Name, entity+".*", EntityEventsExchange, false, nil); err != nil {
+			log.Errorf("[CacheInvalidationConsumer] NewCacheInvalidationConsumer - 5: %v", err)
+			return nil, err
+		}
+	}
+
+	return &CacheInvalidationConsumer{
+		conn:       conn,
+		ch:         ch,
+		products:   products,
+		users:      users,
+		warehouses: warehouses,
+	}, nil
+}
+
+func (cc *CacheInvalidationConsumer) Consume(ctx context.Context) error {
+	msgs, err := cc.ch.Consume(CacheInvalidationQueue, "", false, false, false, false, nil)
+	if err != nil {
+		log.Errorf("[CacheInvalidationConsumer] Consume - 1: %v", err)
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Stopping cache invalidation consumer...")
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				log.Info("Cache invalidation consumer channel closed")
+				return nil
+			}
+			cc.handle(ctx, msg)
+		}
+	}
+}
+
+// handle membuang pesan rusak; gagal menghapus key (mis. redis tidak tersedia) di-requeue sekali,
+// setelah itu key tetap kedaluwarsa sesuai TTL cached client
+func (cc *CacheInvalidationConsumer) handle(ctx context.Context, msg amqp.Delivery) {
+	var event EntityChangedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Errorf("[CacheInvalidationConsumer] handle - 1: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	if event.ID == 0 {
+		log.Errorf("[CacheInvalidationConsumer] handle - 2: invalid event %+v", event)
+		msg.Nack(false, false)
+		return
+	}
+
+	if err := cc.evict(ctx, event); err != nil {
+		log.Errorf("[CacheInvalidationConsumer] handle - 3: %v", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
+	}
+
+	msg.Ack(false)
+}
+
+func (cc *CacheInvalidationConsumer) evict(ctx context.Context, event EntityChangedEvent) error {
+	switch event.Entity {
+	case EntityProduct:
+		return cc.products.EvictProduct(ctx, event.ID, event.Barcodes)
+	case EntityUser:
+		return cc.users.EvictUser(ctx, event.ID, event.Roles)
+	case EntityWarehouse:
+		if event.Action != EntityActionStockChanged {
+			if err := cc.warehouses.EvictWarehouse(ctx, event.ID); err != nil {
+				return err
+			}
+		}
+		return cc.warehouses.EvictWarehouseProductStocks(ctx, event.ID, event.ProductIDs)
+	}
+
+	log.Infof("[CacheInvalidationConsumer] evict - ignoring %s.%s", event.Entity, event.Action)
+	return nil
+}
+
+func (cc *CacheInvalidationConsumer) Close() error {
+	if cc.ch != nil {
+		cc.ch.Close()
+	}
+	if cc.conn != nil {
+		return cc.conn.Close()
+	}
+	return nil
+}
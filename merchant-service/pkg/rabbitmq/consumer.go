@@ -3,11 +3,18 @@ package rabbitmq
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/configs"
+	"micro-warehouse/merchant-service/model"
 	"micro-warehouse/merchant-service/repository"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/streadway/amqp"
+	"gorm.io/gorm"
 )
 
 type StockReducedEvent struct {
@@ -22,13 +29,47 @@ type StockReducedEventProduct struct {
 	Quantity  int  `json:"quantity"`
 }
 
+const (
+	StockEventsExchange   = "business_events"
+	StockEventsQueue      = "merchant_stock_events"
+	StockEventsRetryQueue = "merchant_stock_events.retry"
+	StockEventsDLQ        = "merchant_stock_events.dlq"
+
+	headerRetryCount         = "x-retry-count"
+	headerDeadLetterReason   = "x-dead-letter-reason"
+	headerDeadLetteredAt     = "x-dead-lettered-at"
+	headerOriginalRoutingKey = "x-original-routing-key"
+
+	defaultStockConsumerMaxRetries       = 5
+	defaultStockConsumerRetryBaseSeconds = 5
+	defaultStockConsumerRetryMaxSeconds  = 300
+	defaultStockConsumerPrefetch         = 10
+)
+
+// StockReducedRoutingKey adalah satu-satunya event di merchant_stock_events yang mengurangi stock;
+// event lain dengan prefix merchant.stock. (mis. merchant.stock.low) ikut ter-bind dan diabaikan
+const StockReducedRoutingKey = "merchant.stock.reduced"
+
+// LowStockChecker dipanggil setelah stock order berhasil dikurangi untuk mengecek min_stock
+type LowStockChecker interface {
+	CheckMovements(ctx context.Context, movements []model.MerchantStockMovement)
+}
+
+// errInvalidStockEvent menandai payload yang tidak akan pernah berhasil diproses (poison message)
+var errInvalidStockEvent = errors.New("invalid stock event")
+
 type StockConsumer struct {
 	conn         *amqp.Connection
 	ch           *amqp.Channel
 	merchantRepo repository.MerchantProductRepositoryInterface
+	stockAlert   LowStockChecker
+
+	maxRetries int
+	retryBase  time.Duration
+	retryMax   time.Duration
 }
 
-func NewStockConsumer(url string, merchantRepo repository.MerchantProductRepositoryInterface) (*StockConsumer, error) {
+func NewStockConsumer(url string, merchantRepo repository.MerchantProductRepositoryInterface, stockAlert LowStockChecker, consumerCfg configs.StockConsumer) (*StockConsumer, error) {
 	conn, err := amqp.Dial(url)
 	if err != nil {
 		log.Errorf("[StockConsumer] NewStockConsumer - 1: %v", err)
@@ -42,8 +83,33 @@ func NewStockConsumer(url string, merchantRepo repository.MerchantProductReposit
 		return nil, err
 	}
 
-	err = ch.ExchangeDeclare(
-		"business_events",
+	if err := declareStockEventTopology(ch); err != nil {
+		log.Errorf("[StockConsumer] NewStockConsumer - 3: %v", err)
+		return nil, err
+	}
+
+	// Batasi jumlah pesan yang sedang diproses agar goroutine handler tidak tumbuh tanpa batas
+	if err := ch.Qos(intOrDefault(consumerCfg.Prefetch, defaultStockConsumerPrefetch), 0, false); err != nil {
+		log.Errorf("[StockConsumer] NewStockConsumer - 4: %v", err)
+		return nil, err
+	}
+
+	return &StockConsumer{
+		conn:         conn,
+		ch:           ch,
+		merchantRepo: merchantRepo,
+		stockAlert:   stockAlert,
+		maxRetries:   intOrDefault(consumerCfg.MaxRetries, defaultStockConsumerMaxRetries),
+		retryBase:    time.Duration(intOrDefault(consumerCfg.RetryBaseSeconds, defaultStockConsumerRetryBaseSeconds)) * time.Second,
+		retryMax:     time.Duration(intOrDefault(consumerCfg.RetryMaxSeconds, defaultStockConsumerRetryMaxSeconds)) * time.Second,
+	}, nil
+}
+
+// declareStockEventTopology mendeklarasikan queue utama, retry queue dan dead-letter queue.
+// Retry queue tidak punya consumer: pesan menunggu sampai TTL habis lalu dikembalikan ke queue utama.
+func declareStockEventTopology(ch *amqp.Channel) error {
+	err := ch.ExchangeDeclare(
+		StockEventsExchange,
 		"topic",
 		true,
 		false,
@@ -51,49 +117,63 @@ func NewStockConsumer(url string, merchantRepo repository.MerchantProductReposit
 		false,
 		nil,
 	)
-
 	if err != nil {
-		log.Errorf("[StockConsumer] NewStockConsumer - 3: %v", err)
-		return nil, err
+		return err
 	}
 
 	q, err := ch.QueueDeclare(
-		"merchant_stock_events",
+		StockEventsQueue,
 		true,
 		false,
 		false,
 		false,
 		nil,
 	)
-
 	if err != nil {
-		log.Errorf("[StockConsumer] NewStockConsumer - 4: %v", err)
-		return nil, err
+		return err
 	}
 
 	err = ch.QueueBind(
 		q.Name,
 		"merchant.stock.*",
-		"business_events",
+		StockEventsExchange,
 		false,
 		nil,
 	)
+	if err != nil {
+		return err
+	}
 
+	_, err = ch.QueueDeclare(
+		StockEventsRetryQueue,
+		true,
+		false,
+		false,
+		false,
+		amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": StockEventsQueue,
+		},
+	)
 	if err != nil {
-		log.Errorf("[StockConsumer] NewStockConsumer - 5: %v", err)
-		return nil, err
+		return err
 	}
 
-	return &StockConsumer{
-		conn:         conn,
-		ch:           ch,
-		merchantRepo: merchantRepo,
-	}, nil
+	_, err = ch.QueueDeclare(
+		StockEventsDLQ,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+
+	return err
 }
 
 func (s *StockConsumer) ConsumeStockReductionEvents(ctx context.Context) error {
 	msgs, err := s.ch.Consume(
-		"merchant_stock_events",
+		StockEventsQueue,
 		"",
 		false,
 		false,
@@ -112,42 +192,187 @@ func (s *StockConsumer) ConsumeStockReductionEvents(ctx context.Context) error {
 		case <-ctx.Done():
 			log.Info("Stopping stock consumer...")
 			return nil
-		case msg := <-msgs:
+		case msg, ok := <-msgs:
+			if !ok {
+				log.Info("Stock consumer channel closed")
+				return nil
+			}
 			go s.handleStockReductionEvent(msg)
 		}
 	}
 }
 
-func (sc *StockConsumer) handleStockReductionEvent(msg amqp.Delivery) error {
-	defer msg.Ack(false)
+// handleStockReductionEvent selalu menyelesaikan pesan: ack jika berhasil atau duplikat,
+// dijadwalkan ulang untuk error sementara, dan dipindah ke DLQ untuk poison message
+func (sc *StockConsumer) handleStockReductionEvent(msg amqp.Delivery) {
+	// Pesan retry/replay masuk lewat default exchange dengan routing key nama queue
+	if strings.HasPrefix(msg.RoutingKey, "merchant.stock.") && msg.RoutingKey != StockReducedRoutingKey {
+		msg.Ack(false)
+		return
+	}
 
+	err := sc.processStockReductionEvent(msg.Body)
+
+	switch {
+	case err == nil:
+		msg.Ack(false)
+	case errors.Is(err, repository.ErrStockEventAlreadyProcessed):
+		log.Infof("[StockConsumer] handleStockReductionEvent - skipping duplicate delivery: %v", err)
+		msg.Ack(false)
+	case isPermanentStockEventError(err):
+		sc.deadLetter(msg, err)
+	default:
+		sc.retry(msg, err)
+	}
+}
+
+func (sc *StockConsumer) processStockReductionEvent(body []byte) error {
 	var event StockReducedEvent
-	if err := json.Unmarshal(msg.Body, &event); err != nil {
-		log.Errorf("[StockConsumer] handleStockReductionEvent - 1: %v", err)
-		return err
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Errorf("[StockConsumer] processStockReductionEvent - 1: %v", err)
+		return fmt.Errorf("%w: %v", errInvalidStockEvent, err)
+	}
+
+	if event.MerchantID == 0 || len(event.Products) == 0 {
+		log.Errorf("[StockConsumer] processStockReductionEvent - 2: order %s has no merchant or products", event.OrderID)
+		return fmt.Errorf("%w: missing merchant_id or products", errInvalidStockEvent)
 	}
 
+	items := make([]model.StockReductionItem, 0, len(event.Products))
 	for _, product := range event.Products {
-		if err := sc.reduceStock(event.MerchantID, product.ProductID, product.Quantity); err != nil {
-			log.Errorf("[StockConsumer] handleStockReductionEvent - 2: %v", err)
-			continue
-		}
+		items = append(items, model.StockReductionItem{
+			ProductID: product.ProductID,
+			Quantity:  product.Quantity,
+		})
+	}
 
-		log.Infof("Successfully reduced stock for product %d by %d", product.ProductID, product.Quantity)
+	meta := model.StockMovementMeta{
+		MovementType: model.StockMovementSale,
+		Reference:    event.OrderID,
 	}
 
+	movements, err := sc.merchantRepo.ReduceStocks(context.Background(), event.MerchantID, event.OrderID, items, meta)
+	if err != nil {
+		log.Errorf("[StockConsumer] processStockReductionEvent - 3: order %s: %v", event.OrderID, err)
+		return err
+	}
+
+	if sc.stockAlert != nil {
+		sc.stockAlert.CheckMovements(context.Background(), movements)
+	}
+
+	log.Infof("Successfully reduced stock for order %s (%d products)", event.OrderID, len(items))
+
 	return nil
 }
 
-func (sc *StockConsumer) reduceStock(merchantID uint, productID uint, quantity int) error {
-	err := sc.merchantRepo.ReduceStock(context.Background(), merchantID, productID, int64(quantity))
+// retry menjadwalkan ulang pesan lewat retry queue dengan jeda exponential: base, 2x base, 4x base ... hingga max
+func (sc *StockConsumer) retry(msg amqp.Delivery, cause error) {
+	attempts := retryCount(msg.Headers)
+	if attempts >= sc.maxRetries {
+		sc.deadLetter(msg, fmt.Errorf("giving up after %d retries: %w", attempts, cause))
+		return
+	}
+
+	delay := retryBackoff(sc.retryBase, sc.retryMax, attempts)
+	headers := copyHeaders(msg.Headers)
+	headers[headerRetryCount] = int32(attempts + 1)
+	if _, ok := headers[headerOriginalRoutingKey]; !ok {
+		headers[headerOriginalRoutingKey] = msg.RoutingKey
+	}
+
+	err := sc.ch.Publish("", StockEventsRetryQueue, false, false, amqp.Publishing{
+		ContentType:  msg.ContentType,
+		Body:         msg.Body,
+		Headers:      headers,
+		DeliveryMode: amqp.Persistent,
+		Expiration:   strconv.FormatInt(delay.Milliseconds(), 10),
+		Timestamp:    time.Now(),
+	})
 	if err != nil {
-		log.Errorf("[StockConsumer] reduceStock - 1: %v", err)
-		return err
+		// Gagal menjadwalkan ulang, kembalikan ke queue utama agar pesan tidak hilang
+		log.Errorf("[StockConsumer] retry - 1: %v", err)
+		msg.Nack(false, true)
+		return
 	}
 
-	return nil
+	log.Warnf("[StockConsumer] retry - attempt %d/%d in %s: %v", attempts+1, sc.maxRetries, delay, cause)
+	msg.Ack(false)
+}
+
+func (sc *StockConsumer) deadLetter(msg amqp.Delivery, cause error) {
+	headers := copyHeaders(msg.Headers)
+	headers[headerDeadLetterReason] = cause.Error()
+	headers[headerDeadLetteredAt] = time.Now().Format(time.RFC3339)
+	if _, ok := headers[headerOriginalRoutingKey]; !ok {
+		headers[headerOriginalRoutingKey] = msg.RoutingKey
+	}
+
+	err := sc.ch.Publish("", StockEventsDLQ, false, false, amqp.Publishing{
+		ContentType:  msg.ContentType,
+		Body:         msg.Body,
+		Headers:      headers,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+	})
+	if err != nil {
+		log.Errorf("[StockConsumer] deadLetter - 1: %v", err)
+		msg.Nack(false, true)
+		return
+	}
+
+	log.Errorf("[StockConsumer] deadLetter - moved message to %s: %v", StockEventsDLQ, cause)
+	msg.Ack(false)
+}
+
+// isPermanentStockEventError: error yang tidak akan hilang dengan retry, langsung masuk DLQ
+// dan dapat di-replay manual (mis. setelah stock merchant ditambah)
+func isPermanentStockEventError(err error) bool {
+	return errors.Is(err, errInvalidStockEvent) ||
+		errors.Is(err, repository.ErrStockNotEnough) ||
+		errors.Is(err, gorm.ErrRecordNotFound)
+}
+
+func retryCount(headers amqp.Table) int {
+	switch value := headers[headerRetryCount].(type) {
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case int:
+		return value
+	default:
+		return 0
+	}
+}
+
+func copyHeaders(headers amqp.Table) amqp.Table {
+	copied := amqp.Table{}
+	for key, value := range headers {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+func retryBackoff(base, max time.Duration, attempts int) time.Duration {
+	wait := base
+	for i := 0; i < attempts; i++ {
+		wait *= 2
+		if wait >= max {
+			return max
+		}
+	}
+
+	return wait
+}
+
+func intOrDefault(value, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
 
+	return value
 }
 
 func (sc *StockConsumer) Close() error {
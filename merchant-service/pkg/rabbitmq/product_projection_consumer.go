@@ -0,0 +1,142 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/streadway/amqp"
+)
+
+const ProductProjectionQueue = "merchant_product_projection"
+
+// ProductProjectionConsumer menjaga tabel product_projections tetap sama dengan product-service.
+// Queue-nya terpisah dari cache invalidation supaya kegagalan database tidak menahan penghapusan cache.
+type ProductProjectionConsumer struct {
+	conn                  *amqp.Connection
+	ch                    *amqp.Channel
+	productProjectionRepo repository.ProductProjectionRepositoryInterface
+}
+
+func NewProductProjectionConsumer(url string, productProjectionRepo repository.ProductProjectionRepositoryInterface) (*ProductProjectionConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		log.Errorf("[ProductProjectionConsumer] NewProductProjectionConsumer - 1: %v", err)
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[ProductProjectionConsumer] NewProductProjectionConsumer - 2: %v", err)
+		return nil, err
+	}
+
+	if err := ch.ExchangeDeclare(EntityEventsExchange, "topic", true, false, false, false, nil); err != nil {
+		log.Errorf("[ProductProjectionConsumer] NewProductProjectionConsumer - 3: %v", err)
+		return nil, err
+	}
+
+	q, err := ch.QueueDeclare(ProductProjectionQueue, true, false, false, false, nil)
+	if err != nil {
+		log.Errorf("[ProductProjectionConsumer] NewProductProjectionConsumer - 4: %v", err)
+		return nil, err
+	}
+
+	if err := ch.QueueBind(q.Name, EntityProduct+".*", EntityEventsExchange, false, nil); err != nil {
+		log.Errorf("[ProductProjectionConsumer] NewProductProjectionConsumer - 5: %v", err)
+		return nil, err
+	}
+
+	return &ProductProjectionConsumer{
+		conn:                  conn,
+		ch:                    ch,
+		productProjectionRepo: productProjectionRepo,
+	}, nil
+}
+
+func (pc *ProductProjectionConsumer) Consume(ctx context.Context) error {
+	msgs, err := pc.ch.Consume(ProductProjectionQueue, "", false, false, false, false, nil)
+	if err != nil {
+		log.Errorf("[ProductProjectionConsumer] Consume - 1: %v", err)
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Stopping product projection consumer...")
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				log.Info("Product projection consumer channel closed")
+				return nil
+			}
+			pc.handle(ctx, msg)
+		}
+	}
+}
+
+// handle membuang pesan rusak dan me-requeue kegagalan database sekali; product yang terlewat bisa
+// disinkronkan ulang dengan perintah product-projection sync. Versi snapshot diambil dari updated_at product,
+// karena waktu publish update yang lambat bisa lebih baru dari waktu delete product yang sama.
+func (pc *ProductProjectionConsumer) handle(ctx context.Context, msg amqp.Delivery) {
+	var event EntityChangedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Errorf("[ProductProjectionConsumer] handle - 1: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	if event.Entity != EntityProduct || event.ID == 0 {
+		log.Errorf("[ProductProjectionConsumer] handle - 2: invalid event %+v", event)
+		msg.Nack(false, false)
+		return
+	}
+
+	eventAt := event.Timestamp
+	if eventAt.IsZero() {
+		eventAt = time.Now()
+	}
+
+	var err error
+	switch {
+	case event.Action == EntityActionDeleted:
+		err = pc.productProjectionRepo.DeleteProductProjection(ctx, event.ID, eventAt)
+	case event.Product != nil:
+		syncedAt := event.Product.UpdatedAt
+		if syncedAt.IsZero() {
+			syncedAt = eventAt
+		}
+		err = pc.productProjectionRepo.UpsertProductProjections(ctx, []model.ProductProjection{{
+			ProductID:    event.ID,
+			Name:         event.Product.Name,
+			Barcode:      event.Product.Barcode,
+			CategoryID:   event.Product.CategoryID,
+			CategoryName: event.Product.CategoryName,
+			SyncedAt:     syncedAt,
+		}})
+	default:
+		log.Infof("[ProductProjectionConsumer] handle - product.%s %d has no product data, skipped", event.Action, event.ID)
+	}
+
+	if err != nil {
+		log.Errorf("[ProductProjectionConsumer] handle - 3: %v", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
+	}
+
+	msg.Ack(false)
+}
+
+func (pc *ProductProjectionConsumer) Close() error {
+	if pc.ch != nil {
+		pc.ch.Close()
+	}
+	if pc.conn != nil {
+		return pc.conn.Close()
+	}
+	return nil
+}
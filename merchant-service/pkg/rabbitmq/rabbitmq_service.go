@@ -14,18 +14,61 @@ type RabbitMQService struct {
 	ch   *amqp.Channel
 }
 
+// StockReductionEvent mengurangi stock warehouse sebesar Stock, yaitu selisih alokasi merchant (bukan totalnya).
+// Reference unik per perubahan sehingga warehouse-service tidak menerapkan pesan yang sama dua kali.
 type StockReductionEvent struct {
-	WarhouseID uint      `json:"warhouse_id"`
-	ProductID  uint      `json:"product_id"`
-	Stock      int       `json:"stock"`
-	MerchantID uint      `json:"merchant_id"`
-	Timestamp  time.Time `json:"timestamp"`
+	Reference   string    `json:"reference"`
+	WarehouseID uint      `json:"warehouse_id"`
+	ProductID   uint      `json:"product_id"`
+	Stock       int       `json:"stock"`
+	MerchantID  uint      `json:"merchant_id"`
+	Timestamp   time.Time `json:"timestamp"`
 }
 
+// StockLowEvent dikirim ketika stock merchant product turun melewati min_stock.
+// Recipients sudah di-resolve di merchant-service sehingga notification-service cukup mengirim email.
+type StockLowEvent struct {
+	MerchantID        uint                `json:"merchant_id"`
+	MerchantName      string              `json:"merchant_name"`
+	MerchantProductID uint                `json:"merchant_product_id"`
+	ProductID         uint                `json:"product_id"`
+	ProductName       string              `json:"product_name"`
+	Stock             int                 `json:"stock"`
+	MinStock          int                 `json:"min_stock"`
+	Recipients        []StockLowRecipient `json:"recipients"`
+	Timestamp         time.Time           `json:"timestamp"`
+}
+
+type StockLowRecipient struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+const StockLowRoutingKey = "merchant.stock.low"
+
+// StockReturnedEvent mengembalikan stock ke warehouse asal; satu event per warehouse.
+// Reference sama dengan yang dicatat di ledger merchant sehingga warehouse-service bisa dedupe.
+type StockReturnedEvent struct {
+	Reference   string              `json:"reference"`
+	MerchantID  uint                `json:"merchant_id"`
+	WarehouseID uint                `json:"warehouse_id"`
+	Reason      string              `json:"reason"`
+	Items       []StockReturnedItem `json:"items"`
+	Timestamp   time.Time           `json:"timestamp"`
+}
+
+type StockReturnedItem struct {
+	ProductID uint   `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	Condition string `json:"condition"`
+}
+
+const StockReturnedRoutingKey = "warehouse.stock.returned"
+
 const (
 	ExhangeName = "warehouse_events"
 	QueueName   = "stock_reduction_queue"
-	RoutingKey  = "stock_reduction"
+	RoutingKey  = "stock.reduction"
 )
 
 func NewRabbitMQService(rabbitMQUrl string) (*RabbitMQService, error) {
@@ -56,6 +99,21 @@ func NewRabbitMQService(rabbitMQUrl string) (*RabbitMQService, error) {
 		return nil, err
 	}
 
+	err = ch.ExchangeDeclare(
+		StockEventsExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+
+	if err != nil {
+		log.Errorf("[RabbitMQService] NewRabbitMQService - 4: %v", err)
+		return nil, err
+	}
+
 	q, err := ch.QueueDeclare(
 		QueueName,
 		true,
@@ -66,7 +124,7 @@ func NewRabbitMQService(rabbitMQUrl string) (*RabbitMQService, error) {
 	)
 
 	if err != nil {
-		log.Errorf("[RabbitMQService] NewRabbitMQService - 4: %v", err)
+		log.Errorf("[RabbitMQService] NewRabbitMQService - 5: %v", err)
 		return nil, err
 	}
 
@@ -79,7 +137,7 @@ func NewRabbitMQService(rabbitMQUrl string) (*RabbitMQService, error) {
 	)
 
 	if err != nil {
-		log.Errorf("[RabbitMQService] NewRabbitMQService - 5: %v", err)
+		log.Errorf("[RabbitMQService] NewRabbitMQService - 6: %v", err)
 		return nil, err
 	}
 
@@ -102,8 +160,10 @@ func (r *RabbitMQService) PublishStockReductionEvent(ctx context.Context, event
 		false,
 		false,
 		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
 		},
 	)
 
@@ -115,6 +175,62 @@ func (r *RabbitMQService) PublishStockReductionEvent(ctx context.Context, event
 	return nil
 }
 
+func (r *RabbitMQService) PublishStockLowEvent(ctx context.Context, event StockLowEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("[RabbitMQService] PublishStockLowEvent - 1: %v", err)
+		return err
+	}
+
+	err = r.ch.Publish(
+		StockEventsExchange,
+		StockLowRoutingKey,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+		},
+	)
+
+	if err != nil {
+		log.Errorf("[RabbitMQService] PublishStockLowEvent - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *RabbitMQService) PublishStockReturnedEvent(ctx context.Context, event StockReturnedEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("[RabbitMQService] PublishStockReturnedEvent - 1: %v", err)
+		return err
+	}
+
+	err = r.ch.Publish(
+		ExhangeName,
+		StockReturnedRoutingKey,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+		},
+	)
+
+	if err != nil {
+		log.Errorf("[RabbitMQService] PublishStockReturnedEvent - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 func (r *RabbitMQService) Close() error {
 	if r.ch != nil {
 		r.ch.Close()
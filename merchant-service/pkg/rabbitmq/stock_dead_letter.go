@@ -0,0 +1,168 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/streadway/amqp"
+)
+
+// DeadLetteredStockEvent adalah ringkasan satu pesan di merchant_stock_events.dlq
+type DeadLetteredStockEvent struct {
+	OrderID        string    `json:"order_id"`
+	MerchantID     uint      `json:"merchant_id"`
+	Products       int       `json:"products"`
+	RetryCount     int       `json:"retry_count"`
+	Reason         string    `json:"reason"`
+	DeadLetteredAt string    `json:"dead_lettered_at"`
+	PublishedAt    time.Time `json:"published_at"`
+}
+
+// StockDeadLetterService membaca dan me-replay pesan di dead-letter queue stock event.
+// Pesan dibaca dengan basic.get tanpa ack; pesan yang tidak di-ack kembali ke DLQ saat channel ditutup.
+type StockDeadLetterService struct {
+	conn *amqp.Connection
+}
+
+func NewStockDeadLetterService(url string) (*StockDeadLetterService, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		log.Errorf("[StockDeadLetterService] NewStockDeadLetterService - 1: %v", err)
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Errorf("[StockDeadLetterService] NewStockDeadLetterService - 2: %v", err)
+		return nil, err
+	}
+	defer ch.Close()
+
+	if err := declareStockEventTopology(ch); err != nil {
+		log.Errorf("[StockDeadLetterService] NewStockDeadLetterService - 3: %v", err)
+		return nil, err
+	}
+
+	return &StockDeadLetterService{conn: conn}, nil
+}
+
+// List mengembalikan hingga limit pesan teratas di DLQ tanpa menghapusnya
+func (s *StockDeadLetterService) List(limit int) ([]DeadLetteredStockEvent, error) {
+	ch, err := s.conn.Channel()
+	if err != nil {
+		log.Errorf("[StockDeadLetterService] List - 1: %v", err)
+		return nil, err
+	}
+	defer ch.Close()
+
+	events := []DeadLetteredStockEvent{}
+	for len(events) < limit {
+		msg, ok, err := ch.Get(StockEventsDLQ, false)
+		if err != nil {
+			log.Errorf("[StockDeadLetterService] List - 2: %v", err)
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		events = append(events, toDeadLetteredStockEvent(msg))
+	}
+
+	return events, nil
+}
+
+// Replay mengirim kembali pesan DLQ ke queue utama. orderID kosong berarti replay semua pesan.
+// Pengurangan stock bersifat idempotent per order, sehingga replay order yang sudah diproses aman.
+func (s *StockDeadLetterService) Replay(orderID string) (int, error) {
+	ch, err := s.conn.Channel()
+	if err != nil {
+		log.Errorf("[StockDeadLetterService] Replay - 1: %v", err)
+		return 0, err
+	}
+	defer ch.Close()
+
+	queue, err := ch.QueueInspect(StockEventsDLQ)
+	if err != nil {
+		log.Errorf("[StockDeadLetterService] Replay - 2: %v", err)
+		return 0, err
+	}
+
+	replayed := 0
+	for i := 0; i < queue.Messages; i++ {
+		msg, ok, err := ch.Get(StockEventsDLQ, false)
+		if err != nil {
+			log.Errorf("[StockDeadLetterService] Replay - 3: %v", err)
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+
+		if orderID != "" && toDeadLetteredStockEvent(msg).OrderID != orderID {
+			continue
+		}
+
+		headers := copyHeaders(msg.Headers)
+		delete(headers, headerRetryCount)
+		delete(headers, headerDeadLetterReason)
+		delete(headers, headerDeadLetteredAt)
+
+		err = ch.Publish("", StockEventsQueue, false, false, amqp.Publishing{
+			ContentType:  msg.ContentType,
+			Body:         msg.Body,
+			Headers:      headers,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+		})
+		if err != nil {
+			log.Errorf("[StockDeadLetterService] Replay - 4: %v", err)
+			return replayed, err
+		}
+
+		if err := msg.Ack(false); err != nil {
+			log.Errorf("[StockDeadLetterService] Replay - 5: %v", err)
+			return replayed, err
+		}
+
+		replayed++
+	}
+
+	if orderID != "" && replayed == 0 {
+		return 0, fmt.Errorf("order %s not found in %s", orderID, StockEventsDLQ)
+	}
+
+	return replayed, nil
+}
+
+func (s *StockDeadLetterService) Close() error {
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+func toDeadLetteredStockEvent(msg amqp.Delivery) DeadLetteredStockEvent {
+	deadLettered := DeadLetteredStockEvent{
+		RetryCount:  retryCount(msg.Headers),
+		PublishedAt: msg.Timestamp,
+	}
+
+	if reason, ok := msg.Headers[headerDeadLetterReason].(string); ok {
+		deadLettered.Reason = reason
+	}
+	if deadLetteredAt, ok := msg.Headers[headerDeadLetteredAt].(string); ok {
+		deadLettered.DeadLetteredAt = deadLetteredAt
+	}
+
+	var event StockReducedEvent
+	if err := json.Unmarshal(msg.Body, &event); err == nil {
+		deadLettered.OrderID = event.OrderID
+		deadLettered.MerchantID = event.MerchantID
+		deadLettered.Products = len(event.Products)
+	}
+
+	return deadLettered
+}
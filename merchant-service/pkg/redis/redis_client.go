@@ -67,14 +67,19 @@ func (rc *RedisClient) Set(ctx context.Context, key string, value interface{}, e
 	return nil
 }
 
-func (rc *RedisClient) Delete(ctx context.Context, key string) error {
-	err := rc.client.Del(ctx, key).Err()
+// Delete menghapus beberapa key sekaligus dan mengembalikan jumlah key yang benar-benar ada
+func (rc *RedisClient) Delete(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	deleted, err := rc.client.Del(ctx, keys...).Result()
 	if err != nil {
 		log.Errorf("[RedisClient] Delete - 1: %v", err)
-		return err
+		return 0, err
 	}
 
-	return nil
+	return deleted, nil
 }
 
 func (rc *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
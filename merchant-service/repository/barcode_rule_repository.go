@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"micro-warehouse/merchant-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// CRUD aturan parsing barcode timbangan
+type BarcodeRuleRepositoryInterface interface {
+	GetBarcodeRules(ctx context.Context, activeOnly bool) ([]model.BarcodeRule, error)
+	GetBarcodeRuleByID(ctx context.Context, id uint) (*model.BarcodeRule, error)
+	CreateBarcodeRule(ctx context.Context, rule *model.BarcodeRule) error
+	UpdateBarcodeRule(ctx context.Context, rule *model.BarcodeRule) error
+	DeleteBarcodeRule(ctx context.Context, id uint) error
+}
+
+var ErrBarcodePrefixTaken = errors.New("barcode prefix is already used by another rule")
+
+type barcodeRuleRepository struct {
+	db *gorm.DB
+}
+
+// GetBarcodeRules implements BarcodeRuleRepositoryInterface.
+// Prefix terpanjang didahulukan supaya rule yang lebih spesifik menang.
+func (b *barcodeRuleRepository) GetBarcodeRules(ctx context.Context, activeOnly bool) ([]model.BarcodeRule, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[BarcodeRuleRepository] GetBarcodeRules - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		rules := []model.BarcodeRule{}
+
+		query := b.db.WithContext(ctx)
+		if activeOnly {
+			query = query.Where("is_active = ?", true)
+		}
+
+		if err := query.Order("LENGTH(prefix) DESC, prefix ASC").Find(&rules).Error; err != nil {
+			log.Errorf("[BarcodeRuleRepository] GetBarcodeRules - 2: %v", err)
+			return nil, err
+		}
+
+		return rules, nil
+	}
+}
+
+// GetBarcodeRuleByID implements BarcodeRuleRepositoryInterface.
+func (b *barcodeRuleRepository) GetBarcodeRuleByID(ctx context.Context, id uint) (*model.BarcodeRule, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[BarcodeRuleRepository] GetBarcodeRuleByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var rule model.BarcodeRule
+		if err := b.db.WithContext(ctx).Where("id = ?", id).First(&rule).Error; err != nil {
+			log.Errorf("[BarcodeRuleRepository] GetBarcodeRuleByID - 2: %v", err)
+			return nil, err
+		}
+
+		return &rule, nil
+	}
+}
+
+// CreateBarcodeRule implements BarcodeRuleRepositoryInterface.
+func (b *barcodeRuleRepository) CreateBarcodeRule(ctx context.Context, rule *model.BarcodeRule) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[BarcodeRuleRepository] CreateBarcodeRule - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := b.checkPrefix(ctx, rule); err != nil {
+			return err
+		}
+
+		if err := b.db.WithContext(ctx).Create(rule).Error; err != nil {
+			log.Errorf("[BarcodeRuleRepository] CreateBarcodeRule - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// UpdateBarcodeRule implements BarcodeRuleRepositoryInterface.
+func (b *barcodeRuleRepository) UpdateBarcodeRule(ctx context.Context, rule *model.BarcodeRule) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[BarcodeRuleRepository] UpdateBarcodeRule - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := b.checkPrefix(ctx, rule); err != nil {
+			return err
+		}
+
+		result := b.db.WithContext(ctx).Model(&model.BarcodeRule{}).
+			Where("id = ?", rule.ID).
+			Updates(map[string]interface{}{
+				"name":                  rule.Name,
+				"prefix":                rule.Prefix,
+				"item_code_length":      rule.ItemCodeLength,
+				"measure":               rule.Measure,
+				"value_length":          rule.ValueLength,
+				"decimals":              rule.Decimals,
+				"has_value_check_digit": rule.HasValueCheckDigit,
+				"is_active":             rule.IsActive,
+			})
+		if result.Error != nil {
+			log.Errorf("[BarcodeRuleRepository] UpdateBarcodeRule - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	}
+}
+
+// DeleteBarcodeRule implements BarcodeRuleRepositoryInterface.
+func (b *barcodeRuleRepository) DeleteBarcodeRule(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[BarcodeRuleRepository] DeleteBarcodeRule - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := b.db.WithContext(ctx).Where("id = ?", id).Delete(&model.BarcodeRule{})
+		if result.Error != nil {
+			log.Errorf("[BarcodeRuleRepository] DeleteBarcodeRule - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	}
+}
+
+// checkPrefix memastikan satu prefix hanya dimiliki satu rule
+func (b *barcodeRuleRepository) checkPrefix(ctx context.Context, rule *model.BarcodeRule) error {
+	var count int64
+	if err := b.db.WithContext(ctx).Model(&model.BarcodeRule{}).
+		Where("prefix = ? AND id <> ?", rule.Prefix, rule.ID).
+		Count(&count).Error; err != nil {
+		log.Errorf("[BarcodeRuleRepository] checkPrefix - 1: %v", err)
+		return err
+	}
+
+	if count > 0 {
+		return ErrBarcodePrefixTaken
+	}
+
+	return nil
+}
+
+func NewBarcodeRuleRepository(db *gorm.DB) BarcodeRuleRepositoryInterface {
+	return &barcodeRuleRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/geo"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// list/create/update/delete zona pengiriman merchant, cari zona yang bounding box-nya memuat sebuah titik
+type MerchantDeliveryZoneRepositoryInterface interface {
+	GetDeliveryZones(ctx context.Context, merchantID uint) ([]model.MerchantDeliveryZone, error)
+	CreateDeliveryZone(ctx context.Context, zone *model.MerchantDeliveryZone) error
+	UpdateDeliveryZone(ctx context.Context, zone *model.MerchantDeliveryZone) error
+	DeleteDeliveryZone(ctx context.Context, merchantID, id uint) error
+	GetDeliveryZoneCandidates(ctx context.Context, point geo.Point) ([]model.MerchantDeliveryZone, error)
+}
+
+type merchantDeliveryZoneRepository struct {
+	db *gorm.DB
+}
+
+// GetDeliveryZones implements MerchantDeliveryZoneRepositoryInterface.
+func (m *merchantDeliveryZoneRepository) GetDeliveryZones(ctx context.Context, merchantID uint) ([]model.MerchantDeliveryZone, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantDeliveryZoneRepository] GetDeliveryZones - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		if err := m.db.WithContext(ctx).Where("id = ?", merchantID).First(&model.Merchant{}).Error; err != nil {
+			log.Errorf("[MerchantDeliveryZoneRepository] GetDeliveryZones - 2: %v", err)
+			return nil, err
+		}
+
+		zones := []model.MerchantDeliveryZone{}
+		if err := m.db.WithContext(ctx).Where("merchant_id = ?", merchantID).Order("id ASC").Find(&zones).Error; err != nil {
+			log.Errorf("[MerchantDeliveryZoneRepository] GetDeliveryZones - 3: %v", err)
+			return nil, err
+		}
+
+		return zones, nil
+	}
+}
+
+// CreateDeliveryZone implements MerchantDeliveryZoneRepositoryInterface.
+func (m *merchantDeliveryZoneRepository) CreateDeliveryZone(ctx context.Context, zone *model.MerchantDeliveryZone) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantDeliveryZoneRepository] CreateDeliveryZone - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := m.db.WithContext(ctx).Where("id = ?", zone.MerchantID).First(&model.Merchant{}).Error; err != nil {
+			log.Errorf("[MerchantDeliveryZoneRepository] CreateDeliveryZone - 2: %v", err)
+			return err
+		}
+
+		if err := m.db.WithContext(ctx).Omit("Merchant").Create(zone).Error; err != nil {
+			log.Errorf("[MerchantDeliveryZoneRepository] CreateDeliveryZone - 3: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// UpdateDeliveryZone implements MerchantDeliveryZoneRepositoryInterface.
+// Nama, polygon beserta bounding box-nya, dan is_active selalu diganti utuh.
+func (m *merchantDeliveryZoneRepository) UpdateDeliveryZone(ctx context.Context, zone *model.MerchantDeliveryZone) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantDeliveryZoneRepository] UpdateDeliveryZone - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := m.db.WithContext(ctx).Model(zone).
+			Where("merchant_id = ?", zone.MerchantID).
+			Select("name", "polygon", "min_latitude", "max_latitude", "min_longitude", "max_longitude", "is_active", "updated_at").
+			Updates(zone)
+		if result.Error != nil {
+			log.Errorf("[MerchantDeliveryZoneRepository] UpdateDeliveryZone - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := m.db.WithContext(ctx).Where("id = ?", zone.ID).First(zone).Error; err != nil {
+			log.Errorf("[MerchantDeliveryZoneRepository] UpdateDeliveryZone - 3: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// DeleteDeliveryZone implements MerchantDeliveryZoneRepositoryInterface.
+func (m *merchantDeliveryZoneRepository) DeleteDeliveryZone(ctx context.Context, merchantID uint, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantDeliveryZoneRepository] DeleteDeliveryZone - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := m.db.WithContext(ctx).
+			Where("id = ? AND merchant_id = ?", id, merchantID).
+			Delete(&model.MerchantDeliveryZone{})
+		if result.Error != nil {
+			log.Errorf("[MerchantDeliveryZoneRepository] DeleteDeliveryZone - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	}
+}
+
+// GetDeliveryZoneCandidates implements MerchantDeliveryZoneRepositoryInterface.
+// Zona aktif milik merchant berstatus active yang tidak diarsip dan bounding box-nya memuat point.
+// Pengecekan polygon yang sebenarnya dilakukan di usecase.
+func (m *merchantDeliveryZoneRepository) GetDeliveryZoneCandidates(ctx context.Context, point geo.Point) ([]model.MerchantDeliveryZone, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantDeliveryZoneRepository] GetDeliveryZoneCandidates - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		zones := []model.MerchantDeliveryZone{}
+
+		if err := m.db.WithContext(ctx).
+			Joins("JOIN merchants ON merchants.id = merchant_delivery_zones.merchant_id").
+			Where("merchant_delivery_zones.is_active = ?", true).
+			Where("merchants.deleted_at IS NULL AND merchants.archived_at IS NULL AND merchants.status = ?", model.MerchantStatusActive).
+			Where("merchant_delivery_zones.min_latitude <= ? AND merchant_delivery_zones.max_latitude >= ?", point.Latitude, point.Latitude).
+			Where("merchant_delivery_zones.min_longitude <= ? AND merchant_delivery_zones.max_longitude >= ?", point.Longitude, point.Longitude).
+			Preload("Merchant.OpeningHours", orderOpeningHours).
+			Preload("Merchant.Closures", upcomingClosures).
+			Order("merchant_delivery_zones.id ASC").
+			Find(&zones).Error; err != nil {
+			log.Errorf("[MerchantDeliveryZoneRepository] GetDeliveryZoneCandidates - 2: %v", err)
+			return nil, err
+		}
+
+		return zones, nil
+	}
+}
+
+func NewMerchantDeliveryZoneRepository(db *gorm.DB) MerchantDeliveryZoneRepositoryInterface {
+	return &merchantDeliveryZoneRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"micro-warehouse/merchant-service/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// create, list history, delete scheduled, current price per merchant product
+type MerchantProductPriceRepositoryInterface interface {
+	CreatePrice(ctx context.Context, price *model.MerchantProductPrice) error
+	GetPrices(ctx context.Context, merchantProductID uint) ([]model.MerchantProductPrice, error)
+	DeleteScheduledPrice(ctx context.Context, merchantProductID, priceID uint) error
+	GetCurrentPrices(ctx context.Context, merchantProductIDs []uint, at time.Time) (map[uint]model.MerchantProductPrice, error)
+}
+
+var ErrPriceAlreadyEffective = errors.New("price is already effective and cannot be deleted")
+
+type merchantProductPriceRepository struct {
+	db *gorm.DB
+}
+
+// CreatePrice implements MerchantProductPriceRepositoryInterface.
+func (m *merchantProductPriceRepository) CreatePrice(ctx context.Context, price *model.MerchantProductPrice) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductPriceRepository] CreatePrice - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := m.db.WithContext(ctx).Create(price).Error; err != nil {
+			log.Errorf("[MerchantProductPriceRepository] CreatePrice - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetPrices implements MerchantProductPriceRepositoryInterface.
+// Diurutkan dari effective_from terbaru, termasuk harga yang masih terjadwal.
+func (m *merchantProductPriceRepository) GetPrices(ctx context.Context, merchantProductID uint) ([]model.MerchantProductPrice, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductPriceRepository] GetPrices - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		prices := []model.MerchantProductPrice{}
+		if err := m.db.WithContext(ctx).
+			Where("merchant_product_id = ?", merchantProductID).
+			Order("effective_from DESC, id DESC").
+			Find(&prices).Error; err != nil {
+			log.Errorf("[MerchantProductPriceRepository] GetPrices - 2: %v", err)
+			return nil, err
+		}
+
+		return prices, nil
+	}
+}
+
+// DeleteScheduledPrice implements MerchantProductPriceRepositoryInterface.
+// Harga yang sudah berlaku tidak dihapus agar harga transaksi lampau tetap bisa ditelusuri.
+func (m *merchantProductPriceRepository) DeleteScheduledPrice(ctx context.Context, merchantProductID uint, priceID uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductPriceRepository] DeleteScheduledPrice - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		var price model.MerchantProductPrice
+		if err := m.db.WithContext(ctx).
+			Where("id = ? AND merchant_product_id = ?", priceID, merchantProductID).
+			First(&price).Error; err != nil {
+			log.Errorf("[MerchantProductPriceRepository] DeleteScheduledPrice - 2: %v", err)
+			return err
+		}
+
+		result := m.db.WithContext(ctx).
+			Where("id = ? AND effective_from > ?", price.ID, time.Now()).
+			Delete(&model.MerchantProductPrice{})
+		if result.Error != nil {
+			log.Errorf("[MerchantProductPriceRepository] DeleteScheduledPrice - 3: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrPriceAlreadyEffective
+		}
+
+		return nil
+	}
+}
+
+// GetCurrentPrices implements MerchantProductPriceRepositoryInterface.
+// Mengambil baris dengan effective_from terbaru yang sudah lewat untuk setiap merchant product sekaligus.
+func (m *merchantProductPriceRepository) GetCurrentPrices(ctx context.Context, merchantProductIDs []uint, at time.Time) (map[uint]model.MerchantProductPrice, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductPriceRepository] GetCurrentPrices - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		result := make(map[uint]model.MerchantProductPrice, len(merchantProductIDs))
+		if len(merchantProductIDs) == 0 {
+			return result, nil
+		}
+
+		var prices []model.MerchantProductPrice
+		if err := m.db.WithContext(ctx).
+			Raw(`SELECT DISTINCT ON (merchant_product_id) * FROM merchant_product_prices
+				WHERE merchant_product_id IN ? AND effective_from <= ?
+				ORDER BY merchant_product_id, effective_from DESC, id DESC`, merchantProductIDs, at).
+			Scan(&prices).Error; err != nil {
+			log.Errorf("[MerchantProductPriceRepository] GetCurrentPrices - 2: %v", err)
+			return nil, err
+		}
+
+		for _, price := range prices {
+			result[price.MerchantProductID] = price
+		}
+
+		return result, nil
+	}
+}
+
+func NewMerchantProductPriceRepository(db *gorm.DB) MerchantProductPriceRepositoryInterface {
+	return &merchantProductPriceRepository{
+		db: db,
+	}
+}
@@ -3,38 +3,131 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"micro-warehouse/merchant-service/model"
+	"sort"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// MerchantProductFilter adalah kriteria daftar merchant product; nilai nol berarti tidak difilter
+type MerchantProductFilter struct {
+	Page        int
+	Limit       int
+	Search      string
+	SortBy      string
+	SortOrder   string
+	MerchantID  uint
+	ProductID   uint
+	WarehouseID uint
+	CategoryID  uint
+	StockMin    *int
+	StockMax    *int
+}
+
 // CRUD, get merchant by productID and merchant, delete all product merchant products, get product total stock, reduce stock
 type MerchantProductRepositoryInterface interface {
-	CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error
+	CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, meta model.StockMovementMeta) (*model.MerchantAllocationChange, error)
 	GetMerchantProductByID(ctx context.Context, id uint) (*model.MerchantProduct, error)
-	GetMerchantProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID, productID uint) ([]model.MerchantProduct, int64, error)
+	GetMerchantProducts(ctx context.Context, filter MerchantProductFilter) ([]model.MerchantProduct, int64, error)
 	GetMerchantProductByProductIDAndMerchantID(ctx context.Context, productID uint, merchantID uint) (*model.MerchantProduct, error)
-	UpdateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error
+	GetMerchantProductsByProductIDs(ctx context.Context, merchantID uint, productIDs []uint) ([]model.MerchantProduct, error)
+	UpdateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, meta model.StockMovementMeta) (*model.MerchantProduct, *model.MerchantAllocationChange, error)
 	DeleteMerchantProduct(ctx context.Context, id uint) error
 	DeleteAllProductMerchantProducts(ctx context.Context, productID uint) error
 
 	GetProductTotalStock(ctx context.Context, productID uint) (int, error)
-	ReduceStock(ctx context.Context, merchantID uint, productID uint, quantity int64) error
+	GetMerchantStocksByProductID(ctx context.Context, productID uint) ([]model.MerchantProduct, error)
+	GetMerchantStockSummary(ctx context.Context, merchantID uint) (*model.StockSummary, error)
+	GetWarehouseAllocationSummary(ctx context.Context, warehouseID uint) (*model.StockSummary, error)
+	ReduceStocks(ctx context.Context, merchantID uint, orderID string, items []model.StockReductionItem, meta model.StockMovementMeta) ([]model.MerchantStockMovement, error)
+
+	// Low stock
+	UpdateMinStock(ctx context.Context, id uint, minStock int) (*model.MerchantProduct, error)
+	GetLowStockMerchantProducts(ctx context.Context, merchantID uint, page, limit int) ([]model.MerchantProduct, int64, error)
+
+	// Replenishment
+	UpdateStockLevels(ctx context.Context, id uint, minStock, maxStock int) (*model.MerchantProduct, error)
+	GetReplenishableMerchantProducts(ctx context.Context, merchantID uint) ([]model.MerchantProduct, error)
+
+	// Trash
+	GetDeletedMerchantProducts(ctx context.Context, merchantID, productID uint, page, limit int) ([]model.MerchantProduct, int64, error)
+	RestoreMerchantProduct(ctx context.Context, id uint) (*model.MerchantProduct, error)
+	PurgeDeletedMerchantProducts(ctx context.Context, deletedBefore time.Time) (int64, error)
+
+	// Allocation outbox
+	GetAllocationChanges(ctx context.Context, merchantID uint, unpublishedOnly, failedOnly bool, page, limit int) ([]model.MerchantAllocationChange, int64, error)
+	GetAllocationChangeByID(ctx context.Context, id uint) (*model.MerchantAllocationChange, error)
+	MarkAllocationChangePublished(ctx context.Context, id uint) error
+	MarkAllocationChangeFailed(ctx context.Context, id uint, reason string) error
+
+	// Stock ledger
+	AllocateStock(ctx context.Context, merchantProductID, warehouseID uint, quantity int, meta model.StockMovementMeta) (*model.MerchantAllocationChange, error)
+	AdjustStock(ctx context.Context, merchantProductID uint, delta int, meta model.StockMovementMeta) (*model.MerchantStockMovement, error)
+	GetStockMovements(ctx context.Context, merchantProductID uint, startDate, endDate *time.Time, movementType string, page, limit int) ([]model.MerchantStockMovement, int64, error)
 }
 
+var (
+	ErrStockNotEnough             = errors.New("stock not enough")
+	ErrStockEventAlreadyProcessed = errors.New("stock event already processed")
+	ErrMerchantProductExists      = errors.New("product is already registered at this merchant")
+	ErrMerchantInTrash            = errors.New("merchant is deleted; restore the merchant first")
+	ErrSupplierChangeReducesStock = errors.New("stock cannot be reduced while changing the supplier warehouse; return it to the current warehouse first")
+)
+
 type merchantProductRepository struct {
 	db *gorm.DB
 }
 
 // CreateMerchantProduct implements MerchantProductRepositoryInterface.
-func (m *merchantProductRepository) CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error {
+// Alokasi awal dicatat di outbox dalam transaksi yang sama; nil jika stock awal 0.
+func (m *merchantProductRepository) CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, meta model.StockMovementMeta) (*model.MerchantAllocationChange, error) {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[MerchantProductRepository] CreateMerchantProduct - 1: %v", ctx.Err())
-		return ctx.Err()
+		return nil, ctx.Err()
 	default:
-		return m.db.WithContext(ctx).Create(merchantProduct).Error
+		tx := m.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[MerchantProductRepository] CreateMerchantProduct - 2: %v", tx.Error)
+			return nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] CreateMerchantProduct - 3: %v", r)
+			}
+		}()
+
+		if err := tx.Create(merchantProduct).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] CreateMerchantProduct - 4: %v", err)
+			return nil, err
+		}
+
+		if _, err := recordStockMovement(tx, merchantProduct, merchantProduct.Stock, meta); err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] CreateMerchantProduct - 5: %v", err)
+			return nil, err
+		}
+
+		change, err := recordAllocationChange(tx, merchantProduct, merchantProduct.WarehouseID, merchantProduct.Stock)
+		if err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] CreateMerchantProduct - 6: %v", err)
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[MerchantProductRepository] CreateMerchantProduct - 7: %v", err)
+			return nil, err
+		}
+
+		return change, nil
 	}
 }
 
@@ -100,22 +193,54 @@ func (m *merchantProductRepository) GetMerchantProductByProductIDAndMerchantID(c
 	}
 }
 
+// GetMerchantProductsByProductIDs implements MerchantProductRepositoryInterface.
+func (m *merchantProductRepository) GetMerchantProductsByProductIDs(ctx context.Context, merchantID uint, productIDs []uint) ([]model.MerchantProduct, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetMerchantProductsByProductIDs - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		merchantProducts := []model.MerchantProduct{}
+		if len(productIDs) == 0 {
+			return merchantProducts, nil
+		}
+
+		if err := m.db.WithContext(ctx).
+			Where("merchant_id = ? AND product_id IN ?", merchantID, productIDs).
+			Find(&merchantProducts).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetMerchantProductsByProductIDs - 2: %v", err)
+			return nil, err
+		}
+
+		return merchantProducts, nil
+	}
+}
+
 // GetMerchantProducts implements MerchantProductRepositoryInterface.
-func (m *merchantProductRepository) GetMerchantProducts(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint, productID uint) ([]model.MerchantProduct, int64, error) {
+// Search dicocokkan ke nama, barcode dan category di product_projections; product yang belum tersinkron
+// tetap muncul jika tidak ada search maupun filter category.
+func (m *merchantProductRepository) GetMerchantProducts(ctx context.Context, filter MerchantProductFilter) ([]model.MerchantProduct, int64, error) {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[MerchantProductRepository] GetMerchantProducts - 1: %v", ctx.Err())
 		return nil, 0, ctx.Err()
 	default:
+		page, limit := filter.Page, filter.Limit
 		if page <= 0 {
 			page = 1
 		}
 		if limit <= 0 {
 			limit = 10
 		}
-		if sortBy == "" {
-			sortBy = "created_at"
+		sortBy := "merchant_products.created_at"
+		switch filter.SortBy {
+		case "product_name":
+			sortBy = "product_projections.name"
+		case "":
+		default:
+			sortBy = "merchant_products." + filter.SortBy
 		}
+		sortOrder := filter.SortOrder
 		if sortOrder == "" {
 			sortOrder = "desc"
 		}
@@ -123,19 +248,37 @@ func (m *merchantProductRepository) GetMerchantProducts(ctx context.Context, pag
 		var totalRecords int64
 		modelMerchantProducts := []model.MerchantProduct{}
 
-		query := m.db.WithContext(ctx).Model(&model.MerchantProduct{})
+		query := m.db.WithContext(ctx).Model(&model.MerchantProduct{}).
+			Joins("LEFT JOIN product_projections ON product_projections.product_id = merchant_products.product_id AND product_projections.deleted_at IS NULL")
 
-		if search != "" {
-			searchTerm := "%" + search + "%"
-			query = query.Where("stock::text ILIKE ?", searchTerm)
+		if filter.Search != "" {
+			searchTerm := "%" + filter.Search + "%"
+			query = query.Where("product_projections.name ILIKE ? OR product_projections.barcode ILIKE ? OR product_projections.category_name ILIKE ?",
+				searchTerm, searchTerm, searchTerm)
 		}
 
-		if merchantID != 0 {
-			query = query.Where("merchant_id = ?", merchantID)
+		if filter.MerchantID != 0 {
+			query = query.Where("merchant_products.merchant_id = ?", filter.MerchantID)
 		}
 
-		if productID != 0 {
-			query = query.Where("product_id = ?", productID)
+		if filter.ProductID != 0 {
+			query = query.Where("merchant_products.product_id = ?", filter.ProductID)
+		}
+
+		if filter.WarehouseID != 0 {
+			query = query.Where("merchant_products.warehouse_id = ?", filter.WarehouseID)
+		}
+
+		if filter.CategoryID != 0 {
+			query = query.Where("product_projections.category_id = ?", filter.CategoryID)
+		}
+
+		if filter.StockMin != nil {
+			query = query.Where("merchant_products.stock >= ?", *filter.StockMin)
+		}
+
+		if filter.StockMax != nil {
+			query = query.Where("merchant_products.stock <= ?", *filter.StockMax)
 		}
 
 		if err := query.Count(&totalRecords).Error; err != nil {
@@ -145,6 +288,7 @@ func (m *merchantProductRepository) GetMerchantProducts(ctx context.Context, pag
 		offset := (page - 1) * limit
 		if err := query.Order(sortBy + " " + sortOrder).
 			WithContext(ctx).
+			Select("merchant_products.*").
 			Preload("Merchant").
 			Offset(offset).
 			Limit(limit).
@@ -176,42 +320,323 @@ func (m *merchantProductRepository) GetProductTotalStock(ctx context.Context, pr
 	}
 }
 
-// ReduceStock implements MerchantProductRepositoryInterface.
-func (m *merchantProductRepository) ReduceStock(ctx context.Context, merchantID uint, productID uint, quantity int64) error {
+// GetMerchantStocksByProductID implements MerchantProductRepositoryInterface.
+// Hanya merchant yang masih punya stock dan tidak diarsipkan, beserta jadwalnya supaya status buka bisa dihitung.
+func (m *merchantProductRepository) GetMerchantStocksByProductID(ctx context.Context, productID uint) ([]model.MerchantProduct, error) {
 	select {
 	case <-ctx.Done():
-		log.Errorf("[MerchantProductRepository] ReduceStock - 1: %v", ctx.Err())
-		return ctx.Err()
+		log.Errorf("[MerchantProductRepository] GetMerchantStocksByProductID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		merchantProducts := []model.MerchantProduct{}
+		if err := m.db.WithContext(ctx).
+			Preload("Merchant").
+			Preload("Merchant.OpeningHours", orderOpeningHours).
+			Preload("Merchant.Closures", upcomingClosures).
+			Where("product_id = ? AND stock > 0", productID).
+			Where("merchant_id IN (?)", m.db.Model(&model.Merchant{}).Select("id").Where("archived_at IS NULL")).
+			Order("stock DESC, id ASC").
+			Find(&merchantProducts).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetMerchantStocksByProductID - 2: %v", err)
+			return nil, err
+		}
+
+		return merchantProducts, nil
+	}
+}
+
+// ReduceStocks implements MerchantProductRepositoryInterface.
+// Seluruh item dari satu order dikurangi dalam satu transaksi: jika satu item gagal, tidak ada stock yang berubah.
+// orderID dicatat di transaksi yang sama sehingga order yang sama hanya diproses sekali.
+func (m *merchantProductRepository) ReduceStocks(ctx context.Context, merchantID uint, orderID string, items []model.StockReductionItem, meta model.StockMovementMeta) ([]model.MerchantStockMovement, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] ReduceStocks - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		items = mergeStockReductionItems(items)
+
+		tx := m.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[MerchantProductRepository] ReduceStocks - 2: %v", tx.Error)
+			return nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] ReduceStocks - 3: %v", r)
+			}
+		}()
+
+		if orderID != "" {
+			// Unique index pada order_id: delivery duplikat yang berjalan paralel akan menunggu
+			// transaksi pertama selesai lalu tidak menyisipkan baris apa pun
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&model.ProcessedStockEvent{OrderID: orderID, MerchantID: merchantID})
+			if result.Error != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] ReduceStocks - 4: %v", result.Error)
+				return nil, result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				tx.Rollback()
+				return nil, ErrStockEventAlreadyProcessed
+			}
+		}
+
+		movements := make([]model.MerchantStockMovement, 0, len(items))
+		for _, item := range items {
+			// Update bersyarat: pengecekan stock dan pengurangan terjadi atomik di database,
+			// sehingga consumer yang berjalan paralel tidak bisa saling menimpa atau oversell
+			var merchantProduct model.MerchantProduct
+			result := tx.Model(&merchantProduct).
+				Clauses(clause.Returning{}).
+				Where("merchant_id = ? AND product_id = ? AND stock >= ?", merchantID, item.ProductID, item.Quantity).
+				Update("stock", gorm.Expr("stock - ?", item.Quantity))
+			if result.Error != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] ReduceStocks - 5: %v", result.Error)
+				return nil, result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				tx.Rollback()
+				err := stockShortageError(m.db.WithContext(ctx), merchantID, item.ProductID)
+				log.Errorf("[MerchantProductRepository] ReduceStocks - 6: product %d: %v", item.ProductID, err)
+				return nil, fmt.Errorf("product %d: %w", item.ProductID, err)
+			}
+
+			movement, err := recordStockMovement(tx, &merchantProduct, -item.Quantity, meta)
+			if err != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] ReduceStocks - 7: %v", err)
+				return nil, err
+			}
+			movements = append(movements, *movement)
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[MerchantProductRepository] ReduceStocks - 8: %v", err)
+			return nil, err
+		}
+
+		return movements, nil
+	}
+}
+
+// UpdateMinStock implements MerchantProductRepositoryInterface.
+func (m *merchantProductRepository) UpdateMinStock(ctx context.Context, id uint, minStock int) (*model.MerchantProduct, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] UpdateMinStock - 1: %v", ctx.Err())
+		return nil, ctx.Err()
 	default:
 		var merchantProduct model.MerchantProduct
-		err := m.db.WithContext(ctx).Where("merchant_id = ? AND product_id = ?", merchantID, productID).First(&merchantProduct).Error
-		if err != nil {
-			log.Errorf("[MerchantProductRepository] ReduceStock - 2: %v", err)
-			return err
+		result := m.db.WithContext(ctx).Model(&merchantProduct).
+			Clauses(clause.Returning{}).
+			Where("id = ?", id).
+			Update("min_stock", minStock)
+		if result.Error != nil {
+			log.Errorf("[MerchantProductRepository] UpdateMinStock - 2: %v", result.Error)
+			return nil, result.Error
 		}
 
-		if merchantProduct.Stock < int(quantity) {
-			log.Errorf("[MerchantProductRepository] ReduceStock - 3: %v", errors.New("stock not enough"))
-			return errors.New("stock not enough")
+		if result.RowsAffected == 0 {
+			return nil, gorm.ErrRecordNotFound
 		}
 
-		newStock := merchantProduct.Stock - int(quantity)
+		return &merchantProduct, nil
+	}
+}
+
+// GetLowStockMerchantProducts implements MerchantProductRepositoryInterface.
+// Diurutkan dari kekurangan terbesar (min_stock - stock) agar yang paling mendesak tampil di atas.
+func (m *merchantProductRepository) GetLowStockMerchantProducts(ctx context.Context, merchantID uint, page int, limit int) ([]model.MerchantProduct, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetLowStockMerchantProducts - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		var totalRecords int64
+		merchantProducts := []model.MerchantProduct{}
+
+		query := m.db.WithContext(ctx).Model(&model.MerchantProduct{}).
+			Where("min_stock > 0 AND stock < min_stock")
+
+		if merchantID != 0 {
+			query = query.Where("merchant_id = ?", merchantID)
+		}
 
-		return m.db.WithContext(ctx).Model(&merchantProduct).Update("stock", newStock).Error
+		if err := query.Count(&totalRecords).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetLowStockMerchantProducts - 2: %v", err)
+			return nil, 0, err
+		}
+
+		offset := (page - 1) * limit
+		if err := query.Order("(min_stock - stock) DESC, id ASC").
+			Preload("Merchant").
+			Offset(offset).
+			Limit(limit).
+			Find(&merchantProducts).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetLowStockMerchantProducts - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return merchantProducts, totalRecords, nil
+	}
+}
+
+// UpdateStockLevels implements MerchantProductRepositoryInterface.
+func (m *merchantProductRepository) UpdateStockLevels(ctx context.Context, id uint, minStock int, maxStock int) (*model.MerchantProduct, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] UpdateStockLevels - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var merchantProduct model.MerchantProduct
+		result := m.db.WithContext(ctx).Model(&merchantProduct).
+			Clauses(clause.Returning{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"min_stock": minStock,
+				"max_stock": maxStock,
+			})
+		if result.Error != nil {
+			log.Errorf("[MerchantProductRepository] UpdateStockLevels - 2: %v", result.Error)
+			return nil, result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+
+		return &merchantProduct, nil
+	}
+}
+
+// GetReplenishableMerchantProducts implements MerchantProductRepositoryInterface.
+// Hanya produk dengan min/max level lengkap yang stock-nya sudah di bawah min_stock; merchantID 0 berarti semua merchant.
+func (m *merchantProductRepository) GetReplenishableMerchantProducts(ctx context.Context, merchantID uint) ([]model.MerchantProduct, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetReplenishableMerchantProducts - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		merchantProducts := []model.MerchantProduct{}
+
+		query := m.db.WithContext(ctx).
+			Where("min_stock > 0 AND max_stock > min_stock AND stock < min_stock")
+
+		if merchantID != 0 {
+			query = query.Where("merchant_id = ?", merchantID)
+		}
+
+		if err := query.Order("merchant_id ASC, id ASC").Find(&merchantProducts).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetReplenishableMerchantProducts - 2: %v", err)
+			return nil, err
+		}
+
+		return merchantProducts, nil
+	}
+}
+
+// GetMerchantStockSummary implements MerchantProductRepositoryInterface.
+// Hanya product yang stock-nya masih ada yang dihitung.
+func (m *merchantProductRepository) GetMerchantStockSummary(ctx context.Context, merchantID uint) (*model.StockSummary, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetMerchantStockSummary - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		summary := model.StockSummary{}
+		if err := m.db.WithContext(ctx).Model(&model.MerchantProduct{}).
+			Where("merchant_id = ? AND stock > 0", merchantID).
+			Select("COUNT(*) AS product_count, COUNT(DISTINCT merchant_id) AS merchant_count, COALESCE(SUM(stock), 0) AS total_stock").
+			Scan(&summary).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetMerchantStockSummary - 2: %v", err)
+			return nil, err
+		}
+
+		return &summary, nil
 	}
 }
 
+// GetWarehouseAllocationSummary implements MerchantProductRepositoryInterface.
+// Semua merchant product yang disuplai warehouse dihitung, termasuk yang stock-nya sedang habis,
+// karena replenishment dan retur masih mengarah ke warehouse tersebut.
+func (m *merchantProductRepository) GetWarehouseAllocationSummary(ctx context.Context, warehouseID uint) (*model.StockSummary, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetWarehouseAllocationSummary - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		summary := model.StockSummary{}
+		if err := m.db.WithContext(ctx).Model(&model.MerchantProduct{}).
+			Where("warehouse_id = ?", warehouseID).
+			Select("COUNT(*) AS product_count, COUNT(DISTINCT merchant_id) AS merchant_count, COALESCE(SUM(stock), 0) AS total_stock").
+			Scan(&summary).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetWarehouseAllocationSummary - 2: %v", err)
+			return nil, err
+		}
+
+		return &summary, nil
+	}
+}
+
+// stockShortageError membedakan produk yang tidak dialokasikan ke merchant dengan stock yang kurang
+func stockShortageError(db *gorm.DB, merchantID, productID uint) error {
+	var count int64
+	if err := db.Model(&model.MerchantProduct{}).
+		Where("merchant_id = ? AND product_id = ?", merchantID, productID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return ErrStockNotEnough
+}
+
 // UpdateMerchantProduct implements MerchantProductRepositoryInterface.
-func (m *merchantProductRepository) UpdateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error {
+// Mengembalikan kondisi sebelum update (dibaca dengan lock) dan selisih alokasi yang dicatat di outbox.
+// Saat supplier diganti, stock di rak tetap tercatat di warehouse lama; hanya penambahan yang diambil dari
+// warehouse baru, dan pengurangan ditolak dengan ErrSupplierChangeReducesStock.
+func (m *merchantProductRepository) UpdateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, meta model.StockMovementMeta) (*model.MerchantProduct, *model.MerchantAllocationChange, error) {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[MerchantProductRepository] UpdateMerchantProduct - 1: %v", ctx.Err())
-		return ctx.Err()
+		return nil, nil, ctx.Err()
 	default:
+		tx := m.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[MerchantProductRepository] UpdateMerchantProduct - 2: %v", tx.Error)
+			return nil, nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] UpdateMerchantProduct - 3: %v", r)
+			}
+		}()
+
 		existingMerchantProduct := model.MerchantProduct{}
-		if err := m.db.WithContext(ctx).Where("id = ?", merchantProduct.ID).First(&existingMerchantProduct).Error; err != nil {
-			log.Errorf("[MerchantProductRepository] UpdateMerchantProduct - 2: %v", err)
-			return err
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", merchantProduct.ID).First(&existingMerchantProduct).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] UpdateMerchantProduct - 4: %v", err)
+			return nil, nil, err
+		}
+
+		previousMerchantProduct := existingMerchantProduct
+		delta := merchantProduct.Stock - existingMerchantProduct.Stock
+
+		if existingMerchantProduct.WarehouseID != merchantProduct.WarehouseID && delta < 0 {
+			tx.Rollback()
+			return nil, nil, ErrSupplierChangeReducesStock
 		}
 
 		existingMerchantProduct.Stock = merchantProduct.Stock
@@ -219,7 +644,492 @@ func (m *merchantProductRepository) UpdateMerchantProduct(ctx context.Context, m
 		existingMerchantProduct.ProductID = merchantProduct.ProductID
 		existingMerchantProduct.WarehouseID = merchantProduct.WarehouseID
 
-		return m.db.WithContext(ctx).Save(&existingMerchantProduct).Error
+		if err := tx.Save(&existingMerchantProduct).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] UpdateMerchantProduct - 5: %v", err)
+			return nil, nil, err
+		}
+
+		var change *model.MerchantAllocationChange
+		if delta != 0 {
+			if _, err := recordStockMovement(tx, &existingMerchantProduct, delta, meta); err != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] UpdateMerchantProduct - 6: %v", err)
+				return nil, nil, err
+			}
+
+			recorded, err := recordAllocationChange(tx, &existingMerchantProduct, existingMerchantProduct.WarehouseID, delta)
+			if err != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] UpdateMerchantProduct - 7: %v", err)
+				return nil, nil, err
+			}
+			change = recorded
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[MerchantProductRepository] UpdateMerchantProduct - 8: %v", err)
+			return nil, nil, err
+		}
+
+		return &previousMerchantProduct, change, nil
+	}
+}
+
+// AdjustStock implements MerchantProductRepositoryInterface.
+// delta positif menambah stock, delta negatif mengurangi dan tidak boleh membuat stock minus.
+func (m *merchantProductRepository) AdjustStock(ctx context.Context, merchantProductID uint, delta int, meta model.StockMovementMeta) (*model.MerchantStockMovement, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] AdjustStock - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		tx := m.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[MerchantProductRepository] AdjustStock - 2: %v", tx.Error)
+			return nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] AdjustStock - 3: %v", r)
+			}
+		}()
+
+		var merchantProduct model.MerchantProduct
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", merchantProductID).First(&merchantProduct).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] AdjustStock - 4: %v", err)
+			return nil, err
+		}
+
+		if merchantProduct.Stock+delta < 0 {
+			tx.Rollback()
+			return nil, ErrStockNotEnough
+		}
+
+		merchantProduct.Stock += delta
+		if err := tx.Model(&merchantProduct).Update("stock", merchantProduct.Stock).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] AdjustStock - 5: %v", err)
+			return nil, err
+		}
+
+		movement, err := recordStockMovement(tx, &merchantProduct, delta, meta)
+		if err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] AdjustStock - 6: %v", err)
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[MerchantProductRepository] AdjustStock - 7: %v", err)
+			return nil, err
+		}
+
+		return movement, nil
+	}
+}
+
+// AllocateStock implements MerchantProductRepositoryInterface.
+// Menambah stock sebesar quantity dari warehouseID terhadap saldo yang dikunci, sehingga penjualan yang terjadi
+// bersamaan tidak tertimpa. Movement dan allocation change dicatat dalam transaksi yang sama; supplier tidak diubah.
+func (m *merchantProductRepository) AllocateStock(ctx context.Context, merchantProductID uint, warehouseID uint, quantity int, meta model.StockMovementMeta) (*model.MerchantAllocationChange, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] AllocateStock - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		tx := m.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[MerchantProductRepository] AllocateStock - 2: %v", tx.Error)
+			return nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantProductRepository] AllocateStock - 3: %v", r)
+			}
+		}()
+
+		var merchantProduct model.MerchantProduct
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", merchantProductID).First(&merchantProduct).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] AllocateStock - 4: %v", err)
+			return nil, err
+		}
+
+		merchantProduct.Stock += quantity
+		if err := tx.Model(&merchantProduct).Update("stock", merchantProduct.Stock).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] AllocateStock - 5: %v", err)
+			return nil, err
+		}
+
+		if _, err := recordStockMovement(tx, &merchantProduct, quantity, meta); err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] AllocateStock - 6: %v", err)
+			return nil, err
+		}
+
+		change, err := recordAllocationChange(tx, &merchantProduct, warehouseID, quantity)
+		if err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantProductRepository] AllocateStock - 7: %v", err)
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[MerchantProductRepository] AllocateStock - 8: %v", err)
+			return nil, err
+		}
+
+		return change, nil
+	}
+}
+
+// GetStockMovements implements MerchantProductRepositoryInterface.
+func (m *merchantProductRepository) GetStockMovements(ctx context.Context, merchantProductID uint, startDate *time.Time, endDate *time.Time, movementType string, page int, limit int) ([]model.MerchantStockMovement, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetStockMovements - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		if page <= 0 {
+			page = 1
+		}
+		if limit <= 0 {
+			limit = 10
+		}
+
+		query := m.db.WithContext(ctx).Model(&model.MerchantStockMovement{}).
+			Where("merchant_product_id = ?", merchantProductID)
+
+		if startDate != nil {
+			query = query.Where("created_at >= ?", *startDate)
+		}
+		if endDate != nil {
+			query = query.Where("created_at < ?", *endDate)
+		}
+		if movementType != "" {
+			query = query.Where("movement_type = ?", movementType)
+		}
+
+		var totalRecords int64
+		if err := query.Count(&totalRecords).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetStockMovements - 2: %v", err)
+			return nil, 0, err
+		}
+
+		var movements []model.MerchantStockMovement
+		if err := query.Order("created_at desc, id desc").
+			Offset((page - 1) * limit).
+			Limit(limit).
+			Find(&movements).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetStockMovements - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return movements, totalRecords, nil
+	}
+}
+
+// recordAllocationChange mencatat selisih alokasi terhadap warehouseID; nil jika quantity 0
+func recordAllocationChange(tx *gorm.DB, merchantProduct *model.MerchantProduct, warehouseID uint, quantity int) (*model.MerchantAllocationChange, error) {
+	if quantity == 0 {
+		return nil, nil
+	}
+
+	change := model.MerchantAllocationChange{
+		MerchantProductID: merchantProduct.ID,
+		MerchantID:        merchantProduct.MerchantID,
+		ProductID:         merchantProduct.ProductID,
+		WarehouseID:       warehouseID,
+		Quantity:          quantity,
+	}
+
+	if err := tx.Create(&change).Error; err != nil {
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// GetAllocationChanges implements MerchantProductRepositoryInterface.
+// Diurutkan dari yang paling lama supaya perubahan yang belum terkirim dikirim ulang sesuai urutan.
+func (m *merchantProductRepository) GetAllocationChanges(ctx context.Context, merchantID uint, unpublishedOnly bool, failedOnly bool, page int, limit int) ([]model.MerchantAllocationChange, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetAllocationChanges - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		if page <= 0 {
+			page = 1
+		}
+		if limit <= 0 {
+			limit = 10
+		}
+
+		query := m.db.WithContext(ctx).Model(&model.MerchantAllocationChange{})
+		if merchantID != 0 {
+			query = query.Where("merchant_id = ?", merchantID)
+		}
+		if unpublishedOnly {
+			query = query.Where("published_at IS NULL")
+		}
+		if failedOnly {
+			query = query.Where("failed_at IS NOT NULL")
+		}
+
+		var totalRecords int64
+		if err := query.Count(&totalRecords).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetAllocationChanges - 2: %v", err)
+			return nil, 0, err
+		}
+
+		changes := []model.MerchantAllocationChange{}
+		if err := query.Order("id ASC").Offset((page - 1) * limit).Limit(limit).Find(&changes).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetAllocationChanges - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return changes, totalRecords, nil
+	}
+}
+
+// GetAllocationChangeByID implements MerchantProductRepositoryInterface.
+func (m *merchantProductRepository) GetAllocationChangeByID(ctx context.Context, id uint) (*model.MerchantAllocationChange, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetAllocationChangeByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var change model.MerchantAllocationChange
+		if err := m.db.WithContext(ctx).Where("id = ?", id).First(&change).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetAllocationChangeByID - 2: %v", err)
+			return nil, err
+		}
+
+		return &change, nil
+	}
+}
+
+// MarkAllocationChangePublished implements MerchantProductRepositoryInterface.
+// Penolakan sebelumnya dihapus karena event yang dikirim ulang akan diputuskan lagi oleh warehouse-service.
+func (m *merchantProductRepository) MarkAllocationChangePublished(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] MarkAllocationChangePublished - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := m.db.WithContext(ctx).Model(&model.MerchantAllocationChange{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"published_at":   time.Now(),
+				"failed_at":      nil,
+				"failure_reason": "",
+			}).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] MarkAllocationChangePublished - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// MarkAllocationChangeFailed implements MerchantProductRepositoryInterface.
+func (m *merchantProductRepository) MarkAllocationChangeFailed(ctx context.Context, id uint, reason string) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] MarkAllocationChangeFailed - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := m.db.WithContext(ctx).Model(&model.MerchantAllocationChange{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"failed_at":      time.Now(),
+				"failure_reason": reason,
+			}).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] MarkAllocationChangeFailed - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// recordStockMovement harus dipanggil di dalam transaksi yang sama dengan perubahan stock,
+// setelah merchantProduct.Stock berisi saldo terbaru
+func recordStockMovement(tx *gorm.DB, merchantProduct *model.MerchantProduct, delta int, meta model.StockMovementMeta) (*model.MerchantStockMovement, error) {
+	movement := model.MerchantStockMovement{
+		MerchantProductID: merchantProduct.ID,
+		MerchantID:        merchantProduct.MerchantID,
+		ProductID:         merchantProduct.ProductID,
+		MovementType:      meta.MovementType,
+		QuantityDelta:     delta,
+		BalanceAfter:      merchantProduct.Stock,
+		ActorID:           meta.ActorID,
+		Reference:         meta.Reference,
+		Note:              meta.Note,
+	}
+
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, err
+	}
+
+	return &movement, nil
+}
+
+// mergeStockReductionItems menggabungkan product yang sama dan mengurutkan berdasarkan product_id,
+// sehingga dua order yang berisi produk yang sama selalu mengunci baris dengan urutan yang sama (tanpa deadlock)
+func mergeStockReductionItems(items []model.StockReductionItem) []model.StockReductionItem {
+	quantities := make(map[uint]int)
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			continue
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	merged := make([]model.StockReductionItem, 0, len(quantities))
+	for productID, quantity := range quantities {
+		merged = append(merged, model.StockReductionItem{ProductID: productID, Quantity: quantity})
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].ProductID < merged[j].ProductID
+	})
+
+	return merged
+}
+
+// GetDeletedMerchantProducts implements MerchantProductRepositoryInterface.
+// merchantID dan productID bernilai 0 berarti tidak difilter.
+func (m *merchantProductRepository) GetDeletedMerchantProducts(ctx context.Context, merchantID, productID uint, page, limit int) ([]model.MerchantProduct, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] GetDeletedMerchantProducts - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		if page <= 0 {
+			page = 1
+		}
+		if limit <= 0 {
+			limit = 10
+		}
+
+		query := m.db.WithContext(ctx).Unscoped().Model(&model.MerchantProduct{}).Where("deleted_at IS NOT NULL")
+		if merchantID != 0 {
+			query = query.Where("merchant_id = ?", merchantID)
+		}
+		if productID != 0 {
+			query = query.Where("product_id = ?", productID)
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetDeletedMerchantProducts - 2: %v", err)
+			return nil, 0, err
+		}
+
+		merchantProducts := []model.MerchantProduct{}
+		if err := query.Order("deleted_at desc").Offset((page - 1) * limit).Limit(limit).Find(&merchantProducts).Error; err != nil {
+			log.Errorf("[MerchantProductRepository] GetDeletedMerchantProducts - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return merchantProducts, total, nil
+	}
+}
+
+// RestoreMerchantProduct implements MerchantProductRepositoryInterface.
+// Ditolak jika merchant-nya masih di trash atau product yang sama sudah didaftarkan ulang di merchant itu.
+func (m *merchantProductRepository) RestoreMerchantProduct(ctx context.Context, id uint) (*model.MerchantProduct, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] RestoreMerchantProduct - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		merchantProduct := model.MerchantProduct{}
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&merchantProduct).Error; err != nil {
+				log.Errorf("[MerchantProductRepository] RestoreMerchantProduct - 2: %v", err)
+				return err
+			}
+
+			var activeMerchants int64
+			if err := tx.Model(&model.Merchant{}).Where("id = ?", merchantProduct.MerchantID).Count(&activeMerchants).Error; err != nil {
+				log.Errorf("[MerchantProductRepository] RestoreMerchantProduct - 3: %v", err)
+				return err
+			}
+			if activeMerchants == 0 {
+				return ErrMerchantInTrash
+			}
+
+			var duplicates int64
+			if err := tx.Model(&model.MerchantProduct{}).
+				Where("merchant_id = ? AND product_id = ?", merchantProduct.MerchantID, merchantProduct.ProductID).
+				Count(&duplicates).Error; err != nil {
+				log.Errorf("[MerchantProductRepository] RestoreMerchantProduct - 4: %v", err)
+				return err
+			}
+			if duplicates > 0 {
+				return ErrMerchantProductExists
+			}
+
+			return tx.Unscoped().Model(&merchantProduct).Update("deleted_at", nil).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &merchantProduct, nil
+	}
+}
+
+// PurgeDeletedMerchantProducts implements MerchantProductRepositoryInterface.
+// Riwayat harga ikut dihapus; ledger stock tetap disimpan sebagai riwayat merchant.
+func (m *merchantProductRepository) PurgeDeletedMerchantProducts(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantProductRepository] PurgeDeletedMerchantProducts - 1: %v", ctx.Err())
+		return 0, ctx.Err()
+	default:
+		var purged int64
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			merchantProductIDs := []uint{}
+			if err := tx.Unscoped().Model(&model.MerchantProduct{}).
+				Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+				Pluck("id", &merchantProductIDs).Error; err != nil {
+				log.Errorf("[MerchantProductRepository] PurgeDeletedMerchantProducts - 2: %v", err)
+				return err
+			}
+
+			if len(merchantProductIDs) == 0 {
+				return nil
+			}
+
+			if err := tx.Where("merchant_product_id IN ?", merchantProductIDs).Delete(&model.MerchantProductPrice{}).Error; err != nil {
+				log.Errorf("[MerchantProductRepository] PurgeDeletedMerchantProducts - 3: %v", err)
+				return err
+			}
+
+			result := tx.Unscoped().Where("id IN ?", merchantProductIDs).Delete(&model.MerchantProduct{})
+			if result.Error != nil {
+				log.Errorf("[MerchantProductRepository] PurgeDeletedMerchantProducts - 4: %v", result.Error)
+				return result.Error
+			}
+			purged = result.RowsAffected
+
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		return purged, nil
 	}
 }
 
@@ -3,19 +3,36 @@ package repository
 import (
 	"context"
 	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/geo"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
 )
 
-// create, get all with pagination, get by ID, update, delete, get merchant by keeper id,
+// create, get all with pagination, get by ID, update, delete, get merchants by staff user, check staff, update status, archive, trash, location
 type MerchantRepositoryInterface interface {
 	CreateMerchant(ctx context.Context, merchant *model.Merchant) error
-	GetAllMerchants(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Merchant, int64, error)
+	GetAllMerchants(ctx context.Context, page, limit int, search, sortBy, sortOrder, status string, openNow, archived bool) ([]model.Merchant, int64, error)
 	GetMerchantByID(ctx context.Context, id uint) (*model.Merchant, error)
 	UpdateMerchant(ctx context.Context, merchant *model.Merchant) error
 	DeleteMerchant(ctx context.Context, id uint) error
-	GetMerchantByKeeperID(ctx context.Context, keeperID uint) (*model.Merchant, error)
+	GetMerchantsByStaffUserID(ctx context.Context, userID uint) ([]model.Merchant, error)
+	IsActiveStaff(ctx context.Context, merchantID, userID uint) (bool, error)
+	UpdateMerchantStatus(ctx context.Context, id uint, status, reason string) error
+	ArchiveMerchant(ctx context.Context, id uint, archivedAt time.Time) error
+	UnarchiveMerchant(ctx context.Context, id uint) error
+	GetDeletedMerchants(ctx context.Context, page, limit int, search string) ([]model.Merchant, int64, error)
+	RestoreMerchant(ctx context.Context, id uint) error
+	PurgeDeletedMerchants(ctx context.Context, deletedBefore time.Time) (*MerchantPurgeResult, error)
+	GetMerchantsWithinBounds(ctx context.Context, bounds geo.BoundingBox, status string, openNow bool) ([]model.Merchant, error)
+}
+
+// MerchantPurgeResult membedakan merchant yang dihapus permanen dengan yang dipertahankan karena masih
+// direferensikan transfer order, replenishment order, stock return atau stocktake
+type MerchantPurgeResult struct {
+	Purged   int64
+	Retained int64
 }
 
 type merchantRepository struct {
@@ -34,18 +51,92 @@ func (m *merchantRepository) CreateMerchant(ctx context.Context, merchant *model
 }
 
 // DeleteMerchant implements MerchantRepositoryInterface.
+// Merchant product ikut masuk trash dengan deleted_at yang sama sehingga bisa di-restore bersama merchant-nya.
 func (m *merchantRepository) DeleteMerchant(ctx context.Context, id uint) error {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[MerchantRepository] DeleteMerchant - 1: %v", ctx.Err())
 		return ctx.Err()
 	default:
-		return m.db.WithContext(ctx).Delete(&model.Merchant{}, id).Error
+		deletedAt := time.Now()
+
+		return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&model.Merchant{}).Where("id = ?", id).Update("deleted_at", deletedAt)
+			if result.Error != nil {
+				log.Errorf("[MerchantRepository] DeleteMerchant - 2: %v", result.Error)
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+
+			if err := tx.Model(&model.MerchantProduct{}).Where("merchant_id = ?", id).Update("deleted_at", deletedAt).Error; err != nil {
+				log.Errorf("[MerchantRepository] DeleteMerchant - 3: %v", err)
+				return err
+			}
+
+			return nil
+		})
+	}
+}
+
+// ArchiveMerchant implements MerchantRepositoryInterface.
+// Merchant yang diarsipkan sekaligus ditutup agar tidak menerima penjualan baru.
+func (m *merchantRepository) ArchiveMerchant(ctx context.Context, id uint, archivedAt time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] ArchiveMerchant - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := m.db.WithContext(ctx).Model(&model.Merchant{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"archived_at":   archivedAt,
+				"status":        model.MerchantStatusClosed,
+				"status_reason": model.MerchantArchivedStatusReason,
+			})
+		if result.Error != nil {
+			log.Errorf("[MerchantRepository] ArchiveMerchant - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	}
+}
+
+// UnarchiveMerchant implements MerchantRepositoryInterface.
+// Status tetap closed; merchant diaktifkan kembali lewat endpoint status.
+func (m *merchantRepository) UnarchiveMerchant(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] UnarchiveMerchant - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := m.db.WithContext(ctx).Model(&model.Merchant{}).
+			Where("id = ?", id).
+			Update("archived_at", nil)
+		if result.Error != nil {
+			log.Errorf("[MerchantRepository] UnarchiveMerchant - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
 	}
 }
 
 // GetAllMerchants implements MerchantRepositoryInterface.
-func (m *merchantRepository) GetAllMerchants(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Merchant, int64, error) {
+// openNow memakai aturan yang sama dengan model.Merchant.IsOpenAt, dihitung di database supaya pagination tetap benar.
+// Merchant yang diarsipkan hanya muncul jika archived bernilai true, dan saat itu hanya merchant arsip yang dikembalikan.
+func (m *merchantRepository) GetAllMerchants(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, status string, openNow bool, archived bool) ([]model.Merchant, int64, error) {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[MerchantRepository] GetAllMerchants - 1: %v", ctx.Err())
@@ -71,16 +162,32 @@ func (m *merchantRepository) GetAllMerchants(ctx context.Context, page int, limi
 
 		query := m.db.WithContext(ctx).Model(&model.Merchant{})
 
+		if archived {
+			query = query.Where("archived_at IS NOT NULL")
+		} else {
+			query = query.Where("archived_at IS NULL")
+		}
+
 		if search != "" {
 			query = query.Where("name ILIKE ? OR address ILIKE ?", "%"+search+"%", "%"+search+"%")
 		}
 
+		if status != "" {
+			query = query.Where("status = ?", status)
+		}
+
+		if openNow {
+			query = query.Where(openNowCondition, model.MerchantStatusActive)
+		}
+
 		if err := query.Count(&totalRecords).Error; err != nil {
 			log.Errorf("[MerchantRepository] GetAllMerchants - 2: %v", err)
 			return nil, 0, err
 		}
 
-		if err := query.Preload("MerchantProducts").Order(sortBy + " " + sortOrder).
+		if err := query.Preload("MerchantProducts").Preload("Staff", orderStaff).
+			Preload("OpeningHours", orderOpeningHours).Preload("Closures", upcomingClosures).
+			Order(sortBy + " " + sortOrder).
 			WithContext(ctx).
 			Offset(offset).
 			Limit(limit).
@@ -102,7 +209,9 @@ func (m *merchantRepository) GetMerchantByID(ctx context.Context, id uint) (*mod
 	default:
 		modelMerchant := model.Merchant{}
 
-		if err := m.db.WithContext(ctx).Where("id = ?", id).Preload("MerchantProducts").First(&modelMerchant).Error; err != nil {
+		if err := m.db.WithContext(ctx).Where("id = ?", id).Preload("MerchantProducts").Preload("Staff", orderStaff).
+			Preload("OpeningHours", orderOpeningHours).Preload("Closures", upcomingClosures).
+			First(&modelMerchant).Error; err != nil {
 			log.Errorf("[MerchantRepository] GetMerchantByID - 2: %v", err)
 			return nil, err
 		}
@@ -110,19 +219,72 @@ func (m *merchantRepository) GetMerchantByID(ctx context.Context, id uint) (*mod
 	}
 }
 
-// GetMerchantByKeeperID implements MerchantRepositoryInterface.
-func (m *merchantRepository) GetMerchantByKeeperID(ctx context.Context, keeperID uint) (*model.Merchant, error) {
+// GetMerchantsByStaffUserID implements MerchantRepositoryInterface.
+// Hanya merchant tempat user sedang aktif bertugas yang dikembalikan. Merchant arsip tetap ikut
+// supaya staff masih bisa membaca riwayat transaksi dan stock-nya.
+func (m *merchantRepository) GetMerchantsByStaffUserID(ctx context.Context, userID uint) ([]model.Merchant, error) {
 	select {
 	case <-ctx.Done():
-		log.Errorf("[MerchantRepository] GetMerchantByKeeperID - 1: %v", ctx.Err())
+		log.Errorf("[MerchantRepository] GetMerchantsByStaffUserID - 1: %v", ctx.Err())
 		return nil, ctx.Err()
 	default:
-		modelMerchant := model.Merchant{}
-		if err := m.db.WithContext(ctx).Where("keeper_id = ?", keeperID).Preload("MerchantProducts").First(&modelMerchant).Error; err != nil {
-			log.Errorf("[MerchantRepository] GetMerchantByKeeperID - 2: %v", err)
+		modelMerchants := []model.Merchant{}
+		if err := m.db.WithContext(ctx).
+			Where("id IN (?)", activeStaffQuery(m.db, time.Now()).Select("merchant_id").Where("user_id = ?", userID)).
+			Preload("MerchantProducts").
+			Preload("Staff", orderStaff).
+			Preload("OpeningHours", orderOpeningHours).
+			Preload("Closures", upcomingClosures).
+			Order("id ASC").
+			Find(&modelMerchants).Error; err != nil {
+			log.Errorf("[MerchantRepository] GetMerchantsByStaffUserID - 2: %v", err)
 			return nil, err
 		}
-		return &modelMerchant, nil
+		return modelMerchants, nil
+	}
+}
+
+// IsActiveStaff implements MerchantRepositoryInterface.
+func (m *merchantRepository) IsActiveStaff(ctx context.Context, merchantID uint, userID uint) (bool, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] IsActiveStaff - 1: %v", ctx.Err())
+		return false, ctx.Err()
+	default:
+		var count int64
+		if err := activeStaffQuery(m.db.WithContext(ctx), time.Now()).
+			Where("merchant_id = ? AND user_id = ?", merchantID, userID).
+			Count(&count).Error; err != nil {
+			log.Errorf("[MerchantRepository] IsActiveStaff - 2: %v", err)
+			return false, err
+		}
+		return count > 0, nil
+	}
+}
+
+// UpdateMerchantStatus implements MerchantRepositoryInterface.
+func (m *merchantRepository) UpdateMerchantStatus(ctx context.Context, id uint, status string, reason string) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] UpdateMerchantStatus - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := m.db.WithContext(ctx).Model(&model.Merchant{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"status":        status,
+				"status_reason": reason,
+			})
+		if result.Error != nil {
+			log.Errorf("[MerchantRepository] UpdateMerchantStatus - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
 	}
 }
 
@@ -143,12 +305,221 @@ func (m *merchantRepository) UpdateMerchant(ctx context.Context, merchant *model
 		existingMerchant.Address = merchant.Address
 		existingMerchant.Photo = merchant.Photo
 		existingMerchant.Phone = merchant.Phone
-		existingMerchant.KeeperID = merchant.KeeperID
+		existingMerchant.Latitude = merchant.Latitude
+		existingMerchant.Longitude = merchant.Longitude
 
 		return m.db.WithContext(ctx).Save(&existingMerchant).Error
 	}
 }
 
+// GetMerchantsWithinBounds implements MerchantRepositoryInterface.
+// Hanya menyaring dengan bounding box; jarak sebenarnya dihitung ulang di usecase. Merchant arsip tidak ikut.
+func (m *merchantRepository) GetMerchantsWithinBounds(ctx context.Context, bounds geo.BoundingBox, status string, openNow bool) ([]model.Merchant, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] GetMerchantsWithinBounds - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		modelMerchants := []model.Merchant{}
+
+		query := m.db.WithContext(ctx).Model(&model.Merchant{}).
+			Where("archived_at IS NULL").
+			Where("latitude BETWEEN ? AND ?", bounds.MinLatitude, bounds.MaxLatitude).
+			Where("longitude BETWEEN ? AND ?", bounds.MinLongitude, bounds.MaxLongitude)
+
+		if status != "" {
+			query = query.Where("status = ?", status)
+		}
+
+		if openNow {
+			query = query.Where(openNowCondition, model.MerchantStatusActive)
+		}
+
+		if err := query.Preload("OpeningHours", orderOpeningHours).Preload("Closures", upcomingClosures).
+			Find(&modelMerchants).Error; err != nil {
+			log.Errorf("[MerchantRepository] GetMerchantsWithinBounds - 2: %v", err)
+			return nil, err
+		}
+
+		return modelMerchants, nil
+	}
+}
+
+// activeStaffQuery memilih penugasan staff yang aktif pada waktu at
+func activeStaffQuery(db *gorm.DB, at time.Time) *gorm.DB {
+	return db.Model(&model.MerchantStaff{}).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date > ?)", at, at)
+}
+
+// orderStaff membuat urutan staff stabil sehingga lead pertama selalu sama
+func orderStaff(db *gorm.DB) *gorm.DB {
+	return db.Order("start_date ASC, id ASC")
+}
+
+// openNowCondition: status active, tidak dalam penutupan sementara, dan tanpa jam buka atau sedang dalam
+// salah satu rentang jam buka pada timezone merchant (termasuk sisa shift malam dari hari sebelumnya)
+const openNowCondition = `merchants.status = ?
+	AND NOT EXISTS (
+		SELECT 1 FROM merchant_closures mc
+		WHERE mc.merchant_id = merchants.id AND mc.starts_at <= NOW() AND mc.ends_at > NOW()
+	)
+	AND (
+		NOT EXISTS (SELECT 1 FROM merchant_opening_hours oh WHERE oh.merchant_id = merchants.id)
+		OR EXISTS (
+			SELECT 1
+			FROM merchant_opening_hours oh,
+				LATERAL (SELECT NOW() AT TIME ZONE merchants.timezone AS local_time) lt
+			WHERE oh.merchant_id = merchants.id AND (
+				(oh.weekday = EXTRACT(DOW FROM lt.local_time)::int
+					AND to_char(lt.local_time, 'HH24:MI') >= oh.opens_at
+					AND (oh.closes_at <= oh.opens_at OR to_char(lt.local_time, 'HH24:MI') < oh.closes_at))
+				OR (oh.closes_at <= oh.opens_at
+					AND oh.weekday = (EXTRACT(DOW FROM lt.local_time)::int + 6) % 7
+					AND to_char(lt.local_time, 'HH24:MI') < oh.closes_at)
+			)
+		)
+	)`
+
+func orderOpeningHours(db *gorm.DB) *gorm.DB {
+	return db.Order("weekday ASC, opens_at ASC")
+}
+
+// upcomingClosures hanya memuat penutupan yang belum berakhir; riwayat diambil lewat endpoint closures
+func upcomingClosures(db *gorm.DB) *gorm.DB {
+	return db.Where("ends_at > ?", time.Now()).Order("starts_at ASC")
+}
+
+// GetDeletedMerchants implements MerchantRepositoryInterface.
+// Isi trash diurutkan dari yang terakhir dihapus.
+func (m *merchantRepository) GetDeletedMerchants(ctx context.Context, page, limit int, search string) ([]model.Merchant, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] GetDeletedMerchants - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		if page <= 0 {
+			page = 1
+		}
+		if limit <= 0 {
+			limit = 10
+		}
+
+		query := m.db.WithContext(ctx).Unscoped().Model(&model.Merchant{}).Where("deleted_at IS NOT NULL")
+
+		if search != "" {
+			query = query.Where("name ILIKE ? OR address ILIKE ?", "%"+search+"%", "%"+search+"%")
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			log.Errorf("[MerchantRepository] GetDeletedMerchants - 2: %v", err)
+			return nil, 0, err
+		}
+
+		modelMerchants := []model.Merchant{}
+		if err := query.Order("deleted_at desc").Offset((page - 1) * limit).Limit(limit).Find(&modelMerchants).Error; err != nil {
+			log.Errorf("[MerchantRepository] GetDeletedMerchants - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return modelMerchants, total, nil
+	}
+}
+
+// RestoreMerchant implements MerchantRepositoryInterface.
+// Hanya merchant product yang terhapus bersama merchant (deleted_at sama) yang ikut di-restore; product yang
+// sudah didaftarkan ulang selama merchant di trash dilewati. Mengembalikan gorm.ErrRecordNotFound jika
+// merchant tidak ada di trash.
+func (m *merchantRepository) RestoreMerchant(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] RestoreMerchant - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			deletedMerchant := model.Merchant{}
+			if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&deletedMerchant).Error; err != nil {
+				log.Errorf("[MerchantRepository] RestoreMerchant - 2: %v", err)
+				return err
+			}
+
+			if err := tx.Unscoped().Model(&model.MerchantProduct{}).
+				Where("merchant_id = ? AND deleted_at = ?", id, deletedMerchant.DeletedAt.Time).
+				Where(`NOT EXISTS (SELECT 1 FROM merchant_products active WHERE active.merchant_id = merchant_products.merchant_id
+					AND active.product_id = merchant_products.product_id AND active.deleted_at IS NULL)`).
+				Update("deleted_at", nil).Error; err != nil {
+				log.Errorf("[MerchantRepository] RestoreMerchant - 3: %v", err)
+				return err
+			}
+
+			return tx.Unscoped().Model(&deletedMerchant).Update("deleted_at", nil).Error
+		})
+	}
+}
+
+// PurgeDeletedMerchants implements MerchantRepositoryInterface.
+// Merchant dihapus permanen bersama staff, jam buka, penutupan dan zona pengirimannya setelah seluruh merchant product-nya
+// ikut di-purge. Ledger stock tidak memiliki foreign key ke merchant dan tetap disimpan.
+func (m *merchantRepository) PurgeDeletedMerchants(ctx context.Context, deletedBefore time.Time) (*MerchantPurgeResult, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantRepository] PurgeDeletedMerchants - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		result := &MerchantPurgeResult{}
+
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			expired := func() *gorm.DB {
+				return tx.Unscoped().Model(&model.Merchant{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore)
+			}
+
+			var expiredCount int64
+			if err := expired().Count(&expiredCount).Error; err != nil {
+				log.Errorf("[MerchantRepository] PurgeDeletedMerchants - 2: %v", err)
+				return err
+			}
+
+			merchantIDs := []uint{}
+			if err := expired().
+				Where("NOT EXISTS (?)", tx.Unscoped().Model(&model.MerchantProduct{}).Select("1").Where("merchant_id = merchants.id")).
+				Where("NOT EXISTS (?)", tx.Model(&model.TransferOrder{}).Select("1").Where("merchant_id = merchants.id")).
+				Where("NOT EXISTS (?)", tx.Model(&model.ReplenishmentOrder{}).Select("1").Where("merchant_id = merchants.id")).
+				Where("NOT EXISTS (?)", tx.Model(&model.StockReturn{}).Select("1").Where("merchant_id = merchants.id")).
+				Where("NOT EXISTS (?)", tx.Model(&model.Stocktake{}).Select("1").Where("merchant_id = merchants.id")).
+				Pluck("id", &merchantIDs).Error; err != nil {
+				log.Errorf("[MerchantRepository] PurgeDeletedMerchants - 3: %v", err)
+				return err
+			}
+
+			result.Retained = expiredCount - int64(len(merchantIDs))
+			if len(merchantIDs) == 0 {
+				return nil
+			}
+
+			for _, dependent := range []interface{}{&model.MerchantStaff{}, &model.MerchantOpeningHour{}, &model.MerchantClosure{}, &model.MerchantDeliveryZone{}} {
+				if err := tx.Unscoped().Where("merchant_id IN ?", merchantIDs).Delete(dependent).Error; err != nil {
+					log.Errorf("[MerchantRepository] PurgeDeletedMerchants - 4: %v", err)
+					return err
+				}
+			}
+
+			purged := tx.Unscoped().Where("id IN ?", merchantIDs).Delete(&model.Merchant{})
+			if purged.Error != nil {
+				log.Errorf("[MerchantRepository] PurgeDeletedMerchants - 5: %v", purged.Error)
+				return purged.Error
+			}
+			result.Purged = purged.RowsAffected
+
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+
+		return result, nil
+	}
+}
+
 func NewMerchantRepository(db *gorm.DB) MerchantRepositoryInterface {
 	return &merchantRepository{db: db}
 }
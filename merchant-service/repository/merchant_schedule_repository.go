@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"micro-warehouse/merchant-service/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// replace opening hours, list/create/delete closures
+type MerchantScheduleRepositoryInterface interface {
+	ReplaceOpeningHours(ctx context.Context, merchantID uint, timezone string, hours []model.MerchantOpeningHour) error
+	GetClosures(ctx context.Context, merchantID uint, includePast bool) ([]model.MerchantClosure, error)
+	CreateClosure(ctx context.Context, closure *model.MerchantClosure) error
+	DeleteClosure(ctx context.Context, merchantID, id uint) error
+}
+
+type merchantScheduleRepository struct {
+	db *gorm.DB
+}
+
+// ReplaceOpeningHours implements MerchantScheduleRepositoryInterface.
+// Jadwal mingguan selalu diganti utuh bersama timezone-nya; hours kosong berarti buka sepanjang waktu.
+func (m *merchantScheduleRepository) ReplaceOpeningHours(ctx context.Context, merchantID uint, timezone string, hours []model.MerchantOpeningHour) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantScheduleRepository] ReplaceOpeningHours - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		tx := m.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[MerchantScheduleRepository] ReplaceOpeningHours - 2: %v", tx.Error)
+			return tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantScheduleRepository] ReplaceOpeningHours - 3: %v", r)
+			}
+		}()
+
+		result := tx.Model(&model.Merchant{}).Where("id = ?", merchantID).Update("timezone", timezone)
+		if result.Error != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantScheduleRepository] ReplaceOpeningHours - 4: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("merchant_id = ?", merchantID).Delete(&model.MerchantOpeningHour{}).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantScheduleRepository] ReplaceOpeningHours - 5: %v", err)
+			return err
+		}
+
+		if len(hours) > 0 {
+			for i := range hours {
+				hours[i].ID = 0
+				hours[i].MerchantID = merchantID
+			}
+
+			if err := tx.Create(&hours).Error; err != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantScheduleRepository] ReplaceOpeningHours - 6: %v", err)
+				return err
+			}
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[MerchantScheduleRepository] ReplaceOpeningHours - 7: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetClosures implements MerchantScheduleRepositoryInterface.
+func (m *merchantScheduleRepository) GetClosures(ctx context.Context, merchantID uint, includePast bool) ([]model.MerchantClosure, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantScheduleRepository] GetClosures - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		closures := []model.MerchantClosure{}
+
+		query := m.db.WithContext(ctx).Where("merchant_id = ?", merchantID)
+		if !includePast {
+			query = query.Where("ends_at > ?", time.Now())
+		}
+
+		if err := query.Order("starts_at ASC").Find(&closures).Error; err != nil {
+			log.Errorf("[MerchantScheduleRepository] GetClosures - 2: %v", err)
+			return nil, err
+		}
+
+		return closures, nil
+	}
+}
+
+// CreateClosure implements MerchantScheduleRepositoryInterface.
+func (m *merchantScheduleRepository) CreateClosure(ctx context.Context, closure *model.MerchantClosure) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantScheduleRepository] CreateClosure - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := m.db.WithContext(ctx).Where("id = ?", closure.MerchantID).First(&model.Merchant{}).Error; err != nil {
+			log.Errorf("[MerchantScheduleRepository] CreateClosure - 2: %v", err)
+			return err
+		}
+
+		if err := m.db.WithContext(ctx).Create(closure).Error; err != nil {
+			log.Errorf("[MerchantScheduleRepository] CreateClosure - 3: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// DeleteClosure implements MerchantScheduleRepositoryInterface.
+func (m *merchantScheduleRepository) DeleteClosure(ctx context.Context, merchantID uint, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantScheduleRepository] DeleteClosure - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := m.db.WithContext(ctx).
+			Where("id = ? AND merchant_id = ?", id, merchantID).
+			Delete(&model.MerchantClosure{})
+		if result.Error != nil {
+			log.Errorf("[MerchantScheduleRepository] DeleteClosure - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	}
+}
+
+func NewMerchantScheduleRepository(db *gorm.DB) MerchantScheduleRepositoryInterface {
+	return &merchantScheduleRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"micro-warehouse/merchant-service/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// add, list, get by id, update, end assignment
+type MerchantStaffRepositoryInterface interface {
+	AddStaff(ctx context.Context, staff *model.MerchantStaff) error
+	GetStaff(ctx context.Context, merchantID uint, activeOnly bool) ([]model.MerchantStaff, error)
+	GetStaffByID(ctx context.Context, merchantID, id uint) (*model.MerchantStaff, error)
+	UpdateStaff(ctx context.Context, staff *model.MerchantStaff) error
+	EndStaff(ctx context.Context, merchantID, id uint, endDate time.Time) (*model.MerchantStaff, error)
+}
+
+var ErrStaffAssignmentOverlap = errors.New("user already has an assignment at this merchant in that period")
+
+type merchantStaffRepository struct {
+	db *gorm.DB
+}
+
+// AddStaff implements MerchantStaffRepositoryInterface.
+// Baris merchant dikunci supaya dua penugasan yang tumpang tindih tidak tersimpan bersamaan.
+func (m *merchantStaffRepository) AddStaff(ctx context.Context, staff *model.MerchantStaff) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantStaffRepository] AddStaff - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		tx := m.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[MerchantStaffRepository] AddStaff - 2: %v", tx.Error)
+			return tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantStaffRepository] AddStaff - 3: %v", r)
+			}
+		}()
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", staff.MerchantID).
+			First(&model.Merchant{}).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantStaffRepository] AddStaff - 4: %v", err)
+			return err
+		}
+
+		if err := checkStaffOverlap(tx, staff); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Create(staff).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantStaffRepository] AddStaff - 5: %v", err)
+			return err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[MerchantStaffRepository] AddStaff - 6: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetStaff implements MerchantStaffRepositoryInterface.
+func (m *merchantStaffRepository) GetStaff(ctx context.Context, merchantID uint, activeOnly bool) ([]model.MerchantStaff, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantStaffRepository] GetStaff - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		staff := []model.MerchantStaff{}
+
+		query := m.db.WithContext(ctx).Where("merchant_id = ?", merchantID)
+		if activeOnly {
+			query = activeStaffQuery(query, time.Now())
+		}
+
+		if err := orderStaff(query).Find(&staff).Error; err != nil {
+			log.Errorf("[MerchantStaffRepository] GetStaff - 2: %v", err)
+			return nil, err
+		}
+
+		return staff, nil
+	}
+}
+
+// GetStaffByID implements MerchantStaffRepositoryInterface.
+func (m *merchantStaffRepository) GetStaffByID(ctx context.Context, merchantID uint, id uint) (*model.MerchantStaff, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantStaffRepository] GetStaffByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var staff model.MerchantStaff
+		if err := m.db.WithContext(ctx).
+			Where("id = ? AND merchant_id = ?", id, merchantID).
+			First(&staff).Error; err != nil {
+			log.Errorf("[MerchantStaffRepository] GetStaffByID - 2: %v", err)
+			return nil, err
+		}
+
+		return &staff, nil
+	}
+}
+
+// UpdateStaff implements MerchantStaffRepositoryInterface.
+// Role dan tanggal aktif boleh diubah; user dan merchant penugasan tetap.
+func (m *merchantStaffRepository) UpdateStaff(ctx context.Context, staff *model.MerchantStaff) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantStaffRepository] UpdateStaff - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		tx := m.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[MerchantStaffRepository] UpdateStaff - 2: %v", tx.Error)
+			return tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[MerchantStaffRepository] UpdateStaff - 3: %v", r)
+			}
+		}()
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", staff.MerchantID).
+			First(&model.Merchant{}).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantStaffRepository] UpdateStaff - 4: %v", err)
+			return err
+		}
+
+		if err := checkStaffOverlap(tx, staff); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		result := tx.Model(&model.MerchantStaff{}).
+			Where("id = ? AND merchant_id = ?", staff.ID, staff.MerchantID).
+			Updates(map[string]interface{}{
+				"role":       staff.Role,
+				"start_date": staff.StartDate,
+				"end_date":   staff.EndDate,
+			})
+		if result.Error != nil {
+			tx.Rollback()
+			log.Errorf("[MerchantStaffRepository] UpdateStaff - 5: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[MerchantStaffRepository] UpdateStaff - 6: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// EndStaff implements MerchantStaffRepositoryInterface.
+// Penugasan tidak dihapus, hanya diberi end_date; penugasan yang sudah berakhir lebih awal tidak diubah.
+func (m *merchantStaffRepository) EndStaff(ctx context.Context, merchantID uint, id uint, endDate time.Time) (*model.MerchantStaff, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[MerchantStaffRepository] EndStaff - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		if err := m.db.WithContext(ctx).Model(&model.MerchantStaff{}).
+			Where("id = ? AND merchant_id = ? AND (end_date IS NULL OR end_date > ?)", id, merchantID, endDate).
+			Update("end_date", endDate).Error; err != nil {
+			log.Errorf("[MerchantStaffRepository] EndStaff - 2: %v", err)
+			return nil, err
+		}
+
+		return m.GetStaffByID(ctx, merchantID, id)
+	}
+}
+
+// checkStaffOverlap menolak penugasan user yang sama di merchant yang sama dengan periode yang bersinggungan
+func checkStaffOverlap(tx *gorm.DB, staff *model.MerchantStaff) error {
+	query := tx.Model(&model.MerchantStaff{}).
+		Where("merchant_id = ? AND user_id = ? AND id <> ?", staff.MerchantID, staff.UserID, staff.ID).
+		Where("end_date IS NULL OR end_date > ?", staff.StartDate)
+	if staff.EndDate != nil {
+		query = query.Where("start_date < ?", *staff.EndDate)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		log.Errorf("[MerchantStaffRepository] checkStaffOverlap - 1: %v", err)
+		return err
+	}
+
+	if count > 0 {
+		return ErrStaffAssignmentOverlap
+	}
+
+	return nil
+}
+
+func NewMerchantStaffRepository(db *gorm.DB) MerchantStaffRepositoryInterface {
+	return &merchantStaffRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"micro-warehouse/merchant-service/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// salinan product dari product-service untuk pencarian merchant product
+type ProductProjectionRepositoryInterface interface {
+	UpsertProductProjections(ctx context.Context, projections []model.ProductProjection) error
+	DeleteProductProjection(ctx context.Context, productID uint, deletedAt time.Time) error
+}
+
+type productProjectionRepository struct {
+	db *gorm.DB
+}
+
+// UpsertProductProjections implements ProductProjectionRepositoryInterface.
+// Baris hanya ditimpa jika SyncedAt yang baru tidak lebih lama dari yang tersimpan, dan tombstone hanya
+// dihidupkan lagi oleh snapshot yang lebih baru dari waktu delete-nya (mis. product di-restore).
+func (p *productProjectionRepository) UpsertProductProjections(ctx context.Context, projections []model.ProductProjection) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductProjectionRepository] UpsertProductProjections - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if len(projections) == 0 {
+			return nil
+		}
+
+		err := p.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "barcode", "category_id", "category_name", "synced_at", "deleted_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "product_projections.synced_at <= excluded.synced_at"},
+				clause.Expr{SQL: "(product_projections.deleted_at IS NULL OR product_projections.deleted_at < excluded.synced_at)"},
+			}},
+		}).Create(&projections).Error
+		if err != nil {
+			log.Errorf("[ProductProjectionRepository] UpsertProductProjections - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// DeleteProductProjection implements ProductProjectionRepositoryInterface.
+// Baris ditandai deleted (atau dibuat sebagai tombstone jika belum ada) dengan deletedAt sebagai versinya,
+// sehingga snapshot yang lebih lama tidak bisa membuatnya muncul lagi.
+func (p *productProjectionRepository) DeleteProductProjection(ctx context.Context, productID uint, deletedAt time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductProjectionRepository] DeleteProductProjection - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		tombstone := model.ProductProjection{
+			ProductID: productID,
+			SyncedAt:  deletedAt,
+			DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true},
+		}
+
+		err := p.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"synced_at", "deleted_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "product_projections.synced_at <= excluded.synced_at"},
+			}},
+		}).Create(&tombstone).Error
+		if err != nil {
+			log.Errorf("[ProductProjectionRepository] DeleteProductProjection - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+func NewProductProjectionRepository(db *gorm.DB) ProductProjectionRepositoryInterface {
+	return &productProjectionRepository{
+		db: db,
+	}
+}
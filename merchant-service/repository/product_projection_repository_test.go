@@ -0,0 +1,70 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"micro-warehouse/merchant-service/model"
+	"testing"
+	"time"
+)
+
+// findProjection membaca baris termasuk tombstone
+func findProjection(t *testing.T, repo *productProjectionRepository, productID uint) model.ProductProjection {
+	t.Helper()
+
+	var projection model.ProductProjection
+	if err := repo.db.Unscoped().Where("product_id = ?", productID).First(&projection).Error; err != nil {
+		t.Fatalf("find projection %d: %v", productID, err)
+	}
+	return projection
+}
+
+func TestProductProjectionLateUpdateDoesNotResurrectDeletedProduct(t *testing.T) {
+	db := openTestDB(t)
+	repo := &productProjectionRepository{db: db}
+	ctx := context.Background()
+
+	productID := uint(time.Now().UnixNano() % 1_000_000_000)
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id IN ?", []uint{productID, productID + 1}).Delete(&model.ProductProjection{})
+	})
+
+	base := time.Now().Truncate(time.Microsecond)
+	upsert := func(id uint, name string, at time.Time) {
+		t.Helper()
+		err := repo.UpsertProductProjections(ctx, []model.ProductProjection{{ProductID: id, Name: name, CategoryID: 1, SyncedAt: at}})
+		if err != nil {
+			t.Fatalf("upsert %s: %v", name, err)
+		}
+	}
+
+	upsert(productID, "v1", base)
+	if err := repo.DeleteProductProjection(ctx, productID, base.Add(2*time.Second)); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// Update yang terjadi sebelum delete tapi datang sesudahnya
+	upsert(productID, "late", base.Add(time.Second))
+	projection := findProjection(t, repo, productID)
+	if !projection.DeletedAt.Valid || projection.Name != "v1" {
+		t.Fatalf("late update resurrected the product: %+v", projection)
+	}
+
+	// Snapshot yang lebih baru dari delete (product di-restore) menghidupkan lagi barisnya
+	upsert(productID, "restored", base.Add(3*time.Second))
+	projection = findProjection(t, repo, productID)
+	if projection.DeletedAt.Valid || projection.Name != "restored" {
+		t.Fatalf("restored product not applied: %+v", projection)
+	}
+
+	// Delete yang datang lebih dulu dari create meninggalkan tombstone
+	if err := repo.DeleteProductProjection(ctx, productID+1, base.Add(2*time.Second)); err != nil {
+		t.Fatalf("delete before create: %v", err)
+	}
+	upsert(productID+1, "created", base)
+	projection = findProjection(t, repo, productID+1)
+	if !projection.DeletedAt.Valid {
+		t.Fatalf("create after delete resurrected the product: %+v", projection)
+	}
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"micro-warehouse/merchant-service/model"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// create, get by id, list, update status, update item, open merchant products
+type ReplenishmentOrderRepositoryInterface interface {
+	CreateReplenishmentOrder(ctx context.Context, replenishmentOrder *model.ReplenishmentOrder) error
+	GetReplenishmentOrderByID(ctx context.Context, id uint) (*model.ReplenishmentOrder, error)
+	GetReplenishmentOrders(ctx context.Context, merchantIDs []uint, status string, page, limit int) ([]model.ReplenishmentOrder, int64, error)
+	UpdateReplenishmentOrderStatus(ctx context.Context, id uint, fromStatus string, updates map[string]interface{}) error
+	UpdateReplenishmentOrderItem(ctx context.Context, item *model.ReplenishmentOrderItem) error
+	GetDraftMerchantProductIDs(ctx context.Context, merchantID uint) (map[uint]bool, error)
+}
+
+var ErrReplenishmentOrderInvalidStatus = errors.New("replenishment order status does not allow this action")
+
+type replenishmentOrderRepository struct {
+	db *gorm.DB
+}
+
+// CreateReplenishmentOrder implements ReplenishmentOrderRepositoryInterface.
+func (r *replenishmentOrderRepository) CreateReplenishmentOrder(ctx context.Context, replenishmentOrder *model.ReplenishmentOrder) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ReplenishmentOrderRepository] CreateReplenishmentOrder - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := r.db.WithContext(ctx).Create(replenishmentOrder).Error; err != nil {
+			log.Errorf("[ReplenishmentOrderRepository] CreateReplenishmentOrder - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetReplenishmentOrderByID implements ReplenishmentOrderRepositoryInterface.
+func (r *replenishmentOrderRepository) GetReplenishmentOrderByID(ctx context.Context, id uint) (*model.ReplenishmentOrder, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ReplenishmentOrderRepository] GetReplenishmentOrderByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var replenishmentOrder model.ReplenishmentOrder
+		if err := r.db.WithContext(ctx).
+			Preload("Merchant").
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+			Where("id = ?", id).
+			First(&replenishmentOrder).Error; err != nil {
+			log.Errorf("[ReplenishmentOrderRepository] GetReplenishmentOrderByID - 2: %v", err)
+			return nil, err
+		}
+
+		return &replenishmentOrder, nil
+	}
+}
+
+// GetReplenishmentOrders implements ReplenishmentOrderRepositoryInterface.
+// merchantIDs nil berarti semua merchant; slice kosong berarti tidak ada merchant yang boleh dilihat.
+func (r *replenishmentOrderRepository) GetReplenishmentOrders(ctx context.Context, merchantIDs []uint, status string, page int, limit int) ([]model.ReplenishmentOrder, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ReplenishmentOrderRepository] GetReplenishmentOrders - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		var totalRecords int64
+		replenishmentOrders := []model.ReplenishmentOrder{}
+
+		query := r.db.WithContext(ctx).Model(&model.ReplenishmentOrder{})
+
+		if merchantIDs != nil {
+			if len(merchantIDs) == 0 {
+				return replenishmentOrders, 0, nil
+			}
+			query = query.Where("merchant_id IN ?", merchantIDs)
+		}
+
+		if status != "" {
+			query = query.Where("status = ?", status)
+		}
+
+		if err := query.Count(&totalRecords).Error; err != nil {
+			log.Errorf("[ReplenishmentOrderRepository] GetReplenishmentOrders - 2: %v", err)
+			return nil, 0, err
+		}
+
+		offset := (page - 1) * limit
+		if err := query.Order("created_at DESC").
+			Preload("Merchant").
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+			Offset(offset).
+			Limit(limit).
+			Find(&replenishmentOrders).Error; err != nil {
+			log.Errorf("[ReplenishmentOrderRepository] GetReplenishmentOrders - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return replenishmentOrders, totalRecords, nil
+	}
+}
+
+// UpdateReplenishmentOrderStatus implements ReplenishmentOrderRepositoryInterface.
+// Update bersyarat pada status asal sehingga order tidak bisa di-approve dua kali.
+func (r *replenishmentOrderRepository) UpdateReplenishmentOrderStatus(ctx context.Context, id uint, fromStatus string, updates map[string]interface{}) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ReplenishmentOrderRepository] UpdateReplenishmentOrderStatus - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := r.db.WithContext(ctx).Model(&model.ReplenishmentOrder{}).
+			Where("id = ? AND status = ?", id, fromStatus).
+			Updates(updates)
+		if result.Error != nil {
+			log.Errorf("[ReplenishmentOrderRepository] UpdateReplenishmentOrderStatus - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrReplenishmentOrderInvalidStatus
+		}
+
+		return nil
+	}
+}
+
+// UpdateReplenishmentOrderItem implements ReplenishmentOrderRepositoryInterface.
+func (r *replenishmentOrderRepository) UpdateReplenishmentOrderItem(ctx context.Context, item *model.ReplenishmentOrderItem) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ReplenishmentOrderRepository] UpdateReplenishmentOrderItem - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := r.db.WithContext(ctx).Model(&model.ReplenishmentOrderItem{}).
+			Where("id = ?", item.ID).
+			Updates(map[string]interface{}{
+				"allocated_quantity": item.AllocatedQuantity,
+				"status":             item.Status,
+				"failure_reason":     item.FailureReason,
+			}).Error; err != nil {
+			log.Errorf("[ReplenishmentOrderRepository] UpdateReplenishmentOrderItem - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetDraftMerchantProductIDs implements ReplenishmentOrderRepositoryInterface.
+// Dipakai agar job terjadwal tidak membuat usulan ganda untuk produk yang draft-nya belum direview.
+func (r *replenishmentOrderRepository) GetDraftMerchantProductIDs(ctx context.Context, merchantID uint) (map[uint]bool, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ReplenishmentOrderRepository] GetDraftMerchantProductIDs - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var merchantProductIDs []uint
+		query := r.db.WithContext(ctx).Model(&model.ReplenishmentOrderItem{}).
+			Joins("JOIN replenishment_orders ON replenishment_orders.id = replenishment_order_items.replenishment_order_id").
+			Where("replenishment_orders.status = ?", model.ReplenishmentOrderStatusDraft)
+
+		if merchantID != 0 {
+			query = query.Where("replenishment_orders.merchant_id = ?", merchantID)
+		}
+
+		if err := query.Distinct().Pluck("replenishment_order_items.merchant_product_id", &merchantProductIDs).Error; err != nil {
+			log.Errorf("[ReplenishmentOrderRepository] GetDraftMerchantProductIDs - 2: %v", err)
+			return nil, err
+		}
+
+		result := make(map[uint]bool, len(merchantProductIDs))
+		for _, id := range merchantProductIDs {
+			result[id] = true
+		}
+
+		return result, nil
+	}
+}
+
+func NewReplenishmentOrderRepository(db *gorm.DB) ReplenishmentOrderRepositoryInterface {
+	return &replenishmentOrderRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// create, get by id, list, mark published
+type StockReturnRepositoryInterface interface {
+	CreateStockReturn(ctx context.Context, stockReturn *model.StockReturn) ([]model.MerchantStockMovement, error)
+	GetStockReturnByID(ctx context.Context, id uint) (*model.StockReturn, error)
+	GetStockReturns(ctx context.Context, merchantIDs []uint, page, limit int) ([]model.StockReturn, int64, error)
+	MarkStockReturnPublished(ctx context.Context, id uint) error
+}
+
+type stockReturnRepository struct {
+	db *gorm.DB
+}
+
+// CreateStockReturn implements StockReturnRepositoryInterface.
+// Setiap item mengurangi stock merchant secara atomik dan mencatat movement return_to_warehouse dengan
+// reference stock-return:{id}; WarehouseID item diisi dari warehouse asal merchant product.
+func (s *stockReturnRepository) CreateStockReturn(ctx context.Context, stockReturn *model.StockReturn) ([]model.MerchantStockMovement, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StockReturnRepository] CreateStockReturn - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		items := stockReturn.Items
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].ProductID < items[j].ProductID
+		})
+
+		tx := s.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[StockReturnRepository] CreateStockReturn - 2: %v", tx.Error)
+			return nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[StockReturnRepository] CreateStockReturn - 3: %v", r)
+			}
+		}()
+
+		if err := tx.Omit("Items").Create(stockReturn).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StockReturnRepository] CreateStockReturn - 4: %v", err)
+			return nil, err
+		}
+
+		meta := model.StockMovementMeta{
+			MovementType: model.StockMovementReturnToWarehouse,
+			ActorID:      stockReturn.ReturnedBy,
+			Reference:    stockReturn.Reference(),
+			Note:         stockReturn.Reason,
+		}
+
+		movements := make([]model.MerchantStockMovement, 0, len(items))
+		for i := range items {
+			item := &items[i]
+
+			var merchantProduct model.MerchantProduct
+			result := tx.Model(&merchantProduct).
+				Clauses(clause.Returning{}).
+				Where("merchant_id = ? AND product_id = ? AND stock >= ?", stockReturn.MerchantID, item.ProductID, item.Quantity).
+				Update("stock", gorm.Expr("stock - ?", item.Quantity))
+			if result.Error != nil {
+				tx.Rollback()
+				log.Errorf("[StockReturnRepository] CreateStockReturn - 5: %v", result.Error)
+				return nil, result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				tx.Rollback()
+				err := stockShortageError(s.db.WithContext(ctx), stockReturn.MerchantID, item.ProductID)
+				log.Errorf("[StockReturnRepository] CreateStockReturn - 6: product %d: %v", item.ProductID, err)
+				return nil, fmt.Errorf("product %d: %w", item.ProductID, err)
+			}
+
+			item.StockReturnID = stockReturn.ID
+			item.MerchantProductID = merchantProduct.ID
+			item.WarehouseID = merchantProduct.WarehouseID
+			if err := tx.Create(item).Error; err != nil {
+				tx.Rollback()
+				log.Errorf("[StockReturnRepository] CreateStockReturn - 7: %v", err)
+				return nil, err
+			}
+
+			movement, err := recordStockMovement(tx, &merchantProduct, -item.Quantity, meta)
+			if err != nil {
+				tx.Rollback()
+				log.Errorf("[StockReturnRepository] CreateStockReturn - 8: %v", err)
+				return nil, err
+			}
+			movements = append(movements, *movement)
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[StockReturnRepository] CreateStockReturn - 9: %v", err)
+			return nil, err
+		}
+
+		stockReturn.Items = items
+		return movements, nil
+	}
+}
+
+// GetStockReturnByID implements StockReturnRepositoryInterface.
+func (s *stockReturnRepository) GetStockReturnByID(ctx context.Context, id uint) (*model.StockReturn, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StockReturnRepository] GetStockReturnByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var stockReturn model.StockReturn
+		if err := s.db.WithContext(ctx).
+			Preload("Merchant").
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+			Where("id = ?", id).
+			First(&stockReturn).Error; err != nil {
+			log.Errorf("[StockReturnRepository] GetStockReturnByID - 2: %v", err)
+			return nil, err
+		}
+
+		return &stockReturn, nil
+	}
+}
+
+// GetStockReturns implements StockReturnRepositoryInterface.
+// merchantIDs nil berarti semua merchant; slice kosong berarti tidak ada merchant yang boleh dilihat.
+func (s *stockReturnRepository) GetStockReturns(ctx context.Context, merchantIDs []uint, page int, limit int) ([]model.StockReturn, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StockReturnRepository] GetStockReturns - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		var totalRecords int64
+		stockReturns := []model.StockReturn{}
+
+		query := s.db.WithContext(ctx).Model(&model.StockReturn{})
+
+		if merchantIDs != nil {
+			if len(merchantIDs) == 0 {
+				return stockReturns, 0, nil
+			}
+			query = query.Where("merchant_id IN ?", merchantIDs)
+		}
+
+		if err := query.Count(&totalRecords).Error; err != nil {
+			log.Errorf("[StockReturnRepository] GetStockReturns - 2: %v", err)
+			return nil, 0, err
+		}
+
+		offset := (page - 1) * limit
+		if err := query.Order("created_at DESC").
+			Preload("Merchant").
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+			Offset(offset).
+			Limit(limit).
+			Find(&stockReturns).Error; err != nil {
+			log.Errorf("[StockReturnRepository] GetStockReturns - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return stockReturns, totalRecords, nil
+	}
+}
+
+// MarkStockReturnPublished implements StockReturnRepositoryInterface.
+func (s *stockReturnRepository) MarkStockReturnPublished(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StockReturnRepository] MarkStockReturnPublished - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := s.db.WithContext(ctx).Model(&model.StockReturn{}).
+			Where("id = ?", id).
+			Update("published_at", time.Now()).Error; err != nil {
+			log.Errorf("[StockReturnRepository] MarkStockReturnPublished - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+func NewStockReturnRepository(db *gorm.DB) StockReturnRepositoryInterface {
+	return &stockReturnRepository{
+		db: db,
+	}
+}
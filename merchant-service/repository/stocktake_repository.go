@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"micro-warehouse/merchant-service/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// open, get by id, list, record count, approve, cancel
+type StocktakeRepositoryInterface interface {
+	CreateStocktake(ctx context.Context, stocktake *model.Stocktake) error
+	GetStocktakeByID(ctx context.Context, id uint) (*model.Stocktake, error)
+	GetStocktakes(ctx context.Context, merchantIDs []uint, status string, page, limit int) ([]model.Stocktake, int64, error)
+	RecordCount(ctx context.Context, stocktakeID, productID uint, quantity int, replace bool, countedBy uint, reasonCode string) (*model.StocktakeLine, error)
+	ApproveStocktake(ctx context.Context, id, approvedBy uint, defaultReasonCode, note string) ([]model.MerchantStockMovement, error)
+	CancelStocktake(ctx context.Context, id uint) error
+}
+
+var (
+	ErrStocktakeAlreadyOpen    = errors.New("merchant already has an open stocktake")
+	ErrStocktakeInvalidStatus  = errors.New("stocktake status does not allow this action")
+	ErrStocktakeLineNotFound   = errors.New("product is not part of this stocktake")
+	ErrStocktakeInvalidCount   = errors.New("counted quantity cannot be negative")
+	ErrStocktakeNothingToCount = errors.New("no merchant products match this stocktake")
+)
+
+type stocktakeRepository struct {
+	db *gorm.DB
+}
+
+// CreateStocktake implements StocktakeRepositoryInterface.
+// Baris merchant dikunci supaya dua sesi tidak dibuka bersamaan; stock saat ini dibekukan sebagai expected_stock.
+// CategoryID diisi berarti produk disaring lewat product_projections; produk yang belum tersinkron tidak ikut.
+func (s *stocktakeRepository) CreateStocktake(ctx context.Context, stocktake *model.Stocktake) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StocktakeRepository] CreateStocktake - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		tx := s.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[StocktakeRepository] CreateStocktake - 2: %v", tx.Error)
+			return tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[StocktakeRepository] CreateStocktake - 3: %v", r)
+			}
+		}()
+
+		var merchant model.Merchant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", stocktake.MerchantID).
+			First(&merchant).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] CreateStocktake - 4: %v", err)
+			return err
+		}
+
+		var openCount int64
+		if err := tx.Model(&model.Stocktake{}).
+			Where("merchant_id = ? AND status = ?", stocktake.MerchantID, model.StocktakeStatusOpen).
+			Count(&openCount).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] CreateStocktake - 5: %v", err)
+			return err
+		}
+
+		if openCount > 0 {
+			tx.Rollback()
+			return ErrStocktakeAlreadyOpen
+		}
+
+		var merchantProducts []model.MerchantProduct
+		query := tx.Where("merchant_products.merchant_id = ?", stocktake.MerchantID)
+		if stocktake.CategoryID != 0 {
+			query = query.
+				Joins("JOIN product_projections ON product_projections.product_id = merchant_products.product_id AND product_projections.deleted_at IS NULL").
+				Where("product_projections.category_id = ?", stocktake.CategoryID)
+		}
+		if err := query.Order("merchant_products.product_id ASC").Find(&merchantProducts).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] CreateStocktake - 6: %v", err)
+			return err
+		}
+
+		if len(merchantProducts) == 0 {
+			tx.Rollback()
+			return ErrStocktakeNothingToCount
+		}
+
+		stocktake.Status = model.StocktakeStatusOpen
+		if err := tx.Omit("Lines", "Merchant").Create(stocktake).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] CreateStocktake - 7: %v", err)
+			return err
+		}
+
+		lines := make([]model.StocktakeLine, 0, len(merchantProducts))
+		for _, merchantProduct := range merchantProducts {
+			lines = append(lines, model.StocktakeLine{
+				StocktakeID:       stocktake.ID,
+				MerchantProductID: merchantProduct.ID,
+				ProductID:         merchantProduct.ProductID,
+				ExpectedStock:     merchantProduct.Stock,
+			})
+		}
+
+		if err := tx.Create(&lines).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] CreateStocktake - 8: %v", err)
+			return err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[StocktakeRepository] CreateStocktake - 9: %v", err)
+			return err
+		}
+
+		stocktake.Merchant = merchant
+		stocktake.Lines = lines
+		return nil
+	}
+}
+
+// GetStocktakeByID implements StocktakeRepositoryInterface.
+func (s *stocktakeRepository) GetStocktakeByID(ctx context.Context, id uint) (*model.Stocktake, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StocktakeRepository] GetStocktakeByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var stocktake model.Stocktake
+		if err := s.db.WithContext(ctx).
+			Preload("Merchant").
+			Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("product_id ASC") }).
+			Where("id = ?", id).
+			First(&stocktake).Error; err != nil {
+			log.Errorf("[StocktakeRepository] GetStocktakeByID - 2: %v", err)
+			return nil, err
+		}
+
+		return &stocktake, nil
+	}
+}
+
+// GetStocktakes implements StocktakeRepositoryInterface.
+// merchantIDs nil berarti semua merchant; slice kosong berarti tidak ada merchant yang boleh dilihat.
+// Lines tidak di-preload pada listing; detail diambil lewat GetStocktakeByID.
+func (s *stocktakeRepository) GetStocktakes(ctx context.Context, merchantIDs []uint, status string, page int, limit int) ([]model.Stocktake, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StocktakeRepository] GetStocktakes - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		var totalRecords int64
+		stocktakes := []model.Stocktake{}
+
+		query := s.db.WithContext(ctx).Model(&model.Stocktake{})
+
+		if merchantIDs != nil {
+			if len(merchantIDs) == 0 {
+				return stocktakes, 0, nil
+			}
+			query = query.Where("merchant_id IN ?", merchantIDs)
+		}
+
+		if status != "" {
+			query = query.Where("status = ?", status)
+		}
+
+		if err := query.Count(&totalRecords).Error; err != nil {
+			log.Errorf("[StocktakeRepository] GetStocktakes - 2: %v", err)
+			return nil, 0, err
+		}
+
+		offset := (page - 1) * limit
+		if err := query.Order("created_at DESC").
+			Preload("Merchant").
+			Offset(offset).
+			Limit(limit).
+			Find(&stocktakes).Error; err != nil {
+			log.Errorf("[StocktakeRepository] GetStocktakes - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return stocktakes, totalRecords, nil
+	}
+}
+
+// RecordCount implements StocktakeRepositoryInterface.
+// replace=false menambahkan quantity ke hitungan sebelumnya (scan berulang), replace=true menimpanya.
+func (s *stocktakeRepository) RecordCount(ctx context.Context, stocktakeID uint, productID uint, quantity int, replace bool, countedBy uint, reasonCode string) (*model.StocktakeLine, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StocktakeRepository] RecordCount - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		tx := s.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[StocktakeRepository] RecordCount - 2: %v", tx.Error)
+			return nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[StocktakeRepository] RecordCount - 3: %v", r)
+			}
+		}()
+
+		// Header dikunci FOR SHARE supaya hitungan tidak masuk bersamaan dengan approve/cancel
+		var stocktake model.Stocktake
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+			Where("id = ?", stocktakeID).
+			First(&stocktake).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] RecordCount - 4: %v", err)
+			return nil, err
+		}
+
+		if stocktake.Status != model.StocktakeStatusOpen {
+			tx.Rollback()
+			return nil, ErrStocktakeInvalidStatus
+		}
+
+		var line model.StocktakeLine
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("stocktake_id = ? AND product_id = ?", stocktakeID, productID).
+			First(&line).Error; err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrStocktakeLineNotFound
+			}
+			log.Errorf("[StocktakeRepository] RecordCount - 5: %v", err)
+			return nil, err
+		}
+
+		counted := quantity
+		if !replace && line.CountedQuantity != nil {
+			counted += *line.CountedQuantity
+		}
+
+		if counted < 0 {
+			tx.Rollback()
+			return nil, ErrStocktakeInvalidCount
+		}
+
+		now := time.Now()
+		line.CountedQuantity = &counted
+		line.CountedBy = countedBy
+		line.CountedAt = &now
+		updates := map[string]interface{}{
+			"counted_quantity": counted,
+			"counted_by":       countedBy,
+			"counted_at":       now,
+		}
+		if reasonCode != "" {
+			line.ReasonCode = reasonCode
+			updates["reason_code"] = reasonCode
+		}
+
+		if err := tx.Model(&line).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] RecordCount - 6: %v", err)
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[StocktakeRepository] RecordCount - 7: %v", err)
+			return nil, err
+		}
+
+		return &line, nil
+	}
+}
+
+// ApproveStocktake implements StocktakeRepositoryInterface.
+// Selisih (counted - expected) diterapkan ke stock saat ini sehingga penjualan selama sesi tetap terhitung.
+// Stock tidak pernah dibuat negatif; selisih yang benar-benar diterapkan disimpan di adjusted_delta.
+func (s *stocktakeRepository) ApproveStocktake(ctx context.Context, id uint, approvedBy uint, defaultReasonCode string, note string) ([]model.MerchantStockMovement, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StocktakeRepository] ApproveStocktake - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		tx := s.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[StocktakeRepository] ApproveStocktake - 2: %v", tx.Error)
+			return nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[StocktakeRepository] ApproveStocktake - 3: %v", r)
+			}
+		}()
+
+		var stocktake model.Stocktake
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&stocktake).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] ApproveStocktake - 4: %v", err)
+			return nil, err
+		}
+
+		if stocktake.Status != model.StocktakeStatusOpen {
+			tx.Rollback()
+			return nil, ErrStocktakeInvalidStatus
+		}
+
+		var lines []model.StocktakeLine
+		if err := tx.Where("stocktake_id = ? AND counted_quantity IS NOT NULL", id).
+			Order("product_id ASC").
+			Find(&lines).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] ApproveStocktake - 5: %v", err)
+			return nil, err
+		}
+
+		movements := []model.MerchantStockMovement{}
+		for i := range lines {
+			line := &lines[i]
+			variance := line.Variance()
+			if variance == 0 {
+				continue
+			}
+
+			reasonCode := line.ReasonCode
+			if reasonCode == "" {
+				reasonCode = defaultReasonCode
+			}
+
+			var merchantProduct model.MerchantProduct
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", line.MerchantProductID).
+				First(&merchantProduct).Error; err != nil {
+				tx.Rollback()
+				log.Errorf("[StocktakeRepository] ApproveStocktake - 6: %v", err)
+				return nil, err
+			}
+
+			delta := variance
+			if merchantProduct.Stock+delta < 0 {
+				delta = -merchantProduct.Stock
+			}
+
+			updates := map[string]interface{}{
+				"reason_code":    reasonCode,
+				"adjusted_delta": delta,
+			}
+
+			if delta != 0 {
+				merchantProduct.Stock += delta
+				if err := tx.Model(&merchantProduct).Update("stock", merchantProduct.Stock).Error; err != nil {
+					tx.Rollback()
+					log.Errorf("[StocktakeRepository] ApproveStocktake - 7: %v", err)
+					return nil, err
+				}
+
+				movement, err := recordStockMovement(tx, &merchantProduct, delta, model.StockMovementMeta{
+					MovementType: model.StockMovementStocktake,
+					ActorID:      approvedBy,
+					Reference:    stocktake.Reference(),
+					Note:         reasonCode,
+				})
+				if err != nil {
+					tx.Rollback()
+					log.Errorf("[StocktakeRepository] ApproveStocktake - 8: %v", err)
+					return nil, err
+				}
+				movements = append(movements, *movement)
+				updates["movement_id"] = movement.ID
+			}
+
+			if err := tx.Model(line).Updates(updates).Error; err != nil {
+				tx.Rollback()
+				log.Errorf("[StocktakeRepository] ApproveStocktake - 9: %v", err)
+				return nil, err
+			}
+		}
+
+		stocktakeUpdates := map[string]interface{}{
+			"status":      model.StocktakeStatusApproved,
+			"approved_by": approvedBy,
+			"approved_at": time.Now(),
+		}
+		if note != "" {
+			stocktakeUpdates["note"] = note
+		}
+
+		if err := tx.Model(&stocktake).Updates(stocktakeUpdates).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[StocktakeRepository] ApproveStocktake - 10: %v", err)
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[StocktakeRepository] ApproveStocktake - 11: %v", err)
+			return nil, err
+		}
+
+		return movements, nil
+	}
+}
+
+// CancelStocktake implements StocktakeRepositoryInterface.
+func (s *stocktakeRepository) CancelStocktake(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[StocktakeRepository] CancelStocktake - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := s.db.WithContext(ctx).Model(&model.Stocktake{}).
+			Where("id = ? AND status = ?", id, model.StocktakeStatusOpen).
+			Update("status", model.StocktakeStatusCancelled)
+		if result.Error != nil {
+			log.Errorf("[StocktakeRepository] CancelStocktake - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			var count int64
+			if err := s.db.WithContext(ctx).Model(&model.Stocktake{}).Where("id = ?", id).Count(&count).Error; err != nil {
+				log.Errorf("[StocktakeRepository] CancelStocktake - 3: %v", err)
+				return err
+			}
+			if count == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			return ErrStocktakeInvalidStatus
+		}
+
+		return nil
+	}
+}
+
+func NewStocktakeRepository(db *gorm.DB) StocktakeRepositoryInterface {
+	return &stocktakeRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// create, get by id, list, update status, ship, receive
+type TransferOrderRepositoryInterface interface {
+	CreateTransferOrder(ctx context.Context, transferOrder *model.TransferOrder) error
+	GetTransferOrderByID(ctx context.Context, id uint) (*model.TransferOrder, error)
+	GetTransferOrders(ctx context.Context, merchantIDs []uint, warehouseID uint, status string, page, limit int) ([]model.TransferOrder, int64, error)
+	UpdateTransferOrderStatus(ctx context.Context, id uint, fromStatuses []string, updates map[string]interface{}) error
+	ShipTransferOrder(ctx context.Context, id uint, shippedQuantities map[uint]int, shippedBy uint) error
+	ReceiveTransferOrder(ctx context.Context, id uint, receipt *model.TransferOrderReceipt) (*model.TransferOrder, error)
+}
+
+var (
+	ErrTransferOrderInvalidStatus   = errors.New("transfer order status does not allow this action")
+	ErrTransferOrderInvalidQuantity = errors.New("invalid transfer order quantity")
+)
+
+type transferOrderRepository struct {
+	db *gorm.DB
+}
+
+// CreateTransferOrder implements TransferOrderRepositoryInterface.
+func (t *transferOrderRepository) CreateTransferOrder(ctx context.Context, transferOrder *model.TransferOrder) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransferOrderRepository] CreateTransferOrder - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if err := t.db.WithContext(ctx).Create(transferOrder).Error; err != nil {
+			log.Errorf("[TransferOrderRepository] CreateTransferOrder - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetTransferOrderByID implements TransferOrderRepositoryInterface.
+func (t *transferOrderRepository) GetTransferOrderByID(ctx context.Context, id uint) (*model.TransferOrder, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransferOrderRepository] GetTransferOrderByID - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var transferOrder model.TransferOrder
+		if err := t.db.WithContext(ctx).
+			Preload("Merchant").
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+			Preload("Receipts", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+			Preload("Receipts.Lines").
+			Where("id = ?", id).
+			First(&transferOrder).Error; err != nil {
+			log.Errorf("[TransferOrderRepository] GetTransferOrderByID - 2: %v", err)
+			return nil, err
+		}
+
+		return &transferOrder, nil
+	}
+}
+
+// GetTransferOrders implements TransferOrderRepositoryInterface.
+// merchantIDs nil berarti semua merchant; slice kosong berarti tidak ada merchant yang boleh dilihat.
+func (t *transferOrderRepository) GetTransferOrders(ctx context.Context, merchantIDs []uint, warehouseID uint, status string, page int, limit int) ([]model.TransferOrder, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransferOrderRepository] GetTransferOrders - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		var totalRecords int64
+		transferOrders := []model.TransferOrder{}
+
+		query := t.db.WithContext(ctx).Model(&model.TransferOrder{})
+
+		if merchantIDs != nil {
+			if len(merchantIDs) == 0 {
+				return transferOrders, 0, nil
+			}
+			query = query.Where("merchant_id IN ?", merchantIDs)
+		}
+
+		if warehouseID != 0 {
+			query = query.Where("warehouse_id = ?", warehouseID)
+		}
+
+		if status != "" {
+			query = query.Where("status = ?", status)
+		}
+
+		if err := query.Count(&totalRecords).Error; err != nil {
+			log.Errorf("[TransferOrderRepository] GetTransferOrders - 2: %v", err)
+			return nil, 0, err
+		}
+
+		offset := (page - 1) * limit
+		if err := query.Order("created_at DESC").
+			Preload("Merchant").
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+			Offset(offset).
+			Limit(limit).
+			Find(&transferOrders).Error; err != nil {
+			log.Errorf("[TransferOrderRepository] GetTransferOrders - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return transferOrders, totalRecords, nil
+	}
+}
+
+// UpdateTransferOrderStatus implements TransferOrderRepositoryInterface.
+// Update bersyarat pada status asal sehingga dua aksi bersamaan tidak bisa sama-sama berhasil.
+func (t *transferOrderRepository) UpdateTransferOrderStatus(ctx context.Context, id uint, fromStatuses []string, updates map[string]interface{}) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransferOrderRepository] UpdateTransferOrderStatus - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := t.db.WithContext(ctx).Model(&model.TransferOrder{}).
+			Where("id = ? AND status IN ?", id, fromStatuses).
+			Updates(updates)
+		if result.Error != nil {
+			log.Errorf("[TransferOrderRepository] UpdateTransferOrderStatus - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrTransferOrderInvalidStatus
+		}
+
+		return nil
+	}
+}
+
+// ShipTransferOrder implements TransferOrderRepositoryInterface.
+func (t *transferOrderRepository) ShipTransferOrder(ctx context.Context, id uint, shippedQuantities map[uint]int, shippedBy uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransferOrderRepository] ShipTransferOrder - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		tx := t.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[TransferOrderRepository] ShipTransferOrder - 2: %v", tx.Error)
+			return tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[TransferOrderRepository] ShipTransferOrder - 3: %v", r)
+			}
+		}()
+
+		now := time.Now()
+		result := tx.Model(&model.TransferOrder{}).
+			Where("id = ? AND status = ?", id, model.TransferOrderStatusPicked).
+			Updates(map[string]interface{}{
+				"status":     model.TransferOrderStatusShipped,
+				"shipped_by": shippedBy,
+				"shipped_at": now,
+			})
+		if result.Error != nil {
+			tx.Rollback()
+			log.Errorf("[TransferOrderRepository] ShipTransferOrder - 4: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return ErrTransferOrderInvalidStatus
+		}
+
+		for productID, quantity := range shippedQuantities {
+			if err := tx.Model(&model.TransferOrderItem{}).
+				Where("transfer_order_id = ? AND product_id = ?", id, productID).
+				Update("shipped_quantity", quantity).Error; err != nil {
+				tx.Rollback()
+				log.Errorf("[TransferOrderRepository] ShipTransferOrder - 5: %v", err)
+				return err
+			}
+		}
+
+		return tx.Commit().Error
+	}
+}
+
+// ReceiveTransferOrder implements TransferOrderRepositoryInterface.
+// Jumlah yang diterima langsung menambah stock merchant (dibuatkan MerchantProduct jika belum ada),
+// sedangkan selisih hanya dicatat. Order selesai ketika semua item tidak punya sisa outstanding.
+func (t *transferOrderRepository) ReceiveTransferOrder(ctx context.Context, id uint, receipt *model.TransferOrderReceipt) (*model.TransferOrder, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		tx := t.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 2: %v", tx.Error)
+			return nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 3: %v", r)
+			}
+		}()
+
+		var transferOrder model.TransferOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&transferOrder).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 4: %v", err)
+			return nil, err
+		}
+
+		if transferOrder.Status != model.TransferOrderStatusShipped && transferOrder.Status != model.TransferOrderStatusPartiallyReceived {
+			tx.Rollback()
+			return nil, ErrTransferOrderInvalidStatus
+		}
+
+		var items []model.TransferOrderItem
+		if err := tx.Where("transfer_order_id = ?", id).Find(&items).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 5: %v", err)
+			return nil, err
+		}
+
+		itemsByProduct := make(map[uint]*model.TransferOrderItem, len(items))
+		for i := range items {
+			itemsByProduct[items[i].ProductID] = &items[i]
+		}
+
+		meta := model.StockMovementMeta{
+			MovementType: model.StockMovementTransferIn,
+			ActorID:      receipt.ReceivedBy,
+			Reference:    fmt.Sprintf("transfer-order:%d", id),
+			Note:         receipt.Note,
+		}
+
+		for _, line := range receipt.Lines {
+			item, ok := itemsByProduct[line.ProductID]
+			if !ok {
+				tx.Rollback()
+				return nil, fmt.Errorf("%w: product %d is not part of this transfer order", ErrTransferOrderInvalidQuantity, line.ProductID)
+			}
+
+			if line.ReceivedQuantity+line.DiscrepancyQuantity > item.OutstandingQuantity() {
+				tx.Rollback()
+				return nil, fmt.Errorf("%w: product %d has only %d outstanding", ErrTransferOrderInvalidQuantity, line.ProductID, item.OutstandingQuantity())
+			}
+
+			item.ReceivedQuantity += line.ReceivedQuantity
+			item.DiscrepancyQuantity += line.DiscrepancyQuantity
+			if err := tx.Model(item).Updates(map[string]interface{}{
+				"received_quantity":    item.ReceivedQuantity,
+				"discrepancy_quantity": item.DiscrepancyQuantity,
+			}).Error; err != nil {
+				tx.Rollback()
+				log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 6: %v", err)
+				return nil, err
+			}
+
+			if line.ReceivedQuantity > 0 {
+				if err := creditMerchantStock(tx, transferOrder.MerchantID, transferOrder.WarehouseID, line.ProductID, line.ReceivedQuantity, meta); err != nil {
+					tx.Rollback()
+					log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 7: %v", err)
+					return nil, err
+				}
+			}
+		}
+
+		receipt.TransferOrderID = id
+		if err := tx.Create(receipt).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 8: %v", err)
+			return nil, err
+		}
+
+		status := model.TransferOrderStatusReceived
+		for _, item := range items {
+			if item.OutstandingQuantity() > 0 {
+				status = model.TransferOrderStatusPartiallyReceived
+				break
+			}
+		}
+
+		updates := map[string]interface{}{"status": status}
+		if status == model.TransferOrderStatusReceived {
+			updates["received_at"] = time.Now()
+		}
+
+		if err := tx.Model(&transferOrder).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 9: %v", err)
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[TransferOrderRepository] ReceiveTransferOrder - 10: %v", err)
+			return nil, err
+		}
+
+		return t.GetTransferOrderByID(ctx, id)
+	}
+}
+
+// creditMerchantStock menambah stock merchant product di dalam transaksi yang sedang berjalan
+func creditMerchantStock(tx *gorm.DB, merchantID, warehouseID, productID uint, quantity int, meta model.StockMovementMeta) error {
+	var merchantProduct model.MerchantProduct
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("merchant_id = ? AND product_id = ?", merchantID, productID).
+		First(&merchantProduct).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		merchantProduct = model.MerchantProduct{
+			MerchantID:  merchantID,
+			ProductID:   productID,
+			WarehouseID: warehouseID,
+			Stock:       quantity,
+		}
+		if err := tx.Create(&merchantProduct).Error; err != nil {
+			return err
+		}
+	} else {
+		merchantProduct.Stock += quantity
+		if err := tx.Model(&merchantProduct).Update("stock", merchantProduct.Stock).Error; err != nil {
+			return err
+		}
+	}
+
+	_, err = recordStockMovement(tx, &merchantProduct, quantity, meta)
+	return err
+}
+
+func NewTransferOrderRepository(db *gorm.DB) TransferOrderRepositoryInterface {
+	return &transferOrderRepository{db: db}
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/geo"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/repository"
+	"sort"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	AvailabilitySortStock    = "stock"
+	AvailabilitySortDistance = "distance"
+)
+
+// AvailabilityUsecaseInterface mencari stock satu product di merchant lain dan di semua warehouse
+type AvailabilityUsecaseInterface interface {
+	GetProductAvailability(ctx context.Context, query AvailabilityQuery) (*ProductAvailability, error)
+}
+
+var ErrInvalidAvailabilityQuery = errors.New("invalid availability query")
+
+// AvailabilityQuery: product dicari lewat ProductID atau Barcode. Titik asal untuk jarak diambil
+// dari Origin, atau dari koordinat OriginMerchantID; merchant asal tidak ikut di hasil.
+type AvailabilityQuery struct {
+	ProductID        uint
+	Barcode          string
+	OriginMerchantID uint
+	Origin           *geo.Point
+	SortBy           string
+}
+
+type ProductAvailability struct {
+	Product    *httpclient.ProductResponse
+	Origin     *geo.Point
+	SortBy     string
+	Merchants  []MerchantAvailability
+	Warehouses []WarehouseAvailability
+}
+
+// DistanceKm nil jika titik asal atau koordinat lokasi belum diisi
+type MerchantAvailability struct {
+	MerchantProduct model.MerchantProduct
+	DistanceKm      *float64
+}
+
+type WarehouseAvailability struct {
+	Stock      httpclient.ProductWarehouseStockResponse
+	DistanceKm *float64
+}
+
+type availabilityUsecase struct {
+	merchantProductRepo repository.MerchantProductRepositoryInterface
+	merchantRepo        repository.MerchantRepositoryInterface
+	barcodeRuleRepo     repository.BarcodeRuleRepositoryInterface
+	productClient       httpclient.ProductClientInterface
+	warehouseClient     httpclient.WarehouseClientInterface
+}
+
+// GetProductAvailability implements AvailabilityUsecaseInterface.
+// Tanpa sort distance hasil diurutkan dari stock terbanyak; dengan sort distance lokasi tanpa koordinat
+// diletakkan di akhir.
+func (a *availabilityUsecase) GetProductAvailability(ctx context.Context, query AvailabilityQuery) (*ProductAvailability, error) {
+	if query.SortBy == "" {
+		query.SortBy = AvailabilitySortStock
+	}
+
+	origin := query.Origin
+	if origin == nil && query.OriginMerchantID != 0 {
+		merchant, err := a.merchantRepo.GetMerchantByID(ctx, query.OriginMerchantID)
+		if err != nil {
+			log.Errorf("[AvailabilityUsecase] GetProductAvailability - 1: %v", err)
+			return nil, err
+		}
+
+		if point, ok := merchant.Coordinates(); ok {
+			origin = &point
+		}
+	}
+
+	if query.SortBy == AvailabilitySortDistance && origin == nil {
+		return nil, fmt.Errorf("%w: sorting by distance needs latitude/longitude or a merchant_id with coordinates", ErrInvalidAvailabilityQuery)
+	}
+
+	product, err := a.resolveProduct(ctx, query)
+	if err != nil {
+		log.Errorf("[AvailabilityUsecase] GetProductAvailability - 2: %v", err)
+		return nil, err
+	}
+
+	merchantProducts, err := a.merchantProductRepo.GetMerchantStocksByProductID(ctx, product.ID)
+	if err != nil {
+		log.Errorf("[AvailabilityUsecase] GetProductAvailability - 3: %v", err)
+		return nil, err
+	}
+
+	warehouseStocks, err := a.warehouseClient.GetProductWarehouseStocks(ctx, product.ID)
+	if err != nil {
+		log.Errorf("[AvailabilityUsecase] GetProductAvailability - 4: %v", err)
+		return nil, err
+	}
+
+	availability := ProductAvailability{
+		Product:    product,
+		Origin:     origin,
+		SortBy:     query.SortBy,
+		Merchants:  []MerchantAvailability{},
+		Warehouses: []WarehouseAvailability{},
+	}
+
+	for _, merchantProduct := range merchantProducts {
+		if merchantProduct.MerchantID == query.OriginMerchantID {
+			continue
+		}
+
+		item := MerchantAvailability{MerchantProduct: merchantProduct}
+		if point, ok := merchantProduct.Merchant.Coordinates(); ok && origin != nil {
+			distance := geo.DistanceKm(*origin, point)
+			item.DistanceKm = &distance
+		}
+		availability.Merchants = append(availability.Merchants, item)
+	}
+
+	for _, stock := range warehouseStocks {
+		if stock.Stock <= 0 {
+			continue
+		}
+
+		item := WarehouseAvailability{Stock: stock}
+		if stock.Latitude != nil && stock.Longitude != nil && origin != nil {
+			distance := geo.DistanceKm(*origin, geo.Point{Latitude: *stock.Latitude, Longitude: *stock.Longitude})
+			item.DistanceKm = &distance
+		}
+		availability.Warehouses = append(availability.Warehouses, item)
+	}
+
+	byDistance := query.SortBy == AvailabilitySortDistance
+	sort.SliceStable(availability.Merchants, func(i, j int) bool {
+		left, right := availability.Merchants[i], availability.Merchants[j]
+		return availabilityLess(byDistance, left.DistanceKm, right.DistanceKm, left.MerchantProduct.Stock, right.MerchantProduct.Stock)
+	})
+	sort.SliceStable(availability.Warehouses, func(i, j int) bool {
+		left, right := availability.Warehouses[i], availability.Warehouses[j]
+		return availabilityLess(byDistance, left.DistanceKm, right.DistanceKm, left.Stock.Stock, right.Stock.Stock)
+	})
+
+	return &availability, nil
+}
+
+// resolveProduct memakai product_id jika ada; barcode label timbangan dicari lewat item code-nya
+func (a *availabilityUsecase) resolveProduct(ctx context.Context, query AvailabilityQuery) (*httpclient.ProductResponse, error) {
+	if query.ProductID != 0 {
+		return a.productClient.GetProductByID(ctx, query.ProductID)
+	}
+
+	rules, err := a.barcodeRuleRepo.GetBarcodeRules(ctx, true)
+	if err != nil {
+		log.Errorf("[AvailabilityUsecase] resolveProduct - 1: %v", err)
+		return nil, err
+	}
+
+	scale, err := matchScaleBarcode(rules, query.Barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	lookupCode := query.Barcode
+	if scale != nil {
+		lookupCode = scale.ItemCode
+	}
+
+	return a.productClient.GetProductByBarcode(ctx, lookupCode)
+}
+
+// availabilityLess: urut jarak terdekat (yang tanpa jarak di akhir) lalu stock terbanyak
+func availabilityLess(byDistance bool, leftDistance, rightDistance *float64, leftStock, rightStock int) bool {
+	if byDistance {
+		switch {
+		case leftDistance != nil && rightDistance == nil:
+			return true
+		case leftDistance == nil && rightDistance != nil:
+			return false
+		case leftDistance != nil && rightDistance != nil && *leftDistance != *rightDistance:
+			return *leftDistance < *rightDistance
+		}
+	}
+
+	return leftStock > rightStock
+}
+
+func NewAvailabilityUsecase(merchantProductRepo repository.MerchantProductRepositoryInterface, merchantRepo repository.MerchantRepositoryInterface, barcodeRuleRepo repository.BarcodeRuleRepositoryInterface, productClient httpclient.ProductClientInterface, warehouseClient httpclient.WarehouseClientInterface) AvailabilityUsecaseInterface {
+	return &availabilityUsecase{
+		merchantProductRepo: merchantProductRepo,
+		merchantRepo:        merchantRepo,
+		barcodeRuleRepo:     barcodeRuleRepo,
+		productClient:       productClient,
+		warehouseClient:     warehouseClient,
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// BarcodeRuleUsecaseInterface mengelola aturan parsing label timbangan (GS1 variable measure)
+type BarcodeRuleUsecaseInterface interface {
+	GetBarcodeRules(ctx context.Context) ([]model.BarcodeRule, error)
+	CreateBarcodeRule(ctx context.Context, rule *model.BarcodeRule) error
+	UpdateBarcodeRule(ctx context.Context, rule *model.BarcodeRule) (*model.BarcodeRule, error)
+	DeleteBarcodeRule(ctx context.Context, id uint) error
+}
+
+var ErrInvalidBarcodeRule = errors.New("invalid barcode rule")
+
+type barcodeRuleUsecase struct {
+	barcodeRuleRepo repository.BarcodeRuleRepositoryInterface
+}
+
+// GetBarcodeRules implements BarcodeRuleUsecaseInterface.
+func (b *barcodeRuleUsecase) GetBarcodeRules(ctx context.Context) ([]model.BarcodeRule, error) {
+	rules, err := b.barcodeRuleRepo.GetBarcodeRules(ctx, false)
+	if err != nil {
+		log.Errorf("[BarcodeRuleUsecase] GetBarcodeRules - 1: %v", err)
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// CreateBarcodeRule implements BarcodeRuleUsecaseInterface.
+func (b *barcodeRuleUsecase) CreateBarcodeRule(ctx context.Context, rule *model.BarcodeRule) error {
+	if err := validateBarcodeRule(rule); err != nil {
+		return err
+	}
+
+	if err := b.barcodeRuleRepo.CreateBarcodeRule(ctx, rule); err != nil {
+		log.Errorf("[BarcodeRuleUsecase] CreateBarcodeRule - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateBarcodeRule implements BarcodeRuleUsecaseInterface.
+func (b *barcodeRuleUsecase) UpdateBarcodeRule(ctx context.Context, rule *model.BarcodeRule) (*model.BarcodeRule, error) {
+	if err := validateBarcodeRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := b.barcodeRuleRepo.UpdateBarcodeRule(ctx, rule); err != nil {
+		log.Errorf("[BarcodeRuleUsecase] UpdateBarcodeRule - 1: %v", err)
+		return nil, err
+	}
+
+	return b.barcodeRuleRepo.GetBarcodeRuleByID(ctx, rule.ID)
+}
+
+// DeleteBarcodeRule implements BarcodeRuleUsecaseInterface.
+func (b *barcodeRuleUsecase) DeleteBarcodeRule(ctx context.Context, id uint) error {
+	if err := b.barcodeRuleRepo.DeleteBarcodeRule(ctx, id); err != nil {
+		log.Errorf("[BarcodeRuleUsecase] DeleteBarcodeRule - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// validateBarcodeRule memastikan susunan digit rule tepat mengisi EAN-13
+func validateBarcodeRule(rule *model.BarcodeRule) error {
+	for _, digit := range rule.Prefix {
+		if digit < '0' || digit > '9' {
+			return fmt.Errorf("%w: prefix must contain digits only", ErrInvalidBarcodeRule)
+		}
+	}
+
+	if rule.Length() != model.EAN13Length {
+		return fmt.Errorf("%w: prefix, item code, value and check digits must add up to %d digits, got %d",
+			ErrInvalidBarcodeRule, model.EAN13Length, rule.Length())
+	}
+
+	if rule.HasValueCheckDigit && !model.SupportsValueCheckDigit(rule.ValueLength) {
+		return fmt.Errorf("%w: value check digit is only defined for 4 or 5 digit values", ErrInvalidBarcodeRule)
+	}
+
+	if rule.Decimals > rule.ValueLength {
+		return fmt.Errorf("%w: decimals cannot exceed value_length", ErrInvalidBarcodeRule)
+	}
+
+	return nil
+}
+
+func NewBarcodeRuleUsecase(barcodeRuleRepo repository.BarcodeRuleRepositoryInterface) BarcodeRuleUsecaseInterface {
+	return &barcodeRuleUsecase{
+		barcodeRuleRepo: barcodeRuleRepo,
+	}
+}
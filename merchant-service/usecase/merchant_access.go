@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// authorizeMerchantAccess: manager boleh mengakses semua merchant, selain itu hanya merchant tempat user
+// sedang aktif bertugas menurut roster staff
+func authorizeMerchantAccess(ctx context.Context, merchantRepo repository.MerchantRepositoryInterface, identity authz.Identity, merchantID uint) error {
+	if identity.IsManager() {
+		return nil
+	}
+
+	isStaff, err := merchantRepo.IsActiveStaff(ctx, merchantID, identity.UserID)
+	if err != nil {
+		log.Errorf("[MerchantAccess] authorizeMerchantAccess - 1: %v", err)
+		return err
+	}
+
+	if !isStaff {
+		return authz.ErrForbidden
+	}
+
+	return nil
+}
+
+// scopeMerchantIDs menentukan filter merchant untuk listing: nil berarti semua merchant (manager tanpa filter),
+// staff selalu dibatasi ke merchant tempat ia aktif bertugas
+func scopeMerchantIDs(ctx context.Context, merchantRepo repository.MerchantRepositoryInterface, identity authz.Identity, merchantID uint) ([]uint, error) {
+	var merchantIDs []uint
+	if merchantID != 0 {
+		merchantIDs = []uint{merchantID}
+	}
+
+	if identity.IsManager() {
+		return merchantIDs, nil
+	}
+
+	merchants, err := merchantRepo.GetMerchantsByStaffUserID(ctx, identity.UserID)
+	if err != nil {
+		log.Errorf("[MerchantAccess] scopeMerchantIDs - 1: %v", err)
+		return nil, err
+	}
+
+	keptMerchantIDs := make([]uint, 0, len(merchants))
+	for _, merchant := range merchants {
+		keptMerchantIDs = append(keptMerchantIDs, merchant.ID)
+	}
+
+	if merchantID == 0 {
+		return keptMerchantIDs, nil
+	}
+
+	for _, id := range keptMerchantIDs {
+		if id == merchantID {
+			return merchantIDs, nil
+		}
+	}
+
+	return nil, authz.ErrForbidden
+}
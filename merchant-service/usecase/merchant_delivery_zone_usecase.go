@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/geo"
+	"micro-warehouse/merchant-service/repository"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// MerchantDeliveryZoneUsecaseInterface mengelola zona pengiriman merchant dan memilih merchant yang melayani sebuah titik
+type MerchantDeliveryZoneUsecaseInterface interface {
+	GetDeliveryZones(ctx context.Context, merchantID uint) ([]model.MerchantDeliveryZone, error)
+	CreateDeliveryZone(ctx context.Context, zone *model.MerchantDeliveryZone, polygon geo.Polygon) error
+	UpdateDeliveryZone(ctx context.Context, zone *model.MerchantDeliveryZone, polygon geo.Polygon) error
+	DeleteDeliveryZone(ctx context.Context, merchantID, id uint) error
+	RouteDelivery(ctx context.Context, point geo.Point) (*DeliveryRoute, error)
+}
+
+var (
+	ErrInvalidDeliveryZone = errors.New("invalid delivery zone")
+	ErrLocationNotServed   = errors.New("no merchant delivers to this location")
+)
+
+// DeliveryRoute: Candidates berisi satu zona per merchant yang polygon-nya memuat Point, dengan merchant
+// terpilih di urutan pertama
+type DeliveryRoute struct {
+	Point      geo.Point
+	Candidates []DeliveryCandidate
+}
+
+// DistanceKm nil jika koordinat merchant belum diisi
+type DeliveryCandidate struct {
+	Zone         model.MerchantDeliveryZone
+	DistanceKm   *float64
+	IsOpen       bool
+	ClosedReason string
+}
+
+type merchantDeliveryZoneUsecase struct {
+	deliveryZoneRepo repository.MerchantDeliveryZoneRepositoryInterface
+}
+
+// GetDeliveryZones implements MerchantDeliveryZoneUsecaseInterface.
+func (m *merchantDeliveryZoneUsecase) GetDeliveryZones(ctx context.Context, merchantID uint) ([]model.MerchantDeliveryZone, error) {
+	zones, err := m.deliveryZoneRepo.GetDeliveryZones(ctx, merchantID)
+	if err != nil {
+		log.Errorf("[MerchantDeliveryZoneUsecase] GetDeliveryZones - 1: %v", err)
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+// CreateDeliveryZone implements MerchantDeliveryZoneUsecaseInterface.
+func (m *merchantDeliveryZoneUsecase) CreateDeliveryZone(ctx context.Context, zone *model.MerchantDeliveryZone, polygon geo.Polygon) error {
+	if err := setDeliveryZonePolygon(zone, polygon); err != nil {
+		return err
+	}
+
+	if err := m.deliveryZoneRepo.CreateDeliveryZone(ctx, zone); err != nil {
+		log.Errorf("[MerchantDeliveryZoneUsecase] CreateDeliveryZone - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateDeliveryZone implements MerchantDeliveryZoneUsecaseInterface.
+func (m *merchantDeliveryZoneUsecase) UpdateDeliveryZone(ctx context.Context, zone *model.MerchantDeliveryZone, polygon geo.Polygon) error {
+	if err := setDeliveryZonePolygon(zone, polygon); err != nil {
+		return err
+	}
+
+	if err := m.deliveryZoneRepo.UpdateDeliveryZone(ctx, zone); err != nil {
+		log.Errorf("[MerchantDeliveryZoneUsecase] UpdateDeliveryZone - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteDeliveryZone implements MerchantDeliveryZoneUsecaseInterface.
+func (m *merchantDeliveryZoneUsecase) DeleteDeliveryZone(ctx context.Context, merchantID uint, id uint) error {
+	if err := m.deliveryZoneRepo.DeleteDeliveryZone(ctx, merchantID, id); err != nil {
+		log.Errorf("[MerchantDeliveryZoneUsecase] DeleteDeliveryZone - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RouteDelivery implements MerchantDeliveryZoneUsecaseInterface.
+// Jika beberapa zona tumpang tindih, merchant yang sedang buka didahulukan, lalu yang terdekat dari titik
+// (merchant tanpa koordinat di akhir), lalu zona yang dibuat lebih dulu.
+func (m *merchantDeliveryZoneUsecase) RouteDelivery(ctx context.Context, point geo.Point) (*DeliveryRoute, error) {
+	zones, err := m.deliveryZoneRepo.GetDeliveryZoneCandidates(ctx, point)
+	if err != nil {
+		log.Errorf("[MerchantDeliveryZoneUsecase] RouteDelivery - 1: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	candidates := []DeliveryCandidate{}
+	for _, zone := range zones {
+		if !zone.Polygon.Contains(point) {
+			continue
+		}
+
+		candidate := DeliveryCandidate{Zone: zone}
+		candidate.IsOpen, candidate.ClosedReason = zone.Merchant.IsOpenAt(now)
+		if merchantPoint, ok := zone.Merchant.Coordinates(); ok {
+			distance := geo.DistanceKm(point, merchantPoint)
+			candidate.DistanceKm = &distance
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrLocationNotServed
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		left, right := candidates[i], candidates[j]
+		if left.IsOpen != right.IsOpen {
+			return left.IsOpen
+		}
+
+		return availabilityLess(true, left.DistanceKm, right.DistanceKm, 0, 0)
+	})
+
+	// Zona sudah urut berdasarkan id sehingga zona pertama tiap merchant yang dipertahankan
+	route := DeliveryRoute{Point: point, Candidates: []DeliveryCandidate{}}
+	seen := map[uint]bool{}
+	for _, candidate := range candidates {
+		if seen[candidate.Zone.MerchantID] {
+			continue
+		}
+		seen[candidate.Zone.MerchantID] = true
+		route.Candidates = append(route.Candidates, candidate)
+	}
+
+	return &route, nil
+}
+
+// setDeliveryZonePolygon menormalkan dan memvalidasi polygon sebelum disimpan bersama bounding box-nya
+func setDeliveryZonePolygon(zone *model.MerchantDeliveryZone, polygon geo.Polygon) error {
+	polygon = polygon.Normalize()
+	if err := polygon.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDeliveryZone, err)
+	}
+
+	zone.SetPolygon(polygon)
+	return nil
+}
+
+func NewMerchantDeliveryZoneUsecase(deliveryZoneRepo repository.MerchantDeliveryZoneRepositoryInterface) MerchantDeliveryZoneUsecaseInterface {
+	return &merchantDeliveryZoneUsecase{
+		deliveryZoneRepo: deliveryZoneRepo,
+	}
+}
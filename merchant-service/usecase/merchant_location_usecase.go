@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/geo"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/repository"
+	"sort"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	defaultNearbyMerchantsLimit   = 20
+	defaultNearestWarehousesLimit = 5
+)
+
+// MerchantLocationUsecaseInterface menjawab pertanyaan jarak: merchant dalam radius tertentu dan warehouse terdekat
+type MerchantLocationUsecaseInterface interface {
+	GetNearbyMerchants(ctx context.Context, query NearbyMerchantsQuery) ([]NearbyMerchant, error)
+	GetNearestWarehouses(ctx context.Context, merchantID uint, limit int) (*model.Merchant, []NearbyWarehouse, error)
+}
+
+var ErrMerchantWithoutCoordinates = errors.New("merchant has no latitude/longitude")
+
+type NearbyMerchantsQuery struct {
+	Origin   geo.Point
+	RadiusKm float64
+	Status   string
+	OpenNow  bool
+	Limit    int
+}
+
+type NearbyMerchant struct {
+	Merchant   model.Merchant
+	DistanceKm float64
+}
+
+type NearbyWarehouse struct {
+	Warehouse  httpclient.WarehouseLocationResponse
+	DistanceKm float64
+}
+
+type merchantLocationUsecase struct {
+	merchantRepo    repository.MerchantRepositoryInterface
+	warehouseClient httpclient.WarehouseClientInterface
+}
+
+// GetNearbyMerchants implements MerchantLocationUsecaseInterface.
+// Hasil diurutkan dari yang terdekat; merchant tanpa koordinat tidak pernah ikut.
+func (m *merchantLocationUsecase) GetNearbyMerchants(ctx context.Context, query NearbyMerchantsQuery) ([]NearbyMerchant, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultNearbyMerchantsLimit
+	}
+
+	merchants, err := m.merchantRepo.GetMerchantsWithinBounds(ctx, geo.BoundingBoxAround(query.Origin, query.RadiusKm), query.Status, query.OpenNow)
+	if err != nil {
+		log.Errorf("[MerchantLocationUsecase] GetNearbyMerchants - 1: %v", err)
+		return nil, err
+	}
+
+	nearby := []NearbyMerchant{}
+	for _, merchant := range merchants {
+		point, ok := merchant.Coordinates()
+		if !ok {
+			continue
+		}
+
+		distance := geo.DistanceKm(query.Origin, point)
+		if distance > query.RadiusKm {
+			continue
+		}
+		nearby = append(nearby, NearbyMerchant{Merchant: merchant, DistanceKm: distance})
+	}
+
+	sort.SliceStable(nearby, func(i, j int) bool {
+		if nearby[i].DistanceKm != nearby[j].DistanceKm {
+			return nearby[i].DistanceKm < nearby[j].DistanceKm
+		}
+		return nearby[i].Merchant.ID < nearby[j].Merchant.ID
+	})
+
+	if len(nearby) > query.Limit {
+		nearby = nearby[:query.Limit]
+	}
+
+	return nearby, nil
+}
+
+// GetNearestWarehouses implements MerchantLocationUsecaseInterface.
+// Warehouse pertama adalah yang terdekat; warehouse tanpa koordinat atau yang diarsip tidak ikut.
+func (m *merchantLocationUsecase) GetNearestWarehouses(ctx context.Context, merchantID uint, limit int) (*model.Merchant, []NearbyWarehouse, error) {
+	if limit <= 0 {
+		limit = defaultNearestWarehousesLimit
+	}
+
+	merchant, err := m.merchantRepo.GetMerchantByID(ctx, merchantID)
+	if err != nil {
+		log.Errorf("[MerchantLocationUsecase] GetNearestWarehouses - 1: %v", err)
+		return nil, nil, err
+	}
+
+	origin, ok := merchant.Coordinates()
+	if !ok {
+		return nil, nil, ErrMerchantWithoutCoordinates
+	}
+
+	locations, err := m.warehouseClient.GetWarehouseLocations(ctx)
+	if err != nil {
+		log.Errorf("[MerchantLocationUsecase] GetNearestWarehouses - 2: %v", err)
+		return nil, nil, err
+	}
+
+	warehouses := make([]NearbyWarehouse, 0, len(locations))
+	for _, location := range locations {
+		distance := geo.DistanceKm(origin, geo.Point{Latitude: location.Latitude, Longitude: location.Longitude})
+		warehouses = append(warehouses, NearbyWarehouse{Warehouse: location, DistanceKm: distance})
+	}
+
+	sort.SliceStable(warehouses, func(i, j int) bool {
+		if warehouses[i].DistanceKm != warehouses[j].DistanceKm {
+			return warehouses[i].DistanceKm < warehouses[j].DistanceKm
+		}
+		return warehouses[i].Warehouse.ID < warehouses[j].Warehouse.ID
+	})
+
+	if len(warehouses) > limit {
+		warehouses = warehouses[:limit]
+	}
+
+	return merchant, warehouses, nil
+}
+
+func NewMerchantLocationUsecase(merchantRepo repository.MerchantRepositoryInterface, warehouseClient httpclient.WarehouseClientInterface) MerchantLocationUsecaseInterface {
+	return &merchantLocationUsecase{
+		merchantRepo:    merchantRepo,
+		warehouseClient: warehouseClient,
+	}
+}
@@ -3,7 +3,9 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
 	"micro-warehouse/merchant-service/pkg/httpclient"
 	"micro-warehouse/merchant-service/pkg/rabbitmq"
 	"micro-warehouse/merchant-service/repository"
@@ -14,49 +16,259 @@ import (
 
 // CRUD, get by barcode, delete all by product ID, get product total stocks
 type MerchantProductUsecaseInterface interface {
-	CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error
+	CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, actorID uint) error
 	GetMerchantProductByID(ctx context.Context, id uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error)
-	GetMerchantProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID, productID uint) ([]model.MerchantProduct, []httpclient.ProductResponse, []httpclient.WarehouseResponse, int64, error)
-	GetMerchantProductByBarcode(ctx context.Context, barcode string, merchantID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error)
-	UpdateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error
+	GetMerchantProducts(ctx context.Context, filter repository.MerchantProductFilter) ([]model.MerchantProduct, []httpclient.ProductResponse, []httpclient.WarehouseResponse, int64, error)
+	GetMerchantProductByBarcode(ctx context.Context, barcode string, merchantID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, *model.ScaleBarcode, error)
+	ResolveBarcodes(ctx context.Context, barcodes []string, merchantID uint) ([]BarcodeResolution, error)
+	UpdateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, actorID uint) error
 	DeleteMerchantProduct(ctx context.Context, id uint) error
 	DeleteAllProductMerchantProducts(ctx context.Context, productID uint) error
 
 	GetProductTotalStock(ctx context.Context, productID uint) (int, error)
+	GetWarehouseAllocationSummary(ctx context.Context, warehouseID uint) (*model.StockSummary, error)
+
+	// Stock ledger
+	AllocateStock(ctx context.Context, merchantProductID, warehouseID uint, quantity int, meta model.StockMovementMeta) error
+	RecordStockMovement(ctx context.Context, merchantProductID uint, movementType string, quantity int, actorID uint, reference, note string) (*model.MerchantStockMovement, error)
+	GetStockMovements(ctx context.Context, merchantProductID uint, startDate, endDate *time.Time, movementType string, page, limit int) ([]model.MerchantStockMovement, int64, error)
+
+	// Low stock
+	UpdateMinStock(ctx context.Context, merchantProductID uint, minStock int) (*model.MerchantProduct, error)
+	GetLowStockMerchantProducts(ctx context.Context, merchantID uint, page, limit int) ([]model.MerchantProduct, []httpclient.ProductResponse, int64, error)
+
+	// Replenishment levels
+	UpdateStockLevels(ctx context.Context, merchantProductID uint, minStock, maxStock int) (*model.MerchantProduct, error)
+
+	// Price overrides
+	SetPrice(ctx context.Context, merchantProductID uint, price *int64, effectiveFrom *time.Time, actorID uint) (*model.MerchantProductPrice, error)
+	GetPrices(ctx context.Context, merchantProductID uint) ([]model.MerchantProductPrice, error)
+	DeleteScheduledPrice(ctx context.Context, merchantProductID, priceID uint) error
+
+	// Trash
+	GetDeletedMerchantProducts(ctx context.Context, merchantID, productID uint, page, limit int) ([]model.MerchantProduct, int64, error)
+	RestoreMerchantProduct(ctx context.Context, id uint) error
+
+	// Allocation outbox
+	GetAllocationChanges(ctx context.Context, merchantID uint, unpublishedOnly, failedOnly bool, page, limit int) ([]model.MerchantAllocationChange, int64, error)
+	RepublishAllocationChange(ctx context.Context, identity authz.Identity, id uint) (*model.MerchantAllocationChange, error)
+}
+
+var (
+	ErrInvalidStockMovement = errors.New("invalid stock movement")
+	ErrInvalidStockLevels   = errors.New("max stock must be greater than min stock")
+	ErrInvalidPrice         = errors.New("invalid price")
+	ErrInvalidBarcode       = errors.New("invalid barcode")
+)
+
+const (
+	BarcodeResolved   = "resolved"
+	BarcodeInvalid    = "invalid_barcode"
+	BarcodeUnknown    = "unknown_barcode"
+	BarcodeNotStocked = "not_stocked"
+)
+
+// BarcodeResolution adalah hasil resolve satu barcode dari sesi scan.
+// MerchantProduct hanya terisi jika Status BarcodeResolved.
+type BarcodeResolution struct {
+	Barcode         string
+	Status          string
+	MerchantProduct *model.MerchantProduct
+	Product         *httpclient.ProductResponse
+	Warehouse       *httpclient.WarehouseResponse
+	Scale           *model.ScaleBarcode
 }
 
 type merchantProductUsecase struct {
 	merchantProductRepo repository.MerchantProductRepositoryInterface
+	priceRepo           repository.MerchantProductPriceRepositoryInterface
+	barcodeRuleRepo     repository.BarcodeRuleRepositoryInterface
 	productClient       httpclient.ProductClientInterface
 	warehouseClient     httpclient.WarehouseClientInterface
 	rabbitMQServuce     *rabbitmq.RabbitMQService
+	stockAlert          StockAlertUsecaseInterface
 }
 
 // GetMerchantProductByBarcode implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) GetMerchantProductByBarcode(ctx context.Context, barcode string, merchantID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error) {
-	product, err := m.productClient.GetProductByBarcode(ctx, barcode)
+// Label timbangan dicari berdasarkan item code (PLU); barcode lain dicocokkan apa adanya.
+func (m *merchantProductUsecase) GetMerchantProductByBarcode(ctx context.Context, barcode string, merchantID uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, *model.ScaleBarcode, error) {
+	scale, err := m.parseScaleBarcode(ctx, barcode)
 	if err != nil {
 		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 1: %v", err)
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	merchantProduct, err := m.merchantProductRepo.GetMerchantProductByProductIDAndMerchantID(ctx, product.ID, merchantID)
+	lookupCode := barcode
+	if scale != nil {
+		lookupCode = scale.ItemCode
+	}
+
+	product, err := m.productClient.GetProductByBarcode(ctx, lookupCode)
 	if err != nil {
 		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 2: %v", err)
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	warehouse, err := m.warehouseClient.GetWarehouseByID(ctx, merchantProduct.WarehouseID)
+	merchantProduct, err := m.merchantProductRepo.GetMerchantProductByProductIDAndMerchantID(ctx, product.ID, merchantID)
 	if err != nil {
 		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 3: %v", err)
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	return merchantProduct, product, warehouse, nil
+	warehouse, err := m.warehouseClient.GetWarehouseByID(ctx, merchantProduct.WarehouseID)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 4: %v", err)
+		return nil, nil, nil, nil, err
+	}
+
+	if err := m.attachCurrentPrices(ctx, []*model.MerchantProduct{merchantProduct}); err != nil {
+		log.Errorf("[MerchantProductUsecase] GetMerchantProductByBarcode - 5: %v", err)
+		return nil, nil, nil, nil, err
+	}
+
+	return merchantProduct, product, warehouse, scale, nil
+}
+
+// ResolveBarcodes implements MerchantProductUsecaseInterface.
+// Semua barcode di-resolve dengan satu request ke product-service, satu query merchant product,
+// dan satu lookup per warehouse yang berbeda. Urutan hasil mengikuti urutan barcode yang dikirim.
+func (m *merchantProductUsecase) ResolveBarcodes(ctx context.Context, barcodes []string, merchantID uint) ([]BarcodeResolution, error) {
+	rules, err := m.barcodeRuleRepo.GetBarcodeRules(ctx, true)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] ResolveBarcodes - 1: %v", err)
+		return nil, err
+	}
+
+	resolutions := make([]BarcodeResolution, len(barcodes))
+	lookupCodes := make([]string, len(barcodes))
+	uniqueCodes := []string{}
+	seenCodes := make(map[string]bool)
+	for i, barcode := range barcodes {
+		resolutions[i] = BarcodeResolution{Barcode: barcode, Status: BarcodeUnknown}
+
+		scale, err := matchScaleBarcode(rules, barcode)
+		if err != nil {
+			resolutions[i].Status = BarcodeInvalid
+			continue
+		}
+
+		lookupCodes[i] = barcode
+		if scale != nil {
+			resolutions[i].Scale = scale
+			lookupCodes[i] = scale.ItemCode
+		}
+
+		if !seenCodes[lookupCodes[i]] {
+			seenCodes[lookupCodes[i]] = true
+			uniqueCodes = append(uniqueCodes, lookupCodes[i])
+		}
+	}
+
+	if len(uniqueCodes) == 0 {
+		return resolutions, nil
+	}
+
+	products, err := m.productClient.GetProductsByBarcodes(ctx, uniqueCodes)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] ResolveBarcodes - 2: %v", err)
+		return nil, err
+	}
+
+	productByCode := make(map[string]httpclient.ProductResponse)
+	productIDs := make([]uint, 0, len(products))
+	for _, product := range products {
+		productByCode[product.Barcode] = product
+		productIDs = append(productIDs, product.ID)
+	}
+
+	merchantProducts, err := m.merchantProductRepo.GetMerchantProductsByProductIDs(ctx, merchantID, productIDs)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] ResolveBarcodes - 3: %v", err)
+		return nil, err
+	}
+
+	merchantProductPtrs := make([]*model.MerchantProduct, 0, len(merchantProducts))
+	merchantProductByProductID := make(map[uint]*model.MerchantProduct)
+	for i := range merchantProducts {
+		merchantProductPtrs = append(merchantProductPtrs, &merchantProducts[i])
+		merchantProductByProductID[merchantProducts[i].ProductID] = &merchantProducts[i]
+	}
+
+	if err := m.attachCurrentPrices(ctx, merchantProductPtrs); err != nil {
+		log.Errorf("[MerchantProductUsecase] ResolveBarcodes - 4: %v", err)
+		return nil, err
+	}
+
+	// warehouse yang gagal diambil tidak menggagalkan seluruh sesi scan
+	warehouses := make(map[uint]*httpclient.WarehouseResponse)
+	for _, merchantProduct := range merchantProducts {
+		if _, exists := warehouses[merchantProduct.WarehouseID]; exists {
+			continue
+		}
+
+		warehouse, err := m.warehouseClient.GetWarehouseByID(ctx, merchantProduct.WarehouseID)
+		if err != nil {
+			log.Errorf("[MerchantProductUsecase] ResolveBarcodes - 5: %v", err)
+		}
+		warehouses[merchantProduct.WarehouseID] = warehouse
+	}
+
+	for i := range resolutions {
+		if resolutions[i].Status == BarcodeInvalid {
+			continue
+		}
+
+		product, exists := productByCode[lookupCodes[i]]
+		if !exists {
+			continue
+		}
+		resolutions[i].Product = &product
+
+		merchantProduct, exists := merchantProductByProductID[product.ID]
+		if !exists {
+			resolutions[i].Status = BarcodeNotStocked
+			continue
+		}
+
+		resolutions[i].Status = BarcodeResolved
+		resolutions[i].MerchantProduct = merchantProduct
+		resolutions[i].Warehouse = warehouses[merchantProduct.WarehouseID]
+	}
+
+	return resolutions, nil
+}
+
+// parseScaleBarcode mencari rule timbangan aktif yang cocok dengan barcode.
+// Hasil nil tanpa error berarti barcode biasa.
+func (m *merchantProductUsecase) parseScaleBarcode(ctx context.Context, barcode string) (*model.ScaleBarcode, error) {
+	rules, err := m.barcodeRuleRepo.GetBarcodeRules(ctx, true)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] parseScaleBarcode - 1: %v", err)
+		return nil, err
+	}
+
+	return matchScaleBarcode(rules, barcode)
+}
+
+func matchScaleBarcode(rules []model.BarcodeRule, barcode string) (*model.ScaleBarcode, error) {
+	for _, rule := range rules {
+		if !rule.Matches(barcode) {
+			continue
+		}
+
+		scale, ok := rule.Parse(barcode)
+		if !ok {
+			return nil, fmt.Errorf("%w: check digit mismatch for %s", ErrInvalidBarcode, barcode)
+		}
+
+		return &scale, nil
+	}
+
+	return nil, nil
 }
 
 // CreateMerchantProduct implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error {
+func (m *merchantProductUsecase) CreateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, actorID uint) error {
 	warehouseProductStock, err := m.warehouseClient.GetWarehouseProductStock(ctx, merchantProduct.WarehouseID, merchantProduct.ProductID)
 	if err != nil {
 		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 1: %v", err)
@@ -68,22 +280,20 @@ func (m *merchantProductUsecase) CreateMerchantProduct(ctx context.Context, merc
 		return errors.New("stock not enough")
 	}
 
-	if err := m.merchantProductRepo.CreateMerchantProduct(ctx, merchantProduct); err != nil {
-		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 3: %v", err)
-		return err
+	meta := model.StockMovementMeta{
+		MovementType: model.StockMovementTransferIn,
+		ActorID:      actorID,
+		Reference:    fmt.Sprintf("warehouse:%d", merchantProduct.WarehouseID),
+		Note:         "initial allocation from warehouse",
 	}
 
-	stockReductionEvent := rabbitmq.StockReductionEvent{
-		WarhouseID: merchantProduct.WarehouseID,
-		ProductID:  merchantProduct.ProductID,
-		Stock:      merchantProduct.Stock,
-		MerchantID: merchantProduct.MerchantID,
-		Timestamp:  time.Now(),
+	change, err := m.merchantProductRepo.CreateMerchantProduct(ctx, merchantProduct, meta)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 3: %v", err)
+		return err
 	}
 
-	if err := m.rabbitMQServuce.PublishStockReductionEvent(ctx, stockReductionEvent); err != nil {
-		log.Errorf("[MerchantProductUsecase] CreateMerchantProduct - 4: %v", err)
-	}
+	m.publishAllocationChange(ctx, change)
 
 	return nil
 }
@@ -98,6 +308,21 @@ func (m *merchantProductUsecase) DeleteMerchantProduct(ctx context.Context, id u
 	return m.merchantProductRepo.DeleteMerchantProduct(ctx, id)
 }
 
+// GetDeletedMerchantProducts implements MerchantProductUsecaseInterface.
+func (m *merchantProductUsecase) GetDeletedMerchantProducts(ctx context.Context, merchantID, productID uint, page, limit int) ([]model.MerchantProduct, int64, error) {
+	return m.merchantProductRepo.GetDeletedMerchantProducts(ctx, merchantID, productID, page, limit)
+}
+
+// RestoreMerchantProduct implements MerchantProductUsecaseInterface.
+func (m *merchantProductUsecase) RestoreMerchantProduct(ctx context.Context, id uint) error {
+	if _, err := m.merchantProductRepo.RestoreMerchantProduct(ctx, id); err != nil {
+		log.Errorf("[MerchantProductUsecase] RestoreMerchantProduct - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 // GetMerchantProductByID implements MerchantProductUsecaseInterface.
 func (m *merchantProductUsecase) GetMerchantProductByID(ctx context.Context, id uint) (*model.MerchantProduct, *httpclient.ProductResponse, *httpclient.WarehouseResponse, error) {
 	merchantProduct, err := m.merchantProductRepo.GetMerchantProductByID(ctx, id)
@@ -118,17 +343,31 @@ func (m *merchantProductUsecase) GetMerchantProductByID(ctx context.Context, id
 		return nil, nil, nil, err
 	}
 
+	if err := m.attachCurrentPrices(ctx, []*model.MerchantProduct{merchantProduct}); err != nil {
+		log.Errorf("[MerchantProductUsecase] GetMerchantProductByID - 4: %v", err)
+		return nil, nil, nil, err
+	}
+
 	return merchantProduct, product, warehouse, nil
 }
 
 // GetMerchantProducts implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) GetMerchantProducts(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint, productID uint) ([]model.MerchantProduct, []httpclient.ProductResponse, []httpclient.WarehouseResponse, int64, error) {
-	merchantProducts, total, err := m.merchantProductRepo.GetMerchantProducts(ctx, page, limit, search, sortBy, sortOrder, merchantID, productID)
+func (m *merchantProductUsecase) GetMerchantProducts(ctx context.Context, filter repository.MerchantProductFilter) ([]model.MerchantProduct, []httpclient.ProductResponse, []httpclient.WarehouseResponse, int64, error) {
+	merchantProducts, total, err := m.merchantProductRepo.GetMerchantProducts(ctx, filter)
 	if err != nil {
 		log.Errorf("[MerchantProductUsecase] GetMerchantProducts - 1: %v", err)
 		return nil, nil, nil, 0, err
 	}
 
+	merchantProductRefs := make([]*model.MerchantProduct, 0, len(merchantProducts))
+	for i := range merchantProducts {
+		merchantProductRefs = append(merchantProductRefs, &merchantProducts[i])
+	}
+	if err := m.attachCurrentPrices(ctx, merchantProductRefs); err != nil {
+		log.Errorf("[MerchantProductUsecase] GetMerchantProducts - 4: %v", err)
+		return nil, nil, nil, 0, err
+	}
+
 	var products []httpclient.ProductResponse
 	var warehouses []httpclient.WarehouseResponse
 
@@ -158,27 +397,355 @@ func (m *merchantProductUsecase) GetProductTotalStock(ctx context.Context, produ
 	return m.merchantProductRepo.GetProductTotalStock(ctx, productID)
 }
 
+// GetWarehouseAllocationSummary implements MerchantProductUsecaseInterface.
+func (m *merchantProductUsecase) GetWarehouseAllocationSummary(ctx context.Context, warehouseID uint) (*model.StockSummary, error) {
+	return m.merchantProductRepo.GetWarehouseAllocationSummary(ctx, warehouseID)
+}
+
 // UpdateMerchantProduct implements MerchantProductUsecaseInterface.
-func (m *merchantProductUsecase) UpdateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct) error {
+// Warehouse hanya perlu menutup selisih alokasi. Stock yang sudah di rak tidak dipindah saat supplier diganti:
+// hanya penambahan yang diambil dari warehouse baru, dan pengurangan ditolak oleh repository.
+func (m *merchantProductUsecase) UpdateMerchantProduct(ctx context.Context, merchantProduct *model.MerchantProduct, actorID uint) error {
 	warehouseProductStock, err := m.warehouseClient.GetWarehouseProductStock(ctx, merchantProduct.WarehouseID, merchantProduct.ProductID)
 	if err != nil {
 		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 1: %v", err)
 		return err
 	}
 
-	if warehouseProductStock.Stock < merchantProduct.Stock {
-		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 2: %v", errors.New("stock not enough"))
+	existingMerchantProduct, err := m.merchantProductRepo.GetMerchantProductByID(ctx, merchantProduct.ID)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 2: %v", err)
+		return err
+	}
+
+	required := merchantProduct.Stock - existingMerchantProduct.Stock
+	if existingMerchantProduct.WarehouseID != merchantProduct.WarehouseID && required < 0 {
+		return repository.ErrSupplierChangeReducesStock
+	}
+
+	if required > 0 && warehouseProductStock.Stock < required {
+		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 3: %v", errors.New("stock not enough"))
 		return errors.New("stock not enough")
 	}
 
-	return m.merchantProductRepo.UpdateMerchantProduct(ctx, merchantProduct)
+	meta := model.StockMovementMeta{
+		MovementType: model.StockMovementAdjustment,
+		ActorID:      actorID,
+		Reference:    fmt.Sprintf("warehouse:%d", merchantProduct.WarehouseID),
+		Note:         "allocation updated",
+	}
+
+	previousMerchantProduct, change, err := m.merchantProductRepo.UpdateMerchantProduct(ctx, merchantProduct, meta)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] UpdateMerchantProduct - 4: %v", err)
+		return err
+	}
+
+	m.publishAllocationChange(ctx, change)
+
+	if merchantProduct.Stock < previousMerchantProduct.Stock {
+		go m.stockAlert.CheckLowStock(context.Background(), merchantProduct.ID, previousMerchantProduct.Stock, merchantProduct.Stock)
+	}
+
+	return nil
+}
+
+// AllocateStock implements MerchantProductUsecaseInterface.
+// Stock merchant ditambah sebesar quantity dari warehouseID; supplier merchant product tidak berubah.
+func (m *merchantProductUsecase) AllocateStock(ctx context.Context, merchantProductID uint, warehouseID uint, quantity int, meta model.StockMovementMeta) error {
+	merchantProduct, err := m.merchantProductRepo.GetMerchantProductByID(ctx, merchantProductID)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] AllocateStock - 1: %v", err)
+		return err
+	}
+
+	warehouseProductStock, err := m.warehouseClient.GetWarehouseProductStock(ctx, warehouseID, merchantProduct.ProductID)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] AllocateStock - 2: %v", err)
+		return err
+	}
+
+	if warehouseProductStock.Stock < quantity {
+		return repository.ErrStockNotEnough
+	}
+
+	change, err := m.merchantProductRepo.AllocateStock(ctx, merchantProductID, warehouseID, quantity, meta)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] AllocateStock - 3: %v", err)
+		return err
+	}
+
+	m.publishAllocationChange(ctx, change)
+
+	return nil
+}
+
+// RecordStockMovement implements MerchantProductUsecaseInterface.
+// adjustment memakai quantity bertanda, return selalu menambah dan write_off selalu mengurangi stock.
+func (m *merchantProductUsecase) RecordStockMovement(ctx context.Context, merchantProductID uint, movementType string, quantity int, actorID uint, reference string, note string) (*model.MerchantStockMovement, error) {
+	delta := quantity
+	switch movementType {
+	case model.StockMovementAdjustment:
+	case model.StockMovementReturn:
+		delta = absInt(quantity)
+	case model.StockMovementWriteOff:
+		delta = -absInt(quantity)
+	default:
+		return nil, fmt.Errorf("%w: movement type %s cannot be recorded manually", ErrInvalidStockMovement, movementType)
+	}
+
+	if delta == 0 {
+		return nil, fmt.Errorf("%w: quantity must not be zero", ErrInvalidStockMovement)
+	}
+
+	movement, err := m.merchantProductRepo.AdjustStock(ctx, merchantProductID, delta, model.StockMovementMeta{
+		MovementType: movementType,
+		ActorID:      actorID,
+		Reference:    reference,
+		Note:         note,
+	})
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] RecordStockMovement - 1: %v", err)
+		return nil, err
+	}
+
+	go m.stockAlert.CheckMovements(context.Background(), []model.MerchantStockMovement{*movement})
+
+	return movement, nil
+}
+
+// GetStockMovements implements MerchantProductUsecaseInterface.
+func (m *merchantProductUsecase) GetStockMovements(ctx context.Context, merchantProductID uint, startDate *time.Time, endDate *time.Time, movementType string, page int, limit int) ([]model.MerchantStockMovement, int64, error) {
+	if _, err := m.merchantProductRepo.GetMerchantProductByID(ctx, merchantProductID); err != nil {
+		log.Errorf("[MerchantProductUsecase] GetStockMovements - 1: %v", err)
+		return nil, 0, err
+	}
+
+	movements, total, err := m.merchantProductRepo.GetStockMovements(ctx, merchantProductID, startDate, endDate, movementType, page, limit)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] GetStockMovements - 2: %v", err)
+		return nil, 0, err
+	}
+
+	return movements, total, nil
+}
+
+// UpdateMinStock implements MerchantProductUsecaseInterface.
+// min_stock 0 berarti threshold dimatikan.
+func (m *merchantProductUsecase) UpdateMinStock(ctx context.Context, merchantProductID uint, minStock int) (*model.MerchantProduct, error) {
+	merchantProduct, err := m.merchantProductRepo.UpdateMinStock(ctx, merchantProductID, minStock)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] UpdateMinStock - 1: %v", err)
+		return nil, err
+	}
+
+	return merchantProduct, nil
+}
+
+// UpdateStockLevels implements MerchantProductUsecaseInterface.
+// max_stock 0 mematikan replenishment otomatis untuk produk ini.
+func (m *merchantProductUsecase) UpdateStockLevels(ctx context.Context, merchantProductID uint, minStock int, maxStock int) (*model.MerchantProduct, error) {
+	if maxStock != 0 && maxStock <= minStock {
+		return nil, ErrInvalidStockLevels
+	}
+
+	merchantProduct, err := m.merchantProductRepo.UpdateStockLevels(ctx, merchantProductID, minStock, maxStock)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] UpdateStockLevels - 1: %v", err)
+		return nil, err
+	}
+
+	return merchantProduct, nil
+}
+
+// GetLowStockMerchantProducts implements MerchantProductUsecaseInterface.
+func (m *merchantProductUsecase) GetLowStockMerchantProducts(ctx context.Context, merchantID uint, page int, limit int) ([]model.MerchantProduct, []httpclient.ProductResponse, int64, error) {
+	merchantProducts, total, err := m.merchantProductRepo.GetLowStockMerchantProducts(ctx, merchantID, page, limit)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] GetLowStockMerchantProducts - 1: %v", err)
+		return nil, nil, 0, err
+	}
+
+	var products []httpclient.ProductResponse
+	for _, mp := range merchantProducts {
+		product, err := m.productClient.GetProductByID(ctx, mp.ProductID)
+		if err != nil {
+			log.Errorf("[MerchantProductUsecase] GetLowStockMerchantProducts - 2: %v", err)
+			return nil, nil, 0, err
+		}
+		products = append(products, *product)
+	}
+
+	return merchantProducts, products, total, nil
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
+
+// SetPrice implements MerchantProductUsecaseInterface.
+// effectiveFrom nil berarti berlaku sekarang; price nil mengakhiri override dan kembali ke harga global product.
+func (m *merchantProductUsecase) SetPrice(ctx context.Context, merchantProductID uint, price *int64, effectiveFrom *time.Time, actorID uint) (*model.MerchantProductPrice, error) {
+	if price != nil && *price <= 0 {
+		return nil, fmt.Errorf("%w: price must be positive", ErrInvalidPrice)
+	}
+
+	if _, err := m.merchantProductRepo.GetMerchantProductByID(ctx, merchantProductID); err != nil {
+		log.Errorf("[MerchantProductUsecase] SetPrice - 1: %v", err)
+		return nil, err
+	}
+
+	merchantProductPrice := model.MerchantProductPrice{
+		MerchantProductID: merchantProductID,
+		Price:             price,
+		EffectiveFrom:     time.Now(),
+		CreatedBy:         actorID,
+	}
+	if effectiveFrom != nil {
+		merchantProductPrice.EffectiveFrom = *effectiveFrom
+	}
+
+	if err := m.priceRepo.CreatePrice(ctx, &merchantProductPrice); err != nil {
+		log.Errorf("[MerchantProductUsecase] SetPrice - 2: %v", err)
+		return nil, err
+	}
+
+	return &merchantProductPrice, nil
+}
+
+// GetPrices implements MerchantProductUsecaseInterface.
+func (m *merchantProductUsecase) GetPrices(ctx context.Context, merchantProductID uint) ([]model.MerchantProductPrice, error) {
+	if _, err := m.merchantProductRepo.GetMerchantProductByID(ctx, merchantProductID); err != nil {
+		log.Errorf("[MerchantProductUsecase] GetPrices - 1: %v", err)
+		return nil, err
+	}
+
+	prices, err := m.priceRepo.GetPrices(ctx, merchantProductID)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] GetPrices - 2: %v", err)
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+// DeleteScheduledPrice implements MerchantProductUsecaseInterface.
+func (m *merchantProductUsecase) DeleteScheduledPrice(ctx context.Context, merchantProductID uint, priceID uint) error {
+	if err := m.priceRepo.DeleteScheduledPrice(ctx, merchantProductID, priceID); err != nil {
+		log.Errorf("[MerchantProductUsecase] DeleteScheduledPrice - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// attachCurrentPrices mengisi CurrentPrice dengan harga merchant yang berlaku saat ini
+func (m *merchantProductUsecase) attachCurrentPrices(ctx context.Context, merchantProducts []*model.MerchantProduct) error {
+	ids := make([]uint, 0, len(merchantProducts))
+	for _, merchantProduct := range merchantProducts {
+		ids = append(ids, merchantProduct.ID)
+	}
+
+	currentPrices, err := m.priceRepo.GetCurrentPrices(ctx, ids, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, merchantProduct := range merchantProducts {
+		if price, exists := currentPrices[merchantProduct.ID]; exists {
+			merchantProduct.CurrentPrice = &price
+		}
+	}
+
+	return nil
+}
+
+// GetAllocationChanges implements MerchantProductUsecaseInterface.
+func (m *merchantProductUsecase) GetAllocationChanges(ctx context.Context, merchantID uint, unpublishedOnly, failedOnly bool, page, limit int) ([]model.MerchantAllocationChange, int64, error) {
+	return m.merchantProductRepo.GetAllocationChanges(ctx, merchantID, unpublishedOnly, failedOnly, page, limit)
+}
+
+// RepublishAllocationChange implements MerchantProductUsecaseInterface.
+// Aman diulang karena warehouse-service dedupe per reference.
+func (m *merchantProductUsecase) RepublishAllocationChange(ctx context.Context, identity authz.Identity, id uint) (*model.MerchantAllocationChange, error) {
+	if !identity.IsManager() {
+		return nil, authz.ErrForbidden
+	}
+
+	change, err := m.merchantProductRepo.GetAllocationChangeByID(ctx, id)
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] RepublishAllocationChange - 1: %v", err)
+		return nil, err
+	}
+
+	if !m.publishAllocationChange(ctx, change) {
+		return nil, fmt.Errorf("failed to publish allocation change %d", id)
+	}
+
+	return change, nil
+}
+
+// publishAllocationChange mengirim selisih alokasi ke warehouse: penambahan sebagai StockReductionEvent,
+// pengurangan sebagai StockReturnedEvent, lalu menandai perubahan sebagai terkirim. Kegagalan publish tidak
+// membatalkan perubahan stock; published_at tetap kosong dan manager bisa republish.
+func (m *merchantProductUsecase) publishAllocationChange(ctx context.Context, change *model.MerchantAllocationChange) bool {
+	if change == nil {
+		return true
+	}
+
+	var err error
+	if change.Quantity > 0 {
+		err = m.rabbitMQServuce.PublishStockReductionEvent(ctx, rabbitmq.StockReductionEvent{
+			Reference:   change.Reference(),
+			WarehouseID: change.WarehouseID,
+			ProductID:   change.ProductID,
+			Stock:       change.Quantity,
+			MerchantID:  change.MerchantID,
+			Timestamp:   time.Now(),
+		})
+	} else {
+		err = m.rabbitMQServuce.PublishStockReturnedEvent(ctx, rabbitmq.StockReturnedEvent{
+			Reference:   change.Reference(),
+			MerchantID:  change.MerchantID,
+			WarehouseID: change.WarehouseID,
+			Reason:      "merchant allocation reduced",
+			Items: []rabbitmq.StockReturnedItem{{
+				ProductID: change.ProductID,
+				Quantity:  -change.Quantity,
+				Condition: model.StockReturnConditionSellable,
+			}},
+			Timestamp: time.Now(),
+		})
+	}
+	if err != nil {
+		log.Errorf("[MerchantProductUsecase] publishAllocationChange - 1: %v", err)
+		return false
+	}
+
+	if err := m.merchantProductRepo.MarkAllocationChangePublished(ctx, change.ID); err != nil {
+		log.Errorf("[MerchantProductUsecase] publishAllocationChange - 2: %v", err)
+		return true
+	}
+
+	now := time.Now()
+	change.PublishedAt = &now
+	change.FailedAt = nil
+	change.FailureReason = ""
+	return true
 }
 
-func NewMerchantProductUsecase(merchantProductRepo repository.MerchantProductRepositoryInterface, productClient httpclient.ProductClientInterface, warehouseClient httpclient.WarehouseClientInterface, rabbitMQServuce *rabbitmq.RabbitMQService) MerchantProductUsecaseInterface {
+func NewMerchantProductUsecase(merchantProductRepo repository.MerchantProductRepositoryInterface, priceRepo repository.MerchantProductPriceRepositoryInterface, barcodeRuleRepo repository.BarcodeRuleRepositoryInterface, productClient httpclient.ProductClientInterface, warehouseClient httpclient.WarehouseClientInterface, rabbitMQServuce *rabbitmq.RabbitMQService, stockAlert StockAlertUsecaseInterface) MerchantProductUsecaseInterface {
 	return &merchantProductUsecase{
 		merchantProductRepo: merchantProductRepo,
+		priceRepo:           priceRepo,
+		barcodeRuleRepo:     barcodeRuleRepo,
 		productClient:       productClient,
 		warehouseClient:     warehouseClient,
 		rabbitMQServuce:     rabbitMQServuce,
+		stockAlert:          stockAlert,
 	}
 }
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// MerchantScheduleUsecaseInterface mengelola jam buka mingguan dan penutupan sementara merchant
+type MerchantScheduleUsecaseInterface interface {
+	GetSchedule(ctx context.Context, merchantID uint) (*model.Merchant, error)
+	ReplaceOpeningHours(ctx context.Context, merchantID uint, timezone string, hours []model.MerchantOpeningHour) (*model.Merchant, error)
+	GetClosures(ctx context.Context, merchantID uint, includePast bool) ([]model.MerchantClosure, error)
+	CreateClosure(ctx context.Context, closure *model.MerchantClosure) error
+	DeleteClosure(ctx context.Context, merchantID, id uint) error
+}
+
+var ErrInvalidMerchantSchedule = errors.New("invalid merchant schedule")
+
+type merchantScheduleUsecase struct {
+	merchantScheduleRepo repository.MerchantScheduleRepositoryInterface
+	merchantRepo         repository.MerchantRepositoryInterface
+}
+
+// GetSchedule implements MerchantScheduleUsecaseInterface.
+func (m *merchantScheduleUsecase) GetSchedule(ctx context.Context, merchantID uint) (*model.Merchant, error) {
+	merchant, err := m.merchantRepo.GetMerchantByID(ctx, merchantID)
+	if err != nil {
+		log.Errorf("[MerchantScheduleUsecase] GetSchedule - 1: %v", err)
+		return nil, err
+	}
+
+	return merchant, nil
+}
+
+// ReplaceOpeningHours implements MerchantScheduleUsecaseInterface.
+func (m *merchantScheduleUsecase) ReplaceOpeningHours(ctx context.Context, merchantID uint, timezone string, hours []model.MerchantOpeningHour) (*model.Merchant, error) {
+	if timezone == "" {
+		timezone = model.DefaultMerchantTimezone
+	}
+
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %s", ErrInvalidMerchantSchedule, timezone)
+	}
+
+	for _, hour := range hours {
+		if hour.Weekday < 0 || hour.Weekday > 6 {
+			return nil, fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6 (Saturday)", ErrInvalidMerchantSchedule)
+		}
+
+		if !isClock(hour.OpensAt) || !isClock(hour.ClosesAt) {
+			return nil, fmt.Errorf("%w: opening hours must use HH:MM", ErrInvalidMerchantSchedule)
+		}
+	}
+
+	if err := m.merchantScheduleRepo.ReplaceOpeningHours(ctx, merchantID, timezone, hours); err != nil {
+		log.Errorf("[MerchantScheduleUsecase] ReplaceOpeningHours - 1: %v", err)
+		return nil, err
+	}
+
+	return m.GetSchedule(ctx, merchantID)
+}
+
+// GetClosures implements MerchantScheduleUsecaseInterface.
+func (m *merchantScheduleUsecase) GetClosures(ctx context.Context, merchantID uint, includePast bool) ([]model.MerchantClosure, error) {
+	closures, err := m.merchantScheduleRepo.GetClosures(ctx, merchantID, includePast)
+	if err != nil {
+		log.Errorf("[MerchantScheduleUsecase] GetClosures - 1: %v", err)
+		return nil, err
+	}
+
+	return closures, nil
+}
+
+// CreateClosure implements MerchantScheduleUsecaseInterface.
+func (m *merchantScheduleUsecase) CreateClosure(ctx context.Context, closure *model.MerchantClosure) error {
+	if !closure.EndsAt.After(closure.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidMerchantSchedule)
+	}
+
+	if err := m.merchantScheduleRepo.CreateClosure(ctx, closure); err != nil {
+		log.Errorf("[MerchantScheduleUsecase] CreateClosure - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteClosure implements MerchantScheduleUsecaseInterface.
+func (m *merchantScheduleUsecase) DeleteClosure(ctx context.Context, merchantID uint, id uint) error {
+	if err := m.merchantScheduleRepo.DeleteClosure(ctx, merchantID, id); err != nil {
+		log.Errorf("[MerchantScheduleUsecase] DeleteClosure - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// isClock memastikan format HH:MM dengan nol di depan, karena jam dibandingkan sebagai string
+func isClock(value string) bool {
+	parsed, err := time.Parse("15:04", value)
+	return err == nil && parsed.Format("15:04") == value
+}
+
+func NewMerchantScheduleUsecase(merchantScheduleRepo repository.MerchantScheduleRepositoryInterface, merchantRepo repository.MerchantRepositoryInterface) MerchantScheduleUsecaseInterface {
+	return &merchantScheduleUsecase{
+		merchantScheduleRepo: merchantScheduleRepo,
+		merchantRepo:         merchantRepo,
+	}
+}
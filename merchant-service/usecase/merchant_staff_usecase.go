@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// MerchantStaffUsecaseInterface mengelola roster staff merchant.
+// Manager boleh mengubah semua penugasan; lead aktif hanya boleh mengatur cashier di merchant-nya sendiri.
+type MerchantStaffUsecaseInterface interface {
+	GetStaff(ctx context.Context, identity authz.Identity, merchantID uint, includeInactive bool) ([]model.MerchantStaff, map[uint]string, error)
+	AddStaff(ctx context.Context, identity authz.Identity, staff *model.MerchantStaff) error
+	UpdateStaff(ctx context.Context, identity authz.Identity, staff *model.MerchantStaff) (*model.MerchantStaff, error)
+	EndStaff(ctx context.Context, identity authz.Identity, merchantID, id uint) (*model.MerchantStaff, error)
+}
+
+var ErrInvalidMerchantStaff = errors.New("invalid merchant staff")
+
+type merchantStaffUsecase struct {
+	merchantStaffRepo repository.MerchantStaffRepositoryInterface
+	merchantRepo      repository.MerchantRepositoryInterface
+	userClient        httpclient.UserClientInterface
+}
+
+// GetStaff implements MerchantStaffUsecaseInterface.
+// Nama user dikembalikan terpisah per user_id; user yang gagal di-resolve cukup tanpa nama.
+func (m *merchantStaffUsecase) GetStaff(ctx context.Context, identity authz.Identity, merchantID uint, includeInactive bool) ([]model.MerchantStaff, map[uint]string, error) {
+	if err := authorizeMerchantAccess(ctx, m.merchantRepo, identity, merchantID); err != nil {
+		return nil, nil, err
+	}
+
+	staff, err := m.merchantStaffRepo.GetStaff(ctx, merchantID, !includeInactive)
+	if err != nil {
+		log.Errorf("[MerchantStaffUsecase] GetStaff - 1: %v", err)
+		return nil, nil, err
+	}
+
+	userNames := make(map[uint]string)
+	for _, member := range staff {
+		if _, exists := userNames[member.UserID]; exists {
+			continue
+		}
+
+		user, err := m.userClient.GetUserByID(ctx, member.UserID)
+		if err != nil {
+			log.Errorf("[MerchantStaffUsecase] GetStaff - 2: %v", err)
+			userNames[member.UserID] = ""
+			continue
+		}
+		userNames[member.UserID] = user.Name
+	}
+
+	return staff, userNames, nil
+}
+
+// AddStaff implements MerchantStaffUsecaseInterface.
+func (m *merchantStaffUsecase) AddStaff(ctx context.Context, identity authz.Identity, staff *model.MerchantStaff) error {
+	if err := m.authorizeStaffChange(ctx, identity, staff.MerchantID, staff.Role); err != nil {
+		return err
+	}
+
+	if err := validateStaffPeriod(staff); err != nil {
+		return err
+	}
+
+	if _, err := m.userClient.GetUserByID(ctx, staff.UserID); err != nil {
+		log.Errorf("[MerchantStaffUsecase] AddStaff - 1: %v", err)
+		return fmt.Errorf("%w: user %d not found", ErrInvalidMerchantStaff, staff.UserID)
+	}
+
+	if err := m.merchantStaffRepo.AddStaff(ctx, staff); err != nil {
+		log.Errorf("[MerchantStaffUsecase] AddStaff - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStaff implements MerchantStaffUsecaseInterface.
+// Lead tidak boleh mengubah penugasan lead lain maupun menaikkan cashier menjadi lead.
+func (m *merchantStaffUsecase) UpdateStaff(ctx context.Context, identity authz.Identity, staff *model.MerchantStaff) (*model.MerchantStaff, error) {
+	existing, err := m.merchantStaffRepo.GetStaffByID(ctx, staff.MerchantID, staff.ID)
+	if err != nil {
+		log.Errorf("[MerchantStaffUsecase] UpdateStaff - 1: %v", err)
+		return nil, err
+	}
+
+	if err := m.authorizeStaffChange(ctx, identity, existing.MerchantID, existing.Role); err != nil {
+		return nil, err
+	}
+	if err := m.authorizeStaffChange(ctx, identity, staff.MerchantID, staff.Role); err != nil {
+		return nil, err
+	}
+
+	staff.UserID = existing.UserID
+	if err := validateStaffPeriod(staff); err != nil {
+		return nil, err
+	}
+
+	if err := m.merchantStaffRepo.UpdateStaff(ctx, staff); err != nil {
+		log.Errorf("[MerchantStaffUsecase] UpdateStaff - 2: %v", err)
+		return nil, err
+	}
+
+	return m.merchantStaffRepo.GetStaffByID(ctx, staff.MerchantID, staff.ID)
+}
+
+// EndStaff implements MerchantStaffUsecaseInterface.
+func (m *merchantStaffUsecase) EndStaff(ctx context.Context, identity authz.Identity, merchantID uint, id uint) (*model.MerchantStaff, error) {
+	existing, err := m.merchantStaffRepo.GetStaffByID(ctx, merchantID, id)
+	if err != nil {
+		log.Errorf("[MerchantStaffUsecase] EndStaff - 1: %v", err)
+		return nil, err
+	}
+
+	if err := m.authorizeStaffChange(ctx, identity, merchantID, existing.Role); err != nil {
+		return nil, err
+	}
+
+	staff, err := m.merchantStaffRepo.EndStaff(ctx, merchantID, id, time.Now())
+	if err != nil {
+		log.Errorf("[MerchantStaffUsecase] EndStaff - 2: %v", err)
+		return nil, err
+	}
+
+	return staff, nil
+}
+
+// authorizeStaffChange: manager boleh semua role, lead aktif hanya boleh mengatur cashier di merchant yang sama
+func (m *merchantStaffUsecase) authorizeStaffChange(ctx context.Context, identity authz.Identity, merchantID uint, role string) error {
+	if identity.IsManager() {
+		return nil
+	}
+
+	if role != model.MerchantStaffRoleCashier {
+		return authz.ErrForbidden
+	}
+
+	activeStaff, err := m.merchantStaffRepo.GetStaff(ctx, merchantID, true)
+	if err != nil {
+		log.Errorf("[MerchantStaffUsecase] authorizeStaffChange - 1: %v", err)
+		return err
+	}
+
+	for _, member := range activeStaff {
+		if member.UserID == identity.UserID && member.Role == model.MerchantStaffRoleLead {
+			return nil
+		}
+	}
+
+	return authz.ErrForbidden
+}
+
+func validateStaffPeriod(staff *model.MerchantStaff) error {
+	if staff.EndDate != nil && !staff.EndDate.After(staff.StartDate) {
+		return fmt.Errorf("%w: end_date must be after start_date", ErrInvalidMerchantStaff)
+	}
+
+	return nil
+}
+
+func NewMerchantStaffUsecase(merchantStaffRepo repository.MerchantStaffRepositoryInterface, merchantRepo repository.MerchantRepositoryInterface, userClient httpclient.UserClientInterface) MerchantStaffUsecaseInterface {
+	return &merchantStaffUsecase{
+		merchantStaffRepo: merchantStaffRepo,
+		merchantRepo:      merchantRepo,
+		userClient:        userClient,
+	}
+}
@@ -2,29 +2,41 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"micro-warehouse/merchant-service/model"
 	"micro-warehouse/merchant-service/pkg/httpclient"
 	"micro-warehouse/merchant-service/repository"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 )
 
-// CRUD, Get by keeperID, get keepername
+// CRUD, Get by keeperID, get keepername, archive, trash
 type MerchantUsecaseInterface interface {
 	CreateMerchant(ctx context.Context, merchant *model.Merchant) error
-	GetAllMerchants(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Merchant, int64, error)
+	GetAllMerchants(ctx context.Context, page, limit int, search, sortBy, sortOrder, status string, openNow, archived bool) ([]model.Merchant, int64, error)
 	GetMerchantByID(ctx context.Context, id uint) (*model.Merchant, error)
 	UpdateMerchant(ctx context.Context, merchant *model.Merchant) error
 	DeleteMerchant(ctx context.Context, id uint) error
-	GetMerchantByKeeperID(ctx context.Context, keeperID uint) (*model.Merchant, []httpclient.ProductResponse, []httpclient.WarehouseResponse, error)
+	GetMerchantsByKeeperID(ctx context.Context, keeperID uint) ([]model.Merchant, []httpclient.ProductResponse, []httpclient.WarehouseResponse, error)
 	GetKeeperName(ctx context.Context, keeperID uint) (string, error)
+	UpdateMerchantStatus(ctx context.Context, id uint, status, reason string) error
+	ArchiveMerchant(ctx context.Context, id uint) error
+	UnarchiveMerchant(ctx context.Context, id uint) error
+	GetDeletedMerchants(ctx context.Context, page, limit int, search string) ([]model.Merchant, int64, error)
+	RestoreMerchant(ctx context.Context, id uint) error
 }
 
+var ErrInvalidMerchantStatus = errors.New("invalid merchant status")
+
 type merchantUsecase struct {
-	merchantRepo    repository.MerchantRepositoryInterface
-	userClient      httpclient.UserClientInterface
-	warehouseClient httpclient.WarehouseClientInterface
-	productClient   httpclient.ProductClientInterface
+	merchantRepo        repository.MerchantRepositoryInterface
+	merchantProductRepo repository.MerchantProductRepositoryInterface
+	userClient          httpclient.UserClientInterface
+	warehouseClient     httpclient.WarehouseClientInterface
+	productClient       httpclient.ProductClientInterface
+	transactionClient   httpclient.TransactionClientInterface
 }
 
 // CreateMerchant implements MerchantUsecaseInterface.
@@ -33,13 +45,108 @@ func (m *merchantUsecase) CreateMerchant(ctx context.Context, merchant *model.Me
 }
 
 // DeleteMerchant implements MerchantUsecaseInterface.
+// Merchant yang masih memegang stock atau punya transaksi pending ditolak dengan DeletionBlockedError;
+// merchant seperti itu sebaiknya diarsipkan.
 func (m *merchantUsecase) DeleteMerchant(ctx context.Context, id uint) error {
-	return m.merchantRepo.DeleteMerchant(ctx, id)
+	if _, err := m.merchantRepo.GetMerchantByID(ctx, id); err != nil {
+		log.Errorf("[MerchantUsecase] DeleteMerchant - 1: %v", err)
+		return err
+	}
+
+	blockers, err := m.deletionBlockers(ctx, id)
+	if err != nil {
+		log.Errorf("[MerchantUsecase] DeleteMerchant - 2: %v", err)
+		return err
+	}
+
+	if len(blockers) > 0 {
+		return &DeletionBlockedError{Entity: "merchant", ID: id, Blockers: blockers}
+	}
+
+	if err := m.merchantRepo.DeleteMerchant(ctx, id); err != nil {
+		log.Errorf("[MerchantUsecase] DeleteMerchant - 3: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// deletionBlockers mengecek stock di merchant-service dan transaksi pending di transaction-service.
+// Kegagalan memanggil transaction-service menggagalkan penghapusan supaya tidak ada transaksi yang yatim.
+func (m *merchantUsecase) deletionBlockers(ctx context.Context, id uint) ([]DeletionBlocker, error) {
+	blockers := []DeletionBlocker{}
+
+	stock, err := m.merchantProductRepo.GetMerchantStockSummary(ctx, id)
+	if err != nil {
+		log.Errorf("[MerchantUsecase] deletionBlockers - 1: %v", err)
+		return nil, err
+	}
+
+	if stock.ProductCount > 0 {
+		blockers = append(blockers, DeletionBlocker{
+			Type:     DeletionBlockerMerchantStock,
+			Count:    stock.ProductCount,
+			Quantity: stock.TotalStock,
+			Message:  fmt.Sprintf("%d products still have %d units in stock; return or transfer them first", stock.ProductCount, stock.TotalStock),
+		})
+	}
+
+	pending, err := m.transactionClient.CountPendingTransactions(ctx, id)
+	if err != nil {
+		log.Errorf("[MerchantUsecase] deletionBlockers - 2: %v", err)
+		return nil, err
+	}
+
+	if pending > 0 {
+		blockers = append(blockers, DeletionBlocker{
+			Type:    DeletionBlockerPendingTransactions,
+			Count:   pending,
+			Message: fmt.Sprintf("%d transactions are still waiting for payment", pending),
+		})
+	}
+
+	return blockers, nil
+}
+
+// GetDeletedMerchants implements MerchantUsecaseInterface.
+func (m *merchantUsecase) GetDeletedMerchants(ctx context.Context, page, limit int, search string) ([]model.Merchant, int64, error) {
+	return m.merchantRepo.GetDeletedMerchants(ctx, page, limit, search)
+}
+
+// RestoreMerchant implements MerchantUsecaseInterface.
+func (m *merchantUsecase) RestoreMerchant(ctx context.Context, id uint) error {
+	if err := m.merchantRepo.RestoreMerchant(ctx, id); err != nil {
+		log.Errorf("[MerchantUsecase] RestoreMerchant - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ArchiveMerchant implements MerchantUsecaseInterface.
+// Arsip tidak dicek blocker: stock, transaksi dan ledger tetap tersimpan dan bisa dibaca lewat ID merchant.
+func (m *merchantUsecase) ArchiveMerchant(ctx context.Context, id uint) error {
+	if err := m.merchantRepo.ArchiveMerchant(ctx, id, time.Now()); err != nil {
+		log.Errorf("[MerchantUsecase] ArchiveMerchant - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UnarchiveMerchant implements MerchantUsecaseInterface.
+func (m *merchantUsecase) UnarchiveMerchant(ctx context.Context, id uint) error {
+	if err := m.merchantRepo.UnarchiveMerchant(ctx, id); err != nil {
+		log.Errorf("[MerchantUsecase] UnarchiveMerchant - 1: %v", err)
+		return err
+	}
+
+	return nil
 }
 
 // GetAllMerchants implements MerchantUsecaseInterface.
-func (m *merchantUsecase) GetAllMerchants(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Merchant, int64, error) {
-	return m.merchantRepo.GetAllMerchants(ctx, page, limit, search, sortBy, sortOrder)
+func (m *merchantUsecase) GetAllMerchants(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, status string, openNow bool, archived bool) ([]model.Merchant, int64, error) {
+	return m.merchantRepo.GetAllMerchants(ctx, page, limit, search, sortBy, sortOrder, status, openNow, archived)
 }
 
 // GetKeeperName implements MerchantUsecaseInterface.
@@ -60,35 +167,66 @@ func (m *merchantUsecase) GetMerchantByID(ctx context.Context, id uint) (*model.
 	return m.merchantRepo.GetMerchantByID(ctx, id)
 }
 
-// GetMerchantByKeeperID implements MerchantUsecaseInterface.
-func (m *merchantUsecase) GetMerchantByKeeperID(ctx context.Context, keeperID uint) (*model.Merchant, []httpclient.ProductResponse, []httpclient.WarehouseResponse, error) {
-	merchant, err := m.merchantRepo.GetMerchantByKeeperID(ctx, keeperID)
+// GetMerchantsByKeeperID implements MerchantUsecaseInterface.
+// Semua merchant tempat user aktif bertugas (lead maupun cashier) dikembalikan.
+func (m *merchantUsecase) GetMerchantsByKeeperID(ctx context.Context, keeperID uint) ([]model.Merchant, []httpclient.ProductResponse, []httpclient.WarehouseResponse, error) {
+	merchants, err := m.merchantRepo.GetMerchantsByStaffUserID(ctx, keeperID)
 	if err != nil {
-		log.Errorf("[MerchantUsecase] GetMerchantByKeeperID - 1: %v", err)
+		log.Errorf("[MerchantUsecase] GetMerchantsByKeeperID - 1: %v", err)
 		return nil, nil, nil, err
 	}
 
 	var products []httpclient.ProductResponse
 	var warehouses []httpclient.WarehouseResponse
+	seenProducts := make(map[uint]bool)
+	seenWarehouses := make(map[uint]bool)
 
-	if len(merchant.MerchantProducts) > 0 {
+	for _, merchant := range merchants {
 		for _, mp := range merchant.MerchantProducts {
-			product, err := m.productClient.GetProductByID(ctx, mp.ProductID)
-			if err != nil {
-				log.Errorf("[MerchantUsecase] GetMerchantByKeeperID - 2: %v", err)
-				return nil, nil, nil, err
+			if !seenProducts[mp.ProductID] {
+				product, err := m.productClient.GetProductByID(ctx, mp.ProductID)
+				if err != nil {
+					log.Errorf("[MerchantUsecase] GetMerchantsByKeeperID - 2: %v", err)
+					return nil, nil, nil, err
+				}
+				products = append(products, *product)
+				seenProducts[mp.ProductID] = true
 			}
-			products = append(products, *product)
 
-			warehouse, err := m.warehouseClient.GetWarehouseByID(ctx, mp.WarehouseID)
-			if err != nil {
-				log.Errorf("[MerchantUsecase] GetMerchantByKeeperID - 3: %v", err)
-				return nil, nil, nil, err
+			if !seenWarehouses[mp.WarehouseID] {
+				warehouse, err := m.warehouseClient.GetWarehouseByID(ctx, mp.WarehouseID)
+				if err != nil {
+					log.Errorf("[MerchantUsecase] GetMerchantsByKeeperID - 3: %v", err)
+					return nil, nil, nil, err
+				}
+				warehouses = append(warehouses, *warehouse)
+				seenWarehouses[mp.WarehouseID] = true
 			}
-			warehouses = append(warehouses, *warehouse)
 		}
 	}
-	return merchant, products, warehouses, nil
+	return merchants, products, warehouses, nil
+}
+
+// UpdateMerchantStatus implements MerchantUsecaseInterface.
+// Merchant suspended atau closed tidak menerima penjualan baru; alasan wajib agar staff tahu penyebabnya.
+func (m *merchantUsecase) UpdateMerchantStatus(ctx context.Context, id uint, status string, reason string) error {
+	switch status {
+	case model.MerchantStatusActive:
+		reason = ""
+	case model.MerchantStatusSuspended, model.MerchantStatusClosed:
+		if reason == "" {
+			return fmt.Errorf("%w: reason is required when status is %s", ErrInvalidMerchantStatus, status)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidMerchantStatus, status)
+	}
+
+	if err := m.merchantRepo.UpdateMerchantStatus(ctx, id, status, reason); err != nil {
+		log.Errorf("[MerchantUsecase] UpdateMerchantStatus - 1: %v", err)
+		return err
+	}
+
+	return nil
 }
 
 // UpdateMerchant implements MerchantUsecaseInterface.
@@ -96,11 +234,13 @@ func (m *merchantUsecase) UpdateMerchant(ctx context.Context, merchant *model.Me
 	return m.merchantRepo.UpdateMerchant(ctx, merchant)
 }
 
-func NewMerchantUsecase(merchantRepo repository.MerchantRepositoryInterface, userClient httpclient.UserClientInterface, warehouseClient httpclient.WarehouseClientInterface, productClient httpclient.ProductClientInterface) MerchantUsecaseInterface {
+func NewMerchantUsecase(merchantRepo repository.MerchantRepositoryInterface, merchantProductRepo repository.MerchantProductRepositoryInterface, userClient httpclient.UserClientInterface, warehouseClient httpclient.WarehouseClientInterface, productClient httpclient.ProductClientInterface, transactionClient httpclient.TransactionClientInterface) MerchantUsecaseInterface {
 	return &merchantUsecase{
-		merchantRepo:    merchantRepo,
-		userClient:      userClient,
-		warehouseClient: warehouseClient,
-		productClient:   productClient,
+		merchantRepo:        merchantRepo,
+		merchantProductRepo: merchantProductRepo,
+		userClient:          userClient,
+		warehouseClient:     warehouseClient,
+		productClient:       productClient,
+		transactionClient:   transactionClient,
 	}
 }
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+const productProjectionSyncPageSize = 100
+
+// ProductProjectionUsecaseInterface mengisi proyeksi product langsung dari product-service, untuk product yang
+// belum pernah mengirim event (data lama) atau saat event terlewat
+type ProductProjectionUsecaseInterface interface {
+	SyncProduct(ctx context.Context, productID uint) error
+	SyncAllProducts(ctx context.Context) (int, error)
+}
+
+type productProjectionUsecase struct {
+	productProjectionRepo repository.ProductProjectionRepositoryInterface
+	productClient         httpclient.ProductClientInterface
+}
+
+// SyncProduct implements ProductProjectionUsecaseInterface.
+func (p *productProjectionUsecase) SyncProduct(ctx context.Context, productID uint) error {
+	product, err := p.productClient.GetProductByID(ctx, productID)
+	if err != nil {
+		log.Errorf("[ProductProjectionUsecase] SyncProduct - 1: %v", err)
+		return err
+	}
+
+	return p.productProjectionRepo.UpsertProductProjections(ctx, []model.ProductProjection{toProductProjection(*product, time.Now())})
+}
+
+// SyncAllProducts implements ProductProjectionUsecaseInterface.
+// Membaca seluruh product per halaman; mengembalikan jumlah product yang disinkronkan.
+func (p *productProjectionUsecase) SyncAllProducts(ctx context.Context) (int, error) {
+	synced := 0
+	for page := 1; ; page++ {
+		products, err := p.productClient.GetProducts(ctx, page, productProjectionSyncPageSize, "", "id", "asc")
+		if err != nil {
+			log.Errorf("[ProductProjectionUsecase] SyncAllProducts - 1: %v", err)
+			return synced, err
+		}
+
+		now := time.Now()
+		projections := make([]model.ProductProjection, 0, len(products))
+		for _, product := range products {
+			projections = append(projections, toProductProjection(product, now))
+		}
+
+		if err := p.productProjectionRepo.UpsertProductProjections(ctx, projections); err != nil {
+			log.Errorf("[ProductProjectionUsecase] SyncAllProducts - 2: %v", err)
+			return synced, err
+		}
+		synced += len(projections)
+
+		if len(products) < productProjectionSyncPageSize {
+			return synced, nil
+		}
+	}
+}
+
+func toProductProjection(product httpclient.ProductResponse, syncedAt time.Time) model.ProductProjection {
+	return model.ProductProjection{
+		ProductID:    product.ID,
+		Name:         product.Name,
+		Barcode:      product.Barcode,
+		CategoryID:   product.Category.ID,
+		CategoryName: product.Category.Name,
+		SyncedAt:     syncedAt,
+	}
+}
+
+func NewProductProjectionUsecase(productProjectionRepo repository.ProductProjectionRepositoryInterface, productClient httpclient.ProductClientInterface) ProductProjectionUsecaseInterface {
+	return &productProjectionUsecase{
+		productProjectionRepo: productProjectionRepo,
+		productClient:         productClient,
+	}
+}
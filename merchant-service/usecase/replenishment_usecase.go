@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// ReplenishmentUsecaseInterface menghitung usulan penambahan stock dari min/max level dan mengelola draft order-nya.
+// Order yang di-approve dialokasikan lewat CreateMerchantProduct/AllocateStock.
+type ReplenishmentUsecaseInterface interface {
+	GetSuggestions(ctx context.Context, identity authz.Identity, merchantID uint) ([]model.ReplenishmentOrder, error)
+	GenerateDraftOrders(ctx context.Context, merchantID, createdBy uint) ([]model.ReplenishmentOrder, error)
+
+	GetReplenishmentOrders(ctx context.Context, identity authz.Identity, merchantID uint, status string, page, limit int) ([]model.ReplenishmentOrder, int64, error)
+	GetReplenishmentOrderByID(ctx context.Context, identity authz.Identity, id uint) (*model.ReplenishmentOrder, error)
+	ApproveReplenishmentOrder(ctx context.Context, identity authz.Identity, id uint, note string) (*model.ReplenishmentOrder, error)
+	RejectReplenishmentOrder(ctx context.Context, identity authz.Identity, id uint, note string) error
+}
+
+type replenishmentUsecase struct {
+	merchantProductRepo    repository.MerchantProductRepositoryInterface
+	replenishmentOrderRepo repository.ReplenishmentOrderRepositoryInterface
+	merchantRepo           repository.MerchantRepositoryInterface
+	warehouseClient        httpclient.WarehouseClientInterface
+	merchantProductUsecase MerchantProductUsecaseInterface
+}
+
+// GetSuggestions implements ReplenishmentUsecaseInterface.
+// Usulan dihitung langsung dari stock saat ini dan tidak disimpan.
+func (r *replenishmentUsecase) GetSuggestions(ctx context.Context, identity authz.Identity, merchantID uint) ([]model.ReplenishmentOrder, error) {
+	merchantIDs, err := scopeMerchantIDs(ctx, r.merchantRepo, identity, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keeper tanpa merchant tidak punya usulan
+	if merchantIDs != nil && len(merchantIDs) == 0 {
+		return []model.ReplenishmentOrder{}, nil
+	}
+	if len(merchantIDs) == 1 {
+		merchantID = merchantIDs[0]
+	}
+
+	suggestions, err := r.buildSuggestions(ctx, merchantID, nil)
+	if err != nil {
+		log.Errorf("[ReplenishmentUsecase] GetSuggestions - 1: %v", err)
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// GenerateDraftOrders implements ReplenishmentUsecaseInterface.
+// Produk yang masih ada di draft lain dilewati, sehingga job terjadwal aman dijalankan berulang.
+func (r *replenishmentUsecase) GenerateDraftOrders(ctx context.Context, merchantID uint, createdBy uint) ([]model.ReplenishmentOrder, error) {
+	draftMerchantProductIDs, err := r.replenishmentOrderRepo.GetDraftMerchantProductIDs(ctx, merchantID)
+	if err != nil {
+		log.Errorf("[ReplenishmentUsecase] GenerateDraftOrders - 1: %v", err)
+		return nil, err
+	}
+
+	suggestions, err := r.buildSuggestions(ctx, merchantID, draftMerchantProductIDs)
+	if err != nil {
+		log.Errorf("[ReplenishmentUsecase] GenerateDraftOrders - 2: %v", err)
+		return nil, err
+	}
+
+	for i := range suggestions {
+		suggestions[i].CreatedBy = createdBy
+		if err := r.replenishmentOrderRepo.CreateReplenishmentOrder(ctx, &suggestions[i]); err != nil {
+			log.Errorf("[ReplenishmentUsecase] GenerateDraftOrders - 3: %v", err)
+			return suggestions[:i], err
+		}
+	}
+
+	return suggestions, nil
+}
+
+// GetReplenishmentOrders implements ReplenishmentUsecaseInterface.
+func (r *replenishmentUsecase) GetReplenishmentOrders(ctx context.Context, identity authz.Identity, merchantID uint, status string, page int, limit int) ([]model.ReplenishmentOrder, int64, error) {
+	merchantIDs, err := scopeMerchantIDs(ctx, r.merchantRepo, identity, merchantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	replenishmentOrders, total, err := r.replenishmentOrderRepo.GetReplenishmentOrders(ctx, merchantIDs, status, page, limit)
+	if err != nil {
+		log.Errorf("[ReplenishmentUsecase] GetReplenishmentOrders - 1: %v", err)
+		return nil, 0, err
+	}
+
+	return replenishmentOrders, total, nil
+}
+
+// GetReplenishmentOrderByID implements ReplenishmentUsecaseInterface.
+func (r *replenishmentUsecase) GetReplenishmentOrderByID(ctx context.Context, identity authz.Identity, id uint) (*model.ReplenishmentOrder, error) {
+	replenishmentOrder, err := r.replenishmentOrderRepo.GetReplenishmentOrderByID(ctx, id)
+	if err != nil {
+		log.Errorf("[ReplenishmentUsecase] GetReplenishmentOrderByID - 1: %v", err)
+		return nil, err
+	}
+
+	if err := authorizeMerchantAccess(ctx, r.merchantRepo, identity, replenishmentOrder.MerchantID); err != nil {
+		return nil, err
+	}
+
+	return replenishmentOrder, nil
+}
+
+// ApproveReplenishmentOrder implements ReplenishmentUsecaseInterface.
+// Status di-klaim lebih dulu agar order tidak dialokasikan dua kali; hasil alokasi dicatat per item
+// sehingga satu produk yang gagal (misalnya stock warehouse sudah habis) tidak membatalkan produk lain.
+func (r *replenishmentUsecase) ApproveReplenishmentOrder(ctx context.Context, identity authz.Identity, id uint, note string) (*model.ReplenishmentOrder, error) {
+	if !identity.IsManager() {
+		return nil, authz.ErrForbidden
+	}
+
+	replenishmentOrder, err := r.replenishmentOrderRepo.GetReplenishmentOrderByID(ctx, id)
+	if err != nil {
+		log.Errorf("[ReplenishmentUsecase] ApproveReplenishmentOrder - 1: %v", err)
+		return nil, err
+	}
+
+	err = r.replenishmentOrderRepo.UpdateReplenishmentOrderStatus(ctx, id, model.ReplenishmentOrderStatusDraft, map[string]interface{}{
+		"status":      model.ReplenishmentOrderStatusApproved,
+		"reviewed_by": identity.UserID,
+		"reviewed_at": time.Now(),
+		"review_note": note,
+	})
+	if err != nil {
+		log.Errorf("[ReplenishmentUsecase] ApproveReplenishmentOrder - 2: %v", err)
+		return nil, err
+	}
+
+	for i := range replenishmentOrder.Items {
+		item := &replenishmentOrder.Items[i]
+		if item.Status != model.ReplenishmentItemStatusPending {
+			continue
+		}
+
+		if err := r.allocate(ctx, replenishmentOrder.MerchantID, item, identity.UserID); err != nil {
+			log.Errorf("[ReplenishmentUsecase] ApproveReplenishmentOrder - 3: %v", err)
+			item.Status = model.ReplenishmentItemStatusFailed
+			item.FailureReason = err.Error()
+		} else {
+			item.Status = model.ReplenishmentItemStatusAllocated
+			item.AllocatedQuantity = item.SuggestedQuantity
+		}
+
+		if err := r.replenishmentOrderRepo.UpdateReplenishmentOrderItem(ctx, item); err != nil {
+			log.Errorf("[ReplenishmentUsecase] ApproveReplenishmentOrder - 4: %v", err)
+		}
+	}
+
+	return r.replenishmentOrderRepo.GetReplenishmentOrderByID(ctx, id)
+}
+
+// RejectReplenishmentOrder implements ReplenishmentUsecaseInterface.
+func (r *replenishmentUsecase) RejectReplenishmentOrder(ctx context.Context, identity authz.Identity, id uint, note string) error {
+	if !identity.IsManager() {
+		return authz.ErrForbidden
+	}
+
+	if _, err := r.replenishmentOrderRepo.GetReplenishmentOrderByID(ctx, id); err != nil {
+		log.Errorf("[ReplenishmentUsecase] RejectReplenishmentOrder - 1: %v", err)
+		return err
+	}
+
+	err := r.replenishmentOrderRepo.UpdateReplenishmentOrderStatus(ctx, id, model.ReplenishmentOrderStatusDraft, map[string]interface{}{
+		"status":      model.ReplenishmentOrderStatusRejected,
+		"reviewed_by": identity.UserID,
+		"reviewed_at": time.Now(),
+		"review_note": note,
+	})
+	if err != nil {
+		log.Errorf("[ReplenishmentUsecase] RejectReplenishmentOrder - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// allocate menambah stock merchant sebesar usulan dari warehouse sumber; penambahan dihitung terhadap saldo yang
+// dikunci sehingga penjualan yang terjadi sejak draft dibuat tidak tertimpa.
+// Warehouse sumber boleh berbeda dari supplier merchant product; supplier tidak diubah dan stock di rak tidak dipindah.
+// Jika merchant product sudah dihapus sejak draft dibuat, alokasi dibuat ulang dengan CreateMerchantProduct.
+func (r *replenishmentUsecase) allocate(ctx context.Context, merchantID uint, item *model.ReplenishmentOrderItem, actorID uint) error {
+	merchantProduct, err := r.merchantProductRepo.GetMerchantProductByID(ctx, item.MerchantProductID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		return r.merchantProductUsecase.CreateMerchantProduct(ctx, &model.MerchantProduct{
+			MerchantID:  merchantID,
+			ProductID:   item.ProductID,
+			WarehouseID: item.WarehouseID,
+			Stock:       item.SuggestedQuantity,
+		}, actorID)
+	}
+
+	return r.merchantProductUsecase.AllocateStock(ctx, merchantProduct.ID, item.WarehouseID, item.SuggestedQuantity, model.StockMovementMeta{
+		MovementType: model.StockMovementTransferIn,
+		ActorID:      actorID,
+		Reference:    fmt.Sprintf("replenishment:%d", item.ReplenishmentOrderID),
+		Note:         "replenishment allocation",
+	})
+}
+
+// buildSuggestions mengelompokkan usulan per merchant. Warehouse sumber adalah warehouse dengan stock terbanyak,
+// termasuk saat supplier merchant product sedang kosong; jumlah usulan dibatasi stock warehouse tersebut.
+func (r *replenishmentUsecase) buildSuggestions(ctx context.Context, merchantID uint, skipMerchantProductIDs map[uint]bool) ([]model.ReplenishmentOrder, error) {
+	merchantProducts, err := r.merchantProductRepo.GetReplenishableMerchantProducts(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []model.ReplenishmentOrder{}
+	orderIndex := make(map[uint]int)
+	warehouseStocks := make(map[uint][]httpclient.ProductWarehouseStockResponse)
+
+	for _, merchantProduct := range merchantProducts {
+		if skipMerchantProductIDs[merchantProduct.ID] {
+			continue
+		}
+
+		stocks, ok := warehouseStocks[merchantProduct.ProductID]
+		if !ok {
+			stocks, err = r.warehouseClient.GetProductWarehouseStocks(ctx, merchantProduct.ProductID)
+			if err != nil {
+				log.Errorf("[ReplenishmentUsecase] buildSuggestions - 1: product %d: %v", merchantProduct.ProductID, err)
+				continue
+			}
+			warehouseStocks[merchantProduct.ProductID] = stocks
+		}
+
+		source, found := replenishmentSource(stocks, merchantProduct.WarehouseID)
+		if !found {
+			log.Warnf("[ReplenishmentUsecase] buildSuggestions - no warehouse has stock for merchant product %d", merchantProduct.ID)
+			continue
+		}
+
+		quantity := merchantProduct.ReplenishmentQuantity()
+		if source.Stock < quantity {
+			quantity = source.Stock
+		}
+		if quantity <= 0 {
+			log.Warnf("[ReplenishmentUsecase] buildSuggestions - warehouse %d cannot cover merchant product %d", source.WarehouseID, merchantProduct.ID)
+			continue
+		}
+
+		idx, exists := orderIndex[merchantProduct.MerchantID]
+		if !exists {
+			suggestions = append(suggestions, model.ReplenishmentOrder{
+				MerchantID: merchantProduct.MerchantID,
+				Status:     model.ReplenishmentOrderStatusDraft,
+			})
+			idx = len(suggestions) - 1
+			orderIndex[merchantProduct.MerchantID] = idx
+		}
+
+		suggestions[idx].Items = append(suggestions[idx].Items, model.ReplenishmentOrderItem{
+			MerchantProductID: merchantProduct.ID,
+			ProductID:         merchantProduct.ProductID,
+			WarehouseID:       source.WarehouseID,
+			CurrentStock:      merchantProduct.Stock,
+			MinStock:          merchantProduct.MinStock,
+			MaxStock:          merchantProduct.MaxStock,
+			WarehouseStock:    source.Stock,
+			SuggestedQuantity: quantity,
+			Status:            model.ReplenishmentItemStatusPending,
+		})
+	}
+
+	return suggestions, nil
+}
+
+// replenishmentSource memilih warehouse dengan stock terbanyak; jika sama banyak, supplier saat ini didahulukan.
+// found false jika tidak ada warehouse yang punya stock.
+func replenishmentSource(stocks []httpclient.ProductWarehouseStockResponse, supplierWarehouseID uint) (httpclient.ProductWarehouseStockResponse, bool) {
+	var source httpclient.ProductWarehouseStockResponse
+	for _, stock := range stocks {
+		if stock.Stock > source.Stock || (stock.Stock == source.Stock && stock.WarehouseID == supplierWarehouseID) {
+			source = stock
+		}
+	}
+
+	return source, source.Stock > 0
+}
+
+func NewReplenishmentUsecase(merchantProductRepo repository.MerchantProductRepositoryInterface, replenishmentOrderRepo repository.ReplenishmentOrderRepositoryInterface, merchantRepo repository.MerchantRepositoryInterface, warehouseClient httpclient.WarehouseClientInterface, merchantProductUsecase MerchantProductUsecaseInterface) ReplenishmentUsecaseInterface {
+	return &replenishmentUsecase{
+		merchantProductRepo:    merchantProductRepo,
+		replenishmentOrderRepo: replenishmentOrderRepo,
+		merchantRepo:           merchantRepo,
+		warehouseClient:        warehouseClient,
+		merchantProductUsecase: merchantProductUsecase,
+	}
+}
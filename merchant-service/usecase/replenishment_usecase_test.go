@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/repository"
+	"testing"
+)
+
+// Fake hanya mengimplementasikan method yang dipakai buildSuggestions; method lain panic karena interface nil
+type fakeReplenishableRepo struct {
+	repository.MerchantProductRepositoryInterface
+	merchantProducts []model.MerchantProduct
+}
+
+func (f *fakeReplenishableRepo) GetReplenishableMerchantProducts(ctx context.Context, merchantID uint) ([]model.MerchantProduct, error) {
+	return f.merchantProducts, nil
+}
+
+type fakeWarehouseStocks struct {
+	httpclient.WarehouseClientInterface
+	stocks map[uint][]httpclient.ProductWarehouseStockResponse
+}
+
+func (f *fakeWarehouseStocks) GetProductWarehouseStocks(ctx context.Context, productID uint) ([]httpclient.ProductWarehouseStockResponse, error) {
+	return f.stocks[productID], nil
+}
+
+func TestBuildSuggestionsSourcesFromMostStockedWarehouse(t *testing.T) {
+	merchantProduct := model.MerchantProduct{ID: 7, MerchantID: 1, ProductID: 100, WarehouseID: 1, Stock: 2, MinStock: 5, MaxStock: 20}
+
+	tests := []struct {
+		name          string
+		stocks        []httpclient.ProductWarehouseStockResponse
+		wantWarehouse uint
+		wantQuantity  int
+		wantSkipped   bool
+	}{
+		{
+			name: "supplier empty, other warehouse has stock",
+			stocks: []httpclient.ProductWarehouseStockResponse{
+				{WarehouseID: 1, ProductID: 100, Stock: 0},
+				{WarehouseID: 2, ProductID: 100, Stock: 50},
+			},
+			wantWarehouse: 2,
+			wantQuantity:  18,
+		},
+		{
+			name: "other warehouse has more stock than supplier",
+			stocks: []httpclient.ProductWarehouseStockResponse{
+				{WarehouseID: 3, ProductID: 100, Stock: 10},
+				{WarehouseID: 1, ProductID: 100, Stock: 4},
+			},
+			wantWarehouse: 3,
+			wantQuantity:  10,
+		},
+		{
+			name: "tie keeps the supplier",
+			stocks: []httpclient.ProductWarehouseStockResponse{
+				{WarehouseID: 2, ProductID: 100, Stock: 30},
+				{WarehouseID: 1, ProductID: 100, Stock: 30},
+			},
+			wantWarehouse: 1,
+			wantQuantity:  18,
+		},
+		{
+			name: "no warehouse has stock",
+			stocks: []httpclient.ProductWarehouseStockResponse{
+				{WarehouseID: 1, ProductID: 100, Stock: 0},
+			},
+			wantSkipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &replenishmentUsecase{
+				merchantProductRepo: &fakeReplenishableRepo{merchantProducts: []model.MerchantProduct{merchantProduct}},
+				warehouseClient:     &fakeWarehouseStocks{stocks: map[uint][]httpclient.ProductWarehouseStockResponse{100: tt.stocks}},
+			}
+
+			suggestions, err := r.buildSuggestions(context.Background(), 1, nil)
+			if err != nil {
+				t.Fatalf("buildSuggestions: %v", err)
+			}
+
+			if tt.wantSkipped {
+				if len(suggestions) != 0 {
+					t.Fatalf("got %d suggestions, want none", len(suggestions))
+				}
+				return
+			}
+
+			if len(suggestions) != 1 || len(suggestions[0].Items) != 1 {
+				t.Fatalf("got %+v, want one order with one item", suggestions)
+			}
+
+			item := suggestions[0].Items[0]
+			if item.WarehouseID != tt.wantWarehouse {
+				t.Errorf("source warehouse = %d, want %d", item.WarehouseID, tt.wantWarehouse)
+			}
+			if item.SuggestedQuantity != tt.wantQuantity {
+				t.Errorf("suggested quantity = %d, want %d", item.SuggestedQuantity, tt.wantQuantity)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/pkg/rabbitmq"
+	"micro-warehouse/merchant-service/repository"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+const managerRoleName = "Manager"
+
+// StockAlertUsecaseInterface mengecek min_stock setelah stock berubah dan mengirim event merchant.stock.low
+type StockAlertUsecaseInterface interface {
+	CheckMovements(ctx context.Context, movements []model.MerchantStockMovement)
+	CheckLowStock(ctx context.Context, merchantProductID uint, previousStock, currentStock int)
+}
+
+type stockAlertUsecase struct {
+	merchantProductRepo repository.MerchantProductRepositoryInterface
+	merchantRepo        repository.MerchantRepositoryInterface
+	userClient          httpclient.UserClientInterface
+	productClient       httpclient.ProductClientInterface
+	rabbitMQService     *rabbitmq.RabbitMQService
+}
+
+// CheckMovements implements StockAlertUsecaseInterface.
+// Hanya movement yang mengurangi stock yang bisa membuat stock melewati threshold.
+func (s *stockAlertUsecase) CheckMovements(ctx context.Context, movements []model.MerchantStockMovement) {
+	for _, movement := range movements {
+		if movement.QuantityDelta >= 0 {
+			continue
+		}
+
+		s.CheckLowStock(ctx, movement.MerchantProductID, movement.BalanceAfter-movement.QuantityDelta, movement.BalanceAfter)
+	}
+}
+
+// CheckLowStock implements StockAlertUsecaseInterface.
+// previousStock dan currentStock adalah saldo sebelum dan sesudah satu perubahan yang tercatat, bukan stock saat ini,
+// karena perubahan lain bisa sudah terjadi saat pengecekan berjalan. Kegagalan mengirim alert hanya dicatat di log,
+// tidak membatalkan perubahan stock yang sudah tersimpan.
+func (s *stockAlertUsecase) CheckLowStock(ctx context.Context, merchantProductID uint, previousStock, currentStock int) {
+	merchantProduct, err := s.merchantProductRepo.GetMerchantProductByID(ctx, merchantProductID)
+	if err != nil {
+		log.Errorf("[StockAlertUsecase] CheckLowStock - 1: %v", err)
+		return
+	}
+	merchantProduct.Stock = currentStock
+
+	if !merchantProduct.CrossedBelowMinStock(previousStock) {
+		return
+	}
+
+	merchant, err := s.merchantRepo.GetMerchantByID(ctx, merchantProduct.MerchantID)
+	if err != nil {
+		log.Errorf("[StockAlertUsecase] CheckLowStock - 2: %v", err)
+		return
+	}
+
+	event := rabbitmq.StockLowEvent{
+		MerchantID:        merchant.ID,
+		MerchantName:      merchant.Name,
+		MerchantProductID: merchantProduct.ID,
+		ProductID:         merchantProduct.ProductID,
+		Stock:             merchantProduct.Stock,
+		MinStock:          merchantProduct.MinStock,
+		Recipients:        s.resolveRecipients(ctx, merchant.ActiveStaff(time.Now())),
+		Timestamp:         time.Now(),
+	}
+
+	product, err := s.productClient.GetProductByID(ctx, merchantProduct.ProductID)
+	if err != nil {
+		log.Errorf("[StockAlertUsecase] CheckLowStock - 3: %v", err)
+	} else {
+		event.ProductName = product.Name
+	}
+
+	if len(event.Recipients) == 0 {
+		log.Warnf("[StockAlertUsecase] CheckLowStock - no recipients for merchant %d, publishing anyway", merchant.ID)
+	}
+
+	if err := s.rabbitMQService.PublishStockLowEvent(ctx, event); err != nil {
+		log.Errorf("[StockAlertUsecase] CheckLowStock - 4: %v", err)
+		return
+	}
+
+	log.Infof("[StockAlertUsecase] CheckLowStock - merchant product %d is low on stock (%d < %d)", merchantProduct.ID, merchantProduct.Stock, merchantProduct.MinStock)
+}
+
+// resolveRecipients mengumpulkan email staff aktif merchant dan seluruh manager tanpa duplikat
+func (s *stockAlertUsecase) resolveRecipients(ctx context.Context, staff []model.MerchantStaff) []rabbitmq.StockLowRecipient {
+	recipients := []rabbitmq.StockLowRecipient{}
+	seen := make(map[string]bool)
+
+	add := func(user httpclient.UserResponse) {
+		email := strings.ToLower(strings.TrimSpace(user.Email))
+		if email == "" || seen[email] {
+			return
+		}
+		seen[email] = true
+		recipients = append(recipients, rabbitmq.StockLowRecipient{Name: user.Name, Email: user.Email})
+	}
+
+	for _, member := range staff {
+		user, err := s.userClient.GetUserByID(ctx, member.UserID)
+		if err != nil {
+			log.Errorf("[StockAlertUsecase] resolveRecipients - 1: %v", err)
+			continue
+		}
+		add(*user)
+	}
+
+	managers, err := s.userClient.GetUsersByRoleName(ctx, managerRoleName)
+	if err != nil {
+		log.Errorf("[StockAlertUsecase] resolveRecipients - 2: %v", err)
+	}
+	for _, manager := range managers {
+		add(manager)
+	}
+
+	return recipients
+}
+
+func NewStockAlertUsecase(merchantProductRepo repository.MerchantProductRepositoryInterface, merchantRepo repository.MerchantRepositoryInterface, userClient httpclient.UserClientInterface, productClient httpclient.ProductClientInterface, rabbitMQService *rabbitmq.RabbitMQService) StockAlertUsecaseInterface {
+	return &stockAlertUsecase{
+		merchantProductRepo: merchantProductRepo,
+		merchantRepo:        merchantRepo,
+		userClient:          userClient,
+		productClient:       productClient,
+		rabbitMQService:     rabbitMQService,
+	}
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/rabbitmq"
+	"micro-warehouse/merchant-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// StockReturnUsecaseInterface mengembalikan stock merchant ke warehouse asal.
+// Stock merchant dikurangi lebih dulu, lalu warehouse-service diberi tahu lewat event warehouse.stock.returned.
+type StockReturnUsecaseInterface interface {
+	CreateStockReturn(ctx context.Context, identity authz.Identity, stockReturn *model.StockReturn) error
+	GetStockReturns(ctx context.Context, identity authz.Identity, merchantID uint, page, limit int) ([]model.StockReturn, int64, error)
+	GetStockReturnByID(ctx context.Context, identity authz.Identity, id uint) (*model.StockReturn, error)
+	RepublishStockReturn(ctx context.Context, identity authz.Identity, id uint) (*model.StockReturn, error)
+}
+
+var ErrInvalidStockReturn = errors.New("invalid stock return")
+
+type stockReturnUsecase struct {
+	stockReturnRepo repository.StockReturnRepositoryInterface
+	merchantRepo    repository.MerchantRepositoryInterface
+	rabbitMQService *rabbitmq.RabbitMQService
+	stockAlert      StockAlertUsecaseInterface
+}
+
+// CreateStockReturn implements StockReturnUsecaseInterface.
+// Kegagalan publish tidak membatalkan return; published_at tetap kosong dan manager bisa republish.
+func (s *stockReturnUsecase) CreateStockReturn(ctx context.Context, identity authz.Identity, stockReturn *model.StockReturn) error {
+	if err := authorizeMerchantAccess(ctx, s.merchantRepo, identity, stockReturn.MerchantID); err != nil {
+		return err
+	}
+
+	// Gabungkan baris dengan produk dan kondisi yang sama
+	type lineKey struct {
+		productID uint
+		condition string
+	}
+	quantities := make(map[lineKey]int)
+	var lineOrder []lineKey
+	for _, item := range stockReturn.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity for product %d must be positive", ErrInvalidStockReturn, item.ProductID)
+		}
+
+		condition := item.Condition
+		if condition == "" {
+			condition = model.StockReturnConditionSellable
+		}
+		if condition != model.StockReturnConditionSellable && condition != model.StockReturnConditionDamaged {
+			return fmt.Errorf("%w: condition %s is not supported", ErrInvalidStockReturn, condition)
+		}
+
+		key := lineKey{productID: item.ProductID, condition: condition}
+		if _, exists := quantities[key]; !exists {
+			lineOrder = append(lineOrder, key)
+		}
+		quantities[key] += item.Quantity
+	}
+
+	if len(lineOrder) == 0 {
+		return fmt.Errorf("%w: at least one item is required", ErrInvalidStockReturn)
+	}
+
+	items := make([]model.StockReturnItem, 0, len(lineOrder))
+	for _, key := range lineOrder {
+		items = append(items, model.StockReturnItem{
+			ProductID: key.productID,
+			Quantity:  quantities[key],
+			Condition: key.condition,
+		})
+	}
+
+	stockReturn.Items = items
+	stockReturn.ReturnedBy = identity.UserID
+
+	movements, err := s.stockReturnRepo.CreateStockReturn(ctx, stockReturn)
+	if err != nil {
+		log.Errorf("[StockReturnUsecase] CreateStockReturn - 1: %v", err)
+		return err
+	}
+
+	s.publishStockReturn(ctx, stockReturn)
+
+	go s.stockAlert.CheckMovements(context.Background(), movements)
+
+	return nil
+}
+
+// GetStockReturns implements StockReturnUsecaseInterface.
+func (s *stockReturnUsecase) GetStockReturns(ctx context.Context, identity authz.Identity, merchantID uint, page int, limit int) ([]model.StockReturn, int64, error) {
+	merchantIDs, err := scopeMerchantIDs(ctx, s.merchantRepo, identity, merchantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stockReturns, total, err := s.stockReturnRepo.GetStockReturns(ctx, merchantIDs, page, limit)
+	if err != nil {
+		log.Errorf("[StockReturnUsecase] GetStockReturns - 1: %v", err)
+		return nil, 0, err
+	}
+
+	return stockReturns, total, nil
+}
+
+// GetStockReturnByID implements StockReturnUsecaseInterface.
+func (s *stockReturnUsecase) GetStockReturnByID(ctx context.Context, identity authz.Identity, id uint) (*model.StockReturn, error) {
+	stockReturn, err := s.stockReturnRepo.GetStockReturnByID(ctx, id)
+	if err != nil {
+		log.Errorf("[StockReturnUsecase] GetStockReturnByID - 1: %v", err)
+		return nil, err
+	}
+
+	if err := authorizeMerchantAccess(ctx, s.merchantRepo, identity, stockReturn.MerchantID); err != nil {
+		return nil, err
+	}
+
+	return stockReturn, nil
+}
+
+// RepublishStockReturn implements StockReturnUsecaseInterface.
+// Aman diulang karena warehouse-service dedupe per reference, produk dan kondisi.
+func (s *stockReturnUsecase) RepublishStockReturn(ctx context.Context, identity authz.Identity, id uint) (*model.StockReturn, error) {
+	if !identity.IsManager() {
+		return nil, authz.ErrForbidden
+	}
+
+	stockReturn, err := s.stockReturnRepo.GetStockReturnByID(ctx, id)
+	if err != nil {
+		log.Errorf("[StockReturnUsecase] RepublishStockReturn - 1: %v", err)
+		return nil, err
+	}
+
+	if !s.publishStockReturn(ctx, stockReturn) {
+		return nil, fmt.Errorf("failed to publish stock return %d", id)
+	}
+
+	return stockReturn, nil
+}
+
+// publishStockReturn mengirim satu event per warehouse asal dan menandai return sebagai terkirim
+// jika semua event berhasil dipublish.
+func (s *stockReturnUsecase) publishStockReturn(ctx context.Context, stockReturn *model.StockReturn) bool {
+	events := make(map[uint]*rabbitmq.StockReturnedEvent)
+	var warehouseOrder []uint
+	for _, item := range stockReturn.Items {
+		event, exists := events[item.WarehouseID]
+		if !exists {
+			event = &rabbitmq.StockReturnedEvent{
+				Reference:   stockReturn.Reference(),
+				MerchantID:  stockReturn.MerchantID,
+				WarehouseID: item.WarehouseID,
+				Reason:      stockReturn.Reason,
+				Timestamp:   time.Now(),
+			}
+			events[item.WarehouseID] = event
+			warehouseOrder = append(warehouseOrder, item.WarehouseID)
+		}
+
+		event.Items = append(event.Items, rabbitmq.StockReturnedItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Condition: item.Condition,
+		})
+	}
+
+	for _, warehouseID := range warehouseOrder {
+		if err := s.rabbitMQService.PublishStockReturnedEvent(ctx, *events[warehouseID]); err != nil {
+			log.Errorf("[StockReturnUsecase] publishStockReturn - 1: %v", err)
+			return false
+		}
+	}
+
+	if err := s.stockReturnRepo.MarkStockReturnPublished(ctx, stockReturn.ID); err != nil {
+		log.Errorf("[StockReturnUsecase] publishStockReturn - 2: %v", err)
+		return true
+	}
+
+	now := time.Now()
+	stockReturn.PublishedAt = &now
+	return true
+}
+
+func NewStockReturnUsecase(stockReturnRepo repository.StockReturnRepositoryInterface, merchantRepo repository.MerchantRepositoryInterface, rabbitMQService *rabbitmq.RabbitMQService, stockAlert StockAlertUsecaseInterface) StockReturnUsecaseInterface {
+	return &stockReturnUsecase{
+		stockReturnRepo: stockReturnRepo,
+		merchantRepo:    merchantRepo,
+		rabbitMQService: rabbitMQService,
+		stockAlert:      stockAlert,
+	}
+}
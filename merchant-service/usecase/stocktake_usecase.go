@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/repository"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// StocktakeUsecaseInterface mengelola sesi hitung fisik merchant.
+// Expected stock dibekukan saat sesi dibuka; penyesuaian baru diterapkan ke stock saat manager approve.
+type StocktakeUsecaseInterface interface {
+	OpenStocktake(ctx context.Context, identity authz.Identity, stocktake *model.Stocktake) error
+	GetStocktakes(ctx context.Context, identity authz.Identity, merchantID uint, status string, page, limit int) ([]model.Stocktake, int64, error)
+	GetStocktakeByID(ctx context.Context, identity authz.Identity, id uint) (*model.Stocktake, error)
+	RecordCount(ctx context.Context, identity authz.Identity, id uint, count StocktakeCount) (*model.StocktakeLine, error)
+	ApproveStocktake(ctx context.Context, identity authz.Identity, id uint, reasonCode, note string) (*model.Stocktake, error)
+	CancelStocktake(ctx context.Context, identity authz.Identity, id uint) (*model.Stocktake, error)
+}
+
+var ErrInvalidStocktake = errors.New("invalid stocktake")
+
+// StocktakeCount adalah satu hasil scan; Barcode dipakai jika ProductID kosong.
+// Replace=false menambahkan Quantity ke hitungan sebelumnya.
+type StocktakeCount struct {
+	Barcode    string
+	ProductID  uint
+	Quantity   int
+	Replace    bool
+	ReasonCode string
+}
+
+type stocktakeUsecase struct {
+	stocktakeRepo repository.StocktakeRepositoryInterface
+	merchantRepo  repository.MerchantRepositoryInterface
+	productClient httpclient.ProductClientInterface
+	stockAlert    StockAlertUsecaseInterface
+}
+
+// OpenStocktake implements StocktakeUsecaseInterface.
+// CategoryID diisi berarti hanya produk merchant dengan kategori tersebut yang ikut dihitung.
+func (s *stocktakeUsecase) OpenStocktake(ctx context.Context, identity authz.Identity, stocktake *model.Stocktake) error {
+	if err := authorizeMerchantAccess(ctx, s.merchantRepo, identity, stocktake.MerchantID); err != nil {
+		return err
+	}
+
+	stocktake.OpenedBy = identity.UserID
+	if err := s.stocktakeRepo.CreateStocktake(ctx, stocktake); err != nil {
+		log.Errorf("[StocktakeUsecase] OpenStocktake - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetStocktakes implements StocktakeUsecaseInterface.
+func (s *stocktakeUsecase) GetStocktakes(ctx context.Context, identity authz.Identity, merchantID uint, status string, page int, limit int) ([]model.Stocktake, int64, error) {
+	merchantIDs, err := scopeMerchantIDs(ctx, s.merchantRepo, identity, merchantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stocktakes, total, err := s.stocktakeRepo.GetStocktakes(ctx, merchantIDs, status, page, limit)
+	if err != nil {
+		log.Errorf("[StocktakeUsecase] GetStocktakes - 1: %v", err)
+		return nil, 0, err
+	}
+
+	return stocktakes, total, nil
+}
+
+// GetStocktakeByID implements StocktakeUsecaseInterface.
+func (s *stocktakeUsecase) GetStocktakeByID(ctx context.Context, identity authz.Identity, id uint) (*model.Stocktake, error) {
+	stocktake, err := s.stocktakeRepo.GetStocktakeByID(ctx, id)
+	if err != nil {
+		log.Errorf("[StocktakeUsecase] GetStocktakeByID - 1: %v", err)
+		return nil, err
+	}
+
+	if err := authorizeMerchantAccess(ctx, s.merchantRepo, identity, stocktake.MerchantID); err != nil {
+		return nil, err
+	}
+
+	return stocktake, nil
+}
+
+// RecordCount implements StocktakeUsecaseInterface.
+func (s *stocktakeUsecase) RecordCount(ctx context.Context, identity authz.Identity, id uint, count StocktakeCount) (*model.StocktakeLine, error) {
+	if _, err := s.GetStocktakeByID(ctx, identity, id); err != nil {
+		return nil, err
+	}
+
+	productID := count.ProductID
+	if productID == 0 {
+		if count.Barcode == "" {
+			return nil, fmt.Errorf("%w: barcode or product_id is required", ErrInvalidStocktake)
+		}
+
+		product, err := s.productClient.GetProductByBarcode(ctx, count.Barcode)
+		if err != nil {
+			log.Errorf("[StocktakeUsecase] RecordCount - 1: %v", err)
+			return nil, repository.ErrStocktakeLineNotFound
+		}
+		productID = product.ID
+	}
+
+	line, err := s.stocktakeRepo.RecordCount(ctx, id, productID, count.Quantity, count.Replace, identity.UserID, count.ReasonCode)
+	if err != nil {
+		log.Errorf("[StocktakeUsecase] RecordCount - 2: %v", err)
+		return nil, err
+	}
+
+	return line, nil
+}
+
+// ApproveStocktake implements StocktakeUsecaseInterface.
+// reasonCode dipakai untuk baris yang tidak punya reason code sendiri.
+func (s *stocktakeUsecase) ApproveStocktake(ctx context.Context, identity authz.Identity, id uint, reasonCode string, note string) (*model.Stocktake, error) {
+	if !identity.IsManager() {
+		return nil, authz.ErrForbidden
+	}
+
+	movements, err := s.stocktakeRepo.ApproveStocktake(ctx, id, identity.UserID, reasonCode, note)
+	if err != nil {
+		log.Errorf("[StocktakeUsecase] ApproveStocktake - 1: %v", err)
+		return nil, err
+	}
+
+	go s.stockAlert.CheckMovements(context.Background(), movements)
+
+	return s.stocktakeRepo.GetStocktakeByID(ctx, id)
+}
+
+// CancelStocktake implements StocktakeUsecaseInterface.
+func (s *stocktakeUsecase) CancelStocktake(ctx context.Context, identity authz.Identity, id uint) (*model.Stocktake, error) {
+	if _, err := s.GetStocktakeByID(ctx, identity, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.stocktakeRepo.CancelStocktake(ctx, id); err != nil {
+		log.Errorf("[StocktakeUsecase] CancelStocktake - 1: %v", err)
+		return nil, err
+	}
+
+	return s.stocktakeRepo.GetStocktakeByID(ctx, id)
+}
+
+func NewStocktakeUsecase(stocktakeRepo repository.StocktakeRepositoryInterface, merchantRepo repository.MerchantRepositoryInterface, productClient httpclient.ProductClientInterface, stockAlert StockAlertUsecaseInterface) StocktakeUsecaseInterface {
+	return &stocktakeUsecase{
+		stocktakeRepo: stocktakeRepo,
+		merchantRepo:  merchantRepo,
+		productClient: productClient,
+		stockAlert:    stockAlert,
+	}
+}
@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// TransferOrderUsecaseInterface mengatur alur permintaan stock dari merchant ke warehouse.
+// Keeper hanya bisa mengakses order milik merchant yang ia kelola; approve/pick/ship khusus manager.
+type TransferOrderUsecaseInterface interface {
+	CreateTransferOrder(ctx context.Context, identity authz.Identity, transferOrder *model.TransferOrder) error
+	GetTransferOrders(ctx context.Context, identity authz.Identity, merchantID, warehouseID uint, status string, page, limit int) ([]model.TransferOrder, int64, error)
+	GetTransferOrderByID(ctx context.Context, identity authz.Identity, id uint) (*model.TransferOrder, error)
+
+	ApproveTransferOrder(ctx context.Context, identity authz.Identity, id uint, note string) error
+	RejectTransferOrder(ctx context.Context, identity authz.Identity, id uint, note string) error
+	CancelTransferOrder(ctx context.Context, identity authz.Identity, id uint) error
+	PickTransferOrder(ctx context.Context, identity authz.Identity, id uint) error
+	ShipTransferOrder(ctx context.Context, identity authz.Identity, id uint, shippedQuantities map[uint]int) error
+	ReceiveTransferOrder(ctx context.Context, identity authz.Identity, id uint, receipt *model.TransferOrderReceipt) (*model.TransferOrder, error)
+}
+
+var ErrInvalidTransferOrder = errors.New("invalid transfer order")
+
+type transferOrderUsecase struct {
+	transferOrderRepo repository.TransferOrderRepositoryInterface
+	merchantRepo      repository.MerchantRepositoryInterface
+	warehouseClient   httpclient.WarehouseClientInterface
+}
+
+// CreateTransferOrder implements TransferOrderUsecaseInterface.
+func (t *transferOrderUsecase) CreateTransferOrder(ctx context.Context, identity authz.Identity, transferOrder *model.TransferOrder) error {
+	if err := authorizeMerchantAccess(ctx, t.merchantRepo, identity, transferOrder.MerchantID); err != nil {
+		return err
+	}
+
+	if _, err := t.warehouseClient.GetWarehouseByID(ctx, transferOrder.WarehouseID); err != nil {
+		log.Errorf("[TransferOrderUsecase] CreateTransferOrder - 1: %v", err)
+		return fmt.Errorf("%w: warehouse %d not found", ErrInvalidTransferOrder, transferOrder.WarehouseID)
+	}
+
+	// Gabungkan baris dengan produk yang sama agar satu produk hanya punya satu item
+	quantities := make(map[uint]int)
+	var productOrder []uint
+	for _, item := range transferOrder.Items {
+		if item.RequestedQuantity <= 0 {
+			return fmt.Errorf("%w: quantity for product %d must be positive", ErrInvalidTransferOrder, item.ProductID)
+		}
+		if _, exists := quantities[item.ProductID]; !exists {
+			productOrder = append(productOrder, item.ProductID)
+		}
+		quantities[item.ProductID] += item.RequestedQuantity
+	}
+
+	if len(productOrder) == 0 {
+		return fmt.Errorf("%w: at least one item is required", ErrInvalidTransferOrder)
+	}
+
+	items := make([]model.TransferOrderItem, 0, len(productOrder))
+	for _, productID := range productOrder {
+		items = append(items, model.TransferOrderItem{
+			ProductID:         productID,
+			RequestedQuantity: quantities[productID],
+		})
+	}
+
+	transferOrder.Items = items
+	transferOrder.Status = model.TransferOrderStatusRequested
+	transferOrder.RequestedBy = identity.UserID
+
+	if err := t.transferOrderRepo.CreateTransferOrder(ctx, transferOrder); err != nil {
+		log.Errorf("[TransferOrderUsecase] CreateTransferOrder - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetTransferOrders implements TransferOrderUsecaseInterface.
+func (t *transferOrderUsecase) GetTransferOrders(ctx context.Context, identity authz.Identity, merchantID uint, warehouseID uint, status string, page int, limit int) ([]model.TransferOrder, int64, error) {
+	merchantIDs, err := scopeMerchantIDs(ctx, t.merchantRepo, identity, merchantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	transferOrders, total, err := t.transferOrderRepo.GetTransferOrders(ctx, merchantIDs, warehouseID, status, page, limit)
+	if err != nil {
+		log.Errorf("[TransferOrderUsecase] GetTransferOrders - 1: %v", err)
+		return nil, 0, err
+	}
+
+	return transferOrders, total, nil
+}
+
+// GetTransferOrderByID implements TransferOrderUsecaseInterface.
+func (t *transferOrderUsecase) GetTransferOrderByID(ctx context.Context, identity authz.Identity, id uint) (*model.TransferOrder, error) {
+	transferOrder, err := t.transferOrderRepo.GetTransferOrderByID(ctx, id)
+	if err != nil {
+		log.Errorf("[TransferOrderUsecase] GetTransferOrderByID - 1: %v", err)
+		return nil, err
+	}
+
+	if err := authorizeMerchantAccess(ctx, t.merchantRepo, identity, transferOrder.MerchantID); err != nil {
+		return nil, err
+	}
+
+	return transferOrder, nil
+}
+
+// ApproveTransferOrder implements TransferOrderUsecaseInterface.
+func (t *transferOrderUsecase) ApproveTransferOrder(ctx context.Context, identity authz.Identity, id uint, note string) error {
+	return t.review(ctx, identity, id, model.TransferOrderStatusApproved, note)
+}
+
+// RejectTransferOrder implements TransferOrderUsecaseInterface.
+func (t *transferOrderUsecase) RejectTransferOrder(ctx context.Context, identity authz.Identity, id uint, note string) error {
+	return t.review(ctx, identity, id, model.TransferOrderStatusRejected, note)
+}
+
+// CancelTransferOrder implements TransferOrderUsecaseInterface.
+// Order hanya bisa dibatalkan sebelum barang di-pick, oleh manager atau keeper merchant tersebut.
+func (t *transferOrderUsecase) CancelTransferOrder(ctx context.Context, identity authz.Identity, id uint) error {
+	if _, err := t.GetTransferOrderByID(ctx, identity, id); err != nil {
+		return err
+	}
+
+	err := t.transferOrderRepo.UpdateTransferOrderStatus(ctx, id,
+		[]string{model.TransferOrderStatusRequested, model.TransferOrderStatusApproved},
+		map[string]interface{}{"status": model.TransferOrderStatusCancelled},
+	)
+	if err != nil {
+		log.Errorf("[TransferOrderUsecase] CancelTransferOrder - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// PickTransferOrder implements TransferOrderUsecaseInterface.
+func (t *transferOrderUsecase) PickTransferOrder(ctx context.Context, identity authz.Identity, id uint) error {
+	if !identity.IsManager() {
+		return authz.ErrForbidden
+	}
+
+	err := t.transferOrderRepo.UpdateTransferOrderStatus(ctx, id,
+		[]string{model.TransferOrderStatusApproved},
+		map[string]interface{}{
+			"status":    model.TransferOrderStatusPicked,
+			"picked_by": identity.UserID,
+			"picked_at": time.Now(),
+		},
+	)
+	if err != nil {
+		log.Errorf("[TransferOrderUsecase] PickTransferOrder - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ShipTransferOrder implements TransferOrderUsecaseInterface.
+// shippedQuantities kosong berarti semua item dikirim sesuai permintaan; produk yang tidak disebut dianggap tidak dikirim.
+// Stock warehouse dikurangi lebih dulu dengan reference transfer-order:{id} yang idempotent,
+// sehingga jika update status gagal, ship dapat diulang tanpa mengurangi stock warehouse dua kali.
+// Jumlah yang dicatat sebagai shipped selalu diambil dari deduction warehouse, sehingga ship yang diulang
+// dengan jumlah berbeda tetap mencatat jumlah yang benar-benar dikurangi pada percobaan pertama.
+func (t *transferOrderUsecase) ShipTransferOrder(ctx context.Context, identity authz.Identity, id uint, shippedQuantities map[uint]int) error {
+	if !identity.IsManager() {
+		return authz.ErrForbidden
+	}
+
+	transferOrder, err := t.transferOrderRepo.GetTransferOrderByID(ctx, id)
+	if err != nil {
+		log.Errorf("[TransferOrderUsecase] ShipTransferOrder - 1: %v", err)
+		return err
+	}
+
+	if transferOrder.Status != model.TransferOrderStatusPicked {
+		return repository.ErrTransferOrderInvalidStatus
+	}
+
+	quantities := make(map[uint]int, len(transferOrder.Items))
+	var deductions []httpclient.WarehouseStockDeductionItem
+	for _, item := range transferOrder.Items {
+		quantity := item.RequestedQuantity
+		if len(shippedQuantities) > 0 {
+			quantity = shippedQuantities[item.ProductID]
+		}
+
+		if quantity < 0 || quantity > item.RequestedQuantity {
+			return fmt.Errorf("%w: shipped quantity for product %d must be between 0 and %d", repository.ErrTransferOrderInvalidQuantity, item.ProductID, item.RequestedQuantity)
+		}
+
+		quantities[item.ProductID] = quantity
+		if quantity > 0 {
+			deductions = append(deductions, httpclient.WarehouseStockDeductionItem{
+				ProductID: item.ProductID,
+				Quantity:  quantity,
+			})
+		}
+	}
+
+	for productID := range shippedQuantities {
+		if _, ok := quantities[productID]; !ok {
+			return fmt.Errorf("%w: product %d is not part of this transfer order", repository.ErrTransferOrderInvalidQuantity, productID)
+		}
+	}
+
+	if len(deductions) == 0 {
+		return fmt.Errorf("%w: at least one item must be shipped", repository.ErrTransferOrderInvalidQuantity)
+	}
+
+	reference := fmt.Sprintf("transfer-order:%d", transferOrder.ID)
+	deducted, err := t.warehouseClient.DeductWarehouseStocks(ctx, transferOrder.WarehouseID, reference, deductions)
+	if err != nil {
+		log.Errorf("[TransferOrderUsecase] ShipTransferOrder - 2: %v", err)
+		return err
+	}
+
+	// Deduction lama yang belum menyimpan item tidak mengembalikan apa pun; jumlah dari request dipakai
+	if len(deducted) > 0 {
+		for productID := range quantities {
+			quantities[productID] = 0
+		}
+		for _, item := range deducted {
+			if _, ok := quantities[item.ProductID]; !ok {
+				log.Errorf("[TransferOrderUsecase] ShipTransferOrder - 4: %s deducted product %d", reference, item.ProductID)
+				return fmt.Errorf("%s deducted product %d which is not part of this transfer order", reference, item.ProductID)
+			}
+			quantities[item.ProductID] = item.Quantity
+		}
+	}
+
+	if err := t.transferOrderRepo.ShipTransferOrder(ctx, id, quantities, identity.UserID); err != nil {
+		log.Errorf("[TransferOrderUsecase] ShipTransferOrder - 3: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ReceiveTransferOrder implements TransferOrderUsecaseInterface.
+func (t *transferOrderUsecase) ReceiveTransferOrder(ctx context.Context, identity authz.Identity, id uint, receipt *model.TransferOrderReceipt) (*model.TransferOrder, error) {
+	if _, err := t.GetTransferOrderByID(ctx, identity, id); err != nil {
+		return nil, err
+	}
+
+	if len(receipt.Lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one receipt line is required", repository.ErrTransferOrderInvalidQuantity)
+	}
+
+	for _, line := range receipt.Lines {
+		if line.ReceivedQuantity < 0 || line.DiscrepancyQuantity < 0 || line.ReceivedQuantity+line.DiscrepancyQuantity == 0 {
+			return nil, fmt.Errorf("%w: product %d needs a received or discrepancy quantity", repository.ErrTransferOrderInvalidQuantity, line.ProductID)
+		}
+
+		if line.DiscrepancyQuantity > 0 && line.DiscrepancyType == "" {
+			return nil, fmt.Errorf("%w: discrepancy type is required for product %d", ErrInvalidTransferOrder, line.ProductID)
+		}
+	}
+
+	receipt.ReceivedBy = identity.UserID
+
+	transferOrder, err := t.transferOrderRepo.ReceiveTransferOrder(ctx, id, receipt)
+	if err != nil {
+		log.Errorf("[TransferOrderUsecase] ReceiveTransferOrder - 1: %v", err)
+		return nil, err
+	}
+
+	return transferOrder, nil
+}
+
+func NewTransferOrderUsecase(transferOrderRepo repository.TransferOrderRepositoryInterface, merchantRepo repository.MerchantRepositoryInterface, warehouseClient httpclient.WarehouseClientInterface) TransferOrderUsecaseInterface {
+	return &transferOrderUsecase{
+		transferOrderRepo: transferOrderRepo,
+		merchantRepo:      merchantRepo,
+		warehouseClient:   warehouseClient,
+	}
+}
+
+func (t *transferOrderUsecase) review(ctx context.Context, identity authz.Identity, id uint, status string, note string) error {
+	if !identity.IsManager() {
+		return authz.ErrForbidden
+	}
+
+	err := t.transferOrderRepo.UpdateTransferOrderStatus(ctx, id,
+		[]string{model.TransferOrderStatusRequested},
+		map[string]interface{}{
+			"status":      status,
+			"reviewed_by": identity.UserID,
+			"reviewed_at": time.Now(),
+			"review_note": note,
+		},
+	)
+	if err != nil {
+		log.Errorf("[TransferOrderUsecase] review - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"micro-warehouse/merchant-service/model"
+	"micro-warehouse/merchant-service/pkg/authz"
+	"micro-warehouse/merchant-service/pkg/httpclient"
+	"micro-warehouse/merchant-service/repository"
+	"testing"
+)
+
+// fakeTransferOrderRepo gagal pada update status pertama untuk mensimulasikan ship yang harus diulang
+type fakeTransferOrderRepo struct {
+	repository.TransferOrderRepositoryInterface
+	transferOrder model.TransferOrder
+	shipFailures  int
+	shipped       map[uint]int
+}
+
+func (f *fakeTransferOrderRepo) GetTransferOrderByID(ctx context.Context, id uint) (*model.TransferOrder, error) {
+	transferOrder := f.transferOrder
+	return &transferOrder, nil
+}
+
+func (f *fakeTransferOrderRepo) ShipTransferOrder(ctx context.Context, id uint, shippedQuantities map[uint]int, shippedBy uint) error {
+	if f.shipFailures > 0 {
+		f.shipFailures--
+		return errors.New("connection reset")
+	}
+	f.shipped = shippedQuantities
+	return nil
+}
+
+// fakeDeductionWarehouse meniru warehouse-service: reference yang sama hanya dikurangi sekali
+// dan request berikutnya mendapat item yang tercatat pada request pertama
+type fakeDeductionWarehouse struct {
+	httpclient.WarehouseClientInterface
+	deductions map[string][]httpclient.WarehouseStockDeductionItem
+	calls      int
+}
+
+func (f *fakeDeductionWarehouse) DeductWarehouseStocks(ctx context.Context, warehouseID uint, reference string, items []httpclient.WarehouseStockDeductionItem) ([]httpclient.WarehouseStockDeductionItem, error) {
+	f.calls++
+	if recorded, ok := f.deductions[reference]; ok {
+		return recorded, nil
+	}
+	f.deductions[reference] = items
+	return items, nil
+}
+
+func TestShipTransferOrderRetryUsesRecordedDeduction(t *testing.T) {
+	repo := &fakeTransferOrderRepo{
+		transferOrder: model.TransferOrder{
+			ID:          12,
+			WarehouseID: 3,
+			Status:      model.TransferOrderStatusPicked,
+			Items: []model.TransferOrderItem{
+				{ProductID: 100, RequestedQuantity: 10},
+				{ProductID: 200, RequestedQuantity: 5},
+			},
+		},
+		shipFailures: 1,
+	}
+	warehouse := &fakeDeductionWarehouse{deductions: map[string][]httpclient.WarehouseStockDeductionItem{}}
+	uc := NewTransferOrderUsecase(repo, nil, warehouse)
+	manager := authz.Identity{UserID: 1, Roles: []string{authz.RoleManager}}
+
+	if err := uc.ShipTransferOrder(context.Background(), manager, 12, map[uint]int{100: 4, 200: 5}); err == nil {
+		t.Fatal("expected the first ship to fail on the status update")
+	}
+
+	// Percobaan ulang mengirim jumlah berbeda; yang dicatat harus tetap jumlah yang sudah dikurangi warehouse
+	if err := uc.ShipTransferOrder(context.Background(), manager, 12, map[uint]int{100: 10}); err != nil {
+		t.Fatalf("retry ship: %v", err)
+	}
+
+	if warehouse.calls != 2 {
+		t.Fatalf("expected 2 deduction requests, got %d", warehouse.calls)
+	}
+
+	want := map[uint]int{100: 4, 200: 5}
+	for productID, quantity := range want {
+		if repo.shipped[productID] != quantity {
+			t.Errorf("product %d: shipped %d, want %d", productID, repo.shipped[productID], quantity)
+		}
+	}
+	if len(repo.shipped) != len(want) {
+		t.Errorf("shipped %v, want %v", repo.shipped, want)
+	}
+}
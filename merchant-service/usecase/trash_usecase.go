@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"micro-warehouse/merchant-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type TrashUsecaseInterface interface {
+	PurgeDeleted(ctx context.Context, retention time.Duration) (*TrashPurgeResult, error)
+}
+
+// TrashPurgeResult adalah jumlah baris yang dihapus permanen per jenis entity. RetainedMerchants adalah merchant
+// yang sudah melewati masa retensi tetapi masih direferensikan riwayat order, retur atau stocktake.
+type TrashPurgeResult struct {
+	MerchantProducts  int64
+	Merchants         int64
+	RetainedMerchants int64
+}
+
+type trashUsecase struct {
+	merchantRepo        repository.MerchantRepositoryInterface
+	merchantProductRepo repository.MerchantProductRepositoryInterface
+}
+
+// PurgeDeleted implements TrashUsecaseInterface.
+// Merchant product di-purge lebih dulu supaya merchant yang seluruh product-nya sudah kedaluwarsa ikut terhapus.
+func (t *trashUsecase) PurgeDeleted(ctx context.Context, retention time.Duration) (*TrashPurgeResult, error) {
+	deletedBefore := time.Now().Add(-retention)
+	result := &TrashPurgeResult{}
+
+	merchantProducts, err := t.merchantProductRepo.PurgeDeletedMerchantProducts(ctx, deletedBefore)
+	if err != nil {
+		log.Errorf("[TrashUsecase] PurgeDeleted - 1: %v", err)
+		return result, err
+	}
+	result.MerchantProducts = merchantProducts
+
+	merchants, err := t.merchantRepo.PurgeDeletedMerchants(ctx, deletedBefore)
+	if err != nil {
+		log.Errorf("[TrashUsecase] PurgeDeleted - 2: %v", err)
+		return result, err
+	}
+	result.Merchants = merchants.Purged
+	result.RetainedMerchants = merchants.Retained
+
+	return result, nil
+}
+
+func NewTrashUsecase(merchantRepo repository.MerchantRepositoryInterface, merchantProductRepo repository.MerchantProductRepositoryInterface) TrashUsecaseInterface {
+	return &trashUsecase{merchantRepo: merchantRepo, merchantProductRepo: merchantProductRepo}
+}
@@ -40,6 +40,11 @@ func RunServer() {
 		log.Errorw("Failed to start email consumer", "error", err)
 	}
 
+	err = rabbitMQService.ConsumeStockLow(consumerCtx, emailService)
+	if err != nil {
+		log.Errorw("Failed to start stock low consumer", "error", err)
+	}
+
 	zerolog.Printf("RabbitMQ consumers started successfully")
 
 	app := fiber.New(fiber.Config{
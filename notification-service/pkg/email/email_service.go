@@ -14,6 +14,7 @@ import (
 type EmailServiceInterface interface {
 	SendWelcomeEmail(ctx context.Context, payload EmailPayload) error
 	SendCustomEmail(ctx context.Context, to, subject, body string) error
+	SendLowStockEmail(ctx context.Context, recipient LowStockRecipient, payload LowStockPayload) error
 }
 
 type EmailPayload struct {
@@ -24,6 +25,23 @@ type EmailPayload struct {
 	Name     string `json:"name"`
 }
 
+// LowStockPayload adalah isi event merchant.stock.low dari merchant-service
+type LowStockPayload struct {
+	MerchantID        uint                `json:"merchant_id"`
+	MerchantName      string              `json:"merchant_name"`
+	MerchantProductID uint                `json:"merchant_product_id"`
+	ProductID         uint                `json:"product_id"`
+	ProductName       string              `json:"product_name"`
+	Stock             int                 `json:"stock"`
+	MinStock          int                 `json:"min_stock"`
+	Recipients        []LowStockRecipient `json:"recipients"`
+}
+
+type LowStockRecipient struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
 type emailService struct {
 	cfg configs.Config
 }
@@ -118,6 +136,75 @@ func (e *emailService) SendWelcomeEmail(ctx context.Context, payload EmailPayloa
 	return nil
 }
 
+// SendLowStockEmail implements EmailServiceInterface.
+func (e *emailService) SendLowStockEmail(ctx context.Context, recipient LowStockRecipient, payload LowStockPayload) error {
+	productName := payload.ProductName
+	if productName == "" {
+		productName = fmt.Sprintf("Produk #%d", payload.ProductID)
+	}
+
+	subject := fmt.Sprintf("Stok menipis: %s di %s", productName, payload.MerchantName)
+
+	htmlTemplate := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<meta charset="UTF-8">
+			<title>Stok Menipis</title>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #E67E22; color: white; padding: 20px; text-align: center; }
+				.content { padding: 20px; background-color: #f9f9f9; }
+				.footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>Stok Menipis</h1>
+				</div>
+				<div class="content">
+					<h2>Halo {{.Name}},</h2>
+					<p>Stok produk berikut di merchant <strong>{{.MerchantName}}</strong> sudah berada di bawah batas minimum.</p>
+					<p><strong>Produk:</strong> {{.ProductName}}</p>
+					<p><strong>Stok saat ini:</strong> {{.Stock}}</p>
+					<p><strong>Stok minimum:</strong> {{.MinStock}}</p>
+					<p>Silakan ajukan pengisian ulang stok dari warehouse.</p>
+				</div>
+				<div class="footer">
+					<p>Email ini dikirim otomatis, mohon tidak membalas email ini.</p>
+				</div>
+			</div>
+		</body>
+		</html>`
+	tmpl, err := template.New("low_stock").Parse(htmlTemplate)
+	if err != nil {
+		log.Errorf("[EmailService] SendLowStockEmail - 1: %v", err)
+		return fmt.Errorf("failed to parse email template: %v", err)
+	}
+
+	var body strings.Builder
+	err = tmpl.Execute(&body, map[string]interface{}{
+		"Name":         recipient.Name,
+		"MerchantName": payload.MerchantName,
+		"ProductName":  productName,
+		"Stock":        payload.Stock,
+		"MinStock":     payload.MinStock,
+	})
+	if err != nil {
+		log.Errorf("[EmailService] SendLowStockEmail - 2: %v", err)
+		return fmt.Errorf("failed to execute email template: %v", err)
+	}
+
+	if err := e.SendCustomEmail(ctx, recipient.Email, subject, body.String()); err != nil {
+		log.Errorf("[EmailService] SendLowStockEmail - 3: %v", err)
+		return fmt.Errorf("failed to send low stock email: %v", err)
+	}
+
+	return nil
+}
+
 func NewEmailService(cfg configs.Config) EmailServiceInterface {
 	return &emailService{
 		cfg: cfg,
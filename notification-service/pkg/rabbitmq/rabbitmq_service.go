@@ -12,6 +12,7 @@ import (
 
 type RabbitMQServiceInterface interface {
 	ConsumeEmail(ctx context.Context, emailService email.EmailServiceInterface) error
+	ConsumeStockLow(ctx context.Context, emailService email.EmailServiceInterface) error
 	Close() error
 }
 
@@ -116,6 +117,112 @@ func (r *rabbitMQService) ConsumeEmail(ctx context.Context, emailService email.E
 	return nil
 }
 
+// ConsumeStockLow implements RabbitMQServiceInterface.
+// Event merchant.stock.low dari merchant-service sudah membawa daftar penerima (staff aktif merchant dan manager).
+func (r *rabbitMQService) ConsumeStockLow(ctx context.Context, emailService email.EmailServiceInterface) error {
+	ch, err := r.conn.Channel()
+	if err != nil {
+		log.Errorf("[RabbitMQService] ConsumeStockLow - 1: %v", err)
+		return err
+	}
+
+	err = ch.ExchangeDeclare(
+		"business_events", // name
+		"topic",           // type
+		true,              // durable
+		false,             // auto-deleted
+		false,             // internal
+		false,             // no-wait
+		nil,               // arguments
+	)
+	if err != nil {
+		log.Errorf("[RabbitMQService] ConsumeStockLow - 2: %v", err)
+		return err
+	}
+
+	queue, err := ch.QueueDeclare(
+		"notification_stock_low_queue", // name
+		true,                           // durable
+		false,                          // delete when unused
+		false,                          // exclusive
+		false,                          // no-wait
+		nil,                            // arguments
+	)
+	if err != nil {
+		log.Errorf("[RabbitMQService] ConsumeStockLow - 3: %v", err)
+		return err
+	}
+
+	if err := ch.QueueBind(queue.Name, "merchant.stock.low", "business_events", false, nil); err != nil {
+		log.Errorf("[RabbitMQService] ConsumeStockLow - 4: %v", err)
+		return err
+	}
+
+	msgs, err := ch.Consume(
+		queue.Name,
+		"",
+		false, // auto-ack false, kita akan ack manual
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		log.Errorf("[RabbitMQService] ConsumeStockLow - 5: %v", err)
+		return err
+	}
+
+	go func() {
+		defer ch.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("Stock low consumer context cancelled")
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					log.Info("Stock low consumer channel closed")
+					return
+				}
+
+				var payload email.LowStockPayload
+				if err := json.Unmarshal(msg.Body, &payload); err != nil {
+					log.Errorf("[RabbitMQService] ConsumeStockLow - 6: JSON unmarshal error: %v, Raw message: %s", err, string(msg.Body))
+					msg.Nack(false, false)
+					continue
+				}
+
+				if len(payload.Recipients) == 0 {
+					log.Warnf("[RabbitMQService] ConsumeStockLow - No recipients for merchant product %d", payload.MerchantProductID)
+					msg.Ack(false)
+					continue
+				}
+
+				sent := 0
+				for _, recipient := range payload.Recipients {
+					if err := emailService.SendLowStockEmail(ctx, recipient, payload); err != nil {
+						log.Errorf("[RabbitMQService] ConsumeStockLow - 7: %s: %v", recipient.Email, err)
+						continue
+					}
+					sent++
+				}
+
+				// Requeue hanya jika tidak ada email yang terkirim, agar penerima lain tidak menerima email ganda
+				if sent == 0 {
+					msg.Nack(false, true)
+					continue
+				}
+
+				log.Infof("[RabbitMQService] ConsumeStockLow - 8: Low stock email sent to %d of %d recipients", sent, len(payload.Recipients))
+				msg.Ack(false)
+			}
+		}
+	}()
+
+	return nil
+}
+
 func NewRabbitMQService(config configs.Config) (RabbitMQServiceInterface, error) {
 	conn, err := amqp.Dial(config.RabbitMQ.URL())
 	if err != nil {
@@ -5,6 +5,8 @@ import (
 	"micro-warehouse/product-service/configs"
 	"micro-warehouse/product-service/controller"
 	"micro-warehouse/product-service/database"
+	"micro-warehouse/product-service/pkg/httpclient"
+	"micro-warehouse/product-service/pkg/rabbitmq"
 	"micro-warehouse/product-service/pkg/storage"
 	"micro-warehouse/product-service/repository"
 	"micro-warehouse/product-service/usecase"
@@ -14,6 +16,7 @@ type Container struct {
 	ProductController  controller.ProductControllerInterface
 	CategoryController controller.CategoryControllerInterface
 	UploadController   controller.UploadControllerInterface
+	ProductUsecase     usecase.ProductUsecaseInterface
 }
 
 func BuildContainer() *Container {
@@ -23,12 +26,19 @@ func BuildContainer() *Container {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	eventPublisher, err := rabbitmq.NewRabbitMQPublisher(config.RabbitMQ.URL())
+	if err != nil {
+		log.Fatalf("Failed to connect to rabbitmq: %v", err)
+	}
+
 	categoryRepo := repository.NewCategoryRepository(db.DB)
 	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo)
 	categoryController := controller.NewCategoryController(categoryUsecase)
 
 	productRepo := repository.NewProductRepository(db.DB)
-	productUsecase := usecase.NewProductUsecase(productRepo)
+	warehouseClient := httpclient.NewWarehouseClient(*config)
+	merchantClient := httpclient.NewMerchantClient(*config)
+	productUsecase := usecase.NewProductUsecase(productRepo, warehouseClient, merchantClient, eventPublisher)
 	productController := controller.NewProductController(productUsecase)
 
 	supabaseStorage := storage.NewSupabaseStorage(*config)
@@ -39,5 +49,6 @@ func BuildContainer() *Container {
 		ProductController:  productController,
 		CategoryController: categoryController,
 		UploadController:   uploadController,
+		ProductUsecase:     productUsecase,
 	}
 }
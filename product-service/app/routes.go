@@ -16,10 +16,13 @@ func SetupRoutes(app *fiber.App, container *Container) {
 
 	products.Post("/", container.ProductController.CreateProduct)
 	products.Get("/", container.ProductController.GetAllProducts)
+	products.Get("/trash", container.ProductController.GetDeletedProducts)
 	products.Get("/:id", container.ProductController.GetProductByID)
 	products.Get("/barcode/:barcode", container.ProductController.GetProductByBarcode)
+	products.Post("/barcodes", container.ProductController.GetProductsByBarcodes)
 	products.Put("/:id", container.ProductController.UpdateProduct)
 	products.Delete("/:id", container.ProductController.DeleteProduct)
+	products.Post("/:id/restore", container.ProductController.RestoreProduct)
 
 	uploads.Post("/product-image", container.UploadController.UploadProductImage)
 	uploads.Post("/category-image", container.UploadController.UploadCategoryImage)
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"micro-warehouse/product-service/app"
+	"micro-warehouse/product-service/configs"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/spf13/cobra"
+)
+
+const defaultSoftDeleteRetentionDays = 30
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage soft-deleted products",
+}
+
+// Dijalankan terjadwal (cron); product yang sudah dihapus permanen tidak bisa di-restore lagi
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently remove products that have been in the trash longer than the retention period",
+	Run: func(cmd *cobra.Command, args []string) {
+		retentionDays, _ := cmd.Flags().GetInt("retention-days")
+		if retentionDays <= 0 {
+			retentionDays = configs.NewConfig().SoftDelete.RetentionDays
+		}
+		if retentionDays <= 0 {
+			retentionDays = defaultSoftDeleteRetentionDays
+		}
+
+		container := app.BuildContainer()
+
+		purged, err := container.ProductUsecase.PurgeDeletedProducts(context.Background(), time.Duration(retentionDays)*24*time.Hour)
+		if err != nil {
+			log.Fatalf("Failed to purge deleted products: %v", err)
+		}
+
+		fmt.Printf("Purged %d products deleted more than %d days ago\n", purged, retentionDays)
+	},
+}
+
+func init() {
+	trashPurgeCmd.Flags().Int("retention-days", 0, "purge rows deleted more than this many days ago (default SOFT_DELETE_RETENTION_DAYS or 30)")
+
+	trashCmd.AddCommand(trashPurgeCmd)
+	rootCmd.AddCommand(trashCmd)
+}
@@ -1,6 +1,10 @@
 package configs
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
 
 type App struct {
 	AppPort string `json:"app_port"`
@@ -35,6 +39,11 @@ type RabbitMQ struct {
 	Password string `json:"password"`
 }
 
+// SoftDelete mengatur berapa lama data di trash disimpan sebelum boleh di-purge
+type SoftDelete struct {
+	RetentionDays int `json:"retention_days"`
+}
+
 type Supabase struct {
 	Url    string `json:"url"`
 	Key    string `json:"key"`
@@ -42,11 +51,16 @@ type Supabase struct {
 }
 
 type Config struct {
-	App      App      `json:"app"`
-	SqlDB    SqlDB    `json:"sql_db"`
-	Redis    Redis    `json:"redis"`
-	RabbitMQ RabbitMQ `json:"rabbitmq"`
-	Supabase Supabase `json:"supabase"`
+	App        App        `json:"app"`
+	SqlDB      SqlDB      `json:"sql_db"`
+	Redis      Redis      `json:"redis"`
+	RabbitMQ   RabbitMQ   `json:"rabbitmq"`
+	SoftDelete SoftDelete `json:"soft_delete"`
+	Supabase   Supabase   `json:"supabase"`
+}
+
+func (r *RabbitMQ) URL() string {
+	return fmt.Sprintf("amqp://%s:%s@%s:%s/", r.Username, r.Password, r.Host, r.Port)
 }
 
 func NewConfig() *Config {
@@ -75,9 +89,12 @@ func NewConfig() *Config {
 		RabbitMQ: RabbitMQ{
 			Host:     viper.GetString("RABBITMQ_HOST"),
 			Port:     viper.GetString("RABBITMQ_PORT"),
-			Username: viper.GetString("RABBITMQ_USERNAME"),
+			Username: viper.GetString("RABBITMQ_USER"),
 			Password: viper.GetString("RABBITMQ_PASSWORD"),
 		},
+		SoftDelete: SoftDelete{
+			RetentionDays: viper.GetInt("SOFT_DELETE_RETENTION_DAYS"),
+		},
 		Supabase: Supabase{
 			Url:    viper.GetString("SUPABASE_URL"),
 			Key:    viper.GetString("SUPABASE_KEY"),
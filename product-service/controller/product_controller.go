@@ -1,16 +1,19 @@
 package controller
 
 import (
+	"errors"
 	"micro-warehouse/product-service/controller/request"
 	"micro-warehouse/product-service/controller/response"
 	"micro-warehouse/product-service/model"
 	"micro-warehouse/product-service/pkg/conv"
 	"micro-warehouse/product-service/pkg/pagination"
 	"micro-warehouse/product-service/pkg/validator"
+	"micro-warehouse/product-service/repository"
 	"micro-warehouse/product-service/usecase"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
 type ProductControllerInterface interface {
@@ -18,8 +21,11 @@ type ProductControllerInterface interface {
 	GetAllProducts(ctx *fiber.Ctx) error
 	GetProductByID(ctx *fiber.Ctx) error
 	GetProductByBarcode(ctx *fiber.Ctx) error
+	GetProductsByBarcodes(ctx *fiber.Ctx) error
 	UpdateProduct(ctx *fiber.Ctx) error
 	DeleteProduct(ctx *fiber.Ctx) error
+	GetDeletedProducts(ctx *fiber.Ctx) error
+	RestoreProduct(ctx *fiber.Ctx) error
 }
 
 type productController struct {
@@ -139,6 +145,57 @@ func (p *productController) GetAllProducts(ctx *fiber.Ctx) error {
 	})
 }
 
+// GetProductsByBarcodes implements ProductControllerInterface.
+func (p *productController) GetProductsByBarcodes(ctx *fiber.Ctx) error {
+	var req request.GetProductsByBarcodesRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		log.Errorf("[ProductController] GetProductsByBarcodes - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[ProductController] GetProductsByBarcodes - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	products, err := p.productUsecase.GetProductsByBarcodes(ctx.Context(), req.Barcodes)
+	if err != nil {
+		log.Errorf("[ProductController] GetProductsByBarcodes - 3: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get products by barcodes",
+		})
+	}
+
+	productsResponse := []response.ProductResponse{}
+	for _, product := range products {
+		productsResponse = append(productsResponse, response.ProductResponse{
+			ID:         product.ID,
+			Name:       product.Name,
+			Barcode:    product.Barcode,
+			CategoryID: product.CategoryID,
+			Thumbnail:  product.Thumbnail,
+			About:      product.About,
+			Price:      int(product.Price),
+			IsPopular:  product.IsPopular,
+			Category: response.CategoryResponse{
+				ID:      product.Category.ID,
+				Name:    product.Category.Name,
+				Tagline: product.Category.Tagline,
+				Photo:   product.Category.Photo,
+			},
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Products fetched successfully",
+		"data":    productsResponse,
+	})
+}
+
 // GetProductByBarcode implements ProductControllerInterface.
 func (p *productController) GetProductByBarcode(ctx *fiber.Ctx) error {
 	barcode := ctx.Params("barcode")
@@ -250,6 +307,91 @@ func (p *productController) UpdateProduct(ctx *fiber.Ctx) error {
 	})
 }
 
+// GetDeletedProducts implements ProductControllerInterface.
+func (p *productController) GetDeletedProducts(ctx *fiber.Ctx) error {
+	var req request.GetDeletedProductsRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		log.Errorf("[ProductController] GetDeletedProducts - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	products, total, err := p.productUsecase.GetDeletedProducts(ctx.Context(), req.Page, req.Limit, req.Search)
+	if err != nil {
+		log.Errorf("[ProductController] GetDeletedProducts - 2: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get deleted products",
+		})
+	}
+
+	productsResponse := []response.ProductResponse{}
+	for _, product := range products {
+		deletedAt := product.DeletedAt.Time
+		productsResponse = append(productsResponse, response.ProductResponse{
+			ID:         product.ID,
+			Name:       product.Name,
+			Barcode:    product.Barcode,
+			CategoryID: product.CategoryID,
+			Thumbnail:  product.Thumbnail,
+			About:      product.About,
+			Price:      int(product.Price),
+			IsPopular:  product.IsPopular,
+			Category: response.CategoryResponse{
+				ID:    product.CategoryID,
+				Name:  product.Category.Name,
+				Photo: product.Category.Photo,
+			},
+			DeletedAt: &deletedAt,
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Deleted products fetched successfully",
+		"data": response.GetAllProductResponse{
+			Products:   productsResponse,
+			Pagination: pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// RestoreProduct implements ProductControllerInterface.
+func (p *productController) RestoreProduct(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	idUint := conv.StringToUint(id)
+
+	if err := p.productUsecase.RestoreProduct(ctx.Context(), idUint); err != nil {
+		log.Errorf("[ProductController] RestoreProduct - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Product not found in trash",
+			})
+		}
+
+		if errors.Is(err, repository.ErrProductBarcodeTaken) {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to restore product",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Product restored successfully",
+	})
+}
+
 func NewProductController(productUsecase usecase.ProductUsecaseInterface) ProductControllerInterface {
 	return &productController{productUsecase: productUsecase}
 }
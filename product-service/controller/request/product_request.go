@@ -10,6 +10,10 @@ type CreateProductRequest struct {
 	IsPopular  bool   `json:"is_popular"`
 }
 
+type GetProductsByBarcodesRequest struct {
+	Barcodes []string `json:"barcodes" validate:"required,min=1,max=200,dive,required"`
+}
+
 type GetAllProductRequest struct {
 	Page      int    `query:"page"`
 	Limit     int    `query:"limit"`
@@ -17,3 +21,9 @@ type GetAllProductRequest struct {
 	SortBy    string `query:"sort_by"`
 	SortOrder string `query:"sort_order"`
 }
+
+type GetDeletedProductsRequest struct {
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+	Search string `query:"search"`
+}
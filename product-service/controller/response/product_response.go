@@ -1,6 +1,9 @@
 package response
 
-import "micro-warehouse/product-service/pkg/pagination"
+import (
+	"micro-warehouse/product-service/pkg/pagination"
+	"time"
+)
 
 type ProductResponse struct {
 	ID         uint             `json:"id"`
@@ -12,6 +15,7 @@ type ProductResponse struct {
 	Thumbnail  string           `json:"thumbnail"`
 	IsPopular  bool             `json:"is_popular"`
 	Category   CategoryResponse `json:"category"`
+	DeletedAt  *time.Time       `json:"deleted_at,omitempty"`
 }
 
 type GetAllProductResponse struct {
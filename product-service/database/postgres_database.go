@@ -25,6 +25,8 @@ func ConnectionPostgres(cfg configs.Config) (*Postgres, error) {
 	}
 
 	db.AutoMigrate(&model.Category{}, &model.Product{})
+	DropLegacyProductBarcodeIndex(db)
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorf("[Postgres] ConnectionPostgres - 2: %v", err)
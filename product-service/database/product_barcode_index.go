@@ -0,0 +1,15 @@
+package database
+
+import (
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// DropLegacyProductBarcodeIndex menghapus unique index barcode lama yang juga mencakup product di trash.
+// Penggantinya idx_products_barcode_active hanya berlaku untuk product yang belum dihapus, sehingga barcode
+// product yang dihapus bisa dipakai lagi.
+func DropLegacyProductBarcodeIndex(db *gorm.DB) {
+	if err := db.Exec("DROP INDEX IF EXISTS idx_products_barcode").Error; err != nil {
+		log.Errorf("[ProductBarcodeIndex] DropLegacyProductBarcodeIndex - 1: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ go 1.24.3
 
 require (
 	github.com/spf13/viper v1.20.1
+	github.com/streadway/amqp v1.1.0
 	gorm.io/gorm v1.30.1
 )
 
@@ -1,19 +1,23 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Product struct {
-	ID         uint       `json:"id" gorm:"primaryKey"`
-	Name       string     `json:"name" gorm:"type:varchar(100);not null"`
-	Barcode    string     `json:"barcode" gorm:"type:varchar(100);uniqueIndex"`
-	CategoryID uint       `json:"category_id"`
-	Thumbnail  string     `json:"thumbnail"`
-	About      string     `json:"about" gorm:"type:text"`
-	Price      float64    `json:"price" gorm:"not null"`
-	IsPopular  bool       `json:"is_popular" gorm:"default:false"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  *time.Time `json:"updated_at"`
-	DeletedAt  *time.Time `json:"deleted_at"`
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Name       string         `json:"name" gorm:"type:varchar(100);not null"`
+	Barcode    string         `json:"barcode" gorm:"type:varchar(100);uniqueIndex:idx_products_barcode_active,where:deleted_at IS NULL"`
+	CategoryID uint           `json:"category_id"`
+	Thumbnail  string         `json:"thumbnail"`
+	About      string         `json:"about" gorm:"type:text"`
+	Price      float64        `json:"price" gorm:"not null"`
+	IsPopular  bool           `json:"is_popular" gorm:"default:false"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	Category Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
 }
@@ -0,0 +1,122 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// EntityChangedEvent memberi tahu merchant- dan warehouse-service bahwa product yang mereka cache sudah berubah.
+// Barcodes berisi barcode lama dan baru supaya cache lookup per barcode ikut terhapus; Product berisi data terbaru
+// (kosong untuk deleted) yang dipakai merchant-service untuk proyeksi pencarian product.
+type EntityChangedEvent struct {
+	Entity    string           `json:"entity"`
+	Action    string           `json:"action"`
+	ID        uint             `json:"id"`
+	Barcodes  []string         `json:"barcodes,omitempty"`
+	Product   *ProductSnapshot `json:"product,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// ProductSnapshot.UpdatedAt menjadi versi data; penerima mengabaikan snapshot yang lebih lama dari yang tersimpan
+type ProductSnapshot struct {
+	Name         string    `json:"name"`
+	Barcode      string    `json:"barcode"`
+	CategoryID   uint      `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+const (
+	EntityEventsExchange = "entity_events"
+
+	EntityProduct = "product"
+
+	EntityActionCreated = "created"
+	EntityActionUpdated = "updated"
+	EntityActionDeleted = "deleted"
+)
+
+type EventPublisherInterface interface {
+	PublishEntityChanged(ctx context.Context, event EntityChangedEvent) error
+	Close() error
+}
+
+type rabbitMQPublisher struct {
+	conn *amqp.Connection
+	ch   *amqp.Channel
+}
+
+// PublishEntityChanged implements EventPublisherInterface.
+// Routing key-nya "<entity>.<action>", mis. product.updated.
+func (r *rabbitMQPublisher) PublishEntityChanged(ctx context.Context, event EntityChangedEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entity changed event: %w", err)
+	}
+
+	err = r.ch.Publish(
+		EntityEventsExchange,
+		event.Entity+"."+event.Action,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish entity changed event: %w", err)
+	}
+
+	return nil
+}
+
+// Close implements EventPublisherInterface.
+func (r *rabbitMQPublisher) Close() error {
+	if r.ch != nil {
+		r.ch.Close()
+	}
+	if r.conn != nil {
+		return r.conn.Close()
+	}
+	return nil
+}
+
+func NewRabbitMQPublisher(rabbitMQURL string) (EventPublisherInterface, error) {
+	conn, err := amqp.Dial(rabbitMQURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	err = ch.ExchangeDeclare(
+		EntityEventsExchange, // name
+		"topic",              // type
+		true,                 // durable
+		false,                // auto-deleted
+		false,                // internal
+		false,                // no-wait
+		nil,                  // arguments
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare entity events exchange: %w", err)
+	}
+
+	return &rabbitMQPublisher{
+		conn: conn,
+		ch:   ch,
+	}, nil
+}
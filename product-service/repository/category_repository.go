@@ -39,8 +39,11 @@ func (c *categoryRepository) DeleteCategory(ctx context.Context, id uint) error
 		log.Errorf("[CategoryRepository] DeleteCategory - 1: %v", ctx.Err())
 		return ctx.Err()
 	default:
+		// Product di trash ikut dihitung karena masih mereferensikan category sampai di-purge
 		modelCategory := model.Category{}
-		if err := c.db.WithContext(ctx).Where("id = ?", id).Preload("Products").First(&modelCategory).Error; err != nil {
+		if err := c.db.WithContext(ctx).Where("id = ?", id).Preload("Products", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).First(&modelCategory).Error; err != nil {
 			log.Errorf("[CategoryRepository] DeleteCategory - 2: %v", err)
 			return err
 		}
@@ -2,19 +2,27 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"micro-warehouse/product-service/model"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
 )
 
+var ErrProductBarcodeTaken = errors.New("barcode is already used by another product")
+
 type ProductRepositoryInterface interface {
 	CreateProduct(ctx context.Context, product *model.Product) error
 	GetAllProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Product, int64, error)
 	GetProductByID(ctx context.Context, id uint) (*model.Product, error)
 	GetProductByBarcode(ctx context.Context, barcode string) (*model.Product, error)
+	GetProductsByBarcodes(ctx context.Context, barcodes []string) ([]model.Product, error)
 	UpdateProduct(ctx context.Context, product *model.Product) error
 	DeleteProduct(ctx context.Context, id uint) error
+	GetDeletedProducts(ctx context.Context, page, limit int, search string) ([]model.Product, int64, error)
+	RestoreProduct(ctx context.Context, id uint) error
+	PurgeDeletedProducts(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 type productRepository struct {
@@ -115,6 +123,22 @@ func (p *productRepository) GetProductByBarcode(ctx context.Context, barcode str
 	}
 }
 
+// GetProductsByBarcodes implements ProductRepositoryInterface.
+func (p *productRepository) GetProductsByBarcodes(ctx context.Context, barcodes []string) ([]model.Product, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductRepository] GetProductsByBarcodes - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		modelProducts := []model.Product{}
+		if err := p.db.WithContext(ctx).Where("barcode IN ?", barcodes).Preload("Category").Find(&modelProducts).Error; err != nil {
+			log.Errorf("[ProductRepository] GetProductsByBarcodes - 2: %v", err)
+			return nil, err
+		}
+		return modelProducts, nil
+	}
+}
+
 // GetProductByID implements ProductRepositoryInterface.
 func (p *productRepository) GetProductByID(ctx context.Context, id uint) (*model.Product, error) {
 	select {
@@ -159,6 +183,101 @@ func (p *productRepository) UpdateProduct(ctx context.Context, product *model.Pr
 	}
 }
 
+// GetDeletedProducts implements ProductRepositoryInterface.
+// Isi trash diurutkan dari yang terakhir dihapus.
+func (p *productRepository) GetDeletedProducts(ctx context.Context, page, limit int, search string) ([]model.Product, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductRepository] GetDeletedProducts - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		if page <= 0 {
+			page = 1
+		}
+		if limit <= 0 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		query := p.db.WithContext(ctx).Unscoped().Model(&model.Product{}).Where("deleted_at IS NOT NULL")
+
+		if search != "" {
+			query = query.Where("name ILIKE ? OR barcode ILIKE ?", "%"+search+"%", "%"+search+"%")
+		}
+
+		var products []model.Product
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			log.Errorf("[ProductRepository] GetDeletedProducts - 2: %v", err)
+			return nil, 0, err
+		}
+
+		if err := query.
+			Order("deleted_at desc").
+			Preload("Category").
+			Offset(offset).
+			Limit(limit).
+			Find(&products).Error; err != nil {
+			log.Errorf("[ProductRepository] GetDeletedProducts - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return products, total, nil
+	}
+}
+
+// RestoreProduct implements ProductRepositoryInterface.
+// Mengembalikan gorm.ErrRecordNotFound jika product tidak ada di trash dan ErrProductBarcodeTaken jika
+// barcode-nya sudah dipakai product lain selama product ini dihapus.
+func (p *productRepository) RestoreProduct(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductRepository] RestoreProduct - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			deletedProduct := model.Product{}
+			if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&deletedProduct).Error; err != nil {
+				log.Errorf("[ProductRepository] RestoreProduct - 2: %v", err)
+				return err
+			}
+
+			var barcodeTaken int64
+			if err := tx.Model(&model.Product{}).Where("barcode = ?", deletedProduct.Barcode).Count(&barcodeTaken).Error; err != nil {
+				log.Errorf("[ProductRepository] RestoreProduct - 3: %v", err)
+				return err
+			}
+
+			if barcodeTaken > 0 {
+				return ErrProductBarcodeTaken
+			}
+
+			return tx.Unscoped().Model(&deletedProduct).Update("deleted_at", nil).Error
+		})
+	}
+}
+
+// PurgeDeletedProducts implements ProductRepositoryInterface.
+// Menghapus permanen product yang sudah di trash sejak sebelum deletedBefore.
+func (p *productRepository) PurgeDeletedProducts(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[ProductRepository] PurgeDeletedProducts - 1: %v", ctx.Err())
+		return 0, ctx.Err()
+	default:
+		result := p.db.WithContext(ctx).Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Delete(&model.Product{})
+		if result.Error != nil {
+			log.Errorf("[ProductRepository] PurgeDeletedProducts - 2: %v", result.Error)
+			return 0, result.Error
+		}
+
+		return result.RowsAffected, nil
+	}
+}
+
 func NewProductRepository(db *gorm.DB) ProductRepositoryInterface {
 	return &productRepository{db: db}
 }
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"micro-warehouse/product-service/model"
 	"micro-warehouse/product-service/pkg/httpclient"
+	"micro-warehouse/product-service/pkg/rabbitmq"
 	"micro-warehouse/product-service/repository"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 )
@@ -15,19 +17,29 @@ type ProductUsecaseInterface interface {
 	GetAllProducts(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Product, int64, error)
 	GetProductByID(ctx context.Context, id uint) (*model.Product, error)
 	GetProductByBarcode(ctx context.Context, barcode string) (*model.Product, error)
+	GetProductsByBarcodes(ctx context.Context, barcodes []string) ([]model.Product, error)
 	UpdateProduct(ctx context.Context, product *model.Product) error
 	DeleteProduct(ctx context.Context, id uint) error
+	GetDeletedProducts(ctx context.Context, page, limit int, search string) ([]model.Product, int64, error)
+	RestoreProduct(ctx context.Context, id uint) error
+	PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int64, error)
 }
 
 type productUsecase struct {
 	productRepo     repository.ProductRepositoryInterface
 	warehouseClient *httpclient.WarehouseClient
 	merchantClient  *httpclient.MerchantClient
+	eventPublisher  rabbitmq.EventPublisherInterface
 }
 
 // CreateProduct implements ProductUsecaseInterface.
 func (p *productUsecase) CreateProduct(ctx context.Context, product *model.Product) error {
-	return p.productRepo.CreateProduct(ctx, product)
+	if err := p.productRepo.CreateProduct(ctx, product); err != nil {
+		return err
+	}
+
+	p.publishProductChanged(ctx, rabbitmq.EntityActionCreated, product.ID, product.Barcode)
+	return nil
 }
 
 // DeleteProduct implements ProductUsecaseInterface.
@@ -64,7 +76,18 @@ func (p *productUsecase) DeleteProduct(ctx context.Context, id uint) error {
 		return err
 	}
 
-	return p.productRepo.DeleteProduct(ctx, id)
+	product, err := p.productRepo.GetProductByID(ctx, id)
+	if err != nil {
+		log.Errorf("[DeleteProduct] Failed to get product %d", id)
+		return err
+	}
+
+	if err := p.productRepo.DeleteProduct(ctx, id); err != nil {
+		return err
+	}
+
+	p.publishProductChanged(ctx, rabbitmq.EntityActionDeleted, id, product.Barcode)
+	return nil
 }
 
 // GetAllProducts implements ProductUsecaseInterface.
@@ -72,6 +95,12 @@ func (p *productUsecase) GetAllProducts(ctx context.Context, page int, limit int
 	return p.productRepo.GetAllProducts(ctx, page, limit, search, sortBy, sortOrder)
 }
 
+// GetProductsByBarcodes implements ProductUsecaseInterface.
+// Barcode yang tidak dikenal tidak ikut dikembalikan.
+func (p *productUsecase) GetProductsByBarcodes(ctx context.Context, barcodes []string) ([]model.Product, error) {
+	return p.productRepo.GetProductsByBarcodes(ctx, barcodes)
+}
+
 // GetProductByBarcode implements ProductUsecaseInterface.
 func (p *productUsecase) GetProductByBarcode(ctx context.Context, barcode string) (*model.Product, error) {
 	return p.productRepo.GetProductByBarcode(ctx, barcode)
@@ -83,10 +112,78 @@ func (p *productUsecase) GetProductByID(ctx context.Context, id uint) (*model.Pr
 }
 
 // UpdateProduct implements ProductUsecaseInterface.
+// Barcode lama ikut dikirim agar cache lookup barcode lama di service lain ikut terhapus.
 func (p *productUsecase) UpdateProduct(ctx context.Context, product *model.Product) error {
-	return p.productRepo.UpdateProduct(ctx, product)
+	existing, err := p.productRepo.GetProductByID(ctx, product.ID)
+	if err != nil {
+		log.Errorf("[UpdateProduct] Failed to get product %d", product.ID)
+		return err
+	}
+
+	if err := p.productRepo.UpdateProduct(ctx, product); err != nil {
+		return err
+	}
+
+	p.publishProductChanged(ctx, rabbitmq.EntityActionUpdated, product.ID, existing.Barcode, product.Barcode)
+	return nil
+}
+
+// GetDeletedProducts implements ProductUsecaseInterface.
+func (p *productUsecase) GetDeletedProducts(ctx context.Context, page, limit int, search string) ([]model.Product, int64, error) {
+	return p.productRepo.GetDeletedProducts(ctx, page, limit, search)
+}
+
+// RestoreProduct implements ProductUsecaseInterface.
+// Merchant product dan warehouse product yang ikut terhapus bersama product di-restore lewat trash masing-masing.
+func (p *productUsecase) RestoreProduct(ctx context.Context, id uint) error {
+	if err := p.productRepo.RestoreProduct(ctx, id); err != nil {
+		log.Errorf("[RestoreProduct] Failed to restore product %d", id)
+		return err
+	}
+
+	p.publishProductChanged(ctx, rabbitmq.EntityActionCreated, id)
+	return nil
+}
+
+// PurgeDeletedProducts implements ProductUsecaseInterface.
+func (p *productUsecase) PurgeDeletedProducts(ctx context.Context, retention time.Duration) (int64, error) {
+	return p.productRepo.PurgeDeletedProducts(ctx, time.Now().Add(-retention))
+}
+
+// publishProductChanged tidak menggagalkan perubahan yang sudah tersimpan; cache di service lain tetap
+// kedaluwarsa sesuai TTL jika event gagal dikirim. Product dibaca ulang agar nama category ikut terkirim.
+func (p *productUsecase) publishProductChanged(ctx context.Context, action string, productID uint, barcodes ...string) {
+	event := rabbitmq.EntityChangedEvent{
+		Entity:   rabbitmq.EntityProduct,
+		Action:   action,
+		ID:       productID,
+		Barcodes: barcodes,
+	}
+
+	if action != rabbitmq.EntityActionDeleted {
+		product, err := p.productRepo.GetProductByID(ctx, productID)
+		if err != nil {
+			log.Errorf("[ProductUsecase] publishProductChanged - 1: %v", err)
+			return
+		}
+
+		event.Product = &rabbitmq.ProductSnapshot{
+			Name:         product.Name,
+			Barcode:      product.Barcode,
+			CategoryID:   product.CategoryID,
+			CategoryName: product.Category.Name,
+		}
+		if product.UpdatedAt != nil {
+			event.Product.UpdatedAt = *product.UpdatedAt
+		}
+	}
+
+	err := p.eventPublisher.PublishEntityChanged(ctx, event)
+	if err != nil {
+		log.Errorf("[ProductUsecase] publishProductChanged - 2: %v", err)
+	}
 }
 
-func NewProductUsecase(productRepo repository.ProductRepositoryInterface) ProductUsecaseInterface {
-	return &productUsecase{productRepo: productRepo}
+func NewProductUsecase(productRepo repository.ProductRepositoryInterface, warehouseClient *httpclient.WarehouseClient, merchantClient *httpclient.MerchantClient, eventPublisher rabbitmq.EventPublisherInterface) ProductUsecaseInterface {
+	return &productUsecase{productRepo: productRepo, warehouseClient: warehouseClient, merchantClient: merchantClient, eventPublisher: eventPublisher}
 }
@@ -42,6 +42,10 @@ func RunServer() {
 	container := BuildContainer()
 	SetupRoutes(app, container)
 
+	// Fallback untuk callback Midtrans yang terlewat
+	pollerCtx, stopPoller := context.WithCancel(context.Background())
+	go container.PaymentStatusPoller.Start(pollerCtx)
+
 	port := cfg.App.AppPort
 	if port == "" {
 		port = os.Getenv("APP_PORT")
@@ -62,6 +66,7 @@ func RunServer() {
 
 	<-quit
 	zerolog.Printf("Shutting down server...")
+	stopPoller()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
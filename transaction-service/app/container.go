@@ -6,6 +6,7 @@ import (
 	"micro-warehouse/transaction-service/controller"
 	"micro-warehouse/transaction-service/database"
 	"micro-warehouse/transaction-service/pkg/httpclient"
+	"micro-warehouse/transaction-service/pkg/invoice"
 	"micro-warehouse/transaction-service/pkg/midtrans"
 	"micro-warehouse/transaction-service/pkg/rabbitmq"
 	"micro-warehouse/transaction-service/repository"
@@ -14,6 +15,11 @@ import (
 
 type Container struct {
 	TransactionController controller.TransactionControllerInterface
+	AccountingController  controller.AccountingControllerInterface
+	RiskController        controller.RiskControllerInterface
+
+	PaymentPollerController controller.PaymentPollerControllerInterface
+	PaymentStatusPoller     usecase.PaymentStatusPollerInterface
 }
 
 func BuildContainer() *Container {
@@ -37,12 +43,29 @@ func BuildContainer() *Container {
 		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
 	}
 
-	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, merchantClient, rabbitMQService, productClient, userClient)
+	invoiceNumberFormatter := invoice.NewNumberFormatter(*cfg)
+
+	riskRepo := repository.NewRiskRepository(db.DB)
+	riskUsecase := usecase.NewRiskUsecase(riskRepo, *cfg)
+	riskController := controller.NewRiskController(riskUsecase)
+
+	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, merchantClient, rabbitMQService, productClient, userClient, invoiceNumberFormatter, riskUsecase)
 
 	midtransService := midtrans.NewMidtransService(cfg)
 	transactionController := controller.NewTransactionController(transactionUsecase, midtransService)
 
+	accountingRepo := repository.NewAccountingRepository(db.DB)
+	accountingUsecase := usecase.NewAccountingUsecase(accountingRepo)
+	accountingController := controller.NewAccountingController(accountingUsecase)
+
+	paymentStatusPoller := usecase.NewPaymentStatusPoller(*cfg, transactionRepo, transactionUsecase, midtransService)
+	paymentPollerController := controller.NewPaymentPollerController(paymentStatusPoller)
+
 	return &Container{
-		TransactionController: transactionController,
+		TransactionController:   transactionController,
+		AccountingController:    accountingController,
+		RiskController:          riskController,
+		PaymentPollerController: paymentPollerController,
+		PaymentStatusPoller:     paymentStatusPoller,
 	}
 }
@@ -1,18 +1,45 @@
 package app
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"micro-warehouse/transaction-service/middleware"
+	"micro-warehouse/transaction-service/pkg/authz"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func SetupRoutes(app *fiber.App, container *Container) {
 	app.Post("/api/v1/midtrans/callback", container.TransactionController.MidtransCallback)
 
 	api := app.Group("/api/v1")
 
-	dashboard := api.Group("/dashboard")
+	// Didaftarkan sebelum group transactions supaya tidak melewati UserContext; request internal tidak membawa user ID
+	api.Get("/transactions/merchants/:merchant_id/pending-count", middleware.InternalOnly(), container.TransactionController.GetMerchantPendingCount)
+
+	dashboard := api.Group("/dashboard", middleware.UserContext())
 	dashboard.Get("/manager", container.TransactionController.GetManagerDashboard)
 	dashboard.Get("/keeper/merchant/:merchant_id", container.TransactionController.GetDashboardByMerchant)
 
-	transactions := api.Group("/transactions")
+	transactions := api.Group("/transactions", middleware.UserContext())
 	transactions.Post("/", container.TransactionController.CreateTransaction)
 	transactions.Get("/", container.TransactionController.GetTransactions)
 	transactions.Get("/:id", container.TransactionController.GetTransactionByID)
+	transactions.Get("/:id/receipt", container.TransactionController.GetTransactionReceipt)
+	transactions.Post("/:id/approve", middleware.RequireRole(authz.RoleManager), container.TransactionController.ApproveTransaction)
+	transactions.Post("/:id/reject", middleware.RequireRole(authz.RoleManager), container.TransactionController.RejectTransaction)
+
+	accounting := api.Group("/accounting", middleware.UserContext(), middleware.RequireRole(authz.RoleManager))
+	accounting.Get("/journal", container.AccountingController.GetJournal)
+	accounting.Get("/journal/csv", container.AccountingController.ExportJournalCSV)
+	accounting.Get("/account-mappings", container.AccountingController.GetAccountMappings)
+	accounting.Put("/account-mappings", container.AccountingController.UpdateAccountMappings)
+
+	risk := api.Group("/risk", middleware.UserContext(), middleware.RequireRole(authz.RoleManager))
+	risk.Get("/reviews", container.RiskController.GetReviewQueue)
+	risk.Get("/blocked-contacts", container.RiskController.GetBlockedContacts)
+	risk.Post("/blocked-contacts", container.RiskController.BlockContact)
+	risk.Delete("/blocked-contacts/:id", container.RiskController.UnblockContact)
+
+	payments := api.Group("/payments", middleware.UserContext(), middleware.RequireRole(authz.RoleManager))
+	payments.Get("/status-poller/metrics", container.PaymentPollerController.GetMetrics)
+	payments.Post("/status-poller/run", container.PaymentPollerController.RunNow)
 }
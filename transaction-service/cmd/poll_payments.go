@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+	"micro-warehouse/transaction-service/app"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/spf13/cobra"
+)
+
+var pollPaymentsCmd = &cobra.Command{
+	Use:   "poll-payments",
+	Short: "Query Midtrans for pending transactions once and apply the results",
+	Run: func(cmd *cobra.Command, args []string) {
+		container := app.BuildContainer()
+
+		result, err := container.PaymentStatusPoller.RunOnce(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to poll payment status: %v", err)
+		}
+
+		log.Infof("Payment status poll finished: checked %d, updated %d, unchanged %d, not found %d, expired %d, failed %d",
+			result.Checked, result.Updated, result.Unchanged, result.NotFound, result.Expired, result.Failed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pollPaymentsCmd)
+}
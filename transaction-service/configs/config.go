@@ -50,6 +50,35 @@ type Midtrans struct {
 	ClientKey    string `json:"client_key"`
 	MerchantID   string `json:"merchant_id"`
 	IsProduction bool   `json:"is_production"`
+	ApiBaseURL   string `json:"api_base_url"`
+}
+
+type Risk struct {
+	Enabled bool `json:"enabled"`
+
+	MaxQuantityPerProduct  int64  `json:"max_quantity_per_product"`
+	MaxQuantityAction      string `json:"max_quantity_action"`
+	MaxDailyMerchantTotal  int64  `json:"max_daily_merchant_total"`
+	MaxDailyMerchantAction string `json:"max_daily_merchant_action"`
+	VelocityMaxPerPhone    int64  `json:"velocity_max_per_phone"`
+	VelocityWindowMinutes  int    `json:"velocity_window_minutes"`
+	VelocityAction         string `json:"velocity_action"`
+	BlocklistAction        string `json:"blocklist_action"`
+}
+
+type PaymentPoller struct {
+	Enabled            bool `json:"enabled"`
+	IntervalSeconds    int  `json:"interval_seconds"`
+	PendingAgeMinutes  int  `json:"pending_age_minutes"`
+	BatchLimit         int  `json:"batch_limit"`
+	BackoffBaseSeconds int  `json:"backoff_base_seconds"`
+	BackoffMaxSeconds  int  `json:"backoff_max_seconds"`
+	ExpireAfterHours   int  `json:"expire_after_hours"`
+}
+
+type Invoice struct {
+	NumberFormat    string `json:"number_format"`
+	SequencePadding int    `json:"sequence_padding"`
 }
 
 type Config struct {
@@ -59,6 +88,10 @@ type Config struct {
 	RabbitMQ RabbitMQ `json:"rabbitmq"`
 	Supabase Supabase `json:"supabase"`
 	Midtrans Midtrans `json:"midtrans"`
+	Invoice  Invoice  `json:"invoice"`
+
+	PaymentPoller PaymentPoller `json:"payment_poller"`
+	Risk          Risk          `json:"risk"`
 }
 
 // URL returns the RabbitMQ connection string
@@ -105,6 +138,31 @@ func NewConfig() *Config {
 			ClientKey:    viper.GetString("MIDTRANS_CLIENT_KEY"),
 			MerchantID:   viper.GetString("MIDTRANS_MERCHANT_ID"),
 			IsProduction: viper.GetBool("MIDTRANS_IS_PRODUCTION"),
+			ApiBaseURL:   viper.GetString("MIDTRANS_API_BASE_URL"),
+		},
+		Invoice: Invoice{
+			NumberFormat:    viper.GetString("INVOICE_NUMBER_FORMAT"),
+			SequencePadding: viper.GetInt("INVOICE_SEQUENCE_PADDING"),
+		},
+		PaymentPoller: PaymentPoller{
+			Enabled:            viper.GetBool("PAYMENT_POLLER_ENABLED"),
+			IntervalSeconds:    viper.GetInt("PAYMENT_POLLER_INTERVAL_SECONDS"),
+			PendingAgeMinutes:  viper.GetInt("PAYMENT_POLLER_PENDING_AGE_MINUTES"),
+			BatchLimit:         viper.GetInt("PAYMENT_POLLER_BATCH_LIMIT"),
+			BackoffBaseSeconds: viper.GetInt("PAYMENT_POLLER_BACKOFF_BASE_SECONDS"),
+			BackoffMaxSeconds:  viper.GetInt("PAYMENT_POLLER_BACKOFF_MAX_SECONDS"),
+			ExpireAfterHours:   viper.GetInt("PAYMENT_POLLER_EXPIRE_AFTER_HOURS"),
+		},
+		Risk: Risk{
+			Enabled:                viper.GetBool("RISK_ENGINE_ENABLED"),
+			MaxQuantityPerProduct:  viper.GetInt64("RISK_MAX_QUANTITY_PER_PRODUCT"),
+			MaxQuantityAction:      viper.GetString("RISK_MAX_QUANTITY_ACTION"),
+			MaxDailyMerchantTotal:  viper.GetInt64("RISK_MAX_DAILY_MERCHANT_TOTAL"),
+			MaxDailyMerchantAction: viper.GetString("RISK_MAX_DAILY_MERCHANT_TOTAL_ACTION"),
+			VelocityMaxPerPhone:    viper.GetInt64("RISK_VELOCITY_MAX_PER_PHONE"),
+			VelocityWindowMinutes:  viper.GetInt("RISK_VELOCITY_WINDOW_MINUTES"),
+			VelocityAction:         viper.GetString("RISK_VELOCITY_ACTION"),
+			BlocklistAction:        viper.GetString("RISK_BLOCKLIST_ACTION"),
 		},
 	}
 }
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"micro-warehouse/transaction-service/controller/request"
+	"micro-warehouse/transaction-service/controller/response"
+	"micro-warehouse/transaction-service/model"
+	"micro-warehouse/transaction-service/pkg/conv"
+	"micro-warehouse/transaction-service/pkg/validator"
+	"micro-warehouse/transaction-service/usecase"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+const journalDateLayout = "2006-01-02"
+
+type AccountingControllerInterface interface {
+	GetJournal(c *fiber.Ctx) error
+	ExportJournalCSV(c *fiber.Ctx) error
+
+	GetAccountMappings(c *fiber.Ctx) error
+	UpdateAccountMappings(c *fiber.Ctx) error
+}
+
+type accountingController struct {
+	accountingUsecase usecase.AccountingUsecaseInterface
+}
+
+// GetJournal implements AccountingControllerInterface.
+func (a *accountingController) GetJournal(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req, startDate, endDate, err := parseJournalRequest(c)
+	if err != nil {
+		log.Errorf("[AccountingController] GetJournal - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	lines, err := a.accountingUsecase.GetJournal(ctx, req.MerchantID, startDate, endDate)
+	if err != nil {
+		log.Errorf("[AccountingController] GetJournal - 2: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to build journal",
+		})
+	}
+
+	journalResponse := response.JournalResponse{
+		MerchantID: req.MerchantID,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		Lines:      []response.JournalLineResponse{},
+	}
+
+	for _, line := range lines {
+		journalResponse.TotalDebit += line.Debit
+		journalResponse.TotalCredit += line.Credit
+		journalResponse.Lines = append(journalResponse.Lines, response.JournalLineResponse{
+			EntryNumber:   line.EntryNumber,
+			EntryDate:     line.EntryDate,
+			EntryType:     line.EntryType,
+			MerchantID:    line.MerchantID,
+			TransactionID: line.TransactionID,
+			OrderID:       line.OrderID,
+			InvoiceNumber: line.InvoiceNumber,
+			PaymentMethod: line.PaymentMethod,
+			AccountCode:   line.AccountCode,
+			AccountName:   line.AccountName,
+			Description:   line.Description,
+			Debit:         line.Debit,
+			Credit:        line.Credit,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    journalResponse,
+		"message": "Journal fetched successfully",
+	})
+}
+
+// ExportJournalCSV implements AccountingControllerInterface.
+func (a *accountingController) ExportJournalCSV(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	req, startDate, endDate, err := parseJournalRequest(c)
+	if err != nil {
+		log.Errorf("[AccountingController] ExportJournalCSV - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	lines, err := a.accountingUsecase.GetJournal(ctx, req.MerchantID, startDate, endDate)
+	if err != nil {
+		log.Errorf("[AccountingController] ExportJournalCSV - 2: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to build journal",
+		})
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{
+		"entry_number", "entry_date", "entry_type", "merchant_id", "transaction_id", "order_id", "invoice_number",
+		"payment_method", "account_code", "account_name", "description", "debit", "credit",
+	})
+
+	for _, line := range lines {
+		writer.Write([]string{
+			line.EntryNumber,
+			line.EntryDate.Format(time.RFC3339),
+			line.EntryType,
+			strconv.FormatUint(uint64(line.MerchantID), 10),
+			strconv.FormatUint(uint64(line.TransactionID), 10),
+			line.OrderID,
+			line.InvoiceNumber,
+			line.PaymentMethod,
+			line.AccountCode,
+			line.AccountName,
+			line.Description,
+			strconv.FormatInt(line.Debit, 10),
+			strconv.FormatInt(line.Credit, 10),
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Errorf("[AccountingController] ExportJournalCSV - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to export journal",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"journal_%s_%s.csv\"", req.StartDate, req.EndDate))
+
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// GetAccountMappings implements AccountingControllerInterface.
+func (a *accountingController) GetAccountMappings(c *fiber.Ctx) error {
+	ctx := c.Context()
+	merchantID := conv.StringToUint(c.Query("merchant_id"))
+
+	mappings, err := a.accountingUsecase.GetAccountMappings(ctx, merchantID)
+	if err != nil {
+		log.Errorf("[AccountingController] GetAccountMappings - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get account mappings",
+		})
+	}
+
+	var mappingResponses []response.AccountMappingResponse
+	for _, mapping := range mappings {
+		mappingResponses = append(mappingResponses, response.AccountMappingResponse{
+			MerchantID:  merchantID,
+			AccountKey:  mapping.AccountKey,
+			AccountCode: mapping.AccountCode,
+			AccountName: mapping.AccountName,
+			IsDefault:   mapping.ID == 0 || mapping.MerchantID != merchantID,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    mappingResponses,
+		"message": "Account mappings fetched successfully",
+	})
+}
+
+// UpdateAccountMappings implements AccountingControllerInterface.
+func (a *accountingController) UpdateAccountMappings(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.UpdateAccountMappingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[AccountingController] UpdateAccountMappings - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[AccountingController] UpdateAccountMappings - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	var mappings []model.AccountMapping
+	for _, mapping := range req.Mappings {
+		mappings = append(mappings, model.AccountMapping{
+			AccountKey:  mapping.AccountKey,
+			AccountCode: mapping.AccountCode,
+			AccountName: mapping.AccountName,
+		})
+	}
+
+	if err := a.accountingUsecase.UpdateAccountMappings(ctx, req.MerchantID, mappings); err != nil {
+		log.Errorf("[AccountingController] UpdateAccountMappings - 3: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Account mappings updated successfully",
+	})
+}
+
+func NewAccountingController(accountingUsecase usecase.AccountingUsecaseInterface) AccountingControllerInterface {
+	return &accountingController{
+		accountingUsecase: accountingUsecase,
+	}
+}
+
+// parseJournalRequest membaca periode export, end_date bersifat inklusif
+func parseJournalRequest(c *fiber.Ctx) (request.GetJournalRequest, time.Time, time.Time, error) {
+	var req request.GetJournalRequest
+	if err := c.QueryParser(&req); err != nil {
+		return req, time.Time{}, time.Time{}, errors.New("Invalid query parameters")
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return req, time.Time{}, time.Time{}, err
+	}
+
+	startDate, err := time.ParseInLocation(journalDateLayout, req.StartDate, time.Local)
+	if err != nil {
+		return req, time.Time{}, time.Time{}, errors.New("start_date must use format YYYY-MM-DD")
+	}
+
+	endDate, err := time.ParseInLocation(journalDateLayout, req.EndDate, time.Local)
+	if err != nil {
+		return req, time.Time{}, time.Time{}, errors.New("end_date must use format YYYY-MM-DD")
+	}
+
+	if endDate.Before(startDate) {
+		return req, time.Time{}, time.Time{}, errors.New("end_date must not be before start_date")
+	}
+
+	return req, startDate, endDate.AddDate(0, 0, 1), nil
+}
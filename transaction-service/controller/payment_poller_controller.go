@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"micro-warehouse/transaction-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type PaymentPollerControllerInterface interface {
+	GetMetrics(c *fiber.Ctx) error
+	RunNow(c *fiber.Ctx) error
+}
+
+type paymentPollerController struct {
+	paymentStatusPoller usecase.PaymentStatusPollerInterface
+}
+
+// GetMetrics implements PaymentPollerControllerInterface.
+func (p *paymentPollerController) GetMetrics(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    p.paymentStatusPoller.Metrics(),
+		"message": "Payment status poller metrics fetched successfully",
+	})
+}
+
+// RunNow implements PaymentPollerControllerInterface.
+func (p *paymentPollerController) RunNow(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	result, err := p.paymentStatusPoller.RunOnce(ctx)
+	if err != nil {
+		log.Errorf("[PaymentPollerController] RunNow - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to poll payment status",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    result,
+		"message": "Payment status polled successfully",
+	})
+}
+
+func NewPaymentPollerController(paymentStatusPoller usecase.PaymentStatusPollerInterface) PaymentPollerControllerInterface {
+	return &paymentPollerController{
+		paymentStatusPoller: paymentStatusPoller,
+	}
+}
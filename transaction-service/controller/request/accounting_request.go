@@ -0,0 +1,18 @@
+package request
+
+type GetJournalRequest struct {
+	MerchantID uint   `query:"merchant_id" validate:"omitempty"`
+	StartDate  string `query:"start_date" validate:"required"`
+	EndDate    string `query:"end_date" validate:"required"`
+}
+
+type AccountMappingRequest struct {
+	AccountKey  string `json:"account_key" validate:"required"`
+	AccountCode string `json:"account_code" validate:"required"`
+	AccountName string `json:"account_name" validate:"required"`
+}
+
+type UpdateAccountMappingsRequest struct {
+	MerchantID uint                    `json:"merchant_id" validate:"omitempty"`
+	Mappings   []AccountMappingRequest `json:"mappings" validate:"required,min=1,dive"`
+}
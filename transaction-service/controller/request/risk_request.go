@@ -0,0 +1,12 @@
+package request
+
+type GetReviewQueueRequest struct {
+	Page  int `query:"page" validate:"omitempty,min=1"`
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type BlockContactRequest struct {
+	ContactType string `json:"contact_type" validate:"required,oneof=phone email"`
+	Value       string `json:"value" validate:"required,max=255"`
+	Reason      string `json:"reason" validate:"omitempty"`
+}
@@ -4,7 +4,7 @@ type GetAllTransactionRequest struct {
 	Page       int    `form:"page" query:"page" validate:"omitempty,min=1"`
 	Limit      int    `form:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
 	Search     string `form:"search" query:"search" validate:"omitempty"`
-	SortBy     string `form:"sort_by" query:"sort_by" validate:"omitempty,oneof=id name created_at"`
+	SortBy     string `form:"sort_by" query:"sort_by" validate:"omitempty,oneof=id name created_at invoice_number"`
 	SortOrder  string `form:"sort_order" query:"sort_order" validate:"omitempty,oneof=asc desc"`
 	MerchantID string `form:"merchant_id" query:"merchant_id" validate:"omitempty"`
 }
@@ -22,7 +22,7 @@ type CreateTransactionRequest struct {
 type CreateTransactionProductRequest struct {
 	ProductID uint  `json:"product_id" validate:"required"`
 	Quantity  int64 `json:"quantity" validate:"required,min=1"`
-	Price     int64 `json:"price" validate:"required,min=1"`
+	Price     int64 `json:"price" validate:"omitempty,min=1"` // diabaikan; harga diambil dari merchant-service
 }
 
 type CreateTransactionWithProductsRequest struct {
@@ -30,6 +30,10 @@ type CreateTransactionWithProductsRequest struct {
 	Products []CreateTransactionProductRequest `json:"products" validate:"required,min=1,dive"`
 }
 
+type ReviewTransactionRequest struct {
+	Note string `json:"note" validate:"omitempty,max=1000"`
+}
+
 type MidtransCallbackRequest struct {
 	OrderID           string `json:"order_id" validate:"required"`
 	TransactionStatus string `json:"transaction_status" validate:"required"`
@@ -0,0 +1,36 @@
+package response
+
+import "time"
+
+type JournalLineResponse struct {
+	EntryNumber   string    `json:"entry_number"`
+	EntryDate     time.Time `json:"entry_date"`
+	EntryType     string    `json:"entry_type"`
+	MerchantID    uint      `json:"merchant_id"`
+	TransactionID uint      `json:"transaction_id"`
+	OrderID       string    `json:"order_id"`
+	InvoiceNumber string    `json:"invoice_number"`
+	PaymentMethod string    `json:"payment_method"`
+	AccountCode   string    `json:"account_code"`
+	AccountName   string    `json:"account_name"`
+	Description   string    `json:"description"`
+	Debit         int64     `json:"debit"`
+	Credit        int64     `json:"credit"`
+}
+
+type JournalResponse struct {
+	MerchantID  uint                  `json:"merchant_id"`
+	StartDate   string                `json:"start_date"`
+	EndDate     string                `json:"end_date"`
+	TotalDebit  int64                 `json:"total_debit"`
+	TotalCredit int64                 `json:"total_credit"`
+	Lines       []JournalLineResponse `json:"lines"`
+}
+
+type AccountMappingResponse struct {
+	MerchantID  uint   `json:"merchant_id"`
+	AccountKey  string `json:"account_key"`
+	AccountCode string `json:"account_code"`
+	AccountName string `json:"account_name"`
+	IsDefault   bool   `json:"is_default"`
+}
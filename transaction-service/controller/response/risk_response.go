@@ -0,0 +1,34 @@
+package response
+
+import (
+	"micro-warehouse/transaction-service/pkg/pagination"
+	"time"
+)
+
+type ReviewQueueItemResponse struct {
+	ID           uint                 `json:"id"`
+	OrderID      string               `json:"order_id"`
+	MerchantID   uint                 `json:"merchant_id"`
+	Name         string               `json:"name"`
+	Phone        string               `json:"phone"`
+	Email        string               `json:"email"`
+	GrandTotal   int64                `json:"grand_total"`
+	TotalItems   int64                `json:"total_items"`
+	RiskDecision string               `json:"risk_decision"`
+	RiskReasons  []RiskReasonResponse `json:"risk_reasons"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+type ReviewQueueResponse struct {
+	Transactions []ReviewQueueItemResponse     `json:"transactions"`
+	Pagination   pagination.PaginationResponse `json:"pagination"`
+}
+
+type BlockedContactResponse struct {
+	ID          uint      `json:"id"`
+	ContactType string    `json:"contact_type"`
+	Value       string    `json:"value"`
+	Reason      string    `json:"reason"`
+	CreatedBy   uint      `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
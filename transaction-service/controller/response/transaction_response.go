@@ -1,6 +1,9 @@
 package response
 
-import "micro-warehouse/transaction-service/pkg/pagination"
+import (
+	"micro-warehouse/transaction-service/pkg/pagination"
+	"time"
+)
 
 type TransactionResponse struct {
 	ID                  uint                         `json:"id"`
@@ -17,10 +20,19 @@ type TransactionResponse struct {
 	PaymentMethod       string                       `json:"payment_method" `
 	TransactionCode     string                       `json:"transaction_code" `
 	OrderID             string                       `json:"order_id" `
+	InvoiceNumber       string                       `json:"invoice_number" `
+	RiskDecision        string                       `json:"risk_decision" `
+	RiskReasons         []RiskReasonResponse         `json:"risk_reasons" `
 	Notes               string                       `json:"notes" `
 	TransactionProducts []TransactionProductResponse `json:"transaction_products" `
 }
 
+type RiskReasonResponse struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Message string `json:"message"`
+}
+
 type TransactionProductResponse struct {
 	ID            uint   `json:"id"`
 	ProductID     uint   `json:"product_id"`
@@ -79,3 +91,37 @@ type DashboardByMerchantResponse struct {
 	DashboardResponse
 	Merchant MerchantSummary `json:"merchant"`
 }
+
+// MerchantPendingCountResponse dipakai merchant-service untuk mengecek merchant sebelum dihapus
+type MerchantPendingCountResponse struct {
+	MerchantID          uint  `json:"merchant_id"`
+	PendingTransactions int64 `json:"pending_transactions"`
+}
+
+type ReceiptResponse struct {
+	InvoiceNumber string                `json:"invoice_number"`
+	InvoicedAt    *time.Time            `json:"invoiced_at"`
+	IsVoided      bool                  `json:"is_voided"`
+	VoidedAt      *time.Time            `json:"voided_at,omitempty"`
+	OrderID       string                `json:"order_id"`
+	MerchantID    uint                  `json:"merchant_id"`
+	MerchantName  string                `json:"merchant_name"`
+	CustomerName  string                `json:"customer_name"`
+	CustomerPhone string                `json:"customer_phone"`
+	CustomerEmail string                `json:"customer_email"`
+	PaymentMethod string                `json:"payment_method"`
+	PaymentStatus string                `json:"payment_status"`
+	Currency      string                `json:"currency"`
+	Items         []ReceiptItemResponse `json:"items"`
+	SubTotal      int64                 `json:"sub_total"`
+	TaxTotal      int64                 `json:"tax_total"`
+	GrandTotal    int64                 `json:"grand_total"`
+}
+
+type ReceiptItemResponse struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int64  `json:"quantity"`
+	Price       int64  `json:"price"`
+	SubTotal    int64  `json:"sub_total"`
+}
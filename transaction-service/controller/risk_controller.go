@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"errors"
+	"micro-warehouse/transaction-service/controller/request"
+	"micro-warehouse/transaction-service/controller/response"
+	"micro-warehouse/transaction-service/model"
+	"micro-warehouse/transaction-service/pkg/authz"
+	"micro-warehouse/transaction-service/pkg/conv"
+	"micro-warehouse/transaction-service/pkg/pagination"
+	"micro-warehouse/transaction-service/pkg/validator"
+	"micro-warehouse/transaction-service/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type RiskControllerInterface interface {
+	GetReviewQueue(c *fiber.Ctx) error
+
+	GetBlockedContacts(c *fiber.Ctx) error
+	BlockContact(c *fiber.Ctx) error
+	UnblockContact(c *fiber.Ctx) error
+}
+
+type riskController struct {
+	riskUsecase usecase.RiskUsecaseInterface
+}
+
+// GetReviewQueue implements RiskControllerInterface.
+func (r *riskController) GetReviewQueue(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.GetReviewQueueRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[RiskController] GetReviewQueue - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	transactions, total, err := r.riskUsecase.GetReviewQueue(ctx, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[RiskController] GetReviewQueue - 2: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get review queue",
+		})
+	}
+
+	queueResponse := response.ReviewQueueResponse{
+		Transactions: []response.ReviewQueueItemResponse{},
+		Pagination:   pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+	}
+
+	for _, transaction := range transactions {
+		var totalItems int64
+		for _, tp := range transaction.TransactionProducts {
+			totalItems += tp.Quantity
+		}
+
+		queueResponse.Transactions = append(queueResponse.Transactions, response.ReviewQueueItemResponse{
+			ID:           transaction.ID,
+			OrderID:      transaction.OrderID,
+			MerchantID:   transaction.MerchantID,
+			Name:         transaction.Name,
+			Phone:        transaction.Phone,
+			Email:        transaction.Email,
+			GrandTotal:   transaction.GrandTotal,
+			TotalItems:   totalItems,
+			RiskDecision: transaction.RiskDecision,
+			RiskReasons:  toRiskReasonResponses(model.DecodeRiskReasons(transaction.RiskReasons)),
+			CreatedAt:    transaction.CreatedAt,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    queueResponse,
+		"message": "Review queue fetched successfully",
+	})
+}
+
+// GetBlockedContacts implements RiskControllerInterface.
+func (r *riskController) GetBlockedContacts(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	contacts, err := r.riskUsecase.GetBlockedContacts(ctx)
+	if err != nil {
+		log.Errorf("[RiskController] GetBlockedContacts - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get blocked contacts",
+		})
+	}
+
+	contactResponses := []response.BlockedContactResponse{}
+	for _, contact := range contacts {
+		contactResponses = append(contactResponses, toBlockedContactResponse(contact))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    contactResponses,
+		"message": "Blocked contacts fetched successfully",
+	})
+}
+
+// BlockContact implements RiskControllerInterface.
+func (r *riskController) BlockContact(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.BlockContactRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[RiskController] BlockContact - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[RiskController] BlockContact - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	contact, err := r.riskUsecase.BlockContact(ctx, authz.GetIdentity(c), model.BlockedContact{
+		ContactType: req.ContactType,
+		Value:       req.Value,
+		Reason:      req.Reason,
+	})
+	if err != nil {
+		log.Errorf("[RiskController] BlockContact - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to block contact",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data":    toBlockedContactResponse(*contact),
+		"message": "Contact blocked successfully",
+	})
+}
+
+// UnblockContact implements RiskControllerInterface.
+func (r *riskController) UnblockContact(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := conv.StringToUint(c.Params("id"))
+	if id == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid blocked contact ID",
+		})
+	}
+
+	if err := r.riskUsecase.UnblockContact(ctx, id); err != nil {
+		log.Errorf("[RiskController] UnblockContact - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Blocked contact not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to unblock contact",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Contact unblocked successfully",
+	})
+}
+
+func NewRiskController(riskUsecase usecase.RiskUsecaseInterface) RiskControllerInterface {
+	return &riskController{
+		riskUsecase: riskUsecase,
+	}
+}
+
+func toBlockedContactResponse(contact model.BlockedContact) response.BlockedContactResponse {
+	return response.BlockedContactResponse{
+		ID:          contact.ID,
+		ContactType: contact.ContactType,
+		Value:       contact.Value,
+		Reason:      contact.Reason,
+		CreatedBy:   contact.CreatedBy,
+		CreatedAt:   contact.CreatedAt,
+	}
+}
@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"micro-warehouse/transaction-service/controller/request"
 	"micro-warehouse/transaction-service/controller/response"
 	"micro-warehouse/transaction-service/model"
+	"micro-warehouse/transaction-service/pkg/authz"
 	"micro-warehouse/transaction-service/pkg/conv"
 	"micro-warehouse/transaction-service/pkg/midtrans"
 	"micro-warehouse/transaction-service/pkg/pagination"
@@ -13,16 +15,23 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
 type TransactionControllerInterface interface {
 	CreateTransaction(ctx *fiber.Ctx) error
 	GetTransactions(c *fiber.Ctx) error
 	GetTransactionByID(c *fiber.Ctx) error
+	GetTransactionReceipt(c *fiber.Ctx) error
 	MidtransCallback(c *fiber.Ctx) error
 
+	ApproveTransaction(c *fiber.Ctx) error
+	RejectTransaction(c *fiber.Ctx) error
+
 	GetManagerDashboard(c *fiber.Ctx) error
 	GetDashboardByMerchant(c *fiber.Ctx) error
+
+	GetMerchantPendingCount(c *fiber.Ctx) error
 }
 
 type transactionController struct {
@@ -42,32 +51,11 @@ func (t *transactionController) CreateTransaction(ctx *fiber.Ctx) error {
 
 	orderID := fmt.Sprintf("ORDER_%d_%d", time.Now().Unix(), req.MerchantID)
 
-	var subtotal int64
-	var items []midtrans.TransactionItem
-
-	for _, product := range req.Products {
-		productSubtotal := int64(product.Price) * product.Quantity
-		subtotal += productSubtotal
-
-		items = append(items, midtrans.TransactionItem{
-			ID:       fmt.Sprintf("%d", product.ProductID),
-			Price:    product.Price,
-			Quantity: product.Quantity,
-			Name:     fmt.Sprintf("Product %d", product.ProductID),
-		})
-	}
-
-	taxtotal := int64(float64(subtotal) * 0.11)
-	grandtotal := subtotal + taxtotal
-
 	transaction := model.Transaction{
 		Name:          req.Name,
 		Phone:         req.Phone,
 		Email:         req.Email,
 		Address:       req.Address,
-		SubTotal:      subtotal,
-		TaxTotal:      taxtotal,
-		GrandTotal:    grandtotal,
 		MerchantID:    req.MerchantID,
 		Notes:         req.Notes,
 		Currency:      "IDR",
@@ -81,29 +69,50 @@ func (t *transactionController) CreateTransaction(ctx *fiber.Ctx) error {
 			ProductID: product.ProductID,
 			Quantity:  product.Quantity,
 			Price:     product.Price,
-			SubTotal:  product.Price * product.Quantity,
 		})
 	}
 
-	idTransaction, err := t.transactionUsecase.CreateTransaction(ctx.Context(), transaction)
+	// Harga dari request hanya sementara; usecase menggantinya dengan harga efektif merchant
+	idTransaction, assessment, err := t.transactionUsecase.CreateTransaction(ctx.Context(), authz.GetIdentity(ctx), &transaction)
 	if err != nil {
 		log.Errorf("[TransactionController] CreateTransaction - 2: %v", err)
+		if errors.Is(err, authz.ErrForbidden) {
+			return authz.Forbidden(ctx, "You do not have access to this merchant")
+		}
+		if errors.Is(err, usecase.ErrMerchantClosed) {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to create transaction",
 		})
 	}
 
-	midtransReq := midtrans.CreateTransactionRequest{
-		OrderID:       orderID,
-		Amount:        int64(grandtotal),
-		Items:         items,
-		CustomerName:  req.Name,
-		CustomerEmail: req.Email,
-		CustomerPhone: req.Phone,
-		Notes:         req.Notes,
+	switch assessment.Decision {
+	case model.RiskDecisionDeny:
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "Transaction rejected by risk rules",
+			"data": fiber.Map{
+				"transaction_id": idTransaction,
+				"order_id":       orderID,
+				"risk_decision":  assessment.Decision,
+				"risk_reasons":   toRiskReasonResponses(assessment.Reasons),
+			},
+		})
+	case model.RiskDecisionReview:
+		return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "Transaction is held for manager review",
+			"data": fiber.Map{
+				"transaction_id": idTransaction,
+				"order_id":       orderID,
+				"risk_decision":  assessment.Decision,
+				"risk_reasons":   toRiskReasonResponses(assessment.Reasons),
+			},
+		})
 	}
 
-	midtransRes, err := t.midtransService.CreateTransaction(midtransReq)
+	midtransRes, err := t.midtransService.CreateTransaction(buildMidtransRequest(transaction))
 	if err != nil {
 		log.Errorf("[TransactionController] CreateTransaction - 3: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -129,12 +138,12 @@ func (t *transactionController) GetDashboardByMerchant(c *fiber.Ctx) error {
 	merchantIDStr := c.Params("merchant_id")
 	merchantID := conv.StringToUint(merchantIDStr)
 
-	userIDStr := c.Query("user_id")
-	userID := conv.StringToUint(userIDStr)
-
-	totalRevenue, totalTransactions, productsSold, err := t.transactionUsecase.GetDashboardStatsByMerchant(ctx, userID, merchantID)
+	totalRevenue, totalTransactions, productsSold, err := t.transactionUsecase.GetDashboardStatsByMerchant(ctx, authz.GetIdentity(c), merchantID)
 	if err != nil {
 		log.Errorf("[TransactionController] GetDashboardByMerchant - 1: %v", err)
+		if errors.Is(err, authz.ErrForbidden) {
+			return authz.Forbidden(c, "You do not have access to this merchant")
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get dashboard stats by merchant",
 		})
@@ -157,15 +166,37 @@ func (t *transactionController) GetDashboardByMerchant(c *fiber.Ctx) error {
 	})
 }
 
+// GetMerchantPendingCount implements TransactionControllerInterface.
+func (t *transactionController) GetMerchantPendingCount(c *fiber.Ctx) error {
+	merchantID := conv.StringToUint(c.Params("merchant_id"))
+
+	count, err := t.transactionUsecase.CountPendingTransactionsByMerchant(c.Context(), merchantID)
+	if err != nil {
+		log.Errorf("[TransactionController] GetMerchantPendingCount - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to count pending transactions",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response.MerchantPendingCountResponse{
+			MerchantID:          merchantID,
+			PendingTransactions: count,
+		},
+		"message": "Pending transactions counted successfully",
+	})
+}
+
 // GetManagerDashboard implements TransactionControllerInterface.
 func (t *transactionController) GetManagerDashboard(c *fiber.Ctx) error {
 	ctx := c.Context()
-	userIDStr := c.Query("user_id")
-	userID := conv.StringToUint(userIDStr)
 
-	totalRevenue, totalTransactions, productsSold, err := t.transactionUsecase.GetDashboardStats(ctx, userID)
+	totalRevenue, totalTransactions, productsSold, err := t.transactionUsecase.GetDashboardStats(ctx, authz.GetIdentity(c))
 	if err != nil {
 		log.Errorf("[TransactionController] GetManagerDashboard - 1: %v", err)
+		if errors.Is(err, authz.ErrForbidden) {
+			return authz.Forbidden(c, "Only managers can access this dashboard")
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get transactions",
 		})
@@ -205,9 +236,12 @@ func (t *transactionController) GetTransactions(c *fiber.Ctx) error {
 
 	merchantID := conv.StringToUint(query.MerchantID)
 
-	transactions, total, err := t.transactionUsecase.GetTransactions(ctx, query.Page, query.Limit, query.Search, query.SortBy, query.SortOrder, merchantID)
+	transactions, total, err := t.transactionUsecase.GetTransactions(ctx, authz.GetIdentity(c), query.Page, query.Limit, query.Search, query.SortBy, query.SortOrder, merchantID)
 	if err != nil {
 		log.Errorf("[TransactionController] GetTransactions - 2: %v", err)
+		if errors.Is(err, authz.ErrForbidden) {
+			return authz.Forbidden(c, "You do not have access to this merchant")
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get transactions",
 		})
@@ -254,6 +288,9 @@ func (t *transactionController) GetTransactions(c *fiber.Ctx) error {
 			PaymentMethod:       transaction.PaymentMethod,
 			TransactionCode:     transaction.TransactionCode,
 			OrderID:             transaction.OrderID,
+			InvoiceNumber:       transaction.InvoiceNumber,
+			RiskDecision:        transaction.RiskDecision,
+			RiskReasons:         toRiskReasonResponses(model.DecodeRiskReasons(transaction.RiskReasons)),
 			Notes:               transaction.Notes,
 			TransactionProducts: transactionProductResponses,
 		})
@@ -285,9 +322,12 @@ func (t *transactionController) GetTransactionByID(c *fiber.Ctx) error {
 		})
 	}
 
-	transaction, err := t.transactionUsecase.GetTransactionByID(ctx, id)
+	transaction, err := t.transactionUsecase.GetTransactionByID(ctx, authz.GetIdentity(c), id)
 	if err != nil {
 		log.Errorf("[TransactionController] GetTransactionByID - 1: %v", err)
+		if errors.Is(err, authz.ErrForbidden) {
+			return authz.Forbidden(c, "You do not have access to this transaction")
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to get transaction",
 		})
@@ -332,6 +372,9 @@ func (t *transactionController) GetTransactionByID(c *fiber.Ctx) error {
 		PaymentMethod:       transaction.PaymentMethod,
 		TransactionCode:     transaction.TransactionCode,
 		OrderID:             transaction.OrderID,
+		InvoiceNumber:       transaction.InvoiceNumber,
+		RiskDecision:        transaction.RiskDecision,
+		RiskReasons:         toRiskReasonResponses(model.DecodeRiskReasons(transaction.RiskReasons)),
 		Notes:               transaction.Notes,
 		TransactionProducts: transactionProductResponses,
 	}
@@ -342,6 +385,73 @@ func (t *transactionController) GetTransactionByID(c *fiber.Ctx) error {
 	})
 }
 
+// GetTransactionReceipt implements TransactionControllerInterface.
+func (t *transactionController) GetTransactionReceipt(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	idStr := c.Params("id")
+	id := conv.StringToUint(idStr)
+
+	if id == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid transaction ID",
+		})
+	}
+
+	transaction, err := t.transactionUsecase.GetTransactionByID(ctx, authz.GetIdentity(c), id)
+	if err != nil {
+		log.Errorf("[TransactionController] GetTransactionReceipt - 1: %v", err)
+		if errors.Is(err, authz.ErrForbidden) {
+			return authz.Forbidden(c, "You do not have access to this transaction")
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get transaction",
+		})
+	}
+
+	if transaction.InvoiceNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Receipt is only available for paid transactions",
+		})
+	}
+
+	var items []response.ReceiptItemResponse
+	for _, tp := range transaction.TransactionProducts {
+		items = append(items, response.ReceiptItemResponse{
+			ProductID:   tp.ProductID,
+			ProductName: tp.ProductName,
+			Quantity:    tp.Quantity,
+			Price:       tp.Price,
+			SubTotal:    tp.SubTotal,
+		})
+	}
+
+	receiptResponse := response.ReceiptResponse{
+		InvoiceNumber: transaction.InvoiceNumber,
+		InvoicedAt:    transaction.InvoicedAt,
+		IsVoided:      transaction.InvoiceVoidedAt != nil,
+		VoidedAt:      transaction.InvoiceVoidedAt,
+		OrderID:       transaction.OrderID,
+		MerchantID:    transaction.MerchantID,
+		MerchantName:  transaction.MerchantName,
+		CustomerName:  transaction.Name,
+		CustomerPhone: transaction.Phone,
+		CustomerEmail: transaction.Email,
+		PaymentMethod: transaction.PaymentMethod,
+		PaymentStatus: transaction.PaymentStatus,
+		Currency:      transaction.Currency,
+		Items:         items,
+		SubTotal:      transaction.SubTotal,
+		TaxTotal:      transaction.TaxTotal,
+		GrandTotal:    transaction.GrandTotal,
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    receiptResponse,
+		"message": "Receipt fetched successfully",
+	})
+}
+
 // MidtransCallback implements TransactionControllerInterface.
 func (t *transactionController) MidtransCallback(c *fiber.Ctx) error {
 	ctx := c.Context()
@@ -370,9 +480,141 @@ func (t *transactionController) MidtransCallback(c *fiber.Ctx) error {
 	})
 }
 
+// ApproveTransaction implements TransactionControllerInterface.
+func (t *transactionController) ApproveTransaction(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := conv.StringToUint(c.Params("id"))
+	if id == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid transaction ID",
+		})
+	}
+
+	var req request.ReviewTransactionRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		log.Errorf("[TransactionController] ApproveTransaction - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	// Pembayaran dibuat selagi transaksi masih review; jika gagal, transaksi belum disetujui dan stock belum dikurangi
+	transaction, err := t.transactionUsecase.GetTransactionForReview(ctx, authz.GetIdentity(c), id)
+	if err != nil {
+		log.Errorf("[TransactionController] ApproveTransaction - 2: %v", err)
+		return reviewErrorResponse(c, err)
+	}
+
+	midtransRes, err := t.midtransService.CreateTransaction(buildMidtransRequest(*transaction))
+	if err != nil {
+		log.Errorf("[TransactionController] ApproveTransaction - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to create payment; transaction is still waiting for review",
+		})
+	}
+
+	if _, err := t.transactionUsecase.ReviewTransaction(ctx, authz.GetIdentity(c), id, true, req.Note); err != nil {
+		log.Errorf("[TransactionController] ApproveTransaction - 4: %v", err)
+		return reviewErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transaction approved successfully",
+		"data": fiber.Map{
+			"transaction_id": transaction.ID,
+			"payment_token":  midtransRes.PaymentToken,
+			"order_id":       midtransRes.OrderID,
+		},
+	})
+}
+
+// RejectTransaction implements TransactionControllerInterface.
+func (t *transactionController) RejectTransaction(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := conv.StringToUint(c.Params("id"))
+	if id == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid transaction ID",
+		})
+	}
+
+	var req request.ReviewTransactionRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		log.Errorf("[TransactionController] RejectTransaction - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if _, err := t.transactionUsecase.ReviewTransaction(ctx, authz.GetIdentity(c), id, false, req.Note); err != nil {
+		log.Errorf("[TransactionController] RejectTransaction - 2: %v", err)
+		return reviewErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Transaction rejected successfully",
+	})
+}
+
 func NewTransactionController(transactionUsecase usecase.TransactionUsecaseInterface, midtransService midtrans.MidtransServiceInterface) TransactionControllerInterface {
 	return &transactionController{
 		transactionUsecase: transactionUsecase,
 		midtransService:    midtransService,
 	}
 }
+
+func buildMidtransRequest(transaction model.Transaction) midtrans.CreateTransactionRequest {
+	var items []midtrans.TransactionItem
+	for _, product := range transaction.TransactionProducts {
+		items = append(items, midtrans.TransactionItem{
+			ID:       fmt.Sprintf("%d", product.ProductID),
+			Price:    product.Price,
+			Quantity: product.Quantity,
+			Name:     fmt.Sprintf("Product %d", product.ProductID),
+		})
+	}
+
+	return midtrans.CreateTransactionRequest{
+		OrderID:       transaction.OrderID,
+		Amount:        transaction.GrandTotal,
+		Items:         items,
+		CustomerName:  transaction.Name,
+		CustomerEmail: transaction.Email,
+		CustomerPhone: transaction.Phone,
+		Notes:         transaction.Notes,
+	}
+}
+
+func toRiskReasonResponses(reasons []model.RiskReason) []response.RiskReasonResponse {
+	riskReasonResponses := []response.RiskReasonResponse{}
+	for _, reason := range reasons {
+		riskReasonResponses = append(riskReasonResponses, response.RiskReasonResponse{
+			Rule:    reason.Rule,
+			Action:  reason.Action,
+			Message: reason.Message,
+		})
+	}
+
+	return riskReasonResponses
+}
+
+func reviewErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, authz.ErrForbidden):
+		return authz.Forbidden(c, "Only managers can review transactions")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "Transaction not found",
+		})
+	case errors.Is(err, usecase.ErrTransactionNotUnderReview):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": "Transaction is not waiting for review",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to review transaction",
+		})
+	}
+}
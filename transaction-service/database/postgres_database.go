@@ -24,7 +24,7 @@ func ConnectionPostgres(cfg configs.Config) (*Postgres, error) {
 		return nil, err
 	}
 
-	db.AutoMigrate(&model.Transaction{}, &model.TransactionProduct{})
+	db.AutoMigrate(&model.Transaction{}, &model.TransactionProduct{}, &model.InvoiceSequence{}, &model.AccountMapping{}, &model.BlockedContact{})
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorf("[Postgres] ConnectionPostgres - 2: %v", err)
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"micro-warehouse/transaction-service/pkg/authz"
+	"micro-warehouse/transaction-service/pkg/conv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UserContext membaca identitas pemanggil dari header yang diteruskan API Gateway
+func UserContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := conv.StringToUint(c.Get("X-User-ID"))
+		roles := authz.ParseRoles(c.Get("X-User-Roles"))
+
+		if userID == 0 || len(roles) == 0 {
+			return authz.Unauthorized(c, "User context not found")
+		}
+
+		authz.SetIdentity(c, authz.Identity{
+			UserID: userID,
+			Roles:  roles,
+		})
+
+		return c.Next()
+	}
+}
+
+// InternalOnly hanya meneruskan request antar service; API Gateway mengisi role system untuk request internal
+func InternalOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		identity := authz.Identity{Roles: authz.ParseRoles(c.Get("X-User-Roles"))}
+		if !identity.HasRole(authz.RoleSystem) {
+			return authz.Forbidden(c, "Internal endpoint")
+		}
+
+		authz.SetIdentity(c, identity)
+		return c.Next()
+	}
+}
+
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		identity := authz.GetIdentity(c)
+		for _, role := range roles {
+			if identity.HasRole(role) {
+				return c.Next()
+			}
+		}
+
+		return authz.Forbidden(c, "Insufficient permissions")
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	AccountKeySalesRevenue   = "sales_revenue"
+	AccountKeySalesReturn    = "sales_return"
+	AccountKeyTaxPayable     = "tax_payable"
+	AccountKeyPaymentDefault = "payment_default"
+	AccountKeyPaymentPrefix  = "payment_"
+)
+
+// DefaultAccountMappings dipakai ketika merchant maupun mapping global (merchant_id = 0)
+// belum mengatur akun untuk key tertentu.
+var DefaultAccountMappings = map[string]AccountMapping{
+	AccountKeySalesRevenue:                      {AccountKey: AccountKeySalesRevenue, AccountCode: "4100", AccountName: "Sales Revenue"},
+	AccountKeySalesReturn:                       {AccountKey: AccountKeySalesReturn, AccountCode: "4200", AccountName: "Sales Returns"},
+	AccountKeyTaxPayable:                        {AccountKey: AccountKeyTaxPayable, AccountCode: "2100", AccountName: "VAT Output Payable"},
+	AccountKeyPaymentDefault:                    {AccountKey: AccountKeyPaymentDefault, AccountCode: "1100", AccountName: "Payment Clearing"},
+	AccountKeyPaymentPrefix + PaymentMethodQRIS: {AccountKey: AccountKeyPaymentPrefix + PaymentMethodQRIS, AccountCode: "1120", AccountName: "QRIS Clearing"},
+}
+
+// PaymentAccountKey mengembalikan key akun untuk metode pembayaran tertentu
+func PaymentAccountKey(paymentMethod string) string {
+	if paymentMethod == "" {
+		return AccountKeyPaymentDefault
+	}
+
+	return AccountKeyPaymentPrefix + strings.ToLower(paymentMethod)
+}
+
+// AccountMapping memetakan key akun internal ke chart of accounts merchant.
+// MerchantID 0 berlaku sebagai mapping default untuk semua merchant.
+type AccountMapping struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	MerchantID  uint      `json:"merchant_id" gorm:"type:bigint;not null;default:0;uniqueIndex:idx_account_mappings_merchant_key"`
+	AccountKey  string    `json:"account_key" gorm:"type:varchar(100);not null;uniqueIndex:idx_account_mappings_merchant_key"`
+	AccountCode string    `json:"account_code" gorm:"type:varchar(50);not null"`
+	AccountName string    `json:"account_name" gorm:"type:varchar(255);not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
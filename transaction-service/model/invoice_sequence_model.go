@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// InvoiceSequence menyimpan nomor urut invoice terakhir per merchant per tahun.
+// Baris ini dikunci (SELECT ... FOR UPDATE) setiap kali nomor baru diterbitkan
+// sehingga penomoran tetap berurutan tanpa celah walaupun callback datang bersamaan.
+type InvoiceSequence struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	MerchantID uint      `json:"merchant_id" gorm:"type:bigint;not null;uniqueIndex:idx_invoice_sequences_merchant_year"`
+	Year       int       `json:"year" gorm:"not null;uniqueIndex:idx_invoice_sequences_merchant_year"`
+	LastNumber int64     `json:"last_number" gorm:"type:bigint;not null;default:0"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	JournalEntryTypeSale   = "sale"
+	JournalEntryTypeRefund = "refund"
+)
+
+// JournalLine adalah satu baris jurnal double-entry hasil turunan dari transaksi.
+// Tidak disimpan di database, selalu dihitung ulang saat export.
+type JournalLine struct {
+	EntryNumber   string    `json:"entry_number"`
+	EntryDate     time.Time `json:"entry_date"`
+	EntryType     string    `json:"entry_type"`
+	MerchantID    uint      `json:"merchant_id"`
+	TransactionID uint      `json:"transaction_id"`
+	OrderID       string    `json:"order_id"`
+	InvoiceNumber string    `json:"invoice_number"`
+	PaymentMethod string    `json:"payment_method"`
+	AccountCode   string    `json:"account_code"`
+	AccountName   string    `json:"account_name"`
+	Description   string    `json:"description"`
+	Debit         int64     `json:"debit"`
+	Credit        int64     `json:"credit"`
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	RiskDecisionAllow  = "allow"
+	RiskDecisionReview = "review"
+	RiskDecisionDeny   = "deny"
+)
+
+const (
+	RiskRuleMaxQuantityPerProduct = "max_quantity_per_product"
+	RiskRuleMaxDailyMerchantTotal = "max_daily_merchant_total"
+	RiskRuleVelocityPerPhone      = "velocity_per_phone"
+	RiskRuleBlockedPhone          = "blocked_phone"
+	RiskRuleBlockedEmail          = "blocked_email"
+)
+
+const (
+	BlockedContactTypePhone = "phone"
+	BlockedContactTypeEmail = "email"
+)
+
+// RiskDecisionWeight dipakai untuk mengambil keputusan paling berat dari rule yang terpicu
+func RiskDecisionWeight(decision string) int {
+	switch decision {
+	case RiskDecisionDeny:
+		return 2
+	case RiskDecisionReview:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NormalizeContactValue menyamakan format nomor telepon dan email sebelum dicocokkan
+func NormalizeContactValue(contactType, value string) string {
+	value = strings.TrimSpace(value)
+	if contactType == BlockedContactTypeEmail {
+		return strings.ToLower(value)
+	}
+
+	return strings.NewReplacer(" ", "", "-", "").Replace(value)
+}
+
+// RiskReason adalah satu rule yang terpicu beserta action-nya
+type RiskReason struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Message string `json:"message"`
+}
+
+type RiskAssessment struct {
+	Decision string       `json:"decision"`
+	Reasons  []RiskReason `json:"reasons"`
+}
+
+// EncodeRiskReasons menyimpan daftar rule yang terpicu ke kolom transactions.risk_reasons
+func EncodeRiskReasons(reasons []RiskReason) string {
+	if len(reasons) == 0 {
+		return ""
+	}
+
+	encoded, err := json.Marshal(reasons)
+	if err != nil {
+		return ""
+	}
+
+	return string(encoded)
+}
+
+func DecodeRiskReasons(encoded string) []RiskReason {
+	var reasons []RiskReason
+	if encoded == "" {
+		return reasons
+	}
+
+	if err := json.Unmarshal([]byte(encoded), &reasons); err != nil {
+		return nil
+	}
+
+	return reasons
+}
+
+type BlockedContact struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ContactType string    `json:"contact_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_blocked_contacts_type_value"`
+	Value       string    `json:"value" gorm:"type:varchar(255);not null;uniqueIndex:idx_blocked_contacts_type_value"`
+	Reason      string    `json:"reason" gorm:"type:text"`
+	CreatedBy   uint      `json:"created_by" gorm:"type:bigint"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
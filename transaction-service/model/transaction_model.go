@@ -12,6 +12,7 @@ const (
 	PaymentStatusFailed  = "failed"
 	PaymentStatusExpired = "expired"
 	PaymentStatusCancel  = "cancel"
+	PaymentStatusRefund  = "refund"
 )
 
 // ConvertMidtransStatusToInternal mengkonversi status dari Midtrans ke konstanta internal
@@ -21,6 +22,7 @@ const (
 // - deny -> failed (transaksi ditolak)
 // - cancel -> cancel (transaksi dibatalkan)
 // - expire -> expired (transaksi kedaluwarsa)
+// - refund -> refund (dana dikembalikan ke customer)
 func ConvertMidtransStatusToInternal(midtransStatus string) string {
 	statusMap := map[string]string{
 		"capture":    PaymentStatusSuccess,
@@ -29,6 +31,7 @@ func ConvertMidtransStatusToInternal(midtransStatus string) string {
 		"deny":       PaymentStatusFailed,
 		"cancel":     PaymentStatusCancel,
 		"expire":     PaymentStatusExpired,
+		"refund":     PaymentStatusRefund,
 	}
 
 	if internalStatus, exists := statusMap[midtransStatus]; exists {
@@ -43,6 +46,9 @@ const (
 	PaymentMethodQRIS = "qris"
 )
 
+// TaxRate adalah PPN yang dikenakan pada subtotal transaksi
+const TaxRate = 0.11
+
 const (
 	FraudStatusAccept    = "accept"
 	FraudStatusDeny      = "deny"
@@ -71,6 +77,20 @@ type Transaction struct {
 	Notes           string     `json:"notes" gorm:"type:text"`
 	Currency        string     `json:"currency" gorm:"type:varchar(10);default:'IDR'"`
 	FraudStatus     string     `json:"fraud_status" gorm:"type:varchar(50)"`
+	// tax invoice, diterbitkan saat pembayaran sukses
+	InvoiceNumber   string     `json:"invoice_number" gorm:"type:varchar(100);uniqueIndex:idx_transactions_invoice_number,where:invoice_number <> ''"`
+	InvoicedAt      *time.Time `json:"invoiced_at"`
+	InvoiceVoidedAt *time.Time `json:"invoice_voided_at"`
+	// rules engine internal, lihat RiskDecision*
+	RiskDecision   string     `json:"risk_decision" gorm:"type:varchar(20);not null;default:'allow';index"`
+	RiskReasons    string     `json:"risk_reasons" gorm:"type:text"`
+	RiskReviewedBy *uint      `json:"risk_reviewed_by"`
+	RiskReviewedAt *time.Time `json:"risk_reviewed_at"`
+	RiskReviewNote string     `json:"risk_review_note" gorm:"type:text"`
+	// status poller, fallback ketika callback Midtrans tidak diterima
+	StatusPollAttempts int        `json:"status_poll_attempts" gorm:"not null;default:0"`
+	LastStatusPolledAt *time.Time `json:"last_status_polled_at"`
+	NextStatusPollAt   *time.Time `json:"next_status_poll_at" gorm:"index"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -81,3 +101,16 @@ type Transaction struct {
 
 	TransactionProducts []TransactionProduct `json:"transaction_products" gorm:"foreignKey:TransactionID;references:ID"`
 }
+
+// CalculateTotals menghitung ulang subtotal setiap item, subtotal, pajak dan grand total dari harga item
+func (t *Transaction) CalculateTotals() {
+	var subtotal int64
+	for i := range t.TransactionProducts {
+		t.TransactionProducts[i].SubTotal = t.TransactionProducts[i].Price * t.TransactionProducts[i].Quantity
+		subtotal += t.TransactionProducts[i].SubTotal
+	}
+
+	t.SubTotal = subtotal
+	t.TaxTotal = int64(float64(subtotal) * TaxRate)
+	t.GrandTotal = t.SubTotal + t.TaxTotal
+}
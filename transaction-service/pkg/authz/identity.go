@@ -0,0 +1,80 @@
+package authz
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	RoleManager = "Manager"
+	RoleKeeper  = "Keeper"
+	RoleSystem  = "system"
+
+	identityLocalsKey = "identity"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// Identity adalah pemanggil yang sudah diautentikasi oleh API Gateway,
+// diambil dari header X-User-ID dan X-User-Roles
+type Identity struct {
+	UserID uint
+	Roles  []string
+}
+
+func (i Identity) HasRole(role string) bool {
+	for _, r := range i.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i Identity) IsManager() bool {
+	return i.HasRole(RoleManager)
+}
+
+// ParseRoles menerima format "Manager" maupun "[Manager Keeper]" / "Manager,Keeper"
+func ParseRoles(header string) []string {
+	header = strings.Trim(strings.TrimSpace(header), "[]")
+
+	var roles []string
+	for _, role := range strings.FieldsFunc(header, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if role != "" {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+func SetIdentity(c *fiber.Ctx, identity Identity) {
+	c.Locals(identityLocalsKey, identity)
+}
+
+func GetIdentity(c *fiber.Ctx) Identity {
+	identity, _ := c.Locals(identityLocalsKey).(Identity)
+	return identity
+}
+
+func Unauthorized(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error":   "Unauthorized",
+		"message": message,
+		"code":    "UNAUTHORIZED",
+	})
+}
+
+func Forbidden(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error":   "Forbidden",
+		"message": message,
+		"code":    "FORBIDDEN",
+	})
+}
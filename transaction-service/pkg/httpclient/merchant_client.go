@@ -198,6 +198,7 @@ func (m *MerchantClient) GetMerchantProducts(ctx context.Context, merchantID uin
 }
 
 // GetMerchantsByKeeperID implements MerchantClientInterface.
+// merchant-service mengembalikan semua merchant tempat user aktif di roster staff.
 func (m *MerchantClient) GetMerchantsByKeeperID(ctx context.Context, keeperID uint) ([]Merchant, error) {
 	url := fmt.Sprintf("%s/api/v1/merchants?keeper_id=%d", m.UrlApiGateway, keeperID)
 
@@ -233,6 +234,11 @@ func (m *MerchantClient) GetMerchantsByKeeperID(ctx context.Context, keeperID ui
 		return nil, err
 	}
 
+	// merchant-service mengembalikan 404 ketika user tidak aktif di roster merchant mana pun
+	if resp.StatusCode == http.StatusNotFound {
+		return []Merchant{}, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		log.Errorf("[MerchantClient] GetMerchantsByKeeperID - 5: %s", string(body))
 		return nil, errors.New("failed to get merchants by keeper id")
@@ -250,11 +256,14 @@ func (m *MerchantClient) GetMerchantsByKeeperID(ctx context.Context, keeperID ui
 }
 
 type Merchant struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	Address  string `json:"address"`
-	Phone    string `json:"phone"`
-	KeeperID uint   `json:"keeper_id"`
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Address      string `json:"address"`
+	Phone        string `json:"phone"`
+	KeeperID     uint   `json:"keeper_id"`
+	Status       string `json:"status"`
+	IsOpen       bool   `json:"is_open"`
+	ClosedReason string `json:"closed_reason"`
 }
 
 type MerchantProduct struct {
@@ -265,6 +274,7 @@ type MerchantProduct struct {
 	ProductAbout         string `json:"product_about"`
 	ProductPhoto         string `json:"product_photo"`
 	ProductPrice         int    `json:"product_price"`
+	EffectivePrice       int64  `json:"effective_price"`
 	ProductCategory      string `json:"product_category"`
 	ProductCategoryPhoto string `json:"product_category_photo"`
 	Stock                int    `json:"stock"`
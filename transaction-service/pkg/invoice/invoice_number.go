@@ -0,0 +1,48 @@
+package invoice
+
+import (
+	"fmt"
+	"micro-warehouse/transaction-service/configs"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultNumberFormat    = "INV/{merchant}/{yyyy}/{seq}"
+	DefaultSequencePadding = 6
+)
+
+// NumberFormatter menyusun nomor invoice dari format yang dikonfigurasi.
+// Placeholder yang didukung: {merchant}, {yyyy}, {yy} dan {seq}.
+type NumberFormatter struct {
+	format  string
+	padding int
+}
+
+func (f *NumberFormatter) Format(merchantID uint, year int, sequence int64) string {
+	replacer := strings.NewReplacer(
+		"{merchant}", strconv.FormatUint(uint64(merchantID), 10),
+		"{yyyy}", fmt.Sprintf("%04d", year),
+		"{yy}", fmt.Sprintf("%02d", year%100),
+		"{seq}", fmt.Sprintf("%0*d", f.padding, sequence),
+	)
+
+	return replacer.Replace(f.format)
+}
+
+func NewNumberFormatter(cfg configs.Config) *NumberFormatter {
+	format := cfg.Invoice.NumberFormat
+	if format == "" {
+		format = DefaultNumberFormat
+	}
+
+	padding := cfg.Invoice.SequencePadding
+	if padding <= 0 {
+		padding = DefaultSequencePadding
+	}
+
+	return &NumberFormatter{
+		format:  format,
+		padding: padding,
+	}
+}
@@ -1,7 +1,15 @@
 package midtrans
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"micro-warehouse/transaction-service/configs"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/midtrans/midtrans-go"
@@ -10,8 +18,18 @@ import (
 
 type MidtransServiceInterface interface {
 	CreateTransaction(req CreateTransactionRequest) (*CreateTransactionResponse, error)
+	GetTransactionStatus(ctx context.Context, orderID string) (*TransactionStatusResponse, error)
 }
 
+const (
+	SandboxApiBaseURL    = "https://api.sandbox.midtrans.com"
+	ProductionApiBaseURL = "https://api.midtrans.com"
+)
+
+// ErrTransactionNotFound dikembalikan ketika Midtrans belum mengenal order tersebut,
+// misalnya customer belum memilih metode pembayaran di halaman Snap
+var ErrTransactionNotFound = errors.New("midtrans transaction not found")
+
 type TransactionItem struct {
 	ID       string `json:"id"`
 	Price    int64  `json:"price"`
@@ -34,8 +52,21 @@ type CreateTransactionResponse struct {
 	OrderID      string `json:"order_id"`
 }
 
+type TransactionStatusResponse struct {
+	StatusCode        string `json:"status_code"`
+	StatusMessage     string `json:"status_message"`
+	TransactionID     string `json:"transaction_id"`
+	OrderID           string `json:"order_id"`
+	PaymentType       string `json:"payment_type"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	GrossAmount       string `json:"gross_amount"`
+	TransactionTime   string `json:"transaction_time"`
+}
+
 type MidtransService struct {
-	config *configs.Config
+	config     *configs.Config
+	httpClient *http.Client
 }
 
 // CreateTransaction implements MidtransServiceInterface.
@@ -70,8 +101,64 @@ func (m *MidtransService) CreateTransaction(req CreateTransactionRequest) (*Crea
 	}, nil
 }
 
+// GetTransactionStatus implements MidtransServiceInterface.
+func (m *MidtransService) GetTransactionStatus(ctx context.Context, orderID string) (*TransactionStatusResponse, error) {
+	endpoint := fmt.Sprintf("%s/v2/%s/status", m.apiBaseURL(), url.PathEscape(orderID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		log.Errorf("[MidtransService] GetTransactionStatus - 1: %v", err)
+		return nil, err
+	}
+
+	req.SetBasicAuth(m.config.Midtrans.ServerKey, "")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[MidtransService] GetTransactionStatus - 2: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("[MidtransService] GetTransactionStatus - 3: unexpected status code %d", resp.StatusCode)
+		return nil, fmt.Errorf("midtrans status api returned %d", resp.StatusCode)
+	}
+
+	var statusResponse TransactionStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&statusResponse); err != nil {
+		log.Errorf("[MidtransService] GetTransactionStatus - 4: %v", err)
+		return nil, err
+	}
+
+	// Midtrans mengembalikan HTTP 200 dengan status_code di body, termasuk untuk error
+	switch statusResponse.StatusCode {
+	case "200", "201", "202", "407":
+		return &statusResponse, nil
+	case "404":
+		return nil, ErrTransactionNotFound
+	default:
+		log.Errorf("[MidtransService] GetTransactionStatus - 5: %s %s", statusResponse.StatusCode, statusResponse.StatusMessage)
+		return nil, fmt.Errorf("midtrans status api error %s: %s", statusResponse.StatusCode, statusResponse.StatusMessage)
+	}
+}
+
+func (m *MidtransService) apiBaseURL() string {
+	if m.config.Midtrans.ApiBaseURL != "" {
+		return strings.TrimRight(m.config.Midtrans.ApiBaseURL, "/")
+	}
+
+	if m.config.Midtrans.IsProduction {
+		return ProductionApiBaseURL
+	}
+
+	return SandboxApiBaseURL
+}
+
 func NewMidtransService(config *configs.Config) MidtransServiceInterface {
 	return &MidtransService{
-		config: config,
+		config:     config,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
 	}
 }
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"micro-warehouse/transaction-service/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// account mapping per merchant, transaksi untuk jurnal per periode
+type AccountingRepositoryInterface interface {
+	GetAccountMappings(ctx context.Context, merchantID uint) ([]model.AccountMapping, error)
+	UpsertAccountMappings(ctx context.Context, mappings []model.AccountMapping) error
+
+	GetSalesForJournal(ctx context.Context, merchantID uint, startDate, endDate time.Time) ([]model.Transaction, error)
+	GetRefundsForJournal(ctx context.Context, merchantID uint, startDate, endDate time.Time) ([]model.Transaction, error)
+}
+
+type accountingRepository struct {
+	db *gorm.DB
+}
+
+// GetAccountMappings implements AccountingRepositoryInterface.
+// Mengembalikan mapping milik merchant sekaligus mapping default (merchant_id = 0).
+func (a *accountingRepository) GetAccountMappings(ctx context.Context, merchantID uint) ([]model.AccountMapping, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[AccountingRepository] GetAccountMappings - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var mappings []model.AccountMapping
+		if err := a.db.WithContext(ctx).
+			Where("merchant_id IN ?", []uint{0, merchantID}).
+			Order("merchant_id asc, account_key asc").
+			Find(&mappings).Error; err != nil {
+			log.Errorf("[AccountingRepository] GetAccountMappings - 2: %v", err)
+			return nil, err
+		}
+
+		return mappings, nil
+	}
+}
+
+// UpsertAccountMappings implements AccountingRepositoryInterface.
+func (a *accountingRepository) UpsertAccountMappings(ctx context.Context, mappings []model.AccountMapping) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[AccountingRepository] UpsertAccountMappings - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		if len(mappings) == 0 {
+			return nil
+		}
+
+		err := a.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "merchant_id"}, {Name: "account_key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"account_code", "account_name", "updated_at"}),
+		}).Create(&mappings).Error
+		if err != nil {
+			log.Errorf("[AccountingRepository] UpsertAccountMappings - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetSalesForJournal implements AccountingRepositoryInterface.
+// Transaksi dihitung sebagai penjualan pada tanggal invoice diterbitkan,
+// termasuk transaksi yang kemudian di-void (pembalikannya dicatat terpisah).
+func (a *accountingRepository) GetSalesForJournal(ctx context.Context, merchantID uint, startDate time.Time, endDate time.Time) ([]model.Transaction, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[AccountingRepository] GetSalesForJournal - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		query := a.db.WithContext(ctx).Model(&model.Transaction{}).
+			Where("invoice_number <> '' OR payment_status = ?", model.PaymentStatusSuccess).
+			Where("COALESCE(invoiced_at, created_at) >= ? AND COALESCE(invoiced_at, created_at) < ?", startDate, endDate)
+
+		if merchantID != 0 {
+			query = query.Where("merchant_id = ?", merchantID)
+		}
+
+		var transactions []model.Transaction
+		if err := query.Order("COALESCE(invoiced_at, created_at) asc, id asc").Find(&transactions).Error; err != nil {
+			log.Errorf("[AccountingRepository] GetSalesForJournal - 2: %v", err)
+			return nil, err
+		}
+
+		return transactions, nil
+	}
+}
+
+// GetRefundsForJournal implements AccountingRepositoryInterface.
+func (a *accountingRepository) GetRefundsForJournal(ctx context.Context, merchantID uint, startDate time.Time, endDate time.Time) ([]model.Transaction, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[AccountingRepository] GetRefundsForJournal - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		query := a.db.WithContext(ctx).Model(&model.Transaction{}).
+			Where("invoice_voided_at >= ? AND invoice_voided_at < ?", startDate, endDate)
+
+		if merchantID != 0 {
+			query = query.Where("merchant_id = ?", merchantID)
+		}
+
+		var transactions []model.Transaction
+		if err := query.Order("invoice_voided_at asc, id asc").Find(&transactions).Error; err != nil {
+			log.Errorf("[AccountingRepository] GetRefundsForJournal - 2: %v", err)
+			return nil, err
+		}
+
+		return transactions, nil
+	}
+}
+
+func NewAccountingRepository(db *gorm.DB) AccountingRepositoryInterface {
+	return &accountingRepository{db: db}
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"micro-warehouse/transaction-service/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// data pendukung rules engine, blocklist dan antrian review manager
+type RiskRepositoryInterface interface {
+	SumMerchantGrandTotalSince(ctx context.Context, merchantID uint, since time.Time) (int64, error)
+	CountTransactionsByPhoneSince(ctx context.Context, phone string, since time.Time) (int64, error)
+
+	FindBlockedContacts(ctx context.Context, phone, email string) ([]model.BlockedContact, error)
+	GetBlockedContacts(ctx context.Context) ([]model.BlockedContact, error)
+	CreateBlockedContact(ctx context.Context, contact model.BlockedContact) (*model.BlockedContact, error)
+	DeleteBlockedContact(ctx context.Context, id uint) error
+
+	GetTransactionsForReview(ctx context.Context, page, limit int) ([]model.Transaction, int64, error)
+	ResolveReview(ctx context.Context, id uint, decision, paymentStatus string, reviewerID uint, note string) (int64, error)
+}
+
+type riskRepository struct {
+	db *gorm.DB
+}
+
+// SumMerchantGrandTotalSince implements RiskRepositoryInterface.
+// Transaksi yang ditolak atau gagal bayar tidak ikut dihitung.
+func (r *riskRepository) SumMerchantGrandTotalSince(ctx context.Context, merchantID uint, since time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[RiskRepository] SumMerchantGrandTotalSince - 1: %v", ctx.Err())
+		return 0, ctx.Err()
+	default:
+		var total int64
+		err := r.db.WithContext(ctx).Model(&model.Transaction{}).
+			Select("COALESCE(SUM(grand_total), 0)").
+			Where("merchant_id = ? AND created_at >= ?", merchantID, since).
+			Where("risk_decision <> ?", model.RiskDecisionDeny).
+			Where("payment_status IN ?", []string{model.PaymentStatusPending, model.PaymentStatusSuccess}).
+			Scan(&total).Error
+		if err != nil {
+			log.Errorf("[RiskRepository] SumMerchantGrandTotalSince - 2: %v", err)
+			return 0, err
+		}
+
+		return total, nil
+	}
+}
+
+// CountTransactionsByPhoneSince implements RiskRepositoryInterface.
+// phone harus sudah dinormalisasi; kolom phone dinormalisasi dengan cara yang sama seperti NormalizeContactValue
+// sehingga "0812-3456" dan "0812 3456" dihitung sebagai nomor yang sama.
+func (r *riskRepository) CountTransactionsByPhoneSince(ctx context.Context, phone string, since time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[RiskRepository] CountTransactionsByPhoneSince - 1: %v", ctx.Err())
+		return 0, ctx.Err()
+	default:
+		var count int64
+		err := r.db.WithContext(ctx).Model(&model.Transaction{}).
+			Where("REPLACE(REPLACE(TRIM(phone), ' ', ''), '-', '') = ? AND created_at >= ?", phone, since).
+			Count(&count).Error
+		if err != nil {
+			log.Errorf("[RiskRepository] CountTransactionsByPhoneSince - 2: %v", err)
+			return 0, err
+		}
+
+		return count, nil
+	}
+}
+
+// FindBlockedContacts implements RiskRepositoryInterface.
+func (r *riskRepository) FindBlockedContacts(ctx context.Context, phone string, email string) ([]model.BlockedContact, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[RiskRepository] FindBlockedContacts - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var contacts []model.BlockedContact
+		err := r.db.WithContext(ctx).
+			Where("(contact_type = ? AND value = ?) OR (contact_type = ? AND value = ?)",
+				model.BlockedContactTypePhone, phone, model.BlockedContactTypeEmail, email).
+			Find(&contacts).Error
+		if err != nil {
+			log.Errorf("[RiskRepository] FindBlockedContacts - 2: %v", err)
+			return nil, err
+		}
+
+		return contacts, nil
+	}
+}
+
+// GetBlockedContacts implements RiskRepositoryInterface.
+func (r *riskRepository) GetBlockedContacts(ctx context.Context) ([]model.BlockedContact, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[RiskRepository] GetBlockedContacts - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var contacts []model.BlockedContact
+		if err := r.db.WithContext(ctx).Order("contact_type asc, value asc").Find(&contacts).Error; err != nil {
+			log.Errorf("[RiskRepository] GetBlockedContacts - 2: %v", err)
+			return nil, err
+		}
+
+		return contacts, nil
+	}
+}
+
+// CreateBlockedContact implements RiskRepositoryInterface.
+func (r *riskRepository) CreateBlockedContact(ctx context.Context, contact model.BlockedContact) (*model.BlockedContact, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[RiskRepository] CreateBlockedContact - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "contact_type"}, {Name: "value"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason", "created_by", "updated_at"}),
+		}).Create(&contact).Error
+		if err != nil {
+			log.Errorf("[RiskRepository] CreateBlockedContact - 2: %v", err)
+			return nil, err
+		}
+
+		return &contact, nil
+	}
+}
+
+// DeleteBlockedContact implements RiskRepositoryInterface.
+func (r *riskRepository) DeleteBlockedContact(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[RiskRepository] DeleteBlockedContact - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := r.db.WithContext(ctx).Delete(&model.BlockedContact{}, id)
+		if result.Error != nil {
+			log.Errorf("[RiskRepository] DeleteBlockedContact - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	}
+}
+
+// GetTransactionsForReview implements RiskRepositoryInterface.
+func (r *riskRepository) GetTransactionsForReview(ctx context.Context, page int, limit int) ([]model.Transaction, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[RiskRepository] GetTransactionsForReview - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		if page <= 0 {
+			page = 1
+		}
+		if limit <= 0 {
+			limit = 10
+		}
+
+		baseSql := r.db.WithContext(ctx).Model(&model.Transaction{}).
+			Where("risk_decision = ?", model.RiskDecisionReview)
+
+		var totalRecords int64
+		if err := baseSql.Count(&totalRecords).Error; err != nil {
+			log.Errorf("[RiskRepository] GetTransactionsForReview - 2: %v", err)
+			return nil, 0, err
+		}
+
+		var transactions []model.Transaction
+		err := baseSql.
+			Preload("TransactionProducts").
+			Order("created_at asc").
+			Offset((page - 1) * limit).
+			Limit(limit).
+			Find(&transactions).Error
+		if err != nil {
+			log.Errorf("[RiskRepository] GetTransactionsForReview - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return transactions, totalRecords, nil
+	}
+}
+
+// ResolveReview implements RiskRepositoryInterface.
+// Hanya transaksi yang masih berstatus review yang diubah, sehingga dua manager
+// tidak bisa memutus transaksi yang sama dua kali. Mengembalikan jumlah baris yang diubah.
+func (r *riskRepository) ResolveReview(ctx context.Context, id uint, decision string, paymentStatus string, reviewerID uint, note string) (int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[RiskRepository] ResolveReview - 1: %v", ctx.Err())
+		return 0, ctx.Err()
+	default:
+		updates := map[string]interface{}{
+			"risk_decision":    decision,
+			"risk_reviewed_by": reviewerID,
+			"risk_reviewed_at": time.Now(),
+			"risk_review_note": note,
+		}
+		if paymentStatus != "" {
+			updates["payment_status"] = paymentStatus
+		}
+
+		result := r.db.WithContext(ctx).Model(&model.Transaction{}).
+			Where("id = ? AND risk_decision = ?", id, model.RiskDecisionReview).
+			Updates(updates)
+		if result.Error != nil {
+			log.Errorf("[RiskRepository] ResolveReview - 2: %v", result.Error)
+			return 0, result.Error
+		}
+
+		return result.RowsAffected, nil
+	}
+}
+
+func NewRiskRepository(db *gorm.DB) RiskRepositoryInterface {
+	return &riskRepository{
+		db: db,
+	}
+}
@@ -2,23 +2,34 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"micro-warehouse/transaction-service/model"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // get data overview dashboard manager/keeper,create transaction, update status transaction
 type TransactionRepositoryInterface interface {
 	GetDashboardStats(ctx context.Context) (int64, int64, int64, error)
 	GetDashboardStatsByMerchant(ctx context.Context, merchantID uint) (int64, int64, int64, error)
+	CountPendingTransactionsByMerchant(ctx context.Context, merchantID uint) (int64, error)
 
-	GetTransactions(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID uint) ([]model.Transaction, int64, error)
+	GetTransactions(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantIDs []uint) ([]model.Transaction, int64, error)
 	GetTransactionByID(ctx context.Context, id uint) (*model.Transaction, error)
 	CreateTransaction(ctx context.Context, transaction model.Transaction) (int64, error)
 
 	// Midtrans update status transaction
 	UpdatePaymentStatus(ctx context.Context, orderID string, paymentStatus, paymentMethod, transactionID, fraudStatus string) error
+
+	// Tax invoice
+	AssignInvoiceNumber(ctx context.Context, orderID string, formatNumber func(merchantID uint, year int, sequence int64) string) (string, error)
+
+	// Payment status poller
+	GetPendingTransactionsForPolling(ctx context.Context, createdBefore, now time.Time, limit int) ([]model.Transaction, error)
+	RecordStatusPoll(ctx context.Context, id uint, attempts int, polledAt, nextPollAt time.Time) error
 }
 
 type transactionRepository struct {
@@ -172,8 +183,28 @@ func (t *transactionRepository) GetDashboardStatsByMerchant(ctx context.Context,
 	}
 }
 
+// CountPendingTransactionsByMerchant implements TransactionRepositoryInterface.
+// Transaksi yang ditahan rules engine tetap pending sampai direview, sehingga ikut dihitung.
+func (t *transactionRepository) CountPendingTransactionsByMerchant(ctx context.Context, merchantID uint) (int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransactionRepository] CountPendingTransactionsByMerchant - 1: %v", ctx.Err())
+		return 0, ctx.Err()
+	default:
+		var count int64
+		if err := t.db.WithContext(ctx).Model(&model.Transaction{}).
+			Where("merchant_id = ? AND payment_status = ?", merchantID, model.PaymentStatusPending).
+			Count(&count).Error; err != nil {
+			log.Errorf("[TransactionRepository] CountPendingTransactionsByMerchant - 2: %v", err)
+			return 0, err
+		}
+
+		return count, nil
+	}
+}
+
 // GetTransactions implements TransactionRepositoryInterface.
-func (t *transactionRepository) GetTransactions(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint) ([]model.Transaction, int64, error) {
+func (t *transactionRepository) GetTransactions(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantIDs []uint) ([]model.Transaction, int64, error) {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[TransactionRepository] GetTransactions - 1: %v", ctx.Err())
@@ -199,12 +230,12 @@ func (t *transactionRepository) GetTransactions(ctx context.Context, page int, l
 
 		if search != "" {
 			searchTerm := "%" + search + "%"
-			baseSql = baseSql.Where("name ILIKE ? OR phone ILIKE ?",
-				searchTerm, searchTerm)
+			baseSql = baseSql.Where("name ILIKE ? OR phone ILIKE ? OR invoice_number ILIKE ?",
+				searchTerm, searchTerm, searchTerm)
 		}
 
-		if merchantID != 0 {
-			baseSql = baseSql.Where("merchant_id = ?", merchantID)
+		if len(merchantIDs) > 0 {
+			baseSql = baseSql.Where("merchant_id IN ?", merchantIDs)
 		}
 
 		var totalRecords int64
@@ -259,7 +290,8 @@ func (t *transactionRepository) UpdatePaymentStatus(ctx context.Context, orderID
 		return ctx.Err()
 	default:
 
-		if err := t.db.WithContext(ctx).Model(&model.Transaction{}).Where("order_id = ?", orderID).First(&model.Transaction{}).Error; err != nil {
+		var existingTransaction model.Transaction
+		if err := t.db.WithContext(ctx).Model(&model.Transaction{}).Where("order_id = ?", orderID).First(&existingTransaction).Error; err != nil {
 			log.Errorf("[TransactionRepository] UpdatePaymentStatus - 2: %v", err)
 			return err
 		}
@@ -278,6 +310,11 @@ func (t *transactionRepository) UpdatePaymentStatus(ctx context.Context, orderID
 			updates["fraud_status"] = fraudStatus
 		}
 
+		// Nomor invoice yang sudah terbit tidak pernah dipakai ulang, hanya ditandai void
+		if existingTransaction.InvoiceNumber != "" && existingTransaction.InvoiceVoidedAt == nil && paymentStatus != model.PaymentStatusSuccess {
+			updates["invoice_voided_at"] = time.Now()
+		}
+
 		err := t.db.WithContext(ctx).
 			Model(&model.Transaction{}).
 			Where("order_id = ?", orderID).
@@ -292,6 +329,139 @@ func (t *transactionRepository) UpdatePaymentStatus(ctx context.Context, orderID
 	}
 }
 
+// AssignInvoiceNumber implements TransactionRepositoryInterface.
+func (t *transactionRepository) AssignInvoiceNumber(ctx context.Context, orderID string, formatNumber func(merchantID uint, year int, sequence int64) string) (string, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransactionRepository] AssignInvoiceNumber - 1: %v", ctx.Err())
+		return "", ctx.Err()
+	default:
+		tx := t.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[TransactionRepository] AssignInvoiceNumber - 2: %v", tx.Error)
+			return "", tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[TransactionRepository] AssignInvoiceNumber - 3: %v", r)
+			}
+		}()
+
+		var transaction model.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).First(&transaction).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[TransactionRepository] AssignInvoiceNumber - 4: %v", err)
+			return "", err
+		}
+
+		if transaction.InvoiceNumber != "" {
+			tx.Rollback()
+			return transaction.InvoiceNumber, nil
+		}
+
+		if transaction.PaymentStatus != model.PaymentStatusSuccess {
+			tx.Rollback()
+			log.Errorf("[TransactionRepository] AssignInvoiceNumber - 5: payment status is %s", transaction.PaymentStatus)
+			return "", errors.New("invoice number can only be assigned to successful transactions")
+		}
+
+		invoicedAt := time.Now()
+		sequence := model.InvoiceSequence{
+			MerchantID: transaction.MerchantID,
+			Year:       invoicedAt.Year(),
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sequence).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[TransactionRepository] AssignInvoiceNumber - 6: %v", err)
+			return "", err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("merchant_id = ? AND year = ?", sequence.MerchantID, sequence.Year).
+			First(&sequence).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[TransactionRepository] AssignInvoiceNumber - 7: %v", err)
+			return "", err
+		}
+
+		sequence.LastNumber++
+		if err := tx.Model(&sequence).Update("last_number", sequence.LastNumber).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[TransactionRepository] AssignInvoiceNumber - 8: %v", err)
+			return "", err
+		}
+
+		invoiceNumber := formatNumber(transaction.MerchantID, sequence.Year, sequence.LastNumber)
+		if err := tx.Model(&transaction).Updates(map[string]interface{}{
+			"invoice_number": invoiceNumber,
+			"invoiced_at":    invoicedAt,
+		}).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("[TransactionRepository] AssignInvoiceNumber - 9: %v", err)
+			return "", err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[TransactionRepository] AssignInvoiceNumber - 10: %v", err)
+			return "", err
+		}
+
+		return invoiceNumber, nil
+	}
+}
+
 func NewTransactionRepository(db *gorm.DB) TransactionRepositoryInterface {
 	return &transactionRepository{db: db}
 }
+
+// GetPendingTransactionsForPolling implements TransactionRepositoryInterface.
+func (t *transactionRepository) GetPendingTransactionsForPolling(ctx context.Context, createdBefore time.Time, now time.Time, limit int) ([]model.Transaction, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransactionRepository] GetPendingTransactionsForPolling - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var transactions []model.Transaction
+		err := t.db.WithContext(ctx).
+			Where("payment_status = ? AND created_at <= ?", model.PaymentStatusPending, createdBefore).
+			Where("next_status_poll_at IS NULL OR next_status_poll_at <= ?", now).
+			Where("risk_decision <> ?", model.RiskDecisionReview).
+			Order("next_status_poll_at ASC NULLS FIRST").
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&transactions).Error
+		if err != nil {
+			log.Errorf("[TransactionRepository] GetPendingTransactionsForPolling - 2: %v", err)
+			return nil, err
+		}
+
+		return transactions, nil
+	}
+}
+
+// RecordStatusPoll implements TransactionRepositoryInterface.
+func (t *transactionRepository) RecordStatusPoll(ctx context.Context, id uint, attempts int, polledAt time.Time, nextPollAt time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[TransactionRepository] RecordStatusPoll - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		err := t.db.WithContext(ctx).
+			Model(&model.Transaction{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"status_poll_attempts":  attempts,
+				"last_status_polled_at": polledAt,
+				"next_status_poll_at":   nextPollAt,
+			}).Error
+		if err != nil {
+			log.Errorf("[TransactionRepository] RecordStatusPoll - 2: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/transaction-service/model"
+	"micro-warehouse/transaction-service/repository"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type AccountingUsecaseInterface interface {
+	GetJournal(ctx context.Context, merchantID uint, startDate, endDate time.Time) ([]model.JournalLine, error)
+
+	GetAccountMappings(ctx context.Context, merchantID uint) ([]model.AccountMapping, error)
+	UpdateAccountMappings(ctx context.Context, merchantID uint, mappings []model.AccountMapping) error
+}
+
+type accountingUsecase struct {
+	accountingRepo repository.AccountingRepositoryInterface
+}
+
+// accountChart adalah hasil penggabungan mapping bawaan, default global dan milik merchant
+type accountChart map[string]model.AccountMapping
+
+func (c accountChart) resolve(accountKey string) model.AccountMapping {
+	if mapping, exists := c[accountKey]; exists {
+		return mapping
+	}
+
+	if strings.HasPrefix(accountKey, model.AccountKeyPaymentPrefix) {
+		return c[model.AccountKeyPaymentDefault]
+	}
+
+	return model.AccountMapping{AccountKey: accountKey}
+}
+
+// GetJournal implements AccountingUsecaseInterface.
+func (a *accountingUsecase) GetJournal(ctx context.Context, merchantID uint, startDate time.Time, endDate time.Time) ([]model.JournalLine, error) {
+	sales, err := a.accountingRepo.GetSalesForJournal(ctx, merchantID, startDate, endDate)
+	if err != nil {
+		log.Errorf("[AccountingUsecase] GetJournal - 1: %v", err)
+		return nil, err
+	}
+
+	refunds, err := a.accountingRepo.GetRefundsForJournal(ctx, merchantID, startDate, endDate)
+	if err != nil {
+		log.Errorf("[AccountingUsecase] GetJournal - 2: %v", err)
+		return nil, err
+	}
+
+	charts := make(map[uint]accountChart)
+	chartFor := func(merchantID uint) (accountChart, error) {
+		if chart, exists := charts[merchantID]; exists {
+			return chart, nil
+		}
+
+		chart, err := a.buildAccountChart(ctx, merchantID)
+		if err != nil {
+			return nil, err
+		}
+
+		charts[merchantID] = chart
+		return chart, nil
+	}
+
+	var lines []model.JournalLine
+	for _, transaction := range sales {
+		chart, err := chartFor(transaction.MerchantID)
+		if err != nil {
+			log.Errorf("[AccountingUsecase] GetJournal - 3: %v", err)
+			return nil, err
+		}
+
+		entryLines, err := buildSaleJournalLines(transaction, chart)
+		if err != nil {
+			log.Errorf("[AccountingUsecase] GetJournal - 4: %v", err)
+			return nil, err
+		}
+		lines = append(lines, entryLines...)
+	}
+
+	for _, transaction := range refunds {
+		chart, err := chartFor(transaction.MerchantID)
+		if err != nil {
+			log.Errorf("[AccountingUsecase] GetJournal - 5: %v", err)
+			return nil, err
+		}
+
+		entryLines, err := buildRefundJournalLines(transaction, chart)
+		if err != nil {
+			log.Errorf("[AccountingUsecase] GetJournal - 6: %v", err)
+			return nil, err
+		}
+		lines = append(lines, entryLines...)
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].EntryDate.Before(lines[j].EntryDate)
+	})
+
+	return lines, nil
+}
+
+// GetAccountMappings implements AccountingUsecaseInterface.
+func (a *accountingUsecase) GetAccountMappings(ctx context.Context, merchantID uint) ([]model.AccountMapping, error) {
+	chart, err := a.buildAccountChart(ctx, merchantID)
+	if err != nil {
+		log.Errorf("[AccountingUsecase] GetAccountMappings - 1: %v", err)
+		return nil, err
+	}
+
+	mappings := make([]model.AccountMapping, 0, len(chart))
+	for _, mapping := range chart {
+		mappings = append(mappings, mapping)
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].AccountKey < mappings[j].AccountKey
+	})
+
+	return mappings, nil
+}
+
+// UpdateAccountMappings implements AccountingUsecaseInterface.
+func (a *accountingUsecase) UpdateAccountMappings(ctx context.Context, merchantID uint, mappings []model.AccountMapping) error {
+	for i := range mappings {
+		if !isValidAccountKey(mappings[i].AccountKey) {
+			log.Errorf("[AccountingUsecase] UpdateAccountMappings - 1: unknown account key %s", mappings[i].AccountKey)
+			return fmt.Errorf("account key '%s' tidak dikenal", mappings[i].AccountKey)
+		}
+
+		mappings[i].MerchantID = merchantID
+		mappings[i].AccountKey = strings.ToLower(mappings[i].AccountKey)
+	}
+
+	if err := a.accountingRepo.UpsertAccountMappings(ctx, mappings); err != nil {
+		log.Errorf("[AccountingUsecase] UpdateAccountMappings - 2: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func NewAccountingUsecase(accountingRepo repository.AccountingRepositoryInterface) AccountingUsecaseInterface {
+	return &accountingUsecase{
+		accountingRepo: accountingRepo,
+	}
+}
+
+func (a *accountingUsecase) buildAccountChart(ctx context.Context, merchantID uint) (accountChart, error) {
+	chart := make(accountChart)
+	for key, mapping := range model.DefaultAccountMappings {
+		chart[key] = mapping
+	}
+
+	mappings, err := a.accountingRepo.GetAccountMappings(ctx, merchantID)
+	if err != nil {
+		log.Errorf("[AccountingUsecase] buildAccountChart - 1: %v", err)
+		return nil, err
+	}
+
+	// repository mengurutkan merchant_id asc, sehingga mapping merchant menimpa default global
+	for _, mapping := range mappings {
+		chart[mapping.AccountKey] = mapping
+	}
+
+	return chart, nil
+}
+
+func isValidAccountKey(accountKey string) bool {
+	accountKey = strings.ToLower(accountKey)
+	switch accountKey {
+	case model.AccountKeySalesRevenue, model.AccountKeySalesReturn, model.AccountKeyTaxPayable, model.AccountKeyPaymentDefault:
+		return true
+	}
+
+	return strings.HasPrefix(accountKey, model.AccountKeyPaymentPrefix) && len(accountKey) > len(model.AccountKeyPaymentPrefix)
+}
+
+func journalReference(transaction model.Transaction) string {
+	if transaction.InvoiceNumber != "" {
+		return transaction.InvoiceNumber
+	}
+
+	return transaction.OrderID
+}
+
+// buildSaleJournalLines: Dr pembayaran (grand total), Cr pendapatan (sub total), Cr PPN keluaran (tax total)
+func buildSaleJournalLines(transaction model.Transaction, chart accountChart) ([]model.JournalLine, error) {
+	entryDate := transaction.CreatedAt
+	if transaction.InvoicedAt != nil {
+		entryDate = *transaction.InvoicedAt
+	}
+
+	reference := journalReference(transaction)
+	base := model.JournalLine{
+		EntryNumber:   reference,
+		EntryDate:     entryDate,
+		EntryType:     model.JournalEntryTypeSale,
+		MerchantID:    transaction.MerchantID,
+		TransactionID: transaction.ID,
+		OrderID:       transaction.OrderID,
+		InvoiceNumber: transaction.InvoiceNumber,
+		PaymentMethod: transaction.PaymentMethod,
+	}
+
+	lines := []model.JournalLine{
+		journalLine(base, chart.resolve(model.PaymentAccountKey(transaction.PaymentMethod)), "Payment received "+reference, transaction.GrandTotal, 0),
+		journalLine(base, chart.resolve(model.AccountKeySalesRevenue), "Sales "+reference, 0, transaction.SubTotal),
+	}
+
+	if transaction.TaxTotal != 0 {
+		lines = append(lines, journalLine(base, chart.resolve(model.AccountKeyTaxPayable), "VAT output "+reference, 0, transaction.TaxTotal))
+	}
+
+	if err := ensureBalanced(lines); err != nil {
+		return nil, fmt.Errorf("transaction %d: %w", transaction.ID, err)
+	}
+
+	return lines, nil
+}
+
+// buildRefundJournalLines membalik jurnal penjualan ketika invoice di-void
+func buildRefundJournalLines(transaction model.Transaction, chart accountChart) ([]model.JournalLine, error) {
+	reference := journalReference(transaction)
+	base := model.JournalLine{
+		EntryNumber:   "RFD/" + reference,
+		EntryDate:     *transaction.InvoiceVoidedAt,
+		EntryType:     model.JournalEntryTypeRefund,
+		MerchantID:    transaction.MerchantID,
+		TransactionID: transaction.ID,
+		OrderID:       transaction.OrderID,
+		InvoiceNumber: transaction.InvoiceNumber,
+		PaymentMethod: transaction.PaymentMethod,
+	}
+
+	lines := []model.JournalLine{
+		journalLine(base, chart.resolve(model.AccountKeySalesReturn), "Sales return "+reference, transaction.SubTotal, 0),
+	}
+
+	if transaction.TaxTotal != 0 {
+		lines = append(lines, journalLine(base, chart.resolve(model.AccountKeyTaxPayable), "VAT output reversal "+reference, transaction.TaxTotal, 0))
+	}
+
+	lines = append(lines, journalLine(base, chart.resolve(model.PaymentAccountKey(transaction.PaymentMethod)), "Payment refunded "+reference, 0, transaction.GrandTotal))
+
+	if err := ensureBalanced(lines); err != nil {
+		return nil, fmt.Errorf("transaction %d: %w", transaction.ID, err)
+	}
+
+	return lines, nil
+}
+
+func journalLine(base model.JournalLine, account model.AccountMapping, description string, debit, credit int64) model.JournalLine {
+	line := base
+	line.AccountCode = account.AccountCode
+	line.AccountName = account.AccountName
+	line.Description = description
+	line.Debit = debit
+	line.Credit = credit
+
+	return line
+}
+
+func ensureBalanced(lines []model.JournalLine) error {
+	var totalDebit, totalCredit int64
+	for _, line := range lines {
+		totalDebit += line.Debit
+		totalCredit += line.Credit
+	}
+
+	if totalDebit != totalCredit {
+		return errors.New("journal entry is not balanced")
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"micro-warehouse/transaction-service/configs"
+	"micro-warehouse/transaction-service/model"
+	"micro-warehouse/transaction-service/pkg/midtrans"
+	"micro-warehouse/transaction-service/repository"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	defaultPollIntervalSeconds    = 60
+	defaultPollPendingAgeMinutes  = 10
+	defaultPollBatchLimit         = 50
+	defaultPollBackoffBaseSeconds = 60
+	defaultPollBackoffMaxSeconds  = 3600
+	defaultPollExpireAfterHours   = 24
+)
+
+// PaymentStatusPollerInterface menarik status transaksi pending dari Midtrans
+// sebagai fallback ketika callback tidak pernah sampai ke service ini
+type PaymentStatusPollerInterface interface {
+	Start(ctx context.Context)
+	RunOnce(ctx context.Context) (PollRunResult, error)
+	Metrics() PollerMetrics
+}
+
+type PollRunResult struct {
+	Checked   int `json:"checked"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	NotFound  int `json:"not_found"`
+	Expired   int `json:"expired"`
+	Failed    int `json:"failed"`
+}
+
+type PollerMetrics struct {
+	Enabled             bool          `json:"enabled"`
+	Running             bool          `json:"running"`
+	TotalRuns           int64         `json:"total_runs"`
+	FailedRuns          int64         `json:"failed_runs"`
+	ConsecutiveFailures int64         `json:"consecutive_failures"`
+	TotalChecked        int64         `json:"total_checked"`
+	TotalUpdated        int64         `json:"total_updated"`
+	TotalUnchanged      int64         `json:"total_unchanged"`
+	TotalNotFound       int64         `json:"total_not_found"`
+	TotalExpired        int64         `json:"total_expired"`
+	TotalFailed         int64         `json:"total_failed"`
+	LastRunAt           *time.Time    `json:"last_run_at"`
+	LastRunDurationMs   int64         `json:"last_run_duration_ms"`
+	LastRunResult       PollRunResult `json:"last_run_result"`
+	LastError           string        `json:"last_error"`
+}
+
+type paymentStatusPoller struct {
+	transactionRepo    repository.TransactionRepositoryInterface
+	transactionUsecase TransactionUsecaseInterface
+	midtransService    midtrans.MidtransServiceInterface
+
+	enabled     bool
+	interval    time.Duration
+	pendingAge  time.Duration
+	batchLimit  int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	expireAfter time.Duration
+
+	mu      sync.Mutex
+	metrics PollerMetrics
+}
+
+// Start implements PaymentStatusPollerInterface.
+func (p *paymentStatusPoller) Start(ctx context.Context) {
+	if !p.enabled {
+		log.Info("[PaymentStatusPoller] Start - poller disabled")
+		return
+	}
+
+	p.mu.Lock()
+	p.metrics.Running = true
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.metrics.Running = false
+		p.mu.Unlock()
+	}()
+
+	log.Infof("[PaymentStatusPoller] Start - polling every %s for orders pending longer than %s", p.interval, p.pendingAge)
+
+	wait := p.interval
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("[PaymentStatusPoller] Start - stopped")
+			return
+		case <-time.After(wait):
+		}
+
+		if _, err := p.RunOnce(ctx); err != nil {
+			// run gagal total (mis. database down), perpanjang jeda agar tidak membanjiri log
+			p.mu.Lock()
+			failures := p.metrics.ConsecutiveFailures
+			p.mu.Unlock()
+
+			wait = backoffDuration(p.interval, p.backoffMax, int(failures))
+			log.Errorf("[PaymentStatusPoller] Start - run failed, next run in %s: %v", wait, err)
+			continue
+		}
+
+		wait = p.interval
+	}
+}
+
+// RunOnce implements PaymentStatusPollerInterface.
+func (p *paymentStatusPoller) RunOnce(ctx context.Context) (PollRunResult, error) {
+	var result PollRunResult
+	startedAt := time.Now()
+
+	transactions, err := p.transactionRepo.GetPendingTransactionsForPolling(ctx, startedAt.Add(-p.pendingAge), startedAt, p.batchLimit)
+	if err != nil {
+		log.Errorf("[PaymentStatusPoller] RunOnce - 1: %v", err)
+		p.recordRun(startedAt, result, err)
+		return result, err
+	}
+
+	for _, transaction := range transactions {
+		if ctx.Err() != nil {
+			break
+		}
+
+		result.Checked++
+		p.pollTransaction(ctx, transaction, &result)
+	}
+
+	p.recordRun(startedAt, result, nil)
+
+	if result.Checked > 0 {
+		log.Infof("[PaymentStatusPoller] RunOnce - checked %d, updated %d, unchanged %d, not found %d, expired %d, failed %d",
+			result.Checked, result.Updated, result.Unchanged, result.NotFound, result.Expired, result.Failed)
+	}
+
+	return result, nil
+}
+
+// Metrics implements PaymentStatusPollerInterface.
+func (p *paymentStatusPoller) Metrics() PollerMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.metrics
+}
+
+func NewPaymentStatusPoller(cfg configs.Config, transactionRepo repository.TransactionRepositoryInterface, transactionUsecase TransactionUsecaseInterface, midtransService midtrans.MidtransServiceInterface) PaymentStatusPollerInterface {
+	pollerCfg := cfg.PaymentPoller
+
+	return &paymentStatusPoller{
+		transactionRepo:    transactionRepo,
+		transactionUsecase: transactionUsecase,
+		midtransService:    midtransService,
+		enabled:            pollerCfg.Enabled,
+		interval:           time.Duration(intOrDefault(pollerCfg.IntervalSeconds, defaultPollIntervalSeconds)) * time.Second,
+		pendingAge:         time.Duration(intOrDefault(pollerCfg.PendingAgeMinutes, defaultPollPendingAgeMinutes)) * time.Minute,
+		batchLimit:         intOrDefault(pollerCfg.BatchLimit, defaultPollBatchLimit),
+		backoffBase:        time.Duration(intOrDefault(pollerCfg.BackoffBaseSeconds, defaultPollBackoffBaseSeconds)) * time.Second,
+		backoffMax:         time.Duration(intOrDefault(pollerCfg.BackoffMaxSeconds, defaultPollBackoffMaxSeconds)) * time.Second,
+		expireAfter:        time.Duration(intOrDefault(pollerCfg.ExpireAfterHours, defaultPollExpireAfterHours)) * time.Hour,
+		metrics: PollerMetrics{
+			Enabled: pollerCfg.Enabled,
+		},
+	}
+}
+
+func (p *paymentStatusPoller) pollTransaction(ctx context.Context, transaction model.Transaction, result *PollRunResult) {
+	statusResponse, err := p.midtransService.GetTransactionStatus(ctx, transaction.OrderID)
+	if err != nil {
+		if !errors.Is(err, midtrans.ErrTransactionNotFound) {
+			log.Errorf("[PaymentStatusPoller] pollTransaction - 1: order %s: %v", transaction.OrderID, err)
+			result.Failed++
+			p.scheduleNextPoll(ctx, transaction)
+			return
+		}
+
+		// Order belum pernah dibayar di Midtrans, tandai expired setelah batas waktu snap lewat
+		if time.Since(transaction.CreatedAt) < p.expireAfter {
+			result.NotFound++
+			p.scheduleNextPoll(ctx, transaction)
+			return
+		}
+
+		if err := p.transactionUsecase.UpdatePaymentStatus(ctx, transaction.OrderID, model.PaymentStatusExpired, "", "", ""); err != nil {
+			log.Errorf("[PaymentStatusPoller] pollTransaction - 2: order %s: %v", transaction.OrderID, err)
+			result.Failed++
+			p.scheduleNextPoll(ctx, transaction)
+			return
+		}
+
+		result.Expired++
+		return
+	}
+
+	internalStatus := model.ConvertMidtransStatusToInternal(statusResponse.TransactionStatus)
+	if internalStatus == model.PaymentStatusPending {
+		result.Unchanged++
+		p.scheduleNextPoll(ctx, transaction)
+		return
+	}
+
+	log.Infof("[PaymentStatusPoller] pollTransaction - Recovered status: %s -> %s for order_id: %s", statusResponse.TransactionStatus, internalStatus, transaction.OrderID)
+
+	if err := p.transactionUsecase.UpdatePaymentStatus(ctx, transaction.OrderID, internalStatus, statusResponse.PaymentType, statusResponse.TransactionID, statusResponse.FraudStatus); err != nil {
+		log.Errorf("[PaymentStatusPoller] pollTransaction - 3: order %s: %v", transaction.OrderID, err)
+		result.Failed++
+		p.scheduleNextPoll(ctx, transaction)
+		return
+	}
+
+	result.Updated++
+}
+
+// scheduleNextPoll menerapkan exponential backoff per order: base, 2x base, 4x base ... hingga max
+func (p *paymentStatusPoller) scheduleNextPoll(ctx context.Context, transaction model.Transaction) {
+	now := time.Now()
+	attempts := transaction.StatusPollAttempts + 1
+	nextPollAt := now.Add(backoffDuration(p.backoffBase, p.backoffMax, transaction.StatusPollAttempts))
+
+	if err := p.transactionRepo.RecordStatusPoll(ctx, transaction.ID, attempts, now, nextPollAt); err != nil {
+		log.Errorf("[PaymentStatusPoller] scheduleNextPoll - 1: order %s: %v", transaction.OrderID, err)
+	}
+}
+
+func (p *paymentStatusPoller) recordRun(startedAt time.Time, result PollRunResult, runErr error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.metrics.TotalRuns++
+	p.metrics.TotalChecked += int64(result.Checked)
+	p.metrics.TotalUpdated += int64(result.Updated)
+	p.metrics.TotalUnchanged += int64(result.Unchanged)
+	p.metrics.TotalNotFound += int64(result.NotFound)
+	p.metrics.TotalExpired += int64(result.Expired)
+	p.metrics.TotalFailed += int64(result.Failed)
+	p.metrics.LastRunAt = &startedAt
+	p.metrics.LastRunDurationMs = time.Since(startedAt).Milliseconds()
+	p.metrics.LastRunResult = result
+
+	if runErr != nil {
+		p.metrics.FailedRuns++
+		p.metrics.ConsecutiveFailures++
+		p.metrics.LastError = runErr.Error()
+		return
+	}
+
+	p.metrics.ConsecutiveFailures = 0
+	p.metrics.LastError = ""
+}
+
+func backoffDuration(base, max time.Duration, attempts int) time.Duration {
+	wait := base
+	for i := 0; i < attempts; i++ {
+		wait *= 2
+		if wait >= max {
+			return max
+		}
+	}
+
+	if wait > max {
+		return max
+	}
+
+	return wait
+}
+
+func intOrDefault(value, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
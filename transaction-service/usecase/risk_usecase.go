@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"micro-warehouse/transaction-service/configs"
+	"micro-warehouse/transaction-service/model"
+	"micro-warehouse/transaction-service/pkg/authz"
+	"micro-warehouse/transaction-service/repository"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+var ErrTransactionNotUnderReview = errors.New("transaction is not waiting for review")
+
+type RiskUsecaseInterface interface {
+	Evaluate(ctx context.Context, transaction model.Transaction) (model.RiskAssessment, error)
+
+	GetReviewQueue(ctx context.Context, page, limit int) ([]model.Transaction, int64, error)
+	ResolveReview(ctx context.Context, identity authz.Identity, transactionID uint, approve bool, note string) error
+
+	GetBlockedContacts(ctx context.Context) ([]model.BlockedContact, error)
+	BlockContact(ctx context.Context, identity authz.Identity, contact model.BlockedContact) (*model.BlockedContact, error)
+	UnblockContact(ctx context.Context, id uint) error
+}
+
+type riskUsecase struct {
+	riskRepo repository.RiskRepositoryInterface
+	config   configs.Risk
+}
+
+// Evaluate implements RiskUsecaseInterface.
+// Setiap rule yang terpicu menghasilkan action review/deny, keputusan akhir adalah action terberat.
+func (r *riskUsecase) Evaluate(ctx context.Context, transaction model.Transaction) (model.RiskAssessment, error) {
+	assessment := model.RiskAssessment{Decision: model.RiskDecisionAllow}
+	if !r.config.Enabled {
+		return assessment, nil
+	}
+
+	var reasons []model.RiskReason
+
+	if r.config.MaxQuantityPerProduct > 0 {
+		quantities := make(map[uint]int64)
+		for _, product := range transaction.TransactionProducts {
+			quantities[product.ProductID] += product.Quantity
+		}
+
+		for productID, quantity := range quantities {
+			if quantity > r.config.MaxQuantityPerProduct {
+				reasons = append(reasons, model.RiskReason{
+					Rule:    model.RiskRuleMaxQuantityPerProduct,
+					Action:  riskAction(r.config.MaxQuantityAction, model.RiskDecisionReview),
+					Message: fmt.Sprintf("quantity %d for product %d exceeds limit %d", quantity, productID, r.config.MaxQuantityPerProduct),
+				})
+			}
+		}
+	}
+
+	if r.config.MaxDailyMerchantTotal > 0 {
+		now := time.Now()
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+		dailyTotal, err := r.riskRepo.SumMerchantGrandTotalSince(ctx, transaction.MerchantID, startOfDay)
+		if err != nil {
+			log.Errorf("[RiskUsecase] Evaluate - 1: %v", err)
+			return assessment, err
+		}
+
+		if dailyTotal+transaction.GrandTotal > r.config.MaxDailyMerchantTotal {
+			reasons = append(reasons, model.RiskReason{
+				Rule:    model.RiskRuleMaxDailyMerchantTotal,
+				Action:  riskAction(r.config.MaxDailyMerchantAction, model.RiskDecisionReview),
+				Message: fmt.Sprintf("merchant daily total %d would exceed limit %d", dailyTotal+transaction.GrandTotal, r.config.MaxDailyMerchantTotal),
+			})
+		}
+	}
+
+	phone := model.NormalizeContactValue(model.BlockedContactTypePhone, transaction.Phone)
+	if r.config.VelocityMaxPerPhone > 0 && phone != "" {
+		window := time.Duration(intOrDefault(r.config.VelocityWindowMinutes, 60)) * time.Minute
+
+		count, err := r.riskRepo.CountTransactionsByPhoneSince(ctx, phone, time.Now().Add(-window))
+		if err != nil {
+			log.Errorf("[RiskUsecase] Evaluate - 2: %v", err)
+			return assessment, err
+		}
+
+		if count+1 > r.config.VelocityMaxPerPhone {
+			reasons = append(reasons, model.RiskReason{
+				Rule:    model.RiskRuleVelocityPerPhone,
+				Action:  riskAction(r.config.VelocityAction, model.RiskDecisionReview),
+				Message: fmt.Sprintf("phone made %d transactions within %s, limit %d", count+1, window, r.config.VelocityMaxPerPhone),
+			})
+		}
+	}
+
+	blockedContacts, err := r.riskRepo.FindBlockedContacts(ctx,
+		phone,
+		model.NormalizeContactValue(model.BlockedContactTypeEmail, transaction.Email),
+	)
+	if err != nil {
+		log.Errorf("[RiskUsecase] Evaluate - 3: %v", err)
+		return assessment, err
+	}
+
+	for _, contact := range blockedContacts {
+		rule := model.RiskRuleBlockedPhone
+		if contact.ContactType == model.BlockedContactTypeEmail {
+			rule = model.RiskRuleBlockedEmail
+		}
+
+		reasons = append(reasons, model.RiskReason{
+			Rule:    rule,
+			Action:  riskAction(r.config.BlocklistAction, model.RiskDecisionDeny),
+			Message: fmt.Sprintf("%s %s is blocked", contact.ContactType, contact.Value),
+		})
+	}
+
+	for _, reason := range reasons {
+		if model.RiskDecisionWeight(reason.Action) > model.RiskDecisionWeight(assessment.Decision) {
+			assessment.Decision = reason.Action
+		}
+	}
+	assessment.Reasons = reasons
+
+	return assessment, nil
+}
+
+// GetReviewQueue implements RiskUsecaseInterface.
+func (r *riskUsecase) GetReviewQueue(ctx context.Context, page int, limit int) ([]model.Transaction, int64, error) {
+	transactions, total, err := r.riskRepo.GetTransactionsForReview(ctx, page, limit)
+	if err != nil {
+		log.Errorf("[RiskUsecase] GetReviewQueue - 1: %v", err)
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
+// ResolveReview implements RiskUsecaseInterface.
+func (r *riskUsecase) ResolveReview(ctx context.Context, identity authz.Identity, transactionID uint, approve bool, note string) error {
+	if !identity.IsManager() {
+		return authz.ErrForbidden
+	}
+
+	decision, paymentStatus := model.RiskDecisionAllow, ""
+	if !approve {
+		decision, paymentStatus = model.RiskDecisionDeny, model.PaymentStatusCancel
+	}
+
+	rowsAffected, err := r.riskRepo.ResolveReview(ctx, transactionID, decision, paymentStatus, identity.UserID, note)
+	if err != nil {
+		log.Errorf("[RiskUsecase] ResolveReview - 1: %v", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrTransactionNotUnderReview
+	}
+
+	return nil
+}
+
+// GetBlockedContacts implements RiskUsecaseInterface.
+func (r *riskUsecase) GetBlockedContacts(ctx context.Context) ([]model.BlockedContact, error) {
+	return r.riskRepo.GetBlockedContacts(ctx)
+}
+
+// BlockContact implements RiskUsecaseInterface.
+func (r *riskUsecase) BlockContact(ctx context.Context, identity authz.Identity, contact model.BlockedContact) (*model.BlockedContact, error) {
+	contact.ContactType = strings.ToLower(contact.ContactType)
+	contact.Value = model.NormalizeContactValue(contact.ContactType, contact.Value)
+	contact.CreatedBy = identity.UserID
+
+	blockedContact, err := r.riskRepo.CreateBlockedContact(ctx, contact)
+	if err != nil {
+		log.Errorf("[RiskUsecase] BlockContact - 1: %v", err)
+		return nil, err
+	}
+
+	return blockedContact, nil
+}
+
+// UnblockContact implements RiskUsecaseInterface.
+func (r *riskUsecase) UnblockContact(ctx context.Context, id uint) error {
+	if err := r.riskRepo.DeleteBlockedContact(ctx, id); err != nil {
+		log.Errorf("[RiskUsecase] UnblockContact - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func NewRiskUsecase(riskRepo repository.RiskRepositoryInterface, cfg configs.Config) RiskUsecaseInterface {
+	return &riskUsecase{
+		riskRepo: riskRepo,
+		config:   cfg.Risk,
+	}
+}
+
+func riskAction(configured, defaultAction string) string {
+	switch strings.ToLower(configured) {
+	case model.RiskDecisionReview:
+		return model.RiskDecisionReview
+	case model.RiskDecisionDeny:
+		return model.RiskDecisionDeny
+	default:
+		return defaultAction
+	}
+}
@@ -2,9 +2,12 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"micro-warehouse/transaction-service/model"
+	"micro-warehouse/transaction-service/pkg/authz"
 	"micro-warehouse/transaction-service/pkg/httpclient"
+	"micro-warehouse/transaction-service/pkg/invoice"
 	"micro-warehouse/transaction-service/pkg/rabbitmq"
 	"micro-warehouse/transaction-service/repository"
 	"time"
@@ -13,62 +16,141 @@ import (
 )
 
 type TransactionUsecaseInterface interface {
-	GetDashboardStats(ctx context.Context, userID uint) (int64, int64, int64, error)                       // sorting response total revenue, total transactions, products sold
-	GetDashboardStatsByMerchant(ctx context.Context, userID, merchantID uint) (int64, int64, int64, error) // sorting response total revenue, total transactions, products sold
+	GetDashboardStats(ctx context.Context, identity authz.Identity) (int64, int64, int64, error)                            // sorting response total revenue, total transactions, products sold
+	GetDashboardStatsByMerchant(ctx context.Context, identity authz.Identity, merchantID uint) (int64, int64, int64, error) // sorting response total revenue, total transactions, products sold
 
-	GetTransactions(ctx context.Context, page, limit int, search, sortBy, sortOrder string, merchantID uint) ([]model.Transaction, int64, error) // sorting response transaction, total records
-	GetTransactionByID(ctx context.Context, id uint) (*model.Transaction, error)
-	CreateTransaction(ctx context.Context, transaction model.Transaction) (int64, error)
+	GetTransactions(ctx context.Context, identity authz.Identity, page, limit int, search, sortBy, sortOrder string, merchantID uint) ([]model.Transaction, int64, error) // sorting response transaction, total records
+	GetTransactionByID(ctx context.Context, identity authz.Identity, id uint) (*model.Transaction, error)
+	CreateTransaction(ctx context.Context, identity authz.Identity, transaction *model.Transaction) (int64, model.RiskAssessment, error)
+	GetTransactionForReview(ctx context.Context, identity authz.Identity, id uint) (*model.Transaction, error)
+	ReviewTransaction(ctx context.Context, identity authz.Identity, id uint, approve bool, note string) (*model.Transaction, error)
 
 	// Midtrans update status transaction
 	UpdatePaymentStatus(ctx context.Context, orderID string, paymentStatus, paymentMethod, transactionID, fraudStatus string) error
+
+	// Dipakai merchant-service sebelum menghapus merchant
+	CountPendingTransactionsByMerchant(ctx context.Context, merchantID uint) (int64, error)
 }
 
+// ErrMerchantClosed dikembalikan saat merchant sedang suspended, closed, tutup sementara, atau di luar jam buka
+var ErrMerchantClosed = errors.New("merchant is not open for sales")
+
 type transactionUsecase struct {
 	transactionRepo repository.TransactionRepositoryInterface
 	merchantClient  httpclient.MerchantClientInterface
 	rabbitMQService *rabbitmq.RabbitMQService
 	productClient   httpclient.ProductClientInterface
 	userClient      httpclient.UserClientInterface
+	invoiceNumber   *invoice.NumberFormatter
+	riskUsecase     RiskUsecaseInterface
 }
 
 // CreateTransaction implements TransactionUsecaseInterface.
-func (t *transactionUsecase) CreateTransaction(ctx context.Context, transaction model.Transaction) (int64, error) {
-	if err := t.validateProductStocks(ctx, transaction); err != nil {
+// Harga item dan total transaksi ditetapkan ulang dari harga efektif merchant sebelum disimpan.
+func (t *transactionUsecase) CreateTransaction(ctx context.Context, identity authz.Identity, transaction *model.Transaction) (int64, model.RiskAssessment, error) {
+	if err := t.authorizeMerchant(ctx, identity, transaction.MerchantID); err != nil {
 		log.Errorf("[TransactionUsecase] CreateTransaction - 1: %v", err)
-		return 0, err
+		return 0, model.RiskAssessment{}, err
 	}
 
-	transactionID, err := t.transactionRepo.CreateTransaction(ctx, transaction)
+	merchant, err := t.merchantClient.GetMerchantByID(ctx, transaction.MerchantID)
 	if err != nil {
 		log.Errorf("[TransactionUsecase] CreateTransaction - 2: %v", err)
-		return 0, err
+		return 0, model.RiskAssessment{}, err
+	}
+
+	if !merchant.IsOpen {
+		return 0, model.RiskAssessment{}, fmt.Errorf("%w: %s", ErrMerchantClosed, merchant.ClosedReason)
+	}
+
+	if err := t.applyMerchantPrices(ctx, transaction); err != nil {
+		log.Errorf("[TransactionUsecase] CreateTransaction - 3: %v", err)
+		return 0, model.RiskAssessment{}, err
+	}
+
+	assessment, err := t.riskUsecase.Evaluate(ctx, *transaction)
+	if err != nil {
+		log.Errorf("[TransactionUsecase] CreateTransaction - 4: %v", err)
+		return 0, model.RiskAssessment{}, err
+	}
+
+	// transaksi yang ditahan atau ditolak tetap disimpan sebagai jejak audit
+	transaction.RiskDecision = assessment.Decision
+	transaction.RiskReasons = model.EncodeRiskReasons(assessment.Reasons)
+	if assessment.Decision == model.RiskDecisionDeny {
+		transaction.PaymentStatus = model.PaymentStatusFailed
+	}
+
+	transactionID, err := t.transactionRepo.CreateTransaction(ctx, *transaction)
+	if err != nil {
+		log.Errorf("[TransactionUsecase] CreateTransaction - 5: %v", err)
+		return 0, model.RiskAssessment{}, err
+	}
+
+	if assessment.Decision != model.RiskDecisionAllow {
+		log.Warnf("[TransactionUsecase] CreateTransaction - Transaction %d flagged as %s: %s", transactionID, assessment.Decision, transaction.RiskReasons)
+		return transactionID, assessment, nil
 	}
 
 	go func() {
-		if err := t.publishStockReducedEvent(ctx, transaction); err != nil {
-			log.Errorf("[TransactionUsecase] CreateTransaction - 3: %v", err)
+		if err := t.publishStockReducedEvent(ctx, *transaction); err != nil {
+			log.Errorf("[TransactionUsecase] CreateTransaction - 6: %v", err)
 		}
 	}()
 
-	return transactionID, nil
+	return transactionID, assessment, nil
 }
 
-// GetDashboardStats implements TransactionUsecaseInterface.
-func (t *transactionUsecase) GetDashboardStats(ctx context.Context, userID uint) (int64, int64, int64, error) {
-	user, err := t.userClient.GetUserByID(ctx, userID)
+// GetTransactionForReview implements TransactionUsecaseInterface.
+// Dipakai sebelum approve supaya pembayaran dibuat selagi transaksi masih berstatus review;
+// jika pembuatan pembayaran gagal, transaksi tetap bisa di-approve ulang.
+func (t *transactionUsecase) GetTransactionForReview(ctx context.Context, identity authz.Identity, id uint) (*model.Transaction, error) {
+	if !identity.IsManager() {
+		return nil, authz.ErrForbidden
+	}
+
+	transaction, err := t.transactionRepo.GetTransactionByID(ctx, id)
 	if err != nil {
-		log.Errorf("[TransactionUsecase] GetDashboardStats - 1: %v", err)
-		return 0, 0, 0, err
+		log.Errorf("[TransactionUsecase] GetTransactionForReview - 1: %v", err)
+		return nil, err
+	}
+
+	if transaction.RiskDecision != model.RiskDecisionReview {
+		return nil, ErrTransactionNotUnderReview
+	}
+
+	return transaction, nil
+}
+
+// ReviewTransaction implements TransactionUsecaseInterface.
+// Transaksi yang disetujui baru mengurangi stock merchant setelah keputusan manager; saat approve,
+// pembayaran harus sudah dibuat lebih dulu.
+func (t *transactionUsecase) ReviewTransaction(ctx context.Context, identity authz.Identity, id uint, approve bool, note string) (*model.Transaction, error) {
+	if err := t.riskUsecase.ResolveReview(ctx, identity, id, approve, note); err != nil {
+		log.Errorf("[TransactionUsecase] ReviewTransaction - 1: %v", err)
+		return nil, err
 	}
 
-	isManager := false
-	if user.RoleName == "Manager" {
-		isManager = true
+	transaction, err := t.transactionRepo.GetTransactionByID(ctx, id)
+	if err != nil {
+		log.Errorf("[TransactionUsecase] ReviewTransaction - 2: %v", err)
+		return nil, err
+	}
+
+	if approve {
+		if err := t.publishStockReducedEvent(ctx, *transaction); err != nil {
+			log.Errorf("[TransactionUsecase] ReviewTransaction - 3: %v", err)
+		}
 	}
 
-	if !isManager {
-		return 0, 0, 0, fmt.Errorf("user tidak memiliki akses ke dashboard")
+	return transaction, nil
+}
+
+// GetDashboardStats implements TransactionUsecaseInterface.
+func (t *transactionUsecase) GetDashboardStats(ctx context.Context, identity authz.Identity) (int64, int64, int64, error) {
+	if !identity.IsManager() {
+		log.Errorf("[TransactionUsecase] GetDashboardStats - 1: user %d is not a manager", identity.UserID)
+		return 0, 0, 0, authz.ErrForbidden
 	}
 
 	totalRevenue, totalTransactions, productsSold, err := t.transactionRepo.GetDashboardStats(ctx)
@@ -81,47 +163,58 @@ func (t *transactionUsecase) GetDashboardStats(ctx context.Context, userID uint)
 }
 
 // GetDashboardStatsByMerchant implements TransactionUsecaseInterface.
-func (t *transactionUsecase) GetDashboardStatsByMerchant(ctx context.Context, userID, merchantID uint) (int64, int64, int64, error) {
-	user, err := t.userClient.GetUserByID(ctx, userID)
-	if err != nil {
+func (t *transactionUsecase) GetDashboardStatsByMerchant(ctx context.Context, identity authz.Identity, merchantID uint) (int64, int64, int64, error) {
+	if err := t.authorizeMerchant(ctx, identity, merchantID); err != nil {
 		log.Errorf("[TransactionUsecase] GetDashboardStatsByMerchant - 1: %v", err)
 		return 0, 0, 0, err
 	}
 
-	isManager := false
-	if user.RoleName == "Manager" {
-		isManager = true
-	}
-
-	if isManager {
-		return 0, 0, 0, fmt.Errorf("user tidak memiliki akses ke dashboard")
-	}
-
-	merchant, err := t.merchantClient.GetMerchantByID(ctx, merchantID)
+	totalRevenue, totalTransactions, productsSold, err := t.transactionRepo.GetDashboardStatsByMerchant(ctx, merchantID)
 	if err != nil {
 		log.Errorf("[TransactionUsecase] GetDashboardStatsByMerchant - 2: %v", err)
 		return 0, 0, 0, err
 	}
 
-	if merchant.KeeperID != userID {
-		log.Errorf("[TransactionUsecase] GetDashboardStatsByMerchant - 3: %v", err)
-		return 0, 0, 0, fmt.Errorf("user tidak memiliki akses ke merchant")
-	}
+	return totalRevenue, totalTransactions, productsSold, nil
+}
 
-	totalRevenue, totalTransactions, productsSold, err := t.transactionRepo.GetDashboardStatsByMerchant(ctx, merchantID)
+// CountPendingTransactionsByMerchant implements TransactionUsecaseInterface.
+func (t *transactionUsecase) CountPendingTransactionsByMerchant(ctx context.Context, merchantID uint) (int64, error) {
+	count, err := t.transactionRepo.CountPendingTransactionsByMerchant(ctx, merchantID)
 	if err != nil {
-		log.Errorf("[TransactionUsecase] GetDashboardStatsByMerchant - 4: %v", err)
-		return 0, 0, 0, err
+		log.Errorf("[TransactionUsecase] CountPendingTransactionsByMerchant - 1: %v", err)
+		return 0, err
 	}
 
-	return totalRevenue, totalTransactions, productsSold, nil
+	return count, nil
 }
 
 // GetTransactions implements TransactionUsecaseInterface.
-func (t *transactionUsecase) GetTransactions(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint) ([]model.Transaction, int64, error) {
-	transactions, total, err := t.transactionRepo.GetTransactions(ctx, page, limit, search, sortBy, sortOrder, merchantID)
+func (t *transactionUsecase) GetTransactions(ctx context.Context, identity authz.Identity, page int, limit int, search string, sortBy string, sortOrder string, merchantID uint) ([]model.Transaction, int64, error) {
+	var merchantIDs []uint
+	if merchantID != 0 {
+		if err := t.authorizeMerchant(ctx, identity, merchantID); err != nil {
+			log.Errorf("[TransactionUsecase] GetTransactions - 1: %v", err)
+			return nil, 0, err
+		}
+		merchantIDs = []uint{merchantID}
+	} else if !identity.IsManager() {
+		// staff tanpa filter otomatis dibatasi ke merchant tempat ia bertugas
+		keptMerchantIDs, err := t.keptMerchantIDs(ctx, identity)
+		if err != nil {
+			log.Errorf("[TransactionUsecase] GetTransactions - 2: %v", err)
+			return nil, 0, err
+		}
+
+		if len(keptMerchantIDs) == 0 {
+			return []model.Transaction{}, 0, nil
+		}
+		merchantIDs = keptMerchantIDs
+	}
+
+	transactions, total, err := t.transactionRepo.GetTransactions(ctx, page, limit, search, sortBy, sortOrder, merchantIDs)
 	if err != nil {
-		log.Errorf("[TransactionUsecase] GetTransactions - 1: %v", err)
+		log.Errorf("[TransactionUsecase] GetTransactions - 3: %v", err)
 		return nil, 0, err
 	}
 
@@ -141,13 +234,18 @@ func (t *transactionUsecase) GetTransactions(ctx context.Context, page int, limi
 }
 
 // GetTransactionByID implements TransactionUsecaseInterface.
-func (t *transactionUsecase) GetTransactionByID(ctx context.Context, id uint) (*model.Transaction, error) {
+func (t *transactionUsecase) GetTransactionByID(ctx context.Context, identity authz.Identity, id uint) (*model.Transaction, error) {
 	transaction, err := t.transactionRepo.GetTransactionByID(ctx, id)
 	if err != nil {
 		log.Errorf("[TransactionUsecase] GetTransactionByID - 1: %v", err)
 		return nil, err
 	}
 
+	if err := t.authorizeMerchant(ctx, identity, transaction.MerchantID); err != nil {
+		log.Errorf("[TransactionUsecase] GetTransactionByID - 2: %v", err)
+		return nil, err
+	}
+
 	// Enrich transaction with product data
 	if err := t.enrichTransactionWithProductData(ctx, transaction); err != nil {
 		log.Warnf("[TransactionUsecase] GetTransactionByID - Failed to enrich transaction %d with product data: %v", transaction.ID, err)
@@ -165,22 +263,81 @@ func (t *transactionUsecase) GetTransactionByID(ctx context.Context, id uint) (*
 
 // UpdatePaymentStatus implements TransactionUsecaseInterface.
 func (t *transactionUsecase) UpdatePaymentStatus(ctx context.Context, orderID string, paymentStatus string, paymentMethod string, transactionID string, fraudStatus string) error {
-	return t.transactionRepo.UpdatePaymentStatus(ctx, orderID, paymentStatus, paymentMethod, transactionID, fraudStatus)
+	if err := t.transactionRepo.UpdatePaymentStatus(ctx, orderID, paymentStatus, paymentMethod, transactionID, fraudStatus); err != nil {
+		log.Errorf("[TransactionUsecase] UpdatePaymentStatus - 1: %v", err)
+		return err
+	}
+
+	if paymentStatus != model.PaymentStatusSuccess {
+		return nil
+	}
+
+	invoiceNumber, err := t.transactionRepo.AssignInvoiceNumber(ctx, orderID, t.invoiceNumber.Format)
+	if err != nil {
+		log.Errorf("[TransactionUsecase] UpdatePaymentStatus - 2: %v", err)
+		return err
+	}
+
+	log.Infof("[TransactionUsecase] UpdatePaymentStatus - Invoice %s assigned to order %s", invoiceNumber, orderID)
+
+	return nil
 }
 
-func NewTransactionUsecase(transactionRepo repository.TransactionRepositoryInterface, merchantClient httpclient.MerchantClientInterface, rabbitMQService *rabbitmq.RabbitMQService, productClient httpclient.ProductClientInterface, userClient httpclient.UserClientInterface) TransactionUsecaseInterface {
+func NewTransactionUsecase(transactionRepo repository.TransactionRepositoryInterface, merchantClient httpclient.MerchantClientInterface, rabbitMQService *rabbitmq.RabbitMQService, productClient httpclient.ProductClientInterface, userClient httpclient.UserClientInterface, invoiceNumber *invoice.NumberFormatter, riskUsecase RiskUsecaseInterface) TransactionUsecaseInterface {
 	return &transactionUsecase{
 		transactionRepo: transactionRepo,
 		merchantClient:  merchantClient,
 		rabbitMQService: rabbitMQService,
 		productClient:   productClient,
 		userClient:      userClient,
+		invoiceNumber:   invoiceNumber,
+		riskUsecase:     riskUsecase,
+	}
+}
+
+// authorizeMerchant: manager boleh mengakses semua merchant, selain itu hanya merchant tempat user bertugas
+func (t *transactionUsecase) authorizeMerchant(ctx context.Context, identity authz.Identity, merchantID uint) error {
+	if identity.IsManager() {
+		return nil
+	}
+
+	keptMerchantIDs, err := t.keptMerchantIDs(ctx, identity)
+	if err != nil {
+		return err
+	}
+
+	for _, keptMerchantID := range keptMerchantIDs {
+		if keptMerchantID == merchantID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("user %d is not staff of merchant %d: %w", identity.UserID, merchantID, authz.ErrForbidden)
+}
+
+// keptMerchantIDs mengambil merchant tempat user aktif bertugas menurut roster staff merchant-service
+// (lead maupun cashier), terlepas dari role global user
+func (t *transactionUsecase) keptMerchantIDs(ctx context.Context, identity authz.Identity) ([]uint, error) {
+	merchants, err := t.merchantClient.GetMerchantsByKeeperID(ctx, identity.UserID)
+	if err != nil {
+		log.Errorf("[TransactionUsecase] keptMerchantIDs - 1: %v", err)
+		return nil, err
+	}
+
+	merchantIDs := make([]uint, 0, len(merchants))
+	for _, merchant := range merchants {
+		merchantIDs = append(merchantIDs, merchant.ID)
 	}
+
+	return merchantIDs, nil
 }
 
-func (tu *transactionUsecase) validateProductStocks(ctx context.Context, transaction model.Transaction) error {
+// applyMerchantPrices memvalidasi stock merchant dan mengganti harga setiap item dengan harga efektif merchant,
+// lalu menghitung ulang total transaksi. Harga global product dipakai jika merchant-service tidak mengirim harga efektif.
+func (tu *transactionUsecase) applyMerchantPrices(ctx context.Context, transaction *model.Transaction) error {
+	for i := range transaction.TransactionProducts {
+		product := &transaction.TransactionProducts[i]
 
-	for _, product := range transaction.TransactionProducts {
 		// Get stock information from merchant service
 		merchantProduct, err := tu.merchantClient.GetMerchantProductStock(
 			ctx,
@@ -188,22 +345,38 @@ func (tu *transactionUsecase) validateProductStocks(ctx context.Context, transac
 			product.ProductID,
 		)
 		if err != nil {
-			log.Errorf("[TransactionUsecase] validateProductStocks - 3: %v", err)
+			log.Errorf("[TransactionUsecase] applyMerchantPrices - 1: %v", err)
 			return err
 		}
 
 		// Check if available stock is sufficient
 		if merchantProduct.Stock < int(product.Quantity) {
-			log.Errorf("[TransactionUsecase] validateProductStocks - Insufficient stock for product %d. Required: %d, Available: %d",
+			log.Errorf("[TransactionUsecase] applyMerchantPrices - Insufficient stock for product %d. Required: %d, Available: %d",
 				product.ProductID, product.Quantity, merchantProduct.Stock)
 			return fmt.Errorf("stock tidak mencukupi untuk product '%s'. Dibutuhkan: %d, Tersedia: %d",
 				merchantProduct.ProductName, product.Quantity, merchantProduct.Stock)
 		}
 
-		log.Infof("[TransactionUsecase] validateProductStocks - Stock validation passed for product %d (%s). Required: %d, Available: %d",
+		price := merchantProduct.EffectivePrice
+		if price <= 0 {
+			price = int64(merchantProduct.ProductPrice)
+		}
+		if price <= 0 {
+			return fmt.Errorf("harga product '%s' belum ditetapkan", merchantProduct.ProductName)
+		}
+
+		if product.Price != 0 && product.Price != price {
+			log.Warnf("[TransactionUsecase] applyMerchantPrices - Price for product %d replaced from %d to merchant price %d",
+				product.ProductID, product.Price, price)
+		}
+		product.Price = price
+
+		log.Infof("[TransactionUsecase] applyMerchantPrices - Stock validation passed for product %d (%s). Required: %d, Available: %d",
 			product.ProductID, merchantProduct.ProductName, product.Quantity, merchantProduct.Stock)
 	}
 
+	transaction.CalculateTotals()
+
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"micro-warehouse/user-service/configs"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/streadway/amqp"
@@ -18,8 +19,28 @@ type EmailPayload struct {
 	Name     string `json:"name"`
 }
 
+// UserChangedEvent memberi tahu merchant-service bahwa user yang di-cache sudah berubah.
+// Roles berisi role sebelum dan sesudah perubahan agar cache daftar user per role ikut terhapus.
+type UserChangedEvent struct {
+	Entity    string    `json:"entity"`
+	Action    string    `json:"action"`
+	ID        uint      `json:"id"`
+	Roles     []string  `json:"roles,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+const (
+	EntityEventsExchange = "entity_events"
+
+	EntityUser = "user"
+
+	EntityActionUpdated = "updated"
+	EntityActionDeleted = "deleted"
+)
+
 type RabbitMQServiceInterface interface {
 	PublishEmail(ctx context.Context, payload EmailPayload) error
+	PublishUserChanged(ctx context.Context, event UserChangedEvent) error
 	Close() error
 }
 
@@ -80,6 +101,37 @@ func (r *rabbitMQService) PublishEmail(ctx context.Context, payload EmailPayload
 	return nil
 }
 
+// PublishUserChanged implements RabbitMQServiceInterface.
+// Routing key-nya "user.<action>", mis. user.updated.
+func (r *rabbitMQService) PublishUserChanged(ctx context.Context, event UserChangedEvent) error {
+	event.Entity = EntityUser
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user changed event: %v", err)
+	}
+
+	err = r.ch.Publish(
+		EntityEventsExchange,        // exchange
+		EntityUser+"."+event.Action, // routing key
+		false,                       // mandatory
+		false,                       // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish user changed event: %v", err)
+	}
+
+	return nil
+}
+
 func NewRabbitMQService(config configs.Config) (RabbitMQServiceInterface, error) {
 	conn, err := amqp.Dial(fmt.Sprintf("amqp://%s:%s@%s:%s/", config.RabbitMQ.Username, config.RabbitMQ.Password, config.RabbitMQ.Host, config.RabbitMQ.Port))
 	if err != nil {
@@ -93,6 +145,20 @@ func NewRabbitMQService(config configs.Config) (RabbitMQServiceInterface, error)
 		return nil, err
 	}
 
+	err = ch.ExchangeDeclare(
+		EntityEventsExchange, // name
+		"topic",              // type
+		true,                 // durable
+		false,                // auto-deleted
+		false,                // internal
+		false,                // no-wait
+		nil,                  // arguments
+	)
+	if err != nil {
+		log.Errorf("[RabbitMQService] NewRabbitMQService - 3: %v", err)
+		return nil, err
+	}
+
 	return &rabbitMQService{
 		conn:   conn,
 		ch:     ch,
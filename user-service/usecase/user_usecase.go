@@ -33,7 +33,13 @@ type userUsecase struct {
 
 // AssignUserToRole implements UserUsecaseInterface.
 func (u *userUsecase) AssignUserToRole(ctx context.Context, userID uint, roleID uint) error {
-	return u.userRepo.AssignUserToRole(ctx, userID, roleID)
+	if err := u.userRepo.AssignUserToRole(ctx, userID, roleID); err != nil {
+		log.Errorf("[UserUsecase] AssignUserToRole - 1: %v", err)
+		return err
+	}
+
+	u.publishUserChanged(ctx, service.EntityActionUpdated, userID, nil)
+	return nil
 }
 
 // CreateUser implements UserUsecaseInterface.
@@ -70,7 +76,7 @@ func (u *userUsecase) CreateUser(ctx context.Context, user model.User) error {
 
 // DeleteUser implements UserUsecaseInterface.
 func (u *userUsecase) DeleteUser(ctx context.Context, id uint) error {
-	_, err := u.userRepo.GetUserByID(ctx, id)
+	user, err := u.userRepo.GetUserByID(ctx, id)
 	if err != nil {
 		log.Errorf("[UserUsecase] DeleteUser - 1: %v", err)
 		return err
@@ -81,12 +87,29 @@ func (u *userUsecase) DeleteUser(ctx context.Context, id uint) error {
 		return err
 	}
 
+	u.publishUserChanged(ctx, service.EntityActionDeleted, id, user.Roles)
 	return nil
 }
 
 // EditAssignUserToRole implements UserUsecaseInterface.
+// Role lama diambil sebelum diubah supaya cache daftar user di role lama ikut dihapus.
 func (u *userUsecase) EditAssignUserToRole(ctx context.Context, assignRoleID uint, userID uint, roleID uint) error {
-	return u.userRepo.EditAssignUserToRole(ctx, assignRoleID, userID, roleID)
+	previous, err := u.userRepo.GetUserRoleByID(ctx, assignRoleID)
+	if err != nil {
+		log.Errorf("[UserUsecase] EditAssignUserToRole - 1: %v", err)
+		return err
+	}
+
+	if err := u.userRepo.EditAssignUserToRole(ctx, assignRoleID, userID, roleID); err != nil {
+		log.Errorf("[UserUsecase] EditAssignUserToRole - 2: %v", err)
+		return err
+	}
+
+	u.publishUserChanged(ctx, service.EntityActionUpdated, userID, []model.Role{previous.Role})
+	if previous.UserID != userID {
+		u.publishUserChanged(ctx, service.EntityActionUpdated, previous.UserID, []model.Role{previous.Role})
+	}
+	return nil
 }
 
 // GetAllUserRoles implements UserUsecaseInterface.
@@ -140,9 +163,39 @@ func (u *userUsecase) UpdateUser(ctx context.Context, user model.User) error {
 		return err
 	}
 
+	u.publishUserChanged(ctx, service.EntityActionUpdated, user.ID, nil)
 	return nil
 }
 
+// publishUserChanged melengkapi roles dengan role user saat ini. Kegagalan hanya dicatat karena perubahan
+// sudah tersimpan dan cache di merchant-service tetap kedaluwarsa sesuai TTL.
+func (u *userUsecase) publishUserChanged(ctx context.Context, action string, userID uint, roles []model.Role) {
+	if action != service.EntityActionDeleted {
+		if current, err := u.userRepo.GetUserByID(ctx, userID); err == nil {
+			roles = append(roles, current.Roles...)
+		}
+	}
+
+	roleNames := []string{}
+	seen := map[string]bool{}
+	for _, role := range roles {
+		if role.Name == "" || seen[role.Name] {
+			continue
+		}
+		seen[role.Name] = true
+		roleNames = append(roleNames, role.Name)
+	}
+
+	err := u.rabbitMQService.PublishUserChanged(ctx, service.UserChangedEvent{
+		Action: action,
+		ID:     userID,
+		Roles:  roleNames,
+	})
+	if err != nil {
+		log.Errorf("[UserUsecase] publishUserChanged - 1: %v", err)
+	}
+}
+
 func NewUserUsecase(userRepo repository.UserRepositoryInterface, rabbitMQService service.RabbitMQServiceInterface) UserUsecaseInterface {
 	return &userUsecase{userRepo: userRepo, rabbitMQService: rabbitMQService}
 }
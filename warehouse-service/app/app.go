@@ -49,6 +49,12 @@ func RunServer() {
 		}
 	}
 
+	if container.CacheInvalidationConsumer != nil {
+		if err := container.CacheInvalidationConsumer.StartConsuming(context.Background()); err != nil {
+			log.Errorf("Failed to start cache invalidation consumer: %v", err)
+		}
+	}
+
 	port := cfg.App.AppPort
 	if port == "" {
 		port = os.Getenv("APP_PORT")
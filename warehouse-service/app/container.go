@@ -18,7 +18,10 @@ type Container struct {
 	WarehouseController        controller.WarehouseControllerInterface
 	WarehouseProductController controller.WarehouseProductControllerInterface
 	UploadController           controller.UploadControllerInterface
+	CacheController            controller.CacheControllerInterface
 	RabbitMQConsumer           *rabbitmq.RabbitMQConsumer
+	CacheInvalidationConsumer  *rabbitmq.CacheInvalidationConsumer
+	TrashUsecase               usecase.TrashUsecaseInterface
 }
 
 func BuildContainer() *Container {
@@ -28,22 +31,38 @@ func BuildContainer() *Container {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	eventPublisher, err := rabbitmq.NewRabbitMQPublisher(config.RabbitMQ.URL())
+	if err != nil {
+		log.Fatalf("Failed to create rabbitmq publisher: %v", err)
+	}
+
 	productClient := httpclient.NewProductClient(*config)
+	merchantClient := httpclient.NewMerchantClient(*config)
 	redisClient := redis.NewRedisClient(*config)
-	cachedProductClient := httpclient.NewCachedProductClient(productClient, redisClient, 1*time.Hour)
+	cacheMetrics := httpclient.NewCacheMetrics()
+	cachedProductClient := httpclient.NewCachedProductClient(productClient, redisClient, cacheMetrics, 1*time.Hour)
+	cacheController := controller.NewCacheController(cacheMetrics)
+
 	warehouseRepo := repository.NewWarehouseRepository(db.DB)
-	warehouseUsecase := usecase.NewWarehouseUsecase(warehouseRepo)
+	warehouseUsecase := usecase.NewWarehouseUsecase(warehouseRepo, merchantClient, eventPublisher)
 	warehouseController := controller.NewWarehouseController(warehouseUsecase)
 
 	warehouseProductRepo := repository.NewWarehouseProductRepository(db.DB)
-	warehouseProductUsecase := usecase.NewWarehouseProductUsecase(warehouseProductRepo, cachedProductClient)
+	warehouseProductUsecase := usecase.NewWarehouseProductUsecase(warehouseProductRepo, cachedProductClient, eventPublisher)
 	warehouseProductController := controller.NewWarehouseProductController(warehouseProductUsecase)
 
-	rabbitMQConsumer, err := rabbitmq.NewRabbitMQConsumer(config.RabbitMQ.URL(), warehouseProductRepo)
+	trashUsecase := usecase.NewTrashUsecase(warehouseRepo, warehouseProductRepo)
+
+	rabbitMQConsumer, err := rabbitmq.NewRabbitMQConsumer(config.RabbitMQ.URL(), warehouseProductRepo, eventPublisher)
 	if err != nil {
 		log.Fatalf("Failed to create rabbitmq consumer: %v", err)
 	}
 
+	cacheInvalidationConsumer, err := rabbitmq.NewCacheInvalidationConsumer(config.RabbitMQ.URL(), cachedProductClient)
+	if err != nil {
+		log.Fatalf("Failed to create cache invalidation consumer: %v", err)
+	}
+
 	supabaseStorage := storage.NewSupabaseStorage(*config)
 	fileUploadHelper := storage.NewFileUploadHelper(supabaseStorage, *config)
 	uploadController := controller.NewUploadController(fileUploadHelper)
@@ -52,6 +71,9 @@ func BuildContainer() *Container {
 		WarehouseController:        warehouseController,
 		WarehouseProductController: warehouseProductController,
 		UploadController:           uploadController,
+		CacheController:            cacheController,
 		RabbitMQConsumer:           rabbitMQConsumer,
+		CacheInvalidationConsumer:  cacheInvalidationConsumer,
+		TrashUsecase:               trashUsecase,
 	}
 }
@@ -1,6 +1,11 @@
 package app
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"micro-warehouse/warehouse-service/middleware"
+	"micro-warehouse/warehouse-service/pkg/authz"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func SetupRoutes(app *fiber.App, c *Container) {
 	api := app.Group("/api/v1")
@@ -8,19 +13,30 @@ func SetupRoutes(app *fiber.App, c *Container) {
 	warehouses := api.Group("/warehouses")
 	warehouses.Post("/", c.WarehouseController.CreateWarehouse)
 	warehouses.Get("/", c.WarehouseController.GetAllWarehouses)
+	warehouses.Get("/cache/metrics", c.CacheController.GetCacheMetrics)
+	warehouses.Get("/trash", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.WarehouseController.GetDeletedWarehouses)
+	warehouses.Get("/locations", c.WarehouseController.GetWarehouseLocations)
 	warehouses.Get("/:id", c.WarehouseController.GetWarehouseByID)
 	warehouses.Put("/:id", c.WarehouseController.UpdateWarehouse)
 	warehouses.Delete("/:id", c.WarehouseController.DeleteWarehouse)
+	warehouses.Post("/:id/archive", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.WarehouseController.ArchiveWarehouse)
+	warehouses.Post("/:id/unarchive", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.WarehouseController.UnarchiveWarehouse)
+	warehouses.Post("/:id/restore", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.WarehouseController.RestoreWarehouse)
 
 	warehouseProducts := api.Group("/warehouse-products")
+	warehouseProducts.Get("/trash", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.WarehouseProductController.GetDeletedWarehouseProducts)
 	warehouseProducts.Post("/:warehouse_id", c.WarehouseProductController.CreateWarehouseProduct)
 	warehouseProducts.Get("/:warehouse_id", c.WarehouseProductController.GetDetailWarehouse)
+	warehouseProducts.Post("/:warehouse_id/deductions", middleware.InternalOrRole(authz.RoleManager), c.WarehouseProductController.DeductStocks)
+	warehouseProducts.Get("/:warehouse_id/movements", c.WarehouseProductController.GetStockMovements)
 	warehouseProducts.Get("/:warehouse_id/detail/:product_id", c.WarehouseProductController.GetWarehouseProductByWarehouseIDAndProductID)
 	warehouseProducts.Put("/:warehouse_id/detail/:warehouse_product_id", c.WarehouseProductController.UpdateWarehouseProduct)
 	warehouseProducts.Delete("/detail/:warehouse_product_id", c.WarehouseProductController.DeleteWarehouseProduct)
+	warehouseProducts.Post("/detail/:warehouse_product_id/restore", middleware.UserContext(), middleware.RequireRole(authz.RoleManager), c.WarehouseProductController.RestoreWarehouseProduct)
 	warehouseProducts.Delete("/detail/products/:product_id", c.WarehouseProductController.DeleteAllWarehouseProductByProductID)
 	warehouseProducts.Get("/detail/products/:product_id/total-stock", c.WarehouseProductController.GetProductTotalStock)
 	warehouseProducts.Get("/detail/products/:product_id", c.WarehouseProductController.GetWarehouseProductByProductID)
+	warehouseProducts.Get("/detail/products/:product_id/stocks", c.WarehouseProductController.GetProductWarehouseStocks)
 	warehouseProducts.Get("/detail/products/:product_id/warehouses", c.WarehouseProductController.GetDetailWarehouseProductByID)
 
 	api.Post("/upload-warehouse", c.UploadController.UploadPhoto)
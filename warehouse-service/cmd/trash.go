@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"micro-warehouse/warehouse-service/app"
+	"micro-warehouse/warehouse-service/configs"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/spf13/cobra"
+)
+
+const defaultSoftDeleteRetentionDays = 30
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage soft-deleted warehouses and warehouse products",
+}
+
+// Dijalankan terjadwal (cron); data yang sudah dihapus permanen tidak bisa di-restore lagi
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently remove warehouses and warehouse products that have been in the trash longer than the retention period",
+	Run: func(cmd *cobra.Command, args []string) {
+		retentionDays, _ := cmd.Flags().GetInt("retention-days")
+		if retentionDays <= 0 {
+			retentionDays = configs.NewConfig().SoftDelete.RetentionDays
+		}
+		if retentionDays <= 0 {
+			retentionDays = defaultSoftDeleteRetentionDays
+		}
+
+		container := app.BuildContainer()
+
+		result, err := container.TrashUsecase.PurgeDeleted(context.Background(), time.Duration(retentionDays)*24*time.Hour)
+		if err != nil {
+			log.Fatalf("Failed to purge deleted rows after %d warehouse products and %d warehouses: %v", result.WarehouseProducts, result.Warehouses, err)
+		}
+
+		fmt.Printf("Purged %d warehouse products and %d warehouses deleted more than %d days ago\n", result.WarehouseProducts, result.Warehouses, retentionDays)
+	},
+}
+
+func init() {
+	trashPurgeCmd.Flags().Int("retention-days", 0, "purge rows deleted more than this many days ago (default SOFT_DELETE_RETENTION_DAYS or 30)")
+
+	trashCmd.AddCommand(trashPurgeCmd)
+	rootCmd.AddCommand(trashCmd)
+}
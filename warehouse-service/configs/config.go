@@ -39,6 +39,11 @@ type RabbitMQ struct {
 	Password string `json:"password"`
 }
 
+// SoftDelete mengatur berapa lama data di trash disimpan sebelum boleh di-purge
+type SoftDelete struct {
+	RetentionDays int `json:"retention_days"`
+}
+
 type Supabase struct {
 	Url    string `json:"url"`
 	Key    string `json:"key"`
@@ -46,11 +51,12 @@ type Supabase struct {
 }
 
 type Config struct {
-	App      App      `json:"app"`
-	SqlDB    SqlDB    `json:"sql_db"`
-	Redis    Redis    `json:"redis"`
-	RabbitMQ RabbitMQ `json:"rabbitmq"`
-	Supabase Supabase `json:"supabase"`
+	App        App        `json:"app"`
+	SqlDB      SqlDB      `json:"sql_db"`
+	Redis      Redis      `json:"redis"`
+	RabbitMQ   RabbitMQ   `json:"rabbitmq"`
+	SoftDelete SoftDelete `json:"soft_delete"`
+	Supabase   Supabase   `json:"supabase"`
 }
 
 func (r *RabbitMQ) URL() string {
@@ -86,6 +92,9 @@ func NewConfig() *Config {
 			Username: viper.GetString("RABBITMQ_USER"),
 			Password: viper.GetString("RABBITMQ_PASSWORD"),
 		},
+		SoftDelete: SoftDelete{
+			RetentionDays: viper.GetInt("SOFT_DELETE_RETENTION_DAYS"),
+		},
 		Supabase: Supabase{
 			Url:    viper.GetString("SUPABASE_URL"),
 			Key:    viper.GetString("SUPABASE_KEY"),
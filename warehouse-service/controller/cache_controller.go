@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"micro-warehouse/warehouse-service/pkg/httpclient"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CacheControllerInterface interface {
+	GetCacheMetrics(c *fiber.Ctx) error
+}
+
+type cacheController struct {
+	cacheMetrics *httpclient.CacheMetrics
+}
+
+// GetCacheMetrics implements CacheControllerInterface.
+func (cc *cacheController) GetCacheMetrics(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    cc.cacheMetrics.Snapshot(),
+		"message": "Cache metrics fetched successfully",
+	})
+}
+
+func NewCacheController(cacheMetrics *httpclient.CacheMetrics) CacheControllerInterface {
+	return &cacheController{
+		cacheMetrics: cacheMetrics,
+	}
+}
@@ -4,3 +4,27 @@ type CreateWarehouseProductRequest struct {
 	ProductID uint `json:"product_id" validate:"required"`
 	Stock     int  `json:"stock" validate:"required"`
 }
+
+type DeductStockRequest struct {
+	Reference string                   `json:"reference" validate:"required,max=100"`
+	Items     []DeductStockItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type DeductStockItemRequest struct {
+	ProductID uint `json:"product_id" validate:"required"`
+	Quantity  int  `json:"quantity" validate:"required,min=1"`
+}
+
+type GetWarehouseStockMovementsRequest struct {
+	Page      int    `query:"page" validate:"omitempty,min=1"`
+	Limit     int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	ProductID uint   `query:"product_id" validate:"omitempty"`
+	Reference string `query:"reference" validate:"omitempty,max=100"`
+}
+
+type GetDeletedWarehouseProductsRequest struct {
+	Page        int  `query:"page" validate:"omitempty,min=1"`
+	Limit       int  `query:"limit" validate:"omitempty,min=1,max=100"`
+	WarehouseID uint `query:"warehouse_id" validate:"omitempty"`
+	ProductID   uint `query:"product_id" validate:"omitempty"`
+}
@@ -5,6 +5,10 @@ type CreateWarehouseRequest struct {
 	Address string `json:"address" validate:"required"`
 	Phone   string `json:"phone" validate:"required"`
 	Photo   string `json:"photo" validate:"required"`
+
+	// Koordinat opsional, keduanya harus diisi bersamaan
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,min=-180,max=180"`
 }
 
 type GetAllWarehouseRequest struct {
@@ -13,4 +17,11 @@ type GetAllWarehouseRequest struct {
 	Search    string `query:"search" validate:"omitempty"`
 	SortBy    string `query:"sort_by" validate:"omitempty,oneof=id name address phone created_at"`
 	SortOrder string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Archived  bool   `query:"archived"`
+}
+
+type GetDeletedWarehousesRequest struct {
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Search string `query:"search" validate:"omitempty"`
 }
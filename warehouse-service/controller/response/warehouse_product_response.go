@@ -1,5 +1,10 @@
 package response
 
+import (
+	"micro-warehouse/warehouse-service/pkg/pagination"
+	"time"
+)
+
 type WarehouseProductResponse struct {
 	ID                   uint              `json:"id"`
 	WarehouseID          uint              `json:"warehouse_id"`
@@ -11,6 +16,7 @@ type WarehouseProductResponse struct {
 	ProductCategory      string            `json:"product_category"`
 	ProductCategoryPhoto string            `json:"product_category_photo"`
 	Stock                int               `json:"stock"`
+	QuarantinedStock     int               `json:"quarantined_stock"`
 	Warehouse            WarehouseResponse `json:"warehouse"`
 }
 
@@ -33,3 +39,50 @@ type ProductTotalStockResponse struct {
 	ProductID  uint `json:"product_id"`
 	TotalStock int  `json:"total_stock"`
 }
+
+type ProductWarehouseStockResponse struct {
+	WarehouseID      uint     `json:"warehouse_id"`
+	WarehouseName    string   `json:"warehouse_name"`
+	Latitude         *float64 `json:"latitude"`
+	Longitude        *float64 `json:"longitude"`
+	ProductID        uint     `json:"product_id"`
+	Stock            int      `json:"stock"`
+	QuarantinedStock int      `json:"quarantined_stock"`
+}
+
+type WarehouseStockMovementResponse struct {
+	ID           uint      `json:"id"`
+	WarehouseID  uint      `json:"warehouse_id"`
+	ProductID    uint      `json:"product_id"`
+	MovementType string    `json:"movement_type"`
+	Quantity     int       `json:"quantity"`
+	Reference    string    `json:"reference"`
+	MerchantID   uint      `json:"merchant_id"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type GetWarehouseStockMovementsResponse struct {
+	Movements  []WarehouseStockMovementResponse `json:"movements"`
+	Pagination pagination.PaginationResponse    `json:"pagination"`
+}
+
+type DeletedWarehouseProductResponse struct {
+	ID               uint      `json:"id"`
+	WarehouseID      uint      `json:"warehouse_id"`
+	ProductID        uint      `json:"product_id"`
+	Stock            int       `json:"stock"`
+	QuarantinedStock int       `json:"quarantined_stock"`
+	DeletedAt        time.Time `json:"deleted_at"`
+}
+
+type GetDeletedWarehouseProductsResponse struct {
+	WarehouseProducts []DeletedWarehouseProductResponse `json:"warehouse_products"`
+	Pagination        pagination.PaginationResponse     `json:"pagination"`
+}
+
+// DeductedStockItemResponse adalah jumlah yang tercatat untuk reference deduction, termasuk pada request yang diulang
+type DeductedStockItemResponse struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
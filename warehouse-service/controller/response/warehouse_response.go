@@ -1,14 +1,21 @@
 package response
 
-import "micro-warehouse/warehouse-service/pkg/pagination"
+import (
+	"micro-warehouse/warehouse-service/pkg/pagination"
+	"time"
+)
 
 type WarehouseResponse struct {
-	ID           uint   `json:"id"`
-	Name         string `json:"name"`
-	Address      string `json:"address"`
-	Photo        string `json:"photo"`
-	Phone        string `json:"phone"`
-	CountProduct int    `json:"count_product"`
+	ID           uint       `json:"id"`
+	Name         string     `json:"name"`
+	Address      string     `json:"address"`
+	Photo        string     `json:"photo"`
+	Phone        string     `json:"phone"`
+	Latitude     *float64   `json:"latitude"`
+	Longitude    *float64   `json:"longitude"`
+	CountProduct int        `json:"count_product"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
 }
 
 type GetAllWarehouseResponse struct {
@@ -16,11 +23,32 @@ type GetAllWarehouseResponse struct {
 	Pagination pagination.PaginationResponse `json:"pagination"`
 }
 
+// WarehouseLocationResponse hanya berisi warehouse yang koordinatnya sudah diisi
+type WarehouseLocationResponse struct {
+	ID        uint    `json:"id"`
+	Name      string  `json:"name"`
+	Address   string  `json:"address"`
+	Phone     string  `json:"phone"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
 type DetailWarehouseResponse struct {
 	ID                uint                       `json:"id"`
 	Name              string                     `json:"name"`
 	Address           string                     `json:"address"`
 	Photo             string                     `json:"photo"`
 	Phone             string                     `json:"phone"`
+	Latitude          *float64                   `json:"latitude"`
+	Longitude         *float64                   `json:"longitude"`
+	ArchivedAt        *time.Time                 `json:"archived_at,omitempty"`
 	WarehouseProducts []WarehouseProductResponse `json:"warehouse_products"`
 }
+
+// DeletionBlockerResponse adalah satu alasan warehouse belum boleh dihapus
+type DeletionBlockerResponse struct {
+	Type     string `json:"type"`
+	Count    int64  `json:"count"`
+	Quantity int64  `json:"quantity,omitempty"`
+	Message  string `json:"message"`
+}
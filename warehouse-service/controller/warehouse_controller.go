@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"micro-warehouse/warehouse-service/controller/request"
 	"micro-warehouse/warehouse-service/controller/response"
 	"micro-warehouse/warehouse-service/model"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
 type WarehouseControllerInterface interface {
@@ -19,6 +21,11 @@ type WarehouseControllerInterface interface {
 	GetWarehouseByID(ctx *fiber.Ctx) error
 	UpdateWarehouse(ctx *fiber.Ctx) error
 	DeleteWarehouse(ctx *fiber.Ctx) error
+	ArchiveWarehouse(ctx *fiber.Ctx) error
+	UnarchiveWarehouse(ctx *fiber.Ctx) error
+	GetDeletedWarehouses(ctx *fiber.Ctx) error
+	RestoreWarehouse(ctx *fiber.Ctx) error
+	GetWarehouseLocations(ctx *fiber.Ctx) error
 }
 
 type warehouseController struct {
@@ -43,10 +50,12 @@ func (w *warehouseController) CreateWarehouse(ctx *fiber.Ctx) error {
 	}
 
 	reqModel := model.Warehouse{
-		Name:    req.Name,
-		Address: req.Address,
-		Phone:   req.Phone,
-		Photo:   req.Photo,
+		Name:      req.Name,
+		Address:   req.Address,
+		Phone:     req.Phone,
+		Photo:     req.Photo,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
 	}
 
 	if err := w.warehouseUsecase.CreateWarehouse(ctx.Context(), &reqModel); err != nil {
@@ -69,6 +78,19 @@ func (w *warehouseController) DeleteWarehouse(ctx *fiber.Ctx) error {
 
 	if err := w.warehouseUsecase.DeleteWarehouse(ctx.Context(), warehouseID); err != nil {
 		log.Errorf("[WarehouseController] DeleteWarehouse - 1: %v", err)
+		var blocked *usecase.DeletionBlockedError
+		if errors.As(err, &blocked) {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message":  "Warehouse still holds stock or supplies merchants; archive it instead or resolve the blockers first",
+				"code":     "DELETION_BLOCKED",
+				"blockers": toDeletionBlockerResponses(blocked.Blockers),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Warehouse not found",
+			})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to delete warehouse",
 		})
@@ -104,7 +126,7 @@ func (w *warehouseController) GetAllWarehouses(ctx *fiber.Ctx) error {
 		req.Limit = 10
 	}
 
-	warehouses, total, err := w.warehouseUsecase.GetAllWarehouses(ctx.Context(), req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder)
+	warehouses, total, err := w.warehouseUsecase.GetAllWarehouses(ctx.Context(), req.Page, req.Limit, req.Search, req.SortBy, req.SortOrder, req.Archived)
 	if err != nil {
 		log.Errorf("[WarehouseController] GetAllWarehouses - 3: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -121,7 +143,10 @@ func (w *warehouseController) GetAllWarehouses(ctx *fiber.Ctx) error {
 			Address:      warehouse.Address,
 			Photo:        warehouse.Photo,
 			Phone:        warehouse.Phone,
+			Latitude:     warehouse.Latitude,
+			Longitude:    warehouse.Longitude,
 			CountProduct: len(warehouse.WarehouseProducts),
+			ArchivedAt:   warehouse.ArchivedAt,
 		})
 	}
 
@@ -151,19 +176,23 @@ func (w *warehouseController) GetWarehouseByID(ctx *fiber.Ctx) error {
 	}
 
 	respWarehouses := response.DetailWarehouseResponse{
-		ID:      warehouse.ID,
-		Name:    warehouse.Name,
-		Address: warehouse.Address,
-		Photo:   warehouse.Photo,
-		Phone:   warehouse.Phone,
+		ID:         warehouse.ID,
+		Name:       warehouse.Name,
+		Address:    warehouse.Address,
+		Photo:      warehouse.Photo,
+		Phone:      warehouse.Phone,
+		Latitude:   warehouse.Latitude,
+		Longitude:  warehouse.Longitude,
+		ArchivedAt: warehouse.ArchivedAt,
 	}
 
 	for _, warehouseProduct := range warehouse.WarehouseProducts {
 		respWarehouses.WarehouseProducts = append(respWarehouses.WarehouseProducts, response.WarehouseProductResponse{
-			ID:          warehouseProduct.ID,
-			WarehouseID: warehouseProduct.WarehouseID,
-			ProductID:   warehouseProduct.ProductID,
-			Stock:       warehouseProduct.Stock,
+			ID:               warehouseProduct.ID,
+			WarehouseID:      warehouseProduct.WarehouseID,
+			ProductID:        warehouseProduct.ProductID,
+			Stock:            warehouseProduct.Stock,
+			QuarantinedStock: warehouseProduct.QuarantinedStock,
 		})
 	}
 
@@ -195,11 +224,13 @@ func (w *warehouseController) UpdateWarehouse(ctx *fiber.Ctx) error {
 	}
 
 	reqModel := model.Warehouse{
-		ID:      warehouseID,
-		Name:    req.Name,
-		Address: req.Address,
-		Phone:   req.Phone,
-		Photo:   req.Photo,
+		ID:        warehouseID,
+		Name:      req.Name,
+		Address:   req.Address,
+		Phone:     req.Phone,
+		Photo:     req.Photo,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
 	}
 
 	if err := w.warehouseUsecase.UpdateWarehouse(ctx.Context(), &reqModel); err != nil {
@@ -214,8 +245,172 @@ func (w *warehouseController) UpdateWarehouse(ctx *fiber.Ctx) error {
 	})
 }
 
+// ArchiveWarehouse implements WarehouseControllerInterface.
+func (w *warehouseController) ArchiveWarehouse(ctx *fiber.Ctx) error {
+	warehouseID := conv.StringToUint(ctx.Params("id"))
+
+	if err := w.warehouseUsecase.ArchiveWarehouse(ctx.Context(), warehouseID); err != nil {
+		log.Errorf("[WarehouseController] ArchiveWarehouse - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Warehouse not found",
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to archive warehouse",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Warehouse archived successfully",
+	})
+}
+
+// UnarchiveWarehouse implements WarehouseControllerInterface.
+func (w *warehouseController) UnarchiveWarehouse(ctx *fiber.Ctx) error {
+	warehouseID := conv.StringToUint(ctx.Params("id"))
+
+	if err := w.warehouseUsecase.UnarchiveWarehouse(ctx.Context(), warehouseID); err != nil {
+		log.Errorf("[WarehouseController] UnarchiveWarehouse - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Warehouse not found",
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to unarchive warehouse",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Warehouse unarchived successfully",
+	})
+}
+
+// GetWarehouseLocations implements WarehouseControllerInterface.
+// Dipakai merchant-service untuk mencari warehouse terdekat dari sebuah merchant.
+func (w *warehouseController) GetWarehouseLocations(ctx *fiber.Ctx) error {
+	warehouses, err := w.warehouseUsecase.GetWarehouseLocations(ctx.Context())
+	if err != nil {
+		log.Errorf("[WarehouseController] GetWarehouseLocations - 1: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get warehouse locations",
+		})
+	}
+
+	locations := []response.WarehouseLocationResponse{}
+	for _, warehouse := range warehouses {
+		locations = append(locations, response.WarehouseLocationResponse{
+			ID:        warehouse.ID,
+			Name:      warehouse.Name,
+			Address:   warehouse.Address,
+			Phone:     warehouse.Phone,
+			Latitude:  *warehouse.Latitude,
+			Longitude: *warehouse.Longitude,
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    locations,
+		"message": "Warehouse locations fetched successfully",
+	})
+}
+
+// GetDeletedWarehouses implements WarehouseControllerInterface.
+func (w *warehouseController) GetDeletedWarehouses(ctx *fiber.Ctx) error {
+	var req request.GetDeletedWarehousesRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		log.Errorf("[WarehouseController] GetDeletedWarehouses - 1: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[WarehouseController] GetDeletedWarehouses - 2: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	warehouses, total, err := w.warehouseUsecase.GetDeletedWarehouses(ctx.Context(), req.Page, req.Limit, req.Search)
+	if err != nil {
+		log.Errorf("[WarehouseController] GetDeletedWarehouses - 3: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get deleted warehouses",
+		})
+	}
+
+	warehousesResponse := []response.WarehouseResponse{}
+	for _, warehouse := range warehouses {
+		deletedAt := warehouse.DeletedAt.Time
+		warehousesResponse = append(warehousesResponse, response.WarehouseResponse{
+			ID:         warehouse.ID,
+			Name:       warehouse.Name,
+			Address:    warehouse.Address,
+			Photo:      warehouse.Photo,
+			Phone:      warehouse.Phone,
+			Latitude:   warehouse.Latitude,
+			Longitude:  warehouse.Longitude,
+			ArchivedAt: warehouse.ArchivedAt,
+			DeletedAt:  &deletedAt,
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": response.GetAllWarehouseResponse{
+			Warehouses: warehousesResponse,
+			Pagination: pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+		"message": "Deleted warehouses fetched successfully",
+	})
+}
+
+// RestoreWarehouse implements WarehouseControllerInterface.
+func (w *warehouseController) RestoreWarehouse(ctx *fiber.Ctx) error {
+	warehouseID := conv.StringToUint(ctx.Params("id"))
+
+	if err := w.warehouseUsecase.RestoreWarehouse(ctx.Context(), warehouseID); err != nil {
+		log.Errorf("[WarehouseController] RestoreWarehouse - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Warehouse not found in trash",
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to restore warehouse",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Warehouse restored successfully",
+	})
+}
+
 func NewWarehouseController(warehouseUsecase usecase.WarehouseUsecaseInterface) WarehouseControllerInterface {
 	return &warehouseController{
 		warehouseUsecase: warehouseUsecase,
 	}
 }
+
+func toDeletionBlockerResponses(blockers []usecase.DeletionBlocker) []response.DeletionBlockerResponse {
+	resps := []response.DeletionBlockerResponse{}
+	for _, blocker := range blockers {
+		resps = append(resps, response.DeletionBlockerResponse{
+			Type:     blocker.Type,
+			Count:    blocker.Count,
+			Quantity: blocker.Quantity,
+			Message:  blocker.Message,
+		})
+	}
+
+	return resps
+}
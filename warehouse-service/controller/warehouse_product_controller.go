@@ -1,16 +1,20 @@
 package controller
 
 import (
+	"errors"
 	"micro-warehouse/warehouse-service/controller/request"
 	"micro-warehouse/warehouse-service/controller/response"
 	"micro-warehouse/warehouse-service/model"
 	"micro-warehouse/warehouse-service/pkg/conv"
 	"micro-warehouse/warehouse-service/pkg/httpclient"
+	"micro-warehouse/warehouse-service/pkg/pagination"
 	"micro-warehouse/warehouse-service/pkg/validator"
+	"micro-warehouse/warehouse-service/repository"
 	"micro-warehouse/warehouse-service/usecase"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
 )
 
 type WarehouseProductControllerInterface interface {
@@ -23,6 +27,11 @@ type WarehouseProductControllerInterface interface {
 	DeleteAllWarehouseProductByProductID(c *fiber.Ctx) error
 	GetWarehouseProductByProductID(c *fiber.Ctx) error
 	GetProductTotalStock(c *fiber.Ctx) error
+	GetProductWarehouseStocks(c *fiber.Ctx) error
+	DeductStocks(c *fiber.Ctx) error
+	GetStockMovements(c *fiber.Ctx) error
+	GetDeletedWarehouseProducts(c *fiber.Ctx) error
+	RestoreWarehouseProduct(c *fiber.Ctx) error
 }
 
 type warehouseProductController struct {
@@ -134,10 +143,11 @@ func (w *warehouseProductController) GetDetailWarehouse(c *fiber.Ctx) error {
 
 	for _, wp := range warehouse.WarehouseProducts {
 		warehouseProduct := response.WarehouseProductResponse{
-			ID:          wp.ID,
-			WarehouseID: wp.WarehouseID,
-			ProductID:   wp.ProductID,
-			Stock:       wp.Stock,
+			ID:               wp.ID,
+			WarehouseID:      wp.WarehouseID,
+			ProductID:        wp.ProductID,
+			Stock:            wp.Stock,
+			QuarantinedStock: wp.QuarantinedStock,
 		}
 
 		if product, exists := productMap[wp.ProductID]; exists {
@@ -249,6 +259,44 @@ func (w *warehouseProductController) GetWarehouseProductByProductID(c *fiber.Ctx
 	})
 }
 
+// GetProductWarehouseStocks implements WarehouseProductControllerInterface.
+func (w *warehouseProductController) GetProductWarehouseStocks(c *fiber.Ctx) error {
+	ctx := c.Context()
+	productID := c.Params("product_id")
+	productIDUint := conv.StringToUint(productID)
+
+	warehouseProducts, err := w.warehouseProductUsecase.GetWarehouseProductByProductID(ctx, productIDUint)
+	if err != nil {
+		log.Errorf("[WarehouseProductController] GetProductWarehouseStocks - 1: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get product warehouse stocks",
+		})
+	}
+
+	resps := []response.ProductWarehouseStockResponse{}
+	for _, wp := range warehouseProducts {
+		// Warehouse arsip tidak ditawarkan sebagai sumber stock
+		if wp.Warehouse.ArchivedAt != nil {
+			continue
+		}
+
+		resps = append(resps, response.ProductWarehouseStockResponse{
+			WarehouseID:      wp.WarehouseID,
+			WarehouseName:    wp.Warehouse.Name,
+			Latitude:         wp.Warehouse.Latitude,
+			Longitude:        wp.Warehouse.Longitude,
+			ProductID:        wp.ProductID,
+			Stock:            wp.Stock,
+			QuarantinedStock: wp.QuarantinedStock,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    resps,
+		"message": "Product warehouse stocks fetched successfully",
+	})
+}
+
 // GetWarehouseProductByWarehouseIDAndProductID implements WarehouseProductControllerInterface.
 func (w *warehouseProductController) GetWarehouseProductByWarehouseIDAndProductID(c *fiber.Ctx) error {
 	ctx := c.Context()
@@ -266,10 +314,11 @@ func (w *warehouseProductController) GetWarehouseProductByWarehouseIDAndProductI
 	}
 
 	respWarehouseProduct := response.WarehouseProductResponse{
-		ID:          warehouseProduct.ID,
-		WarehouseID: warehouseProduct.WarehouseID,
-		ProductID:   warehouseProduct.ProductID,
-		Stock:       warehouseProduct.Stock,
+		ID:               warehouseProduct.ID,
+		WarehouseID:      warehouseProduct.WarehouseID,
+		ProductID:        warehouseProduct.ProductID,
+		Stock:            warehouseProduct.Stock,
+		QuarantinedStock: warehouseProduct.QuarantinedStock,
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -320,6 +369,207 @@ func (w *warehouseProductController) UpdateWarehouseProduct(c *fiber.Ctx) error
 	})
 }
 
+// GetStockMovements implements WarehouseProductControllerInterface.
+func (w *warehouseProductController) GetStockMovements(c *fiber.Ctx) error {
+	ctx := c.Context()
+	warehouseID := conv.StringToUint(c.Params("warehouse_id"))
+
+	var req request.GetWarehouseStockMovementsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[WarehouseProductController] GetStockMovements - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[WarehouseProductController] GetStockMovements - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	movements, total, err := w.warehouseProductUsecase.GetStockMovements(ctx, warehouseID, req.ProductID, req.Reference, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[WarehouseProductController] GetStockMovements - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get stock movements",
+		})
+	}
+
+	resps := []response.WarehouseStockMovementResponse{}
+	for _, movement := range movements {
+		resps = append(resps, response.WarehouseStockMovementResponse{
+			ID:           movement.ID,
+			WarehouseID:  movement.WarehouseID,
+			ProductID:    movement.ProductID,
+			MovementType: movement.MovementType,
+			Quantity:     movement.Quantity,
+			Reference:    movement.Reference,
+			MerchantID:   movement.MerchantID,
+			Note:         movement.Note,
+			CreatedAt:    movement.CreatedAt,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stock movements fetched successfully",
+		"data": response.GetWarehouseStockMovementsResponse{
+			Movements:  resps,
+			Pagination: pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// GetDeletedWarehouseProducts implements WarehouseProductControllerInterface.
+func (w *warehouseProductController) GetDeletedWarehouseProducts(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req request.GetDeletedWarehouseProductsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Errorf("[WarehouseProductController] GetDeletedWarehouseProducts - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid query parameters",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[WarehouseProductController] GetDeletedWarehouseProducts - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	warehouseProducts, total, err := w.warehouseProductUsecase.GetDeletedWarehouseProducts(ctx, req.WarehouseID, req.ProductID, req.Page, req.Limit)
+	if err != nil {
+		log.Errorf("[WarehouseProductController] GetDeletedWarehouseProducts - 3: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get deleted warehouse products",
+		})
+	}
+
+	resps := []response.DeletedWarehouseProductResponse{}
+	for _, warehouseProduct := range warehouseProducts {
+		resps = append(resps, response.DeletedWarehouseProductResponse{
+			ID:               warehouseProduct.ID,
+			WarehouseID:      warehouseProduct.WarehouseID,
+			ProductID:        warehouseProduct.ProductID,
+			Stock:            warehouseProduct.Stock,
+			QuarantinedStock: warehouseProduct.QuarantinedStock,
+			DeletedAt:        warehouseProduct.DeletedAt.Time,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Deleted warehouse products fetched successfully",
+		"data": response.GetDeletedWarehouseProductsResponse{
+			WarehouseProducts: resps,
+			Pagination:        pagination.CalculatePagination(req.Page, req.Limit, int(total)),
+		},
+	})
+}
+
+// RestoreWarehouseProduct implements WarehouseProductControllerInterface.
+func (w *warehouseProductController) RestoreWarehouseProduct(c *fiber.Ctx) error {
+	ctx := c.Context()
+	warehouseProductID := conv.StringToUint(c.Params("warehouse_product_id"))
+
+	if err := w.warehouseProductUsecase.RestoreWarehouseProduct(ctx, warehouseProductID); err != nil {
+		log.Errorf("[WarehouseProductController] RestoreWarehouseProduct - 1: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Warehouse product not found in trash",
+			})
+		}
+		if errors.Is(err, repository.ErrWarehouseInTrash) || errors.Is(err, repository.ErrWarehouseProductExists) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to restore warehouse product",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Warehouse product restored successfully",
+	})
+}
+
+// DeductStocks implements WarehouseProductControllerInterface.
+// Dipakai merchant-service saat transfer order dikirim; reference yang sama hanya diproses sekali.
+func (w *warehouseProductController) DeductStocks(c *fiber.Ctx) error {
+	ctx := c.Context()
+	warehouseID := conv.StringToUint(c.Params("warehouse_id"))
+
+	var req request.DeductStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Errorf("[WarehouseProductController] DeductStocks - 1: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		log.Errorf("[WarehouseProductController] DeductStocks - 2: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	items := make([]model.StockDeductionItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, model.StockDeductionItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	deduction, applied, err := w.warehouseProductUsecase.DeductStocks(ctx, warehouseID, req.Reference, items)
+	if err != nil {
+		log.Errorf("[WarehouseProductController] DeductStocks - 3: %v", err)
+		if errors.Is(err, repository.ErrStockNotEnough) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to deduct warehouse stock",
+		})
+	}
+
+	deductedItems := []response.DeductedStockItemResponse{}
+	for _, item := range deduction.Items {
+		deductedItems = append(deductedItems, response.DeductedStockItemResponse{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Warehouse stock deducted successfully",
+		"data": fiber.Map{
+			"reference": req.Reference,
+			"applied":   applied,
+			"items":     deductedItems,
+		},
+	})
+}
+
 func NewWarehouseProductController(warehouseProductUsecase usecase.WarehouseProductUsecaseInterface) WarehouseProductControllerInterface {
 	return &warehouseProductController{
 		warehouseProductUsecase: warehouseProductUsecase,
@@ -24,7 +24,7 @@ func ConnectionPostgres(cfg configs.Config) (*Postgres, error) {
 		return nil, err
 	}
 
-	db.AutoMigrate(&model.Warehouse{}, &model.WarehouseProduct{})
+	db.AutoMigrate(&model.Warehouse{}, &model.WarehouseProduct{}, &model.WarehouseStockDeduction{}, &model.WarehouseStockDeductionItem{}, &model.WarehouseStockMovement{})
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Errorf("[Postgres] ConnectionPostgres - 2: %v", err)
@@ -1,16 +1,23 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Warehouse struct {
-	ID        uint       `json:"id" gorm:"primaryKey"`
-	Name      string     `json:"name" gorm:"type:varchar(100);not null"`
-	Address   string     `json:"address" gorm:"type:text"`
-	Photo     string     `json:"photo" gorm:"type:text"`
-	Phone     string     `json:"phone" gorm:"type:varchar(20);not null"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt *time.Time `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Name       string         `json:"name" gorm:"type:varchar(100);not null"`
+	Address    string         `json:"address" gorm:"type:text"`
+	Photo      string         `json:"photo" gorm:"type:text"`
+	Phone      string         `json:"phone" gorm:"type:varchar(20);not null"`
+	Latitude   *float64       `json:"latitude" gorm:"type:double precision"`
+	Longitude  *float64       `json:"longitude" gorm:"type:double precision"`
+	ArchivedAt *time.Time     `json:"archived_at,omitempty" gorm:"index"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	WarehouseProducts []WarehouseProduct `json:"warehouse_products" gorm:"foreignKey:WarehouseID"`
 }
@@ -1,15 +1,21 @@
 package model
 
-import "time"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// QuarantinedStock adalah barang rusak hasil retur merchant; tidak ikut dialokasikan ke merchant
 type WarehouseProduct struct {
-	ID          uint       `json:"id" gorm:"primaryKey"`
-	WarehouseID uint       `json:"warehouse_id" gorm:"not null;index"`
-	ProductID   uint       `json:"product_id" gorm:"not null;index"`
-	Stock       int        `json:"stock" gorm:"not null;default:0"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   *time.Time `json:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	WarehouseID      uint           `json:"warehouse_id" gorm:"not null;index"`
+	ProductID        uint           `json:"product_id" gorm:"not null;index"`
+	Stock            int            `json:"stock" gorm:"not null;default:0"`
+	QuarantinedStock int            `json:"quarantined_stock" gorm:"not null;default:0"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        *time.Time     `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	Warehouse Warehouse `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
 }
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// WarehouseStockDeduction mencatat reference (mis. transfer-order:12) yang stock-nya sudah dikurangi,
+// sehingga request yang diulang oleh pemanggil tidak mengurangi stock dua kali. Items menyimpan jumlah yang
+// benar-benar dikurangi agar request yang diulang mendapat jumlah yang sama.
+type WarehouseStockDeduction struct {
+	ID          uint                          `json:"id" gorm:"primaryKey"`
+	Reference   string                        `json:"reference" gorm:"type:varchar(100);not null;uniqueIndex"`
+	WarehouseID uint                          `json:"warehouse_id" gorm:"not null;index"`
+	Items       []WarehouseStockDeductionItem `json:"items" gorm:"foreignKey:DeductionID"`
+	CreatedAt   time.Time                     `json:"created_at"`
+}
+
+type WarehouseStockDeductionItem struct {
+	ID          uint `json:"id" gorm:"primaryKey"`
+	DeductionID uint `json:"deduction_id" gorm:"not null;index"`
+	ProductID   uint `json:"product_id" gorm:"not null"`
+	Quantity    int  `json:"quantity" gorm:"not null"`
+}
+
+type StockDeductionItem struct {
+	ProductID uint
+	Quantity  int
+}
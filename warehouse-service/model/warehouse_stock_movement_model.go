@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+const (
+	WarehouseMovementMerchantAllocation = "merchant_allocation"
+	WarehouseMovementMerchantReturn     = "merchant_return"
+	WarehouseMovementQuarantineIn       = "quarantine_in"
+)
+
+const (
+	StockReturnConditionSellable = "sellable"
+	StockReturnConditionDamaged  = "damaged"
+)
+
+// WarehouseStockMovement mencatat perubahan stock warehouse yang berasal dari service lain.
+// Quantity negatif untuk alokasi ke merchant, positif untuk retur.
+// Reference sama dengan yang dicatat di sisi pengirim (mis. stock-return:7), dan unique index-nya
+// membuat event yang dikirim ulang tidak diterapkan dua kali.
+type WarehouseStockMovement struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	WarehouseID  uint      `json:"warehouse_id" gorm:"not null;uniqueIndex:idx_warehouse_stock_movements_reference"`
+	ProductID    uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_warehouse_stock_movements_reference"`
+	MovementType string    `json:"movement_type" gorm:"type:varchar(30);not null;uniqueIndex:idx_warehouse_stock_movements_reference"`
+	Reference    string    `json:"reference" gorm:"type:varchar(100);not null;uniqueIndex:idx_warehouse_stock_movements_reference"`
+	Quantity     int       `json:"quantity" gorm:"not null"`
+	MerchantID   uint      `json:"merchant_id" gorm:"index"`
+	Note         string    `json:"note" gorm:"type:text"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// StockReturnItem adalah satu baris retur dari merchant; damaged masuk ke quarantined_stock
+type StockReturnItem struct {
+	ProductID uint
+	Quantity  int
+	Condition string
+}
@@ -0,0 +1,69 @@
+package httpclient
+
+import "sync"
+
+// Nama cache mengikuti prefix key redis sehingga metrics bisa dicocokkan langsung dengan isi redis
+const CacheProductSingle = "product:single"
+
+type CacheStats struct {
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Evictions int64   `json:"evictions"`
+	HitRatio  float64 `json:"hit_ratio"`
+}
+
+// CacheMetrics menghitung hit, miss dan eviction per cache sejak service berjalan
+type CacheMetrics struct {
+	mu    sync.Mutex
+	stats map[string]*CacheStats
+}
+
+func NewCacheMetrics() *CacheMetrics {
+	return &CacheMetrics{
+		stats: map[string]*CacheStats{},
+	}
+}
+
+func (cm *CacheMetrics) Hit(cache string) {
+	cm.update(cache, func(stats *CacheStats) { stats.Hits++ })
+}
+
+func (cm *CacheMetrics) Miss(cache string) {
+	cm.update(cache, func(stats *CacheStats) { stats.Misses++ })
+}
+
+func (cm *CacheMetrics) Evicted(cache string, count int64) {
+	cm.update(cache, func(stats *CacheStats) { stats.Evictions += count })
+}
+
+// Snapshot mengembalikan salinan metrics; cache yang belum pernah diakses tetap muncul dengan nilai nol
+func (cm *CacheMetrics) Snapshot() map[string]CacheStats {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	snapshot := map[string]CacheStats{
+		CacheProductSingle: {},
+	}
+
+	for cache, stats := range cm.stats {
+		copied := *stats
+		if total := copied.Hits + copied.Misses; total > 0 {
+			copied.HitRatio = float64(copied.Hits) / float64(total)
+		}
+		snapshot[cache] = copied
+	}
+
+	return snapshot
+}
+
+func (cm *CacheMetrics) update(cache string, apply func(stats *CacheStats)) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	stats, ok := cm.stats[cache]
+	if !ok {
+		stats = &CacheStats{}
+		cm.stats[cache] = stats
+	}
+	apply(stats)
+}
@@ -10,16 +10,18 @@ import (
 )
 
 type CachedProductClient struct {
-	client ProductClientInterface
-	redis  *redis.RedisClient
-	ttl    time.Duration
+	client  ProductClientInterface
+	redis   *redis.RedisClient
+	metrics *CacheMetrics
+	ttl     time.Duration
 }
 
-func NewCachedProductClient(productClient ProductClientInterface, redisClient *redis.RedisClient, ttl time.Duration) *CachedProductClient {
+func NewCachedProductClient(productClient ProductClientInterface, redisClient *redis.RedisClient, metrics *CacheMetrics, ttl time.Duration) *CachedProductClient {
 	return &CachedProductClient{
-		client: productClient,
-		redis:  redisClient,
-		ttl:    1 * time.Hour,
+		client:  productClient,
+		redis:   redisClient,
+		metrics: metrics,
+		ttl:     ttl,
 	}
 }
 
@@ -41,8 +43,10 @@ func (cpc *CachedProductClient) GetProductByID(ctx context.Context, productID ui
 	var cachedProduct ProductResponse
 	if err := cpc.redis.Get(ctx, cacheKey, &cachedProduct); err == nil {
 		log.Infof("[CachedProductClient] GetProductByID - 1: %v", cachedProduct)
+		cpc.metrics.Hit(CacheProductSingle)
 		return &cachedProduct, nil
 	}
+	cpc.metrics.Miss(CacheProductSingle)
 
 	product, err := cpc.client.GetProductByID(ctx, productID)
 	if err != nil {
@@ -66,3 +70,15 @@ func (cpc *CachedProductClient) GetProducts(ctx context.Context, page int, limit
 func (cpc *CachedProductClient) HealthCheck(ctx context.Context) error {
 	return cpc.client.HealthCheck(ctx)
 }
+
+// EvictProduct menghapus cache product; dipanggil saat product-service mengirim event product.updated/deleted
+func (cpc *CachedProductClient) EvictProduct(ctx context.Context, productID uint) error {
+	deleted, err := cpc.redis.Delete(ctx, cpc.generateCacheKey("single", productID))
+	if err != nil {
+		log.Errorf("[CachedProductClient] EvictProduct - 1: %v", err)
+		return err
+	}
+	cpc.metrics.Evicted(CacheProductSingle, deleted)
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"micro-warehouse/warehouse-service/configs"
+	"micro-warehouse/warehouse-service/pkg/jwt"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type MerchantClientInterface interface {
+	GetWarehouseAllocations(ctx context.Context, warehouseID uint) (*WarehouseAllocationResponse, error)
+}
+
+type MerchantClient struct {
+	UrlApiGateway string
+	httpClient    *http.Client
+	config        configs.Config
+}
+
+func (m *MerchantClient) generateInternalToken() (string, error) {
+	return jwt.GenerateInternalToken(m.config)
+}
+
+// GetWarehouseAllocations implements MerchantClientInterface.
+func (m *MerchantClient) GetWarehouseAllocations(ctx context.Context, warehouseID uint) (*WarehouseAllocationResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/merchant-products/warehouses/%d/allocations", m.UrlApiGateway, warehouseID)
+
+	token, err := m.generateInternalToken()
+	if err != nil {
+		log.Errorf("[MerchantClient] GetWarehouseAllocations - 1: %v", err)
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Errorf("[MerchantClient] GetWarehouseAllocations - 2: %v", err)
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Internal-Request", "true")
+	req.Header.Set("X-Gateway", "warehouse-api-gateway")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("[MerchantClient] GetWarehouseAllocations - 3: %v", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Errorf("[MerchantClient] GetWarehouseAllocations - 4: %v", err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("[MerchantClient] GetWarehouseAllocations - 5: %s", string(body))
+		return nil, errors.New("failed to get warehouse allocations")
+	}
+
+	var allocationResponse WarehouseAllocationServiceResponse
+	if err := json.Unmarshal(body, &allocationResponse); err != nil {
+		log.Errorf("[MerchantClient] GetWarehouseAllocations - 6: %v", err)
+		return nil, err
+	}
+
+	return &allocationResponse.Data, nil
+}
+
+type WarehouseAllocationResponse struct {
+	WarehouseID          uint  `json:"warehouse_id"`
+	MerchantCount        int64 `json:"merchant_count"`
+	MerchantProductCount int64 `json:"merchant_product_count"`
+	TotalStock           int64 `json:"total_stock"`
+}
+
+type WarehouseAllocationServiceResponse struct {
+	Message string                      `json:"message"`
+	Data    WarehouseAllocationResponse `json:"data"`
+	Error   string                      `json:"error,omitempty"`
+}
+
+func NewMerchantClient(cfg configs.Config) MerchantClientInterface {
+	return &MerchantClient{httpClient: &http.Client{
+		Timeout: 30 * time.Second,
+	}, UrlApiGateway: cfg.App.UrlApiGateway, config: cfg}
+}
@@ -0,0 +1,105 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/streadway/amqp"
+)
+
+const CacheInvalidationQueueName = "warehouse_cache_invalidation"
+
+type ProductCacheEvictor interface {
+	EvictProduct(ctx context.Context, productID uint) error
+}
+
+// CacheInvalidationConsumer menghapus cache product milik warehouse-service saat product-service mengubah product
+type CacheInvalidationConsumer struct {
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	products ProductCacheEvictor
+}
+
+func NewCacheInvalidationConsumer(rabbitMQURL string, products ProductCacheEvictor) (*CacheInvalidationConsumer, error) {
+	conn, err := amqp.Dial(rabbitMQURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	err = ch.ExchangeDeclare(EntityEventsExchange, "topic", true, false, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare entity events exchange: %w", err)
+	}
+
+	q, err := ch.QueueDeclare(CacheInvalidationQueueName, true, false, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare cache invalidation queue: %w", err)
+	}
+
+	err = ch.QueueBind(q.Name, EntityProduct+".*", EntityEventsExchange, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind cache invalidation queue: %w", err)
+	}
+
+	return &CacheInvalidationConsumer{
+		conn:     conn,
+		channel:  ch,
+		products: products,
+	}, nil
+}
+
+func (cc *CacheInvalidationConsumer) StartConsuming(ctx context.Context) error {
+	msgs, err := cc.channel.Consume(CacheInvalidationQueueName, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume cache invalidation messages: %w", err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				log.Infof("[CacheInvalidationConsumer] Stopping consumer due to context cancellation")
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					log.Infof("[CacheInvalidationConsumer] Channel closed")
+					return
+				}
+				cc.handleMessage(ctx, msg)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// handleMessage membuang pesan rusak; gagal menghapus key di-requeue sekali, setelah itu cache kedaluwarsa sesuai TTL
+func (cc *CacheInvalidationConsumer) handleMessage(ctx context.Context, msg amqp.Delivery) {
+	var event EntityChangedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Errorf("[CacheInvalidationConsumer] handleMessage - 1: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	if event.Entity != EntityProduct || event.ID == 0 {
+		log.Errorf("[CacheInvalidationConsumer] handleMessage - 2: invalid event %+v", event)
+		msg.Nack(false, false)
+		return
+	}
+
+	if err := cc.products.EvictProduct(ctx, event.ID); err != nil {
+		log.Errorf("[CacheInvalidationConsumer] handleMessage - 3: %v", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
+	}
+
+	msg.Ack(false)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"micro-warehouse/warehouse-service/model"
 	"micro-warehouse/warehouse-service/repository"
 	"time"
 
@@ -13,12 +14,16 @@ import (
 )
 
 type RabbitMQConsumer struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	repo    repository.WarehouseProductRepositoryInterface
+	conn      *amqp.Connection
+	channel   *amqp.Channel
+	repo      repository.WarehouseProductRepositoryInterface
+	publisher EventPublisherInterface
 }
 
+// StockReductionEvent dikirim merchant-service saat alokasi merchant bertambah; Stock adalah selisihnya,
+// bukan total alokasi. Alokasi yang berkurang dikirim sebagai StockReturnedEvent.
 type StockReductionEvent struct {
+	Reference   string    `json:"reference"`
 	WarehouseID uint      `json:"warehouse_id"`
 	ProductID   uint      `json:"product_id"`
 	Stock       int       `json:"stock"`
@@ -26,13 +31,55 @@ type StockReductionEvent struct {
 	Timestamp   time.Time `json:"timestamp"`
 }
 
+// StockReturnedEvent dikirim merchant-service saat keeper mengembalikan barang ke warehouse asal.
+// Reference dicatat di kedua sisi sehingga pergerakan stock bisa ditelusuri dan event tidak diterapkan dua kali.
+type StockReturnedEvent struct {
+	Reference   string              `json:"reference"`
+	MerchantID  uint                `json:"merchant_id"`
+	WarehouseID uint                `json:"warehouse_id"`
+	Reason      string              `json:"reason"`
+	Items       []StockReturnedItem `json:"items"`
+	Timestamp   time.Time           `json:"timestamp"`
+}
+
+// AllocationRejectedEvent dikirim balik ke merchant-service saat alokasi ditolak karena stock warehouse
+// tidak cukup, sehingga baris outbox-nya bisa ditandai gagal lalu dikirim ulang atau dibatalkan.
+type AllocationRejectedEvent struct {
+	Reference   string    `json:"reference"`
+	MerchantID  uint      `json:"merchant_id"`
+	WarehouseID uint      `json:"warehouse_id"`
+	ProductID   uint      `json:"product_id"`
+	Quantity    int       `json:"quantity"`
+	Reason      string    `json:"reason"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type StockReturnedItem struct {
+	ProductID uint   `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	Condition string `json:"condition"`
+}
+
 const (
 	ExchangeName = "warehouse_events"
 	QueueName    = "stock_reduction_queue"
 	RoutingKey   = "stock.reduction"
+
+	// StockReductionDLQ menyimpan alokasi yang ditolak agar bisa diperiksa atau di-replay manual.
+	// Queue utama tetap tanpa argument sehingga deployment lama tidak perlu menghapus queue-nya.
+	StockReductionDLQ = "stock_reduction_queue.dlq"
+
+	AllocationRejectedRoutingKey = "merchant.allocation.rejected"
+
+	headerDeadLetterReason   = "x-dead-letter-reason"
+	headerDeadLetteredAt     = "x-dead-lettered-at"
+	headerOriginalRoutingKey = "x-original-routing-key"
+
+	StockReturnedQueueName  = "warehouse_stock_returned_queue"
+	StockReturnedRoutingKey = "warehouse.stock.returned"
 )
 
-func NewRabbitMQConsumer(rabbitMQURL string, repo repository.WarehouseProductRepositoryInterface) (*RabbitMQConsumer, error) {
+func NewRabbitMQConsumer(rabbitMQURL string, repo repository.WarehouseProductRepositoryInterface, publisher EventPublisherInterface) (*RabbitMQConsumer, error) {
 	conn, err := amqp.Dial(rabbitMQURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
@@ -82,10 +129,46 @@ func NewRabbitMQConsumer(rabbitMQURL string, repo repository.WarehouseProductRep
 		return nil, fmt.Errorf("failed to bind queue: %w", err)
 	}
 
+	_, err = ch.QueueDeclare(
+		StockReductionDLQ, // name
+		true,              // durable
+		false,             // delete when unused
+		false,             // exclusive
+		false,             // no-wait
+		nil,               // arguments
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare stock reduction dead letter queue: %w", err)
+	}
+
+	returnedQueue, err := ch.QueueDeclare(
+		StockReturnedQueueName, // name
+		true,                   // durable
+		false,                  // delete when unused
+		false,                  // exclusive
+		false,                  // no-wait
+		nil,                    // arguments
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare stock returned queue: %w", err)
+	}
+
+	err = ch.QueueBind(
+		returnedQueue.Name,
+		StockReturnedRoutingKey,
+		ExchangeName,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind stock returned queue: %w", err)
+	}
+
 	return &RabbitMQConsumer{
-		conn:    conn,
-		channel: ch,
-		repo:    repo,
+		conn:      conn,
+		channel:   ch,
+		repo:      repo,
+		publisher: publisher,
 	}, nil
 }
 
@@ -93,7 +176,7 @@ func (rc *RabbitMQConsumer) StartConsuming(ctx context.Context) error {
 	msgs, err := rc.channel.Consume(
 		QueueName,
 		"",
-		true,
+		false,
 		false,
 		false,
 		false,
@@ -104,6 +187,20 @@ func (rc *RabbitMQConsumer) StartConsuming(ctx context.Context) error {
 		return fmt.Errorf("failed to consume messages: %w", err)
 	}
 
+	returnedMsgs, err := rc.channel.Consume(
+		StockReturnedQueueName,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to consume stock returned messages: %w", err)
+	}
+
 	go func() {
 		for {
 			select {
@@ -112,6 +209,8 @@ func (rc *RabbitMQConsumer) StartConsuming(ctx context.Context) error {
 				return
 			case msg := <-msgs:
 				rc.handleMessage(ctx, msg)
+			case msg := <-returnedMsgs:
+				rc.handleStockReturned(ctx, msg)
 			}
 		}
 	}()
@@ -119,38 +218,162 @@ func (rc *RabbitMQConsumer) StartConsuming(ctx context.Context) error {
 	return nil
 }
 
+// handleMessage menerapkan alokasi merchant. Seperti retur, pesan di-ack manual setelah stock tercatat
+// dan reference mencegah pesan yang dikirim ulang mengurangi stock dua kali.
 func (rc *RabbitMQConsumer) handleMessage(ctx context.Context, msg amqp.Delivery) {
 	var event StockReductionEvent
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
 		log.Errorf("[RabbitMQConsumer] handleMessage - 1: %v", err)
+		msg.Nack(false, false)
 		return
 	}
 
-	if err := rc.processStockReduction(ctx, event); err != nil {
-		log.Errorf("[RabbitMQConsumer] handleMessage - 2: %v", err)
+	if event.Reference == "" || event.WarehouseID == 0 || event.ProductID == 0 || event.Stock <= 0 {
+		log.Errorf("[RabbitMQConsumer] handleMessage - 2: invalid event %+v", event)
+		msg.Nack(false, false)
 		return
 	}
 
+	applied, err := rc.repo.ApplyMerchantAllocation(ctx, event.WarehouseID, event.MerchantID, event.ProductID, event.Stock, event.Reference)
+	if err != nil {
+		// Stock yang tidak cukup tidak akan berubah dengan requeue; merchant-service diberi tahu
+		// dan pesannya dipindah ke DLQ. Transaksi di-rollback sehingga reference yang sama bisa dikirim ulang.
+		if errors.Is(err, repository.ErrStockNotEnough) {
+			log.Errorf("[RabbitMQConsumer] handleMessage - 3: %s: %v", event.Reference, err)
+			if err := rc.publishAllocationRejected(event, err); err != nil {
+				log.Errorf("[RabbitMQConsumer] handleMessage - 5: %v", err)
+				msg.Nack(false, !msg.Redelivered)
+				return
+			}
+			rc.deadLetter(msg, err)
+			return
+		}
+
+		log.Errorf("[RabbitMQConsumer] handleMessage - 4: %v", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
+	}
+
+	if !applied {
+		log.Infof("[RabbitMQConsumer] handleMessage - %s already applied to warehouse %d", event.Reference, event.WarehouseID)
+	} else {
+		rc.publishStockChanged(ctx, event.WarehouseID, event.ProductID)
+	}
 	msg.Ack(false)
 }
 
-func (rc *RabbitMQConsumer) processStockReduction(ctx context.Context, event StockReductionEvent) error {
-	warehouseProduct, err := rc.repo.GetWarehouseProductByWarehouseIDAndProductID(ctx, event.WarehouseID, event.ProductID)
+// handleStockReturned menerapkan retur merchant. Pesan rusak dibuang; kegagalan database di-requeue sekali,
+// dan karena setiap baris retur unik per reference, pengiriman ulang tidak menambah stock dua kali.
+func (rc *RabbitMQConsumer) handleStockReturned(ctx context.Context, msg amqp.Delivery) {
+	var event StockReturnedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Errorf("[RabbitMQConsumer] handleStockReturned - 1: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	if event.Reference == "" || event.WarehouseID == 0 || len(event.Items) == 0 {
+		log.Errorf("[RabbitMQConsumer] handleStockReturned - 2: invalid event %+v", event)
+		msg.Nack(false, false)
+		return
+	}
+
+	items := make([]model.StockReturnItem, 0, len(event.Items))
+	for _, item := range event.Items {
+		if item.Quantity <= 0 {
+			continue
+		}
+		items = append(items, model.StockReturnItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Condition: item.Condition,
+		})
+	}
+
+	applied, err := rc.repo.ApplyStockReturn(ctx, event.WarehouseID, event.MerchantID, event.Reference, event.Reason, items)
 	if err != nil {
-		log.Errorf("[RabbitMQConsumer] processStockReduction - 1: %v", err)
-		return err
+		log.Errorf("[RabbitMQConsumer] handleStockReturned - 3: %v", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
 	}
 
-	newStock := warehouseProduct.Stock - event.Stock
-	if newStock < 0 {
-		return errors.New("stock not enough")
+	log.Infof("[RabbitMQConsumer] handleStockReturned - %s: %d of %d lines applied to warehouse %d", event.Reference, applied, len(items), event.WarehouseID)
+	if applied > 0 {
+		productIDs := make([]uint, 0, len(items))
+		for _, item := range items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		rc.publishStockChanged(ctx, event.WarehouseID, productIDs...)
 	}
+	msg.Ack(false)
+}
 
-	warehouseProduct.Stock = newStock
+// publishAllocationRejected mengirim penolakan alokasi ke merchant-service lewat exchange warehouse_events
+func (rc *RabbitMQConsumer) publishAllocationRejected(event StockReductionEvent, cause error) error {
+	body, err := json.Marshal(AllocationRejectedEvent{
+		Reference:   event.Reference,
+		MerchantID:  event.MerchantID,
+		WarehouseID: event.WarehouseID,
+		ProductID:   event.ProductID,
+		Quantity:    event.Stock,
+		Reason:      cause.Error(),
+		Timestamp:   time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal allocation rejected event: %w", err)
+	}
 
-	if err := rc.repo.UpdateWarehouseProduct(ctx, warehouseProduct); err != nil {
-		log.Errorf("[RabbitMQConsumer] processStockReduction - 2: %v", err)
+	err = rc.channel.Publish(ExchangeName, AllocationRejectedRoutingKey, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+		Timestamp:    time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish allocation rejected event: %w", err)
 	}
 
 	return nil
 }
+
+// deadLetter memindahkan pesan ke DLQ beserta alasannya; jika gagal, pesan dikembalikan ke queue agar tidak hilang
+func (rc *RabbitMQConsumer) deadLetter(msg amqp.Delivery, cause error) {
+	headers := amqp.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[headerDeadLetterReason] = cause.Error()
+	headers[headerDeadLetteredAt] = time.Now().Format(time.RFC3339)
+	if _, ok := headers[headerOriginalRoutingKey]; !ok {
+		headers[headerOriginalRoutingKey] = msg.RoutingKey
+	}
+
+	err := rc.channel.Publish("", StockReductionDLQ, false, false, amqp.Publishing{
+		ContentType:  msg.ContentType,
+		Body:         msg.Body,
+		Headers:      headers,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+	})
+	if err != nil {
+		log.Errorf("[RabbitMQConsumer] deadLetter - 1: %v", err)
+		msg.Nack(false, true)
+		return
+	}
+
+	log.Errorf("[RabbitMQConsumer] deadLetter - moved message to %s: %v", StockReductionDLQ, cause)
+	msg.Ack(false)
+}
+
+// publishStockChanged memberi tahu merchant-service agar cache stock warehouse yang berubah dihapus
+func (rc *RabbitMQConsumer) publishStockChanged(ctx context.Context, warehouseID uint, productIDs ...uint) {
+	err := rc.publisher.PublishEntityChanged(ctx, EntityChangedEvent{
+		Entity:     EntityWarehouse,
+		Action:     EntityActionStockChanged,
+		ID:         warehouseID,
+		ProductIDs: productIDs,
+	})
+	if err != nil {
+		log.Errorf("[RabbitMQConsumer] publishStockChanged - 1: %v", err)
+	}
+}
@@ -2,10 +2,15 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"micro-warehouse/warehouse-service/model"
+	"sort"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Detail warehouse, Get warehouse product by WarehouseID and ProductID,
@@ -21,8 +26,22 @@ type WarehouseProductRepositoryInterface interface {
 	DeleteAllWarehouseProductByProductID(ctx context.Context, productID uint) error
 	GetWarehouseProductByProductID(ctx context.Context, productID uint) ([]model.WarehouseProduct, error)
 	GetProductTotalStock(ctx context.Context, productID uint) (int, error)
+	DeductStocks(ctx context.Context, warehouseID uint, reference string, items []model.StockDeductionItem) (*model.WarehouseStockDeduction, error)
+	ApplyStockReturn(ctx context.Context, warehouseID, merchantID uint, reference, note string, items []model.StockReturnItem) (int, error)
+	ApplyMerchantAllocation(ctx context.Context, warehouseID, merchantID, productID uint, quantity int, reference string) (bool, error)
+	GetStockMovements(ctx context.Context, warehouseID, productID uint, reference string, page, limit int) ([]model.WarehouseStockMovement, int64, error)
+	GetDeletedWarehouseProducts(ctx context.Context, warehouseID, productID uint, page, limit int) ([]model.WarehouseProduct, int64, error)
+	RestoreWarehouseProduct(ctx context.Context, warehouseProductID uint) (*model.WarehouseProduct, error)
+	PurgeDeletedWarehouseProducts(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
+var (
+	ErrStockNotEnough               = errors.New("stock not enough")
+	ErrStockDeductionAlreadyApplied = errors.New("stock deduction already applied")
+	ErrWarehouseProductExists       = errors.New("product is already registered in this warehouse")
+	ErrWarehouseInTrash             = errors.New("warehouse is deleted; restore the warehouse first")
+)
+
 type warehouseProductRepository struct {
 	db *gorm.DB
 }
@@ -130,6 +149,7 @@ func (w *warehouseProductRepository) GetProductTotalStock(ctx context.Context, p
 }
 
 // GetWarehouseProductByProductID implements WarehouseProductRepositoryInterface.
+// Diurutkan dari stock terbanyak agar pemanggil bisa langsung memilih warehouse sumber.
 func (w *warehouseProductRepository) GetWarehouseProductByProductID(ctx context.Context, productID uint) ([]model.WarehouseProduct, error) {
 	select {
 	case <-ctx.Done():
@@ -140,6 +160,7 @@ func (w *warehouseProductRepository) GetWarehouseProductByProductID(ctx context.
 		if err := w.db.WithContext(ctx).
 			Where("product_id = ?", productID).
 			Preload("Warehouse").
+			Order("stock DESC, warehouse_id ASC").
 			Find(&warehouseProducts).Error; err != nil {
 			log.Errorf("[Repository] GetWarehouseProductByProductID - 2: %v", err)
 			return nil, err
@@ -189,6 +210,391 @@ func (w *warehouseProductRepository) UpdateWarehouseProduct(ctx context.Context,
 	}
 }
 
+// DeductStocks implements WarehouseProductRepositoryInterface.
+// Semua item dikurangi dalam satu transaksi dengan update bersyarat; jika satu item kurang, tidak ada yang berubah.
+// Jika reference sudah pernah diproses, deduction yang tercatat dikembalikan bersama ErrStockDeductionAlreadyApplied.
+func (w *warehouseProductRepository) DeductStocks(ctx context.Context, warehouseID uint, reference string, items []model.StockDeductionItem) (*model.WarehouseStockDeduction, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseProductRepository] DeductStocks - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		// Urutkan berdasarkan product_id agar dua deduction paralel mengunci baris dengan urutan yang sama
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].ProductID < items[j].ProductID
+		})
+
+		tx := w.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[WarehouseProductRepository] DeductStocks - 2: %v", tx.Error)
+			return nil, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[WarehouseProductRepository] DeductStocks - 3: %v", r)
+			}
+		}()
+
+		deduction := model.WarehouseStockDeduction{Reference: reference, WarehouseID: warehouseID}
+		result := tx.Omit("Items").Clauses(clause.OnConflict{DoNothing: true}).Create(&deduction)
+		if result.Error != nil {
+			tx.Rollback()
+			log.Errorf("[WarehouseProductRepository] DeductStocks - 4: %v", result.Error)
+			return nil, result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+
+			var existing model.WarehouseStockDeduction
+			if err := w.db.WithContext(ctx).Preload("Items").Where("reference = ?", reference).First(&existing).Error; err != nil {
+				log.Errorf("[WarehouseProductRepository] DeductStocks - 8: %v", err)
+				return nil, err
+			}
+			return &existing, ErrStockDeductionAlreadyApplied
+		}
+
+		for _, item := range items {
+			result := tx.Model(&model.WarehouseProduct{}).
+				Where("warehouse_id = ? AND product_id = ? AND stock >= ?", warehouseID, item.ProductID, item.Quantity).
+				Update("stock", gorm.Expr("stock - ?", item.Quantity))
+			if result.Error != nil {
+				tx.Rollback()
+				log.Errorf("[WarehouseProductRepository] DeductStocks - 5: %v", result.Error)
+				return nil, result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				tx.Rollback()
+				log.Errorf("[WarehouseProductRepository] DeductStocks - 6: product %d: %v", item.ProductID, ErrStockNotEnough)
+				return nil, fmt.Errorf("product %d: %w", item.ProductID, ErrStockNotEnough)
+			}
+
+			deduction.Items = append(deduction.Items, model.WarehouseStockDeductionItem{
+				DeductionID: deduction.ID,
+				ProductID:   item.ProductID,
+				Quantity:    item.Quantity,
+			})
+		}
+
+		if len(deduction.Items) > 0 {
+			if err := tx.Create(&deduction.Items).Error; err != nil {
+				tx.Rollback()
+				log.Errorf("[WarehouseProductRepository] DeductStocks - 9: %v", err)
+				return nil, err
+			}
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[WarehouseProductRepository] DeductStocks - 7: %v", err)
+			return nil, err
+		}
+
+		return &deduction, nil
+	}
+}
+
+// ApplyStockReturn implements WarehouseProductRepositoryInterface.
+// Mengembalikan jumlah baris yang benar-benar diterapkan; baris yang reference-nya sudah tercatat dilewati.
+// Produk yang belum ada di warehouse dibuat dengan stock 0 sebelum ditambahkan.
+func (w *warehouseProductRepository) ApplyStockReturn(ctx context.Context, warehouseID uint, merchantID uint, reference string, note string, items []model.StockReturnItem) (int, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseProductRepository] ApplyStockReturn - 1: %v", ctx.Err())
+		return 0, ctx.Err()
+	default:
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].ProductID < items[j].ProductID
+		})
+
+		tx := w.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[WarehouseProductRepository] ApplyStockReturn - 2: %v", tx.Error)
+			return 0, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[WarehouseProductRepository] ApplyStockReturn - 3: %v", r)
+			}
+		}()
+
+		applied := 0
+		for _, item := range items {
+			movementType := model.WarehouseMovementMerchantReturn
+			column := "stock"
+			if item.Condition == model.StockReturnConditionDamaged {
+				movementType = model.WarehouseMovementQuarantineIn
+				column = "quarantined_stock"
+			}
+
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.WarehouseStockMovement{
+				WarehouseID:  warehouseID,
+				ProductID:    item.ProductID,
+				MovementType: movementType,
+				Reference:    reference,
+				Quantity:     item.Quantity,
+				MerchantID:   merchantID,
+				Note:         note,
+			})
+			if result.Error != nil {
+				tx.Rollback()
+				log.Errorf("[WarehouseProductRepository] ApplyStockReturn - 4: %v", result.Error)
+				return 0, result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			result = tx.Model(&model.WarehouseProduct{}).
+				Where("warehouse_id = ? AND product_id = ?", warehouseID, item.ProductID).
+				Update(column, gorm.Expr(column+" + ?", item.Quantity))
+			if result.Error != nil {
+				tx.Rollback()
+				log.Errorf("[WarehouseProductRepository] ApplyStockReturn - 5: %v", result.Error)
+				return 0, result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				warehouseProduct := model.WarehouseProduct{
+					WarehouseID: warehouseID,
+					ProductID:   item.ProductID,
+				}
+				if item.Condition == model.StockReturnConditionDamaged {
+					warehouseProduct.QuarantinedStock = item.Quantity
+				} else {
+					warehouseProduct.Stock = item.Quantity
+				}
+
+				if err := tx.Create(&warehouseProduct).Error; err != nil {
+					tx.Rollback()
+					log.Errorf("[WarehouseProductRepository] ApplyStockReturn - 6: %v", err)
+					return 0, err
+				}
+			}
+
+			applied++
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[WarehouseProductRepository] ApplyStockReturn - 7: %v", err)
+			return 0, err
+		}
+
+		return applied, nil
+	}
+}
+
+// ApplyMerchantAllocation implements WarehouseProductRepositoryInterface.
+// Mengurangi stock untuk alokasi merchant; false berarti reference sudah pernah diterapkan sehingga tidak ada yang berubah.
+func (w *warehouseProductRepository) ApplyMerchantAllocation(ctx context.Context, warehouseID uint, merchantID uint, productID uint, quantity int, reference string) (bool, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseProductRepository] ApplyMerchantAllocation - 1: %v", ctx.Err())
+		return false, ctx.Err()
+	default:
+		tx := w.db.WithContext(ctx).Begin()
+		if tx.Error != nil {
+			log.Errorf("[WarehouseProductRepository] ApplyMerchantAllocation - 2: %v", tx.Error)
+			return false, tx.Error
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				log.Errorf("[WarehouseProductRepository] ApplyMerchantAllocation - 3: %v", r)
+			}
+		}()
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.WarehouseStockMovement{
+			WarehouseID:  warehouseID,
+			ProductID:    productID,
+			MovementType: model.WarehouseMovementMerchantAllocation,
+			Reference:    reference,
+			Quantity:     -quantity,
+			MerchantID:   merchantID,
+		})
+		if result.Error != nil {
+			tx.Rollback()
+			log.Errorf("[WarehouseProductRepository] ApplyMerchantAllocation - 4: %v", result.Error)
+			return false, result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return false, nil
+		}
+
+		result = tx.Model(&model.WarehouseProduct{}).
+			Where("warehouse_id = ? AND product_id = ? AND stock >= ?", warehouseID, productID, quantity).
+			Update("stock", gorm.Expr("stock - ?", quantity))
+		if result.Error != nil {
+			tx.Rollback()
+			log.Errorf("[WarehouseProductRepository] ApplyMerchantAllocation - 5: %v", result.Error)
+			return false, result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return false, fmt.Errorf("product %d: %w", productID, ErrStockNotEnough)
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Errorf("[WarehouseProductRepository] ApplyMerchantAllocation - 6: %v", err)
+			return false, err
+		}
+
+		return true, nil
+	}
+}
+
+// GetStockMovements implements WarehouseProductRepositoryInterface.
+func (w *warehouseProductRepository) GetStockMovements(ctx context.Context, warehouseID uint, productID uint, reference string, page int, limit int) ([]model.WarehouseStockMovement, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseProductRepository] GetStockMovements - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		var totalRecords int64
+		movements := []model.WarehouseStockMovement{}
+
+		query := w.db.WithContext(ctx).Model(&model.WarehouseStockMovement{}).
+			Where("warehouse_id = ?", warehouseID)
+
+		if productID != 0 {
+			query = query.Where("product_id = ?", productID)
+		}
+
+		if reference != "" {
+			query = query.Where("reference = ?", reference)
+		}
+
+		if err := query.Count(&totalRecords).Error; err != nil {
+			log.Errorf("[WarehouseProductRepository] GetStockMovements - 2: %v", err)
+			return nil, 0, err
+		}
+
+		offset := (page - 1) * limit
+		if err := query.Order("created_at DESC, id DESC").
+			Offset(offset).
+			Limit(limit).
+			Find(&movements).Error; err != nil {
+			log.Errorf("[WarehouseProductRepository] GetStockMovements - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return movements, totalRecords, nil
+	}
+}
+
+// GetDeletedWarehouseProducts implements WarehouseProductRepositoryInterface.
+// warehouseID dan productID bernilai 0 berarti tidak difilter.
+func (w *warehouseProductRepository) GetDeletedWarehouseProducts(ctx context.Context, warehouseID, productID uint, page, limit int) ([]model.WarehouseProduct, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseProductRepository] GetDeletedWarehouseProducts - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		if page <= 0 {
+			page = 1
+		}
+		if limit <= 0 {
+			limit = 10
+		}
+
+		query := w.db.WithContext(ctx).Unscoped().Model(&model.WarehouseProduct{}).Where("deleted_at IS NOT NULL")
+		if warehouseID != 0 {
+			query = query.Where("warehouse_id = ?", warehouseID)
+		}
+		if productID != 0 {
+			query = query.Where("product_id = ?", productID)
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			log.Errorf("[WarehouseProductRepository] GetDeletedWarehouseProducts - 2: %v", err)
+			return nil, 0, err
+		}
+
+		warehouseProducts := []model.WarehouseProduct{}
+		if err := query.Order("deleted_at desc").Offset((page - 1) * limit).Limit(limit).Find(&warehouseProducts).Error; err != nil {
+			log.Errorf("[WarehouseProductRepository] GetDeletedWarehouseProducts - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return warehouseProducts, total, nil
+	}
+}
+
+// RestoreWarehouseProduct implements WarehouseProductRepositoryInterface.
+// Ditolak jika warehouse-nya masih di trash atau product yang sama sudah didaftarkan ulang di warehouse itu.
+func (w *warehouseProductRepository) RestoreWarehouseProduct(ctx context.Context, warehouseProductID uint) (*model.WarehouseProduct, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseProductRepository] RestoreWarehouseProduct - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		warehouseProduct := model.WarehouseProduct{}
+		err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", warehouseProductID).First(&warehouseProduct).Error; err != nil {
+				log.Errorf("[WarehouseProductRepository] RestoreWarehouseProduct - 2: %v", err)
+				return err
+			}
+
+			var activeWarehouses int64
+			if err := tx.Model(&model.Warehouse{}).Where("id = ?", warehouseProduct.WarehouseID).Count(&activeWarehouses).Error; err != nil {
+				log.Errorf("[WarehouseProductRepository] RestoreWarehouseProduct - 3: %v", err)
+				return err
+			}
+			if activeWarehouses == 0 {
+				return ErrWarehouseInTrash
+			}
+
+			var duplicates int64
+			if err := tx.Model(&model.WarehouseProduct{}).
+				Where("warehouse_id = ? AND product_id = ?", warehouseProduct.WarehouseID, warehouseProduct.ProductID).
+				Count(&duplicates).Error; err != nil {
+				log.Errorf("[WarehouseProductRepository] RestoreWarehouseProduct - 4: %v", err)
+				return err
+			}
+			if duplicates > 0 {
+				return ErrWarehouseProductExists
+			}
+
+			return tx.Unscoped().Model(&warehouseProduct).Update("deleted_at", nil).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &warehouseProduct, nil
+	}
+}
+
+// PurgeDeletedWarehouseProducts implements WarehouseProductRepositoryInterface.
+// Movement stock tidak ikut dihapus karena menjadi riwayat warehouse.
+func (w *warehouseProductRepository) PurgeDeletedWarehouseProducts(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseProductRepository] PurgeDeletedWarehouseProducts - 1: %v", ctx.Err())
+		return 0, ctx.Err()
+	default:
+		result := w.db.WithContext(ctx).Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Delete(&model.WarehouseProduct{})
+		if result.Error != nil {
+			log.Errorf("[WarehouseProductRepository] PurgeDeletedWarehouseProducts - 2: %v", result.Error)
+			return 0, result.Error
+		}
+
+		return result.RowsAffected, nil
+	}
+}
+
 func NewWarehouseProductRepository(db *gorm.DB) WarehouseProductRepositoryInterface {
 	return &warehouseProductRepository{db: db}
 }
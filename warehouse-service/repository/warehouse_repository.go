@@ -2,8 +2,8 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"micro-warehouse/warehouse-service/model"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
@@ -11,10 +11,16 @@ import (
 
 type WarehouseRepositoryInterface interface {
 	CreateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
-	GetAllWarehouses(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Warehouse, int64, error)
+	GetAllWarehouses(ctx context.Context, page, limit int, search, sortBy, sortOrder string, archived bool) ([]model.Warehouse, int64, error)
 	GetWarehouseByID(ctx context.Context, id uint) (*model.Warehouse, error)
 	UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
 	DeleteWarehouse(ctx context.Context, id uint) error
+	ArchiveWarehouse(ctx context.Context, id uint, archivedAt time.Time) error
+	UnarchiveWarehouse(ctx context.Context, id uint) error
+	GetDeletedWarehouses(ctx context.Context, page, limit int, search string) ([]model.Warehouse, int64, error)
+	RestoreWarehouse(ctx context.Context, id uint) error
+	PurgeDeletedWarehouses(ctx context.Context, deletedBefore time.Time) (int64, error)
+	GetWarehouseLocations(ctx context.Context) ([]model.Warehouse, error)
 }
 
 type warehouseRepository struct {
@@ -33,29 +39,68 @@ func (w *warehouseRepository) CreateWarehouse(ctx context.Context, warehouse *mo
 }
 
 // DeleteWarehouse implements WarehouseRepositoryInterface.
+// Stock dan alokasi merchant dicek usecase sebelum repository dipanggil.
 func (w *warehouseRepository) DeleteWarehouse(ctx context.Context, id uint) error {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[WarehouseRepository] DeleteWarehouse - 1: %v", ctx.Err())
 		return ctx.Err()
 	default:
-		modelWarehouse := model.Warehouse{}
-		if err := w.db.WithContext(ctx).Where("id = ?", id).Preload("WarehouseProducts").First(&modelWarehouse).Error; err != nil {
-			log.Errorf("[WarehouseRepository] DeleteWarehouse - 2: %v", err)
-			return err
+		result := w.db.WithContext(ctx).Delete(&model.Warehouse{}, id)
+		if result.Error != nil {
+			log.Errorf("[WarehouseRepository] DeleteWarehouse - 2: %v", result.Error)
+			return result.Error
 		}
 
-		if len(modelWarehouse.WarehouseProducts) > 0 {
-			log.Errorf("[WarehouseRepository] DeleteWarehouse - 3: %v", errors.New("warehouse has products"))
-			return errors.New("warehouse has products")
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
 		}
 
-		return w.db.WithContext(ctx).Delete(&modelWarehouse).Error
+		return nil
+	}
+}
+
+// ArchiveWarehouse implements WarehouseRepositoryInterface.
+func (w *warehouseRepository) ArchiveWarehouse(ctx context.Context, id uint, archivedAt time.Time) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseRepository] ArchiveWarehouse - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		return w.updateArchivedAt(ctx, id, archivedAt)
+	}
+}
+
+// UnarchiveWarehouse implements WarehouseRepositoryInterface.
+func (w *warehouseRepository) UnarchiveWarehouse(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseRepository] UnarchiveWarehouse - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		return w.updateArchivedAt(ctx, id, nil)
 	}
 }
 
+func (w *warehouseRepository) updateArchivedAt(ctx context.Context, id uint, archivedAt interface{}) error {
+	result := w.db.WithContext(ctx).Model(&model.Warehouse{}).
+		Where("id = ?", id).
+		Update("archived_at", archivedAt)
+	if result.Error != nil {
+		log.Errorf("[WarehouseRepository] updateArchivedAt - 1: %v", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
 // GetAllWarehouses implements WarehouseRepositoryInterface.
-func (w *warehouseRepository) GetAllWarehouses(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Warehouse, int64, error) {
+// archived true hanya mengembalikan warehouse arsip; selain itu warehouse arsip tidak ikut.
+func (w *warehouseRepository) GetAllWarehouses(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, archived bool) ([]model.Warehouse, int64, error) {
 	select {
 	case <-ctx.Done():
 		log.Errorf("[WarehouseRepository] GetAllWarehouses - 1: %v", ctx.Err())
@@ -78,6 +123,12 @@ func (w *warehouseRepository) GetAllWarehouses(ctx context.Context, page int, li
 
 		query := w.db.Model(&model.Warehouse{})
 
+		if archived {
+			query = query.Where("archived_at IS NOT NULL")
+		} else {
+			query = query.Where("archived_at IS NULL")
+		}
+
 		if search != "" {
 			query = query.Where("name ILIKE ? OR address ILIKE ? OR phone ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 		}
@@ -138,11 +189,119 @@ func (w *warehouseRepository) UpdateWarehouse(ctx context.Context, warehouse *mo
 		existingWarehouse.Address = warehouse.Address
 		existingWarehouse.Phone = warehouse.Phone
 		existingWarehouse.Photo = warehouse.Photo
+		existingWarehouse.Latitude = warehouse.Latitude
+		existingWarehouse.Longitude = warehouse.Longitude
 
 		return w.db.WithContext(ctx).Save(&existingWarehouse).Error
 	}
 }
 
+// GetWarehouseLocations implements WarehouseRepositoryInterface.
+// Hanya warehouse aktif yang sudah punya koordinat; warehouse arsip tidak ikut.
+func (w *warehouseRepository) GetWarehouseLocations(ctx context.Context) ([]model.Warehouse, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseRepository] GetWarehouseLocations - 1: %v", ctx.Err())
+		return nil, ctx.Err()
+	default:
+		var warehouses []model.Warehouse
+		if err := w.db.WithContext(ctx).
+			Where("archived_at IS NULL AND latitude IS NOT NULL AND longitude IS NOT NULL").
+			Order("id asc").
+			Find(&warehouses).Error; err != nil {
+			log.Errorf("[WarehouseRepository] GetWarehouseLocations - 2: %v", err)
+			return nil, err
+		}
+
+		return warehouses, nil
+	}
+}
+
+// GetDeletedWarehouses implements WarehouseRepositoryInterface.
+// Isi trash diurutkan dari yang terakhir dihapus.
+func (w *warehouseRepository) GetDeletedWarehouses(ctx context.Context, page, limit int, search string) ([]model.Warehouse, int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseRepository] GetDeletedWarehouses - 1: %v", ctx.Err())
+		return nil, 0, ctx.Err()
+	default:
+		if page <= 0 {
+			page = 1
+		}
+		if limit <= 0 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		query := w.db.WithContext(ctx).Unscoped().Model(&model.Warehouse{}).Where("deleted_at IS NOT NULL")
+
+		if search != "" {
+			query = query.Where("name ILIKE ? OR address ILIKE ?", "%"+search+"%", "%"+search+"%")
+		}
+
+		var warehouses []model.Warehouse
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			log.Errorf("[WarehouseRepository] GetDeletedWarehouses - 2: %v", err)
+			return nil, 0, err
+		}
+
+		if err := query.Order("deleted_at desc").Offset(offset).Limit(limit).Find(&warehouses).Error; err != nil {
+			log.Errorf("[WarehouseRepository] GetDeletedWarehouses - 3: %v", err)
+			return nil, 0, err
+		}
+
+		return warehouses, total, nil
+	}
+}
+
+// RestoreWarehouse implements WarehouseRepositoryInterface.
+// Mengembalikan gorm.ErrRecordNotFound jika warehouse tidak ada di trash.
+func (w *warehouseRepository) RestoreWarehouse(ctx context.Context, id uint) error {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseRepository] RestoreWarehouse - 1: %v", ctx.Err())
+		return ctx.Err()
+	default:
+		result := w.db.WithContext(ctx).Unscoped().Model(&model.Warehouse{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			log.Errorf("[WarehouseRepository] RestoreWarehouse - 2: %v", result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	}
+}
+
+// PurgeDeletedWarehouses implements WarehouseRepositoryInterface.
+// Warehouse yang masih direferensikan warehouse product (termasuk yang di trash) dilewati sampai
+// warehouse product tersebut ikut di-purge.
+func (w *warehouseRepository) PurgeDeletedWarehouses(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	select {
+	case <-ctx.Done():
+		log.Errorf("[WarehouseRepository] PurgeDeletedWarehouses - 1: %v", ctx.Err())
+		return 0, ctx.Err()
+	default:
+		result := w.db.WithContext(ctx).Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Where("NOT EXISTS (SELECT 1 FROM warehouse_products WHERE warehouse_products.warehouse_id = warehouses.id)").
+			Delete(&model.Warehouse{})
+		if result.Error != nil {
+			log.Errorf("[WarehouseRepository] PurgeDeletedWarehouses - 2: %v", result.Error)
+			return 0, result.Error
+		}
+
+		return result.RowsAffected, nil
+	}
+}
+
 func NewWarehouseRepository(db *gorm.DB) WarehouseRepositoryInterface {
 	return &warehouseRepository{db: db}
 }
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Jenis alasan warehouse belum boleh dihapus
+const (
+	DeletionBlockerWarehouseStock      = "warehouse_stock"
+	DeletionBlockerMerchantAllocations = "merchant_allocations"
+)
+
+var ErrDeletionBlocked = errors.New("deletion blocked")
+
+// DeletionBlocker adalah satu alasan entity belum boleh dihapus; Quantity diisi untuk blocker berbasis stock
+type DeletionBlocker struct {
+	Type     string
+	Count    int64
+	Quantity int64
+	Message  string
+}
+
+// DeletionBlockedError membawa seluruh blocker sehingga pemanggil bisa membereskan semuanya sekaligus
+type DeletionBlockedError struct {
+	Entity   string
+	ID       uint
+	Blockers []DeletionBlocker
+}
+
+func (e *DeletionBlockedError) Error() string {
+	types := make([]string, 0, len(e.Blockers))
+	for _, blocker := range e.Blockers {
+		types = append(types, blocker.Type)
+	}
+
+	return fmt.Sprintf("%s %d cannot be deleted: %s", e.Entity, e.ID, strings.Join(types, ", "))
+}
+
+func (e *DeletionBlockedError) Unwrap() error {
+	return ErrDeletionBlocked
+}
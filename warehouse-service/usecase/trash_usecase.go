@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"micro-warehouse/warehouse-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type TrashUsecaseInterface interface {
+	PurgeDeleted(ctx context.Context, retention time.Duration) (*TrashPurgeResult, error)
+}
+
+// TrashPurgeResult adalah jumlah baris yang dihapus permanen per jenis entity
+type TrashPurgeResult struct {
+	WarehouseProducts int64
+	Warehouses        int64
+}
+
+type trashUsecase struct {
+	warehouseRepo        repository.WarehouseRepositoryInterface
+	warehouseProductRepo repository.WarehouseProductRepositoryInterface
+}
+
+// PurgeDeleted implements TrashUsecaseInterface.
+// Warehouse product di-purge lebih dulu supaya warehouse yang seluruh product-nya sudah kedaluwarsa ikut terhapus.
+func (t *trashUsecase) PurgeDeleted(ctx context.Context, retention time.Duration) (*TrashPurgeResult, error) {
+	deletedBefore := time.Now().Add(-retention)
+	result := &TrashPurgeResult{}
+
+	warehouseProducts, err := t.warehouseProductRepo.PurgeDeletedWarehouseProducts(ctx, deletedBefore)
+	if err != nil {
+		log.Errorf("[TrashUsecase] PurgeDeleted - 1: %v", err)
+		return result, err
+	}
+	result.WarehouseProducts = warehouseProducts
+
+	warehouses, err := t.warehouseRepo.PurgeDeletedWarehouses(ctx, deletedBefore)
+	if err != nil {
+		log.Errorf("[TrashUsecase] PurgeDeleted - 2: %v", err)
+		return result, err
+	}
+	result.Warehouses = warehouses
+
+	return result, nil
+}
+
+func NewTrashUsecase(warehouseRepo repository.WarehouseRepositoryInterface, warehouseProductRepo repository.WarehouseProductRepositoryInterface) TrashUsecaseInterface {
+	return &trashUsecase{warehouseRepo: warehouseRepo, warehouseProductRepo: warehouseProductRepo}
+}
@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"micro-warehouse/warehouse-service/model"
 	"micro-warehouse/warehouse-service/pkg/httpclient"
+	"micro-warehouse/warehouse-service/pkg/rabbitmq"
 	"micro-warehouse/warehouse-service/repository"
 
 	"github.com/gofiber/fiber/v2/log"
@@ -20,11 +22,16 @@ type WarehouseProductUsecaseInterface interface {
 	DeleteAllWarehouseProductByProductID(ctx context.Context, productID uint) error
 	GetWarehouseProductByProductID(ctx context.Context, productID uint) ([]model.WarehouseProduct, error)
 	GetProductTotalStock(ctx context.Context, productID uint) (int, error)
+	DeductStocks(ctx context.Context, warehouseID uint, reference string, items []model.StockDeductionItem) (*model.WarehouseStockDeduction, bool, error)
+	GetStockMovements(ctx context.Context, warehouseID, productID uint, reference string, page, limit int) ([]model.WarehouseStockMovement, int64, error)
+	GetDeletedWarehouseProducts(ctx context.Context, warehouseID, productID uint, page, limit int) ([]model.WarehouseProduct, int64, error)
+	RestoreWarehouseProduct(ctx context.Context, warehouseProductID uint) error
 }
 
 type warehouseProductUsecase struct {
 	warehouseProductRepo repository.WarehouseProductRepositoryInterface
 	productClient        httpclient.ProductClientInterface
+	eventPublisher       rabbitmq.EventPublisherInterface
 }
 
 // CreateWarehouseProduct implements WarehouseProductUsecaseInterface.
@@ -39,20 +46,54 @@ func (w *warehouseProductUsecase) CreateWarehouseProduct(ctx context.Context, wa
 
 	if result != nil {
 		warehouseProduct.ID = result.ID
-		return w.warehouseProductRepo.UpdateWarehouseProduct(ctx, warehouseProduct)
+		err = w.warehouseProductRepo.UpdateWarehouseProduct(ctx, warehouseProduct)
+	} else {
+		err = w.warehouseProductRepo.CreateWarehouseProduct(ctx, warehouseProduct)
 	}
 
-	return w.warehouseProductRepo.CreateWarehouseProduct(ctx, warehouseProduct)
+	if err != nil {
+		log.Errorf("[WarehouseProductUsecase] CreateWarehouseProduct - 2: %v", err)
+		return err
+	}
+
+	w.publishStockChanged(ctx, warehouseProduct.WarehouseID, warehouseProduct.ProductID)
+	return nil
 }
 
 // DeleteAllWarehouseProductByProductID implements WarehouseProductUsecaseInterface.
 func (w *warehouseProductUsecase) DeleteAllWarehouseProductByProductID(ctx context.Context, productID uint) error {
-	return w.warehouseProductRepo.DeleteAllWarehouseProductByProductID(ctx, productID)
+	warehouseProducts, err := w.warehouseProductRepo.GetWarehouseProductByProductID(ctx, productID)
+	if err != nil {
+		log.Errorf("[WarehouseProductUsecase] DeleteAllWarehouseProductByProductID - 1: %v", err)
+		return err
+	}
+
+	if err := w.warehouseProductRepo.DeleteAllWarehouseProductByProductID(ctx, productID); err != nil {
+		log.Errorf("[WarehouseProductUsecase] DeleteAllWarehouseProductByProductID - 2: %v", err)
+		return err
+	}
+
+	for _, warehouseProduct := range warehouseProducts {
+		w.publishStockChanged(ctx, warehouseProduct.WarehouseID, productID)
+	}
+	return nil
 }
 
 // DeleteWarehouseProduct implements WarehouseProductUsecaseInterface.
 func (w *warehouseProductUsecase) DeleteWarehouseProduct(ctx context.Context, warehouseProductID uint) error {
-	return w.warehouseProductRepo.DeleteWarehouseProduct(ctx, warehouseProductID)
+	warehouseProduct, err := w.warehouseProductRepo.GetDetailWarehouseProductByID(ctx, warehouseProductID)
+	if err != nil {
+		log.Errorf("[WarehouseProductUsecase] DeleteWarehouseProduct - 1: %v", err)
+		return err
+	}
+
+	if err := w.warehouseProductRepo.DeleteWarehouseProduct(ctx, warehouseProductID); err != nil {
+		log.Errorf("[WarehouseProductUsecase] DeleteWarehouseProduct - 2: %v", err)
+		return err
+	}
+
+	w.publishStockChanged(ctx, warehouseProduct.WarehouseID, warehouseProduct.ProductID)
+	return nil
 }
 
 // GetDetailWarehouse implements WarehouseProductUsecaseInterface.
@@ -114,9 +155,77 @@ func (w *warehouseProductUsecase) GetWarehouseProductByWarehouseIDAndProductID(c
 
 // UpdateWarehouseProduct implements WarehouseProductUsecaseInterface.
 func (w *warehouseProductUsecase) UpdateWarehouseProduct(ctx context.Context, warehouseProduct *model.WarehouseProduct) error {
-	return w.warehouseProductRepo.UpdateWarehouseProduct(ctx, warehouseProduct)
+	if err := w.warehouseProductRepo.UpdateWarehouseProduct(ctx, warehouseProduct); err != nil {
+		log.Errorf("[WarehouseProductUsecase] UpdateWarehouseProduct - 1: %v", err)
+		return err
+	}
+
+	w.publishStockChanged(ctx, warehouseProduct.WarehouseID, warehouseProduct.ProductID)
+	return nil
+}
+
+// DeductStocks implements WarehouseProductUsecaseInterface.
+// Mengembalikan false tanpa error jika reference sudah pernah diproses (request diulang); deduction yang
+// dikembalikan tetap berisi jumlah yang dikurangi pada request pertama, bukan items dari request ulangan.
+func (w *warehouseProductUsecase) DeductStocks(ctx context.Context, warehouseID uint, reference string, items []model.StockDeductionItem) (*model.WarehouseStockDeduction, bool, error) {
+	deduction, err := w.warehouseProductRepo.DeductStocks(ctx, warehouseID, reference, items)
+	if err != nil {
+		if errors.Is(err, repository.ErrStockDeductionAlreadyApplied) {
+			log.Infof("[WarehouseProductUsecase] DeductStocks - reference %s already applied", reference)
+			return deduction, false, nil
+		}
+
+		log.Errorf("[WarehouseProductUsecase] DeductStocks - 1: %v", err)
+		return nil, false, err
+	}
+
+	productIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	w.publishStockChanged(ctx, warehouseID, productIDs...)
+
+	return deduction, true, nil
+}
+
+// GetStockMovements implements WarehouseProductUsecaseInterface.
+func (w *warehouseProductUsecase) GetStockMovements(ctx context.Context, warehouseID uint, productID uint, reference string, page int, limit int) ([]model.WarehouseStockMovement, int64, error) {
+	movements, total, err := w.warehouseProductRepo.GetStockMovements(ctx, warehouseID, productID, reference, page, limit)
+	if err != nil {
+		log.Errorf("[WarehouseProductUsecase] GetStockMovements - 1: %v", err)
+		return nil, 0, err
+	}
+
+	return movements, total, nil
+}
+
+// GetDeletedWarehouseProducts implements WarehouseProductUsecaseInterface.
+func (w *warehouseProductUsecase) GetDeletedWarehouseProducts(ctx context.Context, warehouseID, productID uint, page, limit int) ([]model.WarehouseProduct, int64, error) {
+	return w.warehouseProductRepo.GetDeletedWarehouseProducts(ctx, warehouseID, productID, page, limit)
+}
+
+// RestoreWarehouseProduct implements WarehouseProductUsecaseInterface.
+// Stock yang tercatat saat dihapus ikut kembali, sehingga cache stock di service lain perlu dihapus.
+func (w *warehouseProductUsecase) RestoreWarehouseProduct(ctx context.Context, warehouseProductID uint) error {
+	warehouseProduct, err := w.warehouseProductRepo.RestoreWarehouseProduct(ctx, warehouseProductID)
+	if err != nil {
+		log.Errorf("[WarehouseProductUsecase] RestoreWarehouseProduct - 1: %v", err)
+		return err
+	}
+
+	w.publishStockChanged(ctx, warehouseProduct.WarehouseID, warehouseProduct.ProductID)
+	return nil
+}
+
+func (w *warehouseProductUsecase) publishStockChanged(ctx context.Context, warehouseID uint, productIDs ...uint) {
+	publishEntityChanged(ctx, w.eventPublisher, rabbitmq.EntityChangedEvent{
+		Entity:     rabbitmq.EntityWarehouse,
+		Action:     rabbitmq.EntityActionStockChanged,
+		ID:         warehouseID,
+		ProductIDs: productIDs,
+	})
 }
 
-func NewWarehouseProductUsecase(warehouseProductRepo repository.WarehouseProductRepositoryInterface, productClient httpclient.ProductClientInterface) WarehouseProductUsecaseInterface {
-	return &warehouseProductUsecase{warehouseProductRepo: warehouseProductRepo, productClient: productClient}
+func NewWarehouseProductUsecase(warehouseProductRepo repository.WarehouseProductRepositoryInterface, productClient httpclient.ProductClientInterface, eventPublisher rabbitmq.EventPublisherInterface) WarehouseProductUsecaseInterface {
+	return &warehouseProductUsecase{warehouseProductRepo: warehouseProductRepo, productClient: productClient, eventPublisher: eventPublisher}
 }
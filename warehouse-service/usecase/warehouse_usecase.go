@@ -2,20 +2,33 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"micro-warehouse/warehouse-service/model"
+	"micro-warehouse/warehouse-service/pkg/httpclient"
+	"micro-warehouse/warehouse-service/pkg/rabbitmq"
 	"micro-warehouse/warehouse-service/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
 )
 
 type WarehouseUsecaseInterface interface {
 	CreateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
-	GetAllWarehouses(ctx context.Context, page, limit int, search, sortBy, sortOrder string) ([]model.Warehouse, int64, error)
+	GetAllWarehouses(ctx context.Context, page, limit int, search, sortBy, sortOrder string, archived bool) ([]model.Warehouse, int64, error)
 	GetWarehouseByID(ctx context.Context, id uint) (*model.Warehouse, error)
 	UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
 	DeleteWarehouse(ctx context.Context, id uint) error
+	ArchiveWarehouse(ctx context.Context, id uint) error
+	UnarchiveWarehouse(ctx context.Context, id uint) error
+	GetDeletedWarehouses(ctx context.Context, page, limit int, search string) ([]model.Warehouse, int64, error)
+	RestoreWarehouse(ctx context.Context, id uint) error
+	GetWarehouseLocations(ctx context.Context) ([]model.Warehouse, error)
 }
 
 type warehouseUsecase struct {
-	warehouseRepo repository.WarehouseRepositoryInterface
+	warehouseRepo  repository.WarehouseRepositoryInterface
+	merchantClient httpclient.MerchantClientInterface
+	eventPublisher rabbitmq.EventPublisherInterface
 }
 
 // CreateWarehouse implements WarehouseUsecaseInterface.
@@ -24,13 +37,131 @@ func (w *warehouseUsecase) CreateWarehouse(ctx context.Context, warehouse *model
 }
 
 // DeleteWarehouse implements WarehouseUsecaseInterface.
+// Warehouse yang masih menyimpan product atau masih menyuplai merchant ditolak dengan DeletionBlockedError.
+// Product yang tersimpan di warehouse ikut dikirim agar cache stock-nya di service lain ikut dihapus.
 func (w *warehouseUsecase) DeleteWarehouse(ctx context.Context, id uint) error {
-	return w.warehouseRepo.DeleteWarehouse(ctx, id)
+	warehouse, err := w.warehouseRepo.GetWarehouseByID(ctx, id)
+	if err != nil {
+		log.Errorf("[WarehouseUsecase] DeleteWarehouse - 1: %v", err)
+		return err
+	}
+
+	blockers, err := w.deletionBlockers(ctx, warehouse)
+	if err != nil {
+		log.Errorf("[WarehouseUsecase] DeleteWarehouse - 2: %v", err)
+		return err
+	}
+
+	if len(blockers) > 0 {
+		return &DeletionBlockedError{Entity: "warehouse", ID: id, Blockers: blockers}
+	}
+
+	if err := w.warehouseRepo.DeleteWarehouse(ctx, id); err != nil {
+		log.Errorf("[WarehouseUsecase] DeleteWarehouse - 3: %v", err)
+		return err
+	}
+
+	productIDs := make([]uint, 0, len(warehouse.WarehouseProducts))
+	for _, warehouseProduct := range warehouse.WarehouseProducts {
+		productIDs = append(productIDs, warehouseProduct.ProductID)
+	}
+
+	publishEntityChanged(ctx, w.eventPublisher, rabbitmq.EntityChangedEvent{
+		Entity:     rabbitmq.EntityWarehouse,
+		Action:     rabbitmq.EntityActionDeleted,
+		ID:         id,
+		ProductIDs: productIDs,
+	})
+	return nil
+}
+
+// deletionBlockers mengecek product yang masih tercatat di warehouse dan alokasi merchant di merchant-service.
+// Kegagalan memanggil merchant-service menggagalkan penghapusan supaya merchant product tidak kehilangan supplier.
+func (w *warehouseUsecase) deletionBlockers(ctx context.Context, warehouse *model.Warehouse) ([]DeletionBlocker, error) {
+	blockers := []DeletionBlocker{}
+
+	if len(warehouse.WarehouseProducts) > 0 {
+		var quantity int64
+		for _, warehouseProduct := range warehouse.WarehouseProducts {
+			quantity += int64(warehouseProduct.Stock + warehouseProduct.QuarantinedStock)
+		}
+
+		blockers = append(blockers, DeletionBlocker{
+			Type:     DeletionBlockerWarehouseStock,
+			Count:    int64(len(warehouse.WarehouseProducts)),
+			Quantity: quantity,
+			Message:  fmt.Sprintf("%d products are still registered with %d units in stock", len(warehouse.WarehouseProducts), quantity),
+		})
+	}
+
+	allocations, err := w.merchantClient.GetWarehouseAllocations(ctx, warehouse.ID)
+	if err != nil {
+		log.Errorf("[WarehouseUsecase] deletionBlockers - 1: %v", err)
+		return nil, err
+	}
+
+	if allocations.MerchantProductCount > 0 {
+		blockers = append(blockers, DeletionBlocker{
+			Type:     DeletionBlockerMerchantAllocations,
+			Count:    allocations.MerchantCount,
+			Quantity: allocations.TotalStock,
+			Message:  fmt.Sprintf("%d merchants still source %d products from this warehouse", allocations.MerchantCount, allocations.MerchantProductCount),
+		})
+	}
+
+	return blockers, nil
+}
+
+// ArchiveWarehouse implements WarehouseUsecaseInterface.
+// Arsip tidak dicek blocker: stock, movement dan alokasi merchant tetap tersimpan dan bisa dibaca lewat ID warehouse.
+func (w *warehouseUsecase) ArchiveWarehouse(ctx context.Context, id uint) error {
+	if err := w.warehouseRepo.ArchiveWarehouse(ctx, id, time.Now()); err != nil {
+		log.Errorf("[WarehouseUsecase] ArchiveWarehouse - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UnarchiveWarehouse implements WarehouseUsecaseInterface.
+func (w *warehouseUsecase) UnarchiveWarehouse(ctx context.Context, id uint) error {
+	if err := w.warehouseRepo.UnarchiveWarehouse(ctx, id); err != nil {
+		log.Errorf("[WarehouseUsecase] UnarchiveWarehouse - 1: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetWarehouseLocations implements WarehouseUsecaseInterface.
+func (w *warehouseUsecase) GetWarehouseLocations(ctx context.Context) ([]model.Warehouse, error) {
+	return w.warehouseRepo.GetWarehouseLocations(ctx)
+}
+
+// GetDeletedWarehouses implements WarehouseUsecaseInterface.
+func (w *warehouseUsecase) GetDeletedWarehouses(ctx context.Context, page, limit int, search string) ([]model.Warehouse, int64, error) {
+	return w.warehouseRepo.GetDeletedWarehouses(ctx, page, limit, search)
+}
+
+// RestoreWarehouse implements WarehouseUsecaseInterface.
+// Event updated dikirim supaya lookup warehouse yang sempat di-cache sebagai tidak ditemukan ikut dihapus.
+func (w *warehouseUsecase) RestoreWarehouse(ctx context.Context, id uint) error {
+	if err := w.warehouseRepo.RestoreWarehouse(ctx, id); err != nil {
+		log.Errorf("[WarehouseUsecase] RestoreWarehouse - 1: %v", err)
+		return err
+	}
+
+	publishEntityChanged(ctx, w.eventPublisher, rabbitmq.EntityChangedEvent{
+		Entity: rabbitmq.EntityWarehouse,
+		Action: rabbitmq.EntityActionUpdated,
+		ID:     id,
+	})
+	return nil
 }
 
 // GetAllWarehouses implements WarehouseUsecaseInterface.
-func (w *warehouseUsecase) GetAllWarehouses(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string) ([]model.Warehouse, int64, error) {
-	return w.warehouseRepo.GetAllWarehouses(ctx, page, limit, search, sortBy, sortOrder)
+func (w *warehouseUsecase) GetAllWarehouses(ctx context.Context, page int, limit int, search string, sortBy string, sortOrder string, archived bool) ([]model.Warehouse, int64, error) {
+	return w.warehouseRepo.GetAllWarehouses(ctx, page, limit, search, sortBy, sortOrder, archived)
 }
 
 // GetWarehouseByID implements WarehouseUsecaseInterface.
@@ -40,9 +171,27 @@ func (w *warehouseUsecase) GetWarehouseByID(ctx context.Context, id uint) (*mode
 
 // UpdateWarehouse implements WarehouseUsecaseInterface.
 func (w *warehouseUsecase) UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
-	return w.warehouseRepo.UpdateWarehouse(ctx, warehouse)
+	if err := w.warehouseRepo.UpdateWarehouse(ctx, warehouse); err != nil {
+		log.Errorf("[WarehouseUsecase] UpdateWarehouse - 1: %v", err)
+		return err
+	}
+
+	publishEntityChanged(ctx, w.eventPublisher, rabbitmq.EntityChangedEvent{
+		Entity: rabbitmq.EntityWarehouse,
+		Action: rabbitmq.EntityActionUpdated,
+		ID:     warehouse.ID,
+	})
+	return nil
+}
+
+// publishEntityChanged tidak menggagalkan perubahan yang sudah tersimpan; cache di service lain tetap
+// kedaluwarsa sesuai TTL jika event gagal dikirim
+func publishEntityChanged(ctx context.Context, publisher rabbitmq.EventPublisherInterface, event rabbitmq.EntityChangedEvent) {
+	if err := publisher.PublishEntityChanged(ctx, event); err != nil {
+		log.Errorf("[EntityEvents] publish %s.%s %d: %v", event.Entity, event.Action, event.ID, err)
+	}
 }
 
-func NewWarehouseUsecase(warehouseRepo repository.WarehouseRepositoryInterface) WarehouseUsecaseInterface {
-	return &warehouseUsecase{warehouseRepo: warehouseRepo}
+func NewWarehouseUsecase(warehouseRepo repository.WarehouseRepositoryInterface, merchantClient httpclient.MerchantClientInterface, eventPublisher rabbitmq.EventPublisherInterface) WarehouseUsecaseInterface {
+	return &warehouseUsecase{warehouseRepo: warehouseRepo, merchantClient: merchantClient, eventPublisher: eventPublisher}
 }